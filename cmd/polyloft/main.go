@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -13,7 +14,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/ArubikU/polyloft/internal/analysis"
+	"github.com/ArubikU/polyloft/internal/ast"
 	"github.com/ArubikU/polyloft/internal/auth"
 	"github.com/ArubikU/polyloft/internal/builder"
 	"github.com/ArubikU/polyloft/internal/config"
@@ -52,6 +56,12 @@ func main() {
 	case "run":
 		runCmd := flag.NewFlagSet("run", flag.ExitOnError)
 		configFile := runCmd.String("config", "polyloft.toml", "configuration file")
+		profileMode := runCmd.String("profile", "", "collect a profile while running: cpu or mem")
+		profileOut := runCmd.String("profile-out", "", "profile output file (default <mode>.pprof)")
+		coverage := runCmd.Bool("coverage", false, "report which lines executed")
+		sandbox := runCmd.Bool("sandbox", false, "run untrusted code without IO, Process, Net, Http or Sockets, within default limits")
+		deterministic := runCmd.Bool("deterministic", false, "iterate, print and serialize Maps and Sets in sorted key order")
+		warn := runCmd.Bool("warn", false, "report unused variables and unreachable code before running")
 		_ = runCmd.Parse(os.Args[2:])
 		
 		var file string
@@ -73,18 +83,173 @@ func main() {
 			file = runCmd.Arg(0)
 		}
 		
-		if err := runFile(file); err != nil {
+		if *warn {
+			warnFile(file)
+		}
+		opts := engine.Options{Stdout: os.Stdout, Stdin: os.Stdin, Args: scriptArgs(runCmd), Sandbox: *sandbox, Deterministic: *deterministic}
+		if *coverage {
+			opts.Coverage = engine.NewCoverage()
+		}
+		run := func() error { return runFileWithOptions(file, opts) }
+		if *profileMode != "" {
+			run = func() error { return profileRun(*profileMode, *profileOut, os.Stderr, func() error { return runFileWithOptions(file, opts) }) }
+		}
+		err := run()
+		if opts.Coverage != nil {
+			fmt.Fprintln(os.Stderr)
+			opts.Coverage.Report(os.Stderr)
+		}
+		if err != nil {
 			// Use the engine's error formatter for better output
 			formattedErr := engine.FormatError(err)
 			fmt.Fprint(os.Stderr, formattedErr)
 			os.Exit(1)
 		}
+	case "debug":
+		debugCmd := flag.NewFlagSet("debug", flag.ExitOnError)
+		breaks := debugCmd.String("b", "", "comma-separated breakpoints as file:line or line")
+		_ = debugCmd.Parse(os.Args[2:])
+		if debugCmd.NArg() < 1 {
+			fmt.Fprintln(os.Stderr, "usage: polyloft debug [-b file:line,...] <file.pf>")
+			os.Exit(1)
+		}
+
+		dbg := engine.NewDebugger(os.Stdin, os.Stdout)
+		if *breaks == "" {
+			// Without breakpoints, pause on the first statement
+			dbg.StepOnStart()
+		}
+		for _, spec := range strings.Split(*breaks, ",") {
+			if spec = strings.TrimSpace(spec); spec == "" {
+				continue
+			}
+			if err := dbg.AddBreakpoint(spec); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+
+		err := runFileWithOptions(debugCmd.Arg(0), engine.Options{Stdout: os.Stdout, Stdin: os.Stdin, Args: debugCmd.Args()[1:], Debugger: dbg})
+		if err != nil && !errors.Is(err, engine.ErrDebugQuit) {
+			fmt.Fprint(os.Stderr, engine.FormatError(err))
+			os.Exit(1)
+		}
+	case "bench":
+		benchCmd := flag.NewFlagSet("bench", flag.ExitOnError)
+		benchTime := benchCmd.Duration("time", 200*time.Millisecond, "target duration of each measured round")
+		rounds := benchCmd.Int("rounds", 5, "number of measured rounds")
+		filter := benchCmd.String("run", "", "only run benchmarks whose name contains this text")
+		_ = benchCmd.Parse(os.Args[2:])
+		if benchCmd.NArg() < 1 {
+			fmt.Fprintln(os.Stderr, "usage: polyloft bench [-time 200ms] [-rounds 5] [-run name] <file.pf>")
+			os.Exit(1)
+		}
+
+		if err := benchFile(benchCmd.Arg(0), engine.BenchConfig{Time: *benchTime, Rounds: *rounds, Filter: *filter}); err != nil {
+			fmt.Fprint(os.Stderr, engine.FormatError(err))
+			os.Exit(1)
+		}
+	case "test":
+		testCmd := flag.NewFlagSet("test", flag.ExitOnError)
+		filter := testCmd.String("run", "", "only run tests whose name contains this text")
+		updateSnapshots := testCmd.Bool("update-snapshots", false, "rewrite snapshots that no longer match instead of failing")
+		coverage := testCmd.Bool("coverage", false, "report which lines the tests executed")
+		_ = testCmd.Parse(os.Args[2:])
+
+		paths := testCmd.Args()
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		files, err := findTestFiles(paths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(files) == 0 {
+			fmt.Println("no *_test.pf files found")
+			return
+		}
+
+		opts := engine.Options{Stdout: os.Stdout, Stdin: os.Stdin}
+		if *coverage {
+			opts.Coverage = engine.NewCoverage()
+		}
+		failed := false
+		for _, file := range files {
+			if !testFile(file, opts, engine.TestConfig{Filter: *filter, UpdateSnapshots: *updateSnapshots}) {
+				failed = true
+			}
+		}
+		if opts.Coverage != nil {
+			fmt.Fprintln(os.Stderr)
+			opts.Coverage.Report(os.Stderr)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	case "lint":
+		lintCmd := flag.NewFlagSet("lint", flag.ExitOnError)
+		configFile := lintCmd.String("config", "polyloft.toml", "configuration file with a [lint.rules] table")
+		_ = lintCmd.Parse(os.Args[2:])
+
+		// Every rule is on unless polyloft.toml turns it off
+		var settings map[string]bool
+		cfg, err := config.Load(*configFile)
+		if err == nil {
+			settings = cfg.Lint.Rules
+		} else if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		rules, err := analysis.EnabledRules(settings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		paths := lintCmd.Args()
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		files, err := findSourceFiles(paths, ".pf", "libs")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		problems := 0
+		for _, file := range files {
+			problems += lintFile(file, rules)
+		}
+		if problems > 0 {
+			fmt.Fprintf(os.Stderr, "%d problem(s) in %d file(s) checked\n", problems, len(files))
+			os.Exit(1)
+		}
 	case "build":
 		buildCmd := flag.NewFlagSet("build", flag.ExitOnError)
 		out := buildCmd.String("o", "", "output artifact (defaults to project name)")
 		configFile := buildCmd.String("config", "polyloft.toml", "configuration file")
+		targetList := buildCmd.String("target", "", "comma-separated os/arch platforms to build for (e.g. linux/amd64,windows/amd64)")
 		_ = buildCmd.Parse(os.Args[2:])
 
+		// Parse targets before anything slow so a typo fails fast
+		var targets []builder.Target
+		for _, spec := range strings.Split(*targetList, ",") {
+			if strings.TrimSpace(spec) == "" {
+				continue
+			}
+			target, err := builder.ParseTarget(spec)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			targets = append(targets, target)
+		}
+		if len(targets) > 1 && *out != "" {
+			fmt.Fprintln(os.Stderr, "Error: -o cannot be used with more than one -target")
+			os.Exit(1)
+		}
+
 		// Load configuration
 		cfg, err := config.Load(*configFile)
 		if err != nil {
@@ -93,26 +258,39 @@ func main() {
 			os.Exit(1)
 		}
 
-		// Determine default output name if not provided
-		if *out == "" {
-			*out = defaultOutputName(cfg)
-		} else if runtime.GOOS == "windows" {
-			// Ensure Windows binaries have a runnable extension when none provided
-			if filepath.Ext(*out) == "" {
-				*out += ".pfx"
+		if len(targets) == 0 {
+			// Build for the host
+			targets = []builder.Target{{}}
+		}
+		for _, target := range targets {
+			output := *out
+			targetOS := target.OS
+			if targetOS == "" {
+				targetOS = runtime.GOOS
+			}
+			// Determine default output name if not provided
+			if output == "" {
+				output = defaultOutputName(cfg, target)
+			} else if targetOS == "windows" {
+				// Ensure Windows binaries have a runnable extension when none provided
+				if filepath.Ext(output) == "" {
+					output += ".pfx"
+				}
 			}
-		}
 
-		// Build the project
-		bldr := builder.New(cfg, *out)
-		if err := bldr.Build(); err != nil {
-			fmt.Fprintf(os.Stderr, "Build failed: %v\n", err)
-			os.Exit(1)
+			// Build the project
+			bldr := builder.New(cfg, output)
+			bldr.Target = target
+			if err := bldr.Build(); err != nil {
+				fmt.Fprintf(os.Stderr, "Build failed: %v\n", err)
+				os.Exit(1)
+			}
 		}
 	case "install":
 		installCmd := flag.NewFlagSet("install", flag.ExitOnError)
 		configFile := installCmd.String("config", "polyloft.toml", "configuration file")
 		globalMode := installCmd.Bool("g", false, "install packages globally")
+		update := installCmd.Bool("update", false, "ignore polyloft.lock and resolve packages again")
 		_ = installCmd.Parse(os.Args[2:])
 
 		// Check if specific packages are provided as arguments
@@ -135,6 +313,7 @@ func main() {
 			
 			inst := installer.New(cfg)
 			inst.SetGlobalMode(*globalMode)
+			inst.SetUpdateMode(*update)
 			if err := inst.InstallPackages(packages); err != nil {
 				fmt.Fprintf(os.Stderr, "Install failed: %v\n", err)
 				os.Exit(1)
@@ -151,6 +330,7 @@ func main() {
 			// Install dependencies
 			inst := installer.New(cfg)
 			inst.SetGlobalMode(*globalMode)
+			inst.SetUpdateMode(*update)
 			if err := inst.Install(); err != nil {
 				fmt.Fprintf(os.Stderr, "Install failed: %v\n", err)
 				os.Exit(1)
@@ -335,6 +515,7 @@ func main() {
 	case "publish":
 		publishCmd := flag.NewFlagSet("publish", flag.ExitOnError)
 		configFile := publishCmd.String("config", "polyloft.toml", "configuration file")
+		dryRun := publishCmd.Bool("dry-run", false, "build and check the package and list its files without uploading it")
 		_ = publishCmd.Parse(os.Args[2:])
 		
 		// Check authentication
@@ -353,6 +534,7 @@ func main() {
 		
 		// Publish package
 		pub := publisher.New(cfg)
+		pub.SetDryRun(*dryRun)
 		if err := pub.Publish(); err != nil {
 			fmt.Fprintf(os.Stderr, "Publish failed: %v\n", err)
 			os.Exit(1)
@@ -360,27 +542,46 @@ func main() {
 		
 	case "search":
 		searchCmd := flag.NewFlagSet("search", flag.ExitOnError)
+		author := searchCmd.String("author", "", "only show packages published by this author")
+		sortBy := searchCmd.String("sort", "", "order results by downloads, recent or name (default: relevance)")
+		limit := searchCmd.Int("limit", 0, "show at most this many packages")
 		_ = searchCmd.Parse(os.Args[2:])
 		
 		if searchCmd.NArg() < 1 {
-			fmt.Fprintln(os.Stderr, "usage: polyloft search <query>")
+			fmt.Fprintln(os.Stderr, "usage: polyloft search [-author name] [-sort downloads|recent|name] [-limit n] <query>")
 			os.Exit(1)
 		}
 		
 		query := searchCmd.Arg(0)
 		s := searcher.New()
-		results, err := s.Search(query)
+		result, err := s.SearchWithOptions(query, searcher.SearchOptions{Author: *author, Sort: *sortBy, Limit: *limit})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Search failed: %v\n", err)
 			os.Exit(1)
 		}
+		results := result.Packages
 		
 		if len(results) == 0 {
 			fmt.Println("No packages found matching your query.")
 		} else {
-			fmt.Printf("Found %d package(s):\n\n", len(results))
+			summary := fmt.Sprintf("Found %d package(s)", result.Total)
+			if len(results) < result.Total {
+				summary += fmt.Sprintf(", showing %d", len(results))
+			}
+			if result.Sort != "" {
+				summary += ", sorted by " + result.Sort
+			}
+			fmt.Println(summary + ":")
+			if result.SortedLocally {
+				fmt.Printf("(the registry does not sort by %s; only the returned results were sorted)\n", result.Sort)
+			}
+			fmt.Println()
 			for _, pkg := range results {
-				fmt.Printf("  %s@%s (v%s)\n", pkg.Name, pkg.Author, pkg.Version)
+				fmt.Printf("  %s@%s (v%s)", pkg.Name, pkg.Author, pkg.Version)
+				if pkg.Downloads > 0 {
+					fmt.Printf(" - %d downloads", pkg.Downloads)
+				}
+				fmt.Println()
 				if pkg.Description != "" {
 					fmt.Printf("    %s\n", pkg.Description)
 				}
@@ -472,6 +673,8 @@ func main() {
 		genMappingsCmd := flag.NewFlagSet("generate-mappings", flag.ExitOnError)
 		out := genMappingsCmd.String("o", "mappings.json", "output file path")
 		root := genMappingsCmd.String("root", ".", "root directory of the project")
+		watch := genMappingsCmd.Bool("watch", false, "keep regenerating the mappings as .pf files under libs/ change")
+		interval := genMappingsCmd.Duration("interval", 500*time.Millisecond, "how often -watch checks for changes")
 		_ = genMappingsCmd.Parse(os.Args[2:])
 		
 		fmt.Printf("Generating mappings from %s...\n", *root)
@@ -483,6 +686,12 @@ func main() {
 		}
 		
 		fmt.Printf("✓ Mappings generated successfully: %s\n", *out)
+
+		if *watch {
+			fmt.Printf("Watching %s for changes (Ctrl+C to stop)...\n", filepath.Join(*root, "libs"))
+			// Mappings are written atomically, so Ctrl+C can stop the process at any point
+			gen.Watch(*out, *interval, 2**interval, nil, os.Stdout)
+		}
 		
 	case "version":
 		fmt.Println(version.String())
@@ -502,9 +711,13 @@ func usage() {
 	fmt.Println("Subcommands:")
 	fmt.Println("  repl                  Start an interactive REPL")
 	fmt.Println("  run [file.pf]         Run a Polyloft source file, or current project if no file specified")
+	fmt.Println("  bench <file.pf>       Run the bench* functions of a file and report ns/op")
+	fmt.Println("  test [paths...]       Run the @Test and test_* functions of every *_test.pf file and report failures")
+	fmt.Println("  lint [paths...]       Report unused code, shadowed variables and other likely mistakes in .pf files")
+	fmt.Println("  debug <file.pf>       Run a file under the interactive debugger (-b file:line sets breakpoints)")
 	fmt.Println("  init                  Initialize a new project with polyloft.toml")
 	fmt.Println("  build                 Build a Polyloft project to executable (requires polyloft.toml)")
-	fmt.Println("  install [package]     Install project dependencies (requires polyloft.toml), or install specific package(s). Use -g for global installation")
+	fmt.Println("  install [package]     Install project dependencies (requires polyloft.toml), or install specific package(s). Use -g for global installation, -update to ignore polyloft.lock")
 	fmt.Println("  search <query>        Search for packages in the registry")
 	fmt.Println("  register              Register a new account on the package registry")
 	fmt.Println("  login                 Authenticate with the package registry")
@@ -515,9 +728,23 @@ func usage() {
 	fmt.Println("  version               Print version information")
 }
 
+// scriptArgs returns the arguments that follow the script path of a run command;
+// there are none when the project's entry point runs
+func scriptArgs(fs *flag.FlagSet) []string {
+	if fs.NArg() < 1 {
+		return []string{}
+	}
+	return fs.Args()[1:]
+}
+
 // runFile is a placeholder execution pipeline that shows where
 // lexing/parsing/execution will be wired in the future.
 func runFile(path string) error {
+	return runFileWithOptions(path, engine.Options{Stdout: os.Stdout, Stdin: os.Stdin})
+}
+
+// runFileWithOptions runs a source file with the given engine options
+func runFileWithOptions(path string, opts engine.Options) error {
 	b, err := os.ReadFile(path)
 	if err != nil {
 		return err
@@ -537,15 +764,166 @@ func runFile(path string) error {
 
 	// Eval with file context and source for better error messages
 	packageName := filepath.Dir(path)
-	_, err = engine.EvalWithContextAndSource(prog, engine.Options{Stdout: os.Stdout}, path, packageName, source)
+	_, err = engine.EvalWithContextAndSource(prog, opts, path, packageName, source)
 	return err
 }
 
+// warnFile prints the static analysis warnings for a source file. Parse errors
+// are left for the run itself to report.
+func warnFile(path string) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan(b), path, string(b)).Parse()
+	if err != nil {
+		return
+	}
+	printWarnings(path, prog)
+}
+
+// printWarnings writes the static analysis warnings for prog to stderr
+func printWarnings(path string, prog *ast.Program) {
+	for _, w := range analysis.Check(prog) {
+		fmt.Fprintln(os.Stderr, w.Format(path))
+	}
+}
+
+// benchFile runs the benchmark functions of a source file and prints their results
+func benchFile(path string, cfg engine.BenchConfig) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	source := string(b)
+
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan(b), path, source).Parse()
+	if err != nil {
+		return err
+	}
+
+	results, err := engine.RunBenchmarks(prog, engine.Options{Stdout: os.Stdout}, path, filepath.Dir(path), source, cfg)
+	engine.FormatBenchResults(os.Stdout, results)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Println("no bench functions found")
+	}
+	return nil
+}
+
+// findTestFiles returns the *_test.pf files among paths, searching directories
+// recursively and skipping hidden ones
+func findTestFiles(paths []string) ([]string, error) {
+	return findSourceFiles(paths, "_test.pf")
+}
+
+// findSourceFiles returns the files among paths whose names end with suffix,
+// searching directories recursively and skipping hidden ones and skipDirs
+func findSourceFiles(paths []string, suffix string, skipDirs ...string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && path != root && (strings.HasPrefix(d.Name(), ".") || containsString(skipDirs, d.Name())) {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), suffix) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// lintFile prints the warnings of rules for a source file and returns how many
+// there were; a file that cannot be read or parsed counts as one
+func lintFile(path string, rules []string) int {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan(b), path, string(b)).Parse()
+	if err != nil {
+		fmt.Fprint(os.Stderr, engine.FormatError(err))
+		return 1
+	}
+	warnings := analysis.Lint(prog, rules...)
+	for _, w := range warnings {
+		fmt.Println(w.Format(path))
+	}
+	return len(warnings)
+}
+
+// testFile runs the test functions of a source file with opts, prints their
+// results and reports whether all of them passed
+func testFile(path string, opts engine.Options, cfg engine.TestConfig) bool {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL %s: %v\n", path, err)
+		return false
+	}
+	source := string(b)
+
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan(b), path, source).Parse()
+	if err == nil {
+		printWarnings(path, prog)
+		var results []engine.TestResult
+		results, err = engine.RunTests(prog, opts, path, filepath.Dir(path), source, cfg)
+		engine.FormatTestResults(os.Stdout, path, results)
+		if err == nil {
+			for _, r := range results {
+				if !r.Passed() {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	fmt.Fprintf(os.Stderr, "FAIL %s\n", path)
+	fmt.Fprint(os.Stderr, engine.FormatError(err))
+	return false
+}
+
 // defaultOutputName builds a sensible default artifact name based on config and OS.
-func defaultOutputName(cfg *config.Config) string {
+// An explicit target adds an "-os-arch" suffix so builds for several
+// platforms can sit side by side.
+func defaultOutputName(cfg *config.Config, target builder.Target) string {
 	name := cfg.Project.Name
 	if name == "" {
 		name = "polyloft-app"
 	}
+	if target != (builder.Target{}) {
+		name += target.Suffix()
+	}
 	return name + ".pfx"
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+// profileRun runs fn while collecting a CPU or memory profile written to outPath.
+// When outPath is empty the profile is written to "<mode>.pprof".
+// A short summary is printed to summary once fn returns.
+func profileRun(mode, outPath string, summary io.Writer, fn func() error) error {
+	if mode != "cpu" && mode != "mem" {
+		return fmt.Errorf("unknown profile mode %q (expected cpu or mem)", mode)
+	}
+	if outPath == "" {
+		outPath = mode + ".pprof"
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("creating profile file: %w", err)
+	}
+	defer f.Close()
+
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if mode == "cpu" {
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return fmt.Errorf("starting CPU profile: %w", err)
+		}
+	}
+
+	start := time.Now()
+	runErr := fn()
+	elapsed := time.Since(start)
+
+	if mode == "cpu" {
+		pprof.StopCPUProfile()
+	} else {
+		runtime.GC() // flush recent allocations into the profile
+		if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+			return fmt.Errorf("writing memory profile: %w", err)
+		}
+	}
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	fmt.Fprintf(summary, "\n%s profile written to %s\n", mode, outPath)
+	fmt.Fprintf(summary, "  wall time:    %s\n", elapsed.Round(time.Microsecond))
+	fmt.Fprintf(summary, "  allocations:  %d (%s)\n", after.Mallocs-before.Mallocs, formatBytes(after.TotalAlloc-before.TotalAlloc))
+	fmt.Fprintf(summary, "  GC cycles:    %d\n", after.NumGC-before.NumGC)
+	fmt.Fprintf(summary, "Inspect with: go tool pprof %s\n", outPath)
+	return runErr
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -9,12 +9,14 @@ require (
 	github.com/lithammer/fuzzysearch v1.1.8 // direct
 )
 
-require golang.org/x/text v0.9.0
+require (
+	github.com/gorilla/websocket v1.5.3
+	golang.org/x/term v0.5.0
+	golang.org/x/text v0.9.0
+)
 
 require (
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	golang.org/x/sys v0.25.0 // indirect
-	golang.org/x/term v0.5.0 // indirect
 )
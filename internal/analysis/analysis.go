@@ -0,0 +1,518 @@
+// Package analysis runs static checks over a parsed program without running it.
+// It reports code that is legal but almost certainly a mistake, such as a
+// variable that is never read or a statement that can never execute.
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+// Warning is one problem found in a program
+type Warning struct {
+	Line    int
+	Column  int
+	Rule    string
+	Message string
+}
+
+// Format renders the warning as "file:line:col: warning: message (rule)"
+func (w Warning) Format(file string) string {
+	return fmt.Sprintf("%s:%d:%d: warning: %s (%s)", file, w.Line, w.Column, w.Message, w.Rule)
+}
+
+// Check returns the warnings of the DefaultRules for prog
+func Check(prog *ast.Program) []Warning {
+	return Lint(prog, DefaultRules...)
+}
+
+// Lint returns the warnings of the given rules for prog, sorted by position
+func Lint(prog *ast.Program, rules ...string) []Warning {
+	c := &checker{enabled: make(map[string]bool), classes: make(map[string]*ast.ClassDecl), imports: make(map[string]int)}
+	for _, rule := range rules {
+		c.enabled[rule] = true
+	}
+	for _, st := range prog.Stmts {
+		if class, ok := st.(*ast.ClassDecl); ok {
+			c.classes[class.Name] = class
+		}
+	}
+	c.scope = newScope(nil)
+	c.stmts(prog.Stmts)
+	c.closeScope()
+	sort.SliceStable(c.warnings, func(a, b int) bool {
+		if c.warnings[a].Line != c.warnings[b].Line {
+			return c.warnings[a].Line < c.warnings[b].Line
+		}
+		return c.warnings[a].Column < c.warnings[b].Column
+	})
+	return c.warnings
+}
+
+// Imports returns every import statement of prog, including those inside
+// functions, classes and blocks
+func Imports(prog *ast.Program) []*ast.ImportStmt {
+	c := &checker{enabled: make(map[string]bool), classes: make(map[string]*ast.ClassDecl), imports: make(map[string]int)}
+	c.scope = newScope(nil)
+	c.stmts(prog.Stmts)
+	c.closeScope()
+	return c.found
+}
+
+// binding is a variable or imported name that is reported when never read
+type binding struct {
+	rule    string
+	message string
+	pos     ast.Position
+	used    bool
+}
+
+// scope holds the names declared by a function body. Blocks such as if and
+// loop bodies share the scope of their function, as they do at runtime.
+type scope struct {
+	parent   *scope
+	bindings map[string][]*binding
+	locals   map[string]bool // parameters, loop variables and other names that are never reported
+	vars     map[string]int  // line where each variable was first declared, for shadowing
+	refs     []string
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{parent: parent, bindings: make(map[string][]*binding), locals: make(map[string]bool), vars: make(map[string]int)}
+}
+
+type checker struct {
+	enabled  map[string]bool
+	classes  map[string]*ast.ClassDecl // top-level classes, for override checks
+	imports  map[string]int            // line of each import, by module path or imported name
+	scope    *scope
+	warnings []Warning
+	found    []*ast.ImportStmt // every import statement, in the order seen
+}
+
+func (c *checker) warn(rule string, pos ast.Position, format string, args ...any) {
+	if c.enabled[rule] {
+		c.warnings = append(c.warnings, Warning{Line: pos.Line, Column: pos.Col, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+func (c *checker) openScope(params []ast.Parameter, pos ast.Position) {
+	c.scope = newScope(c.scope)
+	for _, param := range params {
+		c.declareVar(param.Name, "parameter", pos)
+		c.declareLocal(param.Name)
+	}
+	// Default values are evaluated in the function's own scope
+	for _, param := range params {
+		c.expr(param.Default)
+	}
+}
+
+// closeScope resolves the names read in the current scope. A closure may read
+// a variable declared after it, so names are only resolved once the whole
+// function body has been seen; the ones not declared here belong to the
+// enclosing function.
+func (c *checker) closeScope() {
+	s := c.scope
+	for _, ref := range s.refs {
+		head, _, _ := strings.Cut(ref, ".")
+		matched := false
+		if bindings, ok := s.bindings[head]; ok {
+			for _, b := range bindings {
+				b.used = true
+			}
+			matched = true
+		}
+		// A namespace import of a dotted path is read by any access through it
+		for key, bindings := range s.bindings {
+			if strings.Contains(key, ".") && (ref == key || strings.HasPrefix(ref, key+".") || strings.HasPrefix(key, ref+".")) {
+				for _, b := range bindings {
+					b.used = true
+				}
+				matched = true
+			}
+		}
+		if matched || s.locals[head] {
+			continue
+		}
+		if s.parent != nil {
+			s.parent.refs = append(s.parent.refs, ref)
+		}
+	}
+	for _, bindings := range s.bindings {
+		for _, b := range bindings {
+			if !b.used {
+				c.warn(b.rule, b.pos, "%s", b.message)
+			}
+		}
+	}
+	c.scope = s.parent
+}
+
+// declare records a let, var, const or final binding
+func (c *checker) declare(name, kind string, pos ast.Position) {
+	c.declareVar(name, kind, pos)
+	if name == "" || strings.HasPrefix(name, "_") {
+		c.declareLocal(name)
+		return
+	}
+	c.scope.bindings[name] = append(c.scope.bindings[name], &binding{
+		rule:    RuleUnusedVariable,
+		message: fmt.Sprintf("%s '%s' is declared but never used", kind, name),
+		pos:     pos,
+	})
+}
+
+// declareImport records a name bound by an import statement
+func (c *checker) declareImport(name, message string, pos ast.Position) {
+	c.scope.bindings[name] = append(c.scope.bindings[name], &binding{rule: RuleUnusedImport, message: message, pos: pos})
+}
+
+// declareVar notes a variable for the shadowing rule, which reports a function
+// variable that hides one of an enclosing function. Top-level names are visible
+// to every function, so reusing them for locals is not reported.
+func (c *checker) declareVar(name, kind string, pos ast.Position) {
+	if name == "" || strings.HasPrefix(name, "_") {
+		return
+	}
+	if _, ok := c.scope.vars[name]; !ok {
+		c.scope.vars[name] = pos.Line
+	}
+	for outer := c.scope.parent; outer != nil && outer.parent != nil; outer = outer.parent {
+		if line, ok := outer.vars[name]; ok {
+			c.warn(RuleShadowedVariable, pos, "%s '%s' shadows the variable declared on line %d", kind, name, line)
+			return
+		}
+	}
+}
+
+func (c *checker) declareLocal(name string) {
+	if name != "" {
+		c.scope.locals[name] = true
+	}
+}
+
+func (c *checker) use(name string) {
+	c.scope.refs = append(c.scope.refs, name)
+}
+
+// stmts checks a block, flagging the first statement that follows one which
+// always leaves it
+func (c *checker) stmts(list []ast.Stmt) {
+	exit := ""
+	for _, st := range list {
+		if exit != "" {
+			if located, ok := st.(ast.Locatable); ok {
+				c.warn(RuleUnreachableCode, located.StartPos(), "unreachable code after %s", exit)
+			}
+			exit = ""
+			// Keep checking the dead statements so their names still count as read
+		}
+		c.stmt(st)
+		switch st.(type) {
+		case *ast.ReturnStmt:
+			exit = "return"
+		case *ast.ThrowStmt:
+			exit = "throw"
+		case *ast.BreakStmt:
+			exit = "break"
+		case *ast.ContinueStmt:
+			exit = "continue"
+		}
+	}
+}
+
+func (c *checker) stmt(st ast.Stmt) {
+	switch s := st.(type) {
+	case *ast.LetStmt:
+		c.expr(s.Value)
+		kind := s.Kind
+		if kind == "" {
+			kind = "let"
+		}
+		names := s.Names
+		if len(names) == 0 {
+			names = []string{s.Name}
+		}
+		for _, name := range names {
+			if s.Exported {
+				c.declareLocal(name)
+			} else {
+				c.declare(name, kind, s.Start)
+			}
+		}
+	case *ast.AssignStmt:
+		// Assigning to a variable does not read it; assigning into one does
+		if _, ok := s.Target.(*ast.Ident); !ok {
+			c.expr(s.Target)
+		}
+		c.expr(s.Value)
+	case *ast.ReturnStmt:
+		c.expr(s.Value)
+	case *ast.ExprStmt:
+		c.expr(s.X)
+	case *ast.DefStmt:
+		c.declareLocal(s.Name)
+		for _, annotation := range s.Annotations {
+			for _, arg := range annotation.Args {
+				c.expr(arg)
+			}
+		}
+		c.function(s.Params, s.Body, s.Start)
+	case *ast.IfStmt:
+		for _, clause := range s.Clauses {
+			c.expr(clause.Cond)
+			c.stmts(clause.Body)
+		}
+		c.stmts(s.Else)
+	case *ast.ForInStmt:
+		c.expr(s.Iterable)
+		names := s.Names
+		if len(names) == 0 {
+			names = []string{s.Name}
+		}
+		for _, name := range names {
+			c.declareVar(name, "loop variable", s.Start)
+			c.declareLocal(name)
+		}
+		c.expr(s.Where)
+		c.stmts(s.Body)
+	case *ast.LoopStmt:
+		c.expr(s.Condition)
+		c.stmts(s.Body)
+	case *ast.DoLoopStmt:
+		c.stmts(s.Body)
+		c.expr(s.Condition)
+	case *ast.ImportStmt:
+		c.importStmt(s)
+	case *ast.TryStmt:
+		c.stmts(s.Body)
+		for _, catch := range s.Catches {
+			c.declareLocal(catch.VarName)
+			c.stmts(catch.Body)
+		}
+		c.stmts(s.Finally)
+	case *ast.ThrowStmt:
+		c.expr(s.Value)
+	case *ast.DeferStmt:
+		c.expr(s.Call)
+	case *ast.YieldStmt:
+		c.expr(s.Value)
+	case *ast.AssertStmt:
+		c.expr(s.Cond)
+		c.expr(s.Message)
+	case *ast.SelectStmt:
+		for _, sc := range s.Cases {
+			c.expr(sc.Channel)
+			c.declareLocal(sc.RecvVar)
+			c.stmts(sc.Body)
+		}
+	case *ast.SwitchStmt:
+		c.expr(s.Expr)
+		for _, sc := range s.Cases {
+			for _, value := range sc.Values {
+				c.expr(value)
+			}
+			c.declareLocal(sc.VarName)
+			c.stmts(sc.Body)
+		}
+		c.stmts(s.Default)
+	case *ast.ClassDecl:
+		c.declareLocal(s.Name)
+		c.checkOverrides(s)
+		c.members(s.Fields, s.Methods, s.Constructor)
+	case *ast.EnumDecl:
+		c.declareLocal(s.Name)
+		for _, value := range s.Values {
+			for _, arg := range value.Args {
+				c.expr(arg)
+			}
+		}
+		c.members(s.Fields, s.Methods, s.Constructor)
+	case *ast.RecordDecl:
+		c.declareLocal(s.Name)
+		c.members(nil, s.Methods, nil)
+	case *ast.InterfaceDecl:
+		c.declareLocal(s.Name)
+		c.members(s.Fields, nil, nil)
+		for _, method := range s.Methods {
+			if method.HasDefault {
+				c.function(method.Params, method.DefaultBody, s.Start)
+			}
+		}
+	case *ast.TypeAliasStmt:
+		c.declareLocal(s.Name)
+	}
+}
+
+// importStmt binds the names an import brings in: the listed symbols, or the
+// whole dotted path for a namespace import, which is read through its first part.
+// A module or name imported a second time is reported and not bound again.
+func (c *checker) importStmt(s *ast.ImportStmt) {
+	c.found = append(c.found, s)
+	path := strings.Join(s.Path, ".")
+	if len(s.Names) == 0 {
+		if path == "" {
+			return
+		}
+		if line, ok := c.imports[path]; ok {
+			c.warn(RuleDuplicateImport, s.Start, "'%s' is already imported on line %d", path, line)
+			return
+		}
+		c.imports[path] = s.Start.Line
+		c.declareImport(path, fmt.Sprintf("import '%s' is never used", path), s.Start)
+		return
+	}
+	for _, name := range s.Names {
+		if line, ok := c.imports["{"+name]; ok {
+			c.warn(RuleDuplicateImport, s.Start, "'%s' is already imported on line %d", name, line)
+			continue
+		}
+		c.imports["{"+name] = s.Start.Line
+		c.declareImport(name, fmt.Sprintf("'%s' is imported from %s but never used", name, path), s.Start)
+	}
+}
+
+// members checks the field initializers and method bodies of a type
+func (c *checker) members(fields []ast.FieldDecl, methods []ast.MethodDecl, ctor *ast.ConstructorDecl) {
+	for _, field := range fields {
+		c.expr(field.InitValue)
+	}
+	for _, method := range methods {
+		c.function(method.Params, method.Body, method.Start)
+	}
+	if ctor != nil {
+		c.function(ctor.Params, ctor.Body, ctor.Start)
+	}
+}
+
+func (c *checker) function(params []ast.Parameter, body []ast.Stmt, pos ast.Position) {
+	c.openScope(params, pos)
+	c.stmts(body)
+	c.closeScope()
+}
+
+func (c *checker) expr(e ast.Expr) {
+	switch x := e.(type) {
+	case *ast.Ident:
+		c.use(x.Name)
+	case *ast.StringLit:
+		c.interpolation(x.Value)
+	case *ast.InterpolatedStringLit:
+		c.exprs(x.Parts)
+	case *ast.ArrayLit:
+		c.exprs(x.Elems)
+	case *ast.MapLit:
+		// Keys are literal names, as in {name: 1}, never expressions, so only
+		// the values can use a variable
+		for _, pair := range x.Pairs {
+			c.expr(pair.Value)
+		}
+	case *ast.UnaryExpr:
+		c.expr(x.X)
+	case *ast.BinaryExpr:
+		c.checkFloatEquality(x)
+		c.expr(x.Lhs)
+		c.expr(x.Rhs)
+	case *ast.CallExpr:
+		c.expr(x.Callee)
+		c.exprs(x.Args)
+		for _, arg := range x.NamedArgs {
+			c.expr(arg.Value)
+		}
+	case *ast.GenericCallExpr:
+		c.use(x.Name)
+		c.exprs(x.Args)
+	case *ast.SuperExpr:
+		c.exprs(x.Args)
+	case *ast.IndexExpr:
+		c.expr(x.X)
+		c.expr(x.Index)
+	case *ast.FieldExpr:
+		// a.b.c is read as one dotted name, so namespace imports see which module is used
+		if name, ok := dottedName(x); ok {
+			c.use(name)
+		} else {
+			c.expr(x.X)
+		}
+	case *ast.InstanceOfExpr:
+		c.expr(x.Expr)
+		c.declareLocal(x.Variable)
+	case *ast.TypeExpr:
+		c.expr(x.Expr)
+	case *ast.LambdaExpr:
+		c.openScope(x.Params, x.From)
+		c.expr(x.Body)
+		c.stmts(x.BlockBody)
+		c.closeScope()
+	case *ast.ThreadSpawnExpr:
+		c.function(nil, x.Body, x.From)
+	case *ast.ThreadJoinExpr:
+		c.expr(x.Thread)
+	case *ast.ChannelExpr:
+		c.expr(x.Capacity)
+	case *ast.TernaryExpr:
+		c.expr(x.Condition)
+		c.expr(x.TrueBranch)
+		c.expr(x.FalseBranch)
+	case *ast.RangeExpr:
+		c.expr(x.Start)
+		c.expr(x.End)
+	case *ast.SpreadExpr:
+		c.expr(x.X)
+	}
+}
+
+// dottedName returns "a.b.c" for a field access on a plain name
+func dottedName(e ast.Expr) (string, bool) {
+	switch x := e.(type) {
+	case *ast.Ident:
+		return x.Name, true
+	case *ast.FieldExpr:
+		if prefix, ok := dottedName(x.X); ok {
+			return prefix + "." + x.Name, true
+		}
+	}
+	return "", false
+}
+
+func (c *checker) exprs(list []ast.Expr) {
+	for _, e := range list {
+		c.expr(e)
+	}
+}
+
+// interpolation reads the names used by the #{...} parts of a string, which
+// the runtime parses when the string is evaluated
+func (c *checker) interpolation(s string) {
+	for {
+		start := strings.Index(s, "#{")
+		if start < 0 {
+			return
+		}
+		depth, end := 0, -1
+		for idx := start + 1; idx < len(s) && end < 0; idx++ {
+			switch s[idx] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					end = idx
+				}
+			}
+		}
+		if end < 0 {
+			return
+		}
+		lx := &lexer.Lexer{}
+		if e, err := parser.New(lx.Scan([]byte(strings.TrimSpace(s[start+2 : end])))).ParseExpression(); err == nil {
+			c.expr(e)
+		}
+		s = s[end+1:]
+	}
+}
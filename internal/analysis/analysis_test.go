@@ -0,0 +1,254 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+func check(t *testing.T, source string, rules ...string) []string {
+	t.Helper()
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(source))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var got []string
+	warnings := Check(prog)
+	if len(rules) > 0 {
+		warnings = Lint(prog, rules...)
+	}
+	for _, w := range warnings {
+		got = append(got, w.Format("main.pf"))
+	}
+	return got
+}
+
+func TestUnusedBindings(t *testing.T) {
+	source := `
+let unused = 1
+const LIMIT = 10
+let _ignored = 2
+export let shared = 3
+
+def compute(n):
+    var total = 0
+    let scratch = n * 2
+    for i in range(n):
+        total = total + i
+    end
+    let label = "total"
+    println("#{label}: #{total}")
+    return LIMIT
+end
+
+def later():
+    let callback = () => helper
+    let helper = 5
+    return callback
+end
+
+def assignedOnly():
+    var result = nil
+    result = 42
+end
+
+let cases = [1, 2]
+@Params(cases)
+def test_cases(n):
+    assert n > 0
+end
+
+compute(3)
+later()
+assignedOnly()
+`
+	got := check(t, source)
+	want := []string{
+		"main.pf:2:1: warning: let 'unused' is declared but never used (unused-variable)",
+		"main.pf:9:5: warning: let 'scratch' is declared but never used (unused-variable)",
+		"main.pf:25:5: warning: var 'result' is declared but never used (unused-variable)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUnusedBindings_MapLiteralKeys(t *testing.T) {
+	source := `
+let name = "id"
+let value = 7
+println({name: value})
+`
+	got := check(t, source)
+	want := []string{
+		"main.pf:2:1: warning: let 'name' is declared but never used (unused-variable)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUnreachableCode(t *testing.T) {
+	source := `
+def first(xs):
+    for x in xs:
+        if x > 2:
+            break
+            println("never")
+        end
+        continue
+        println("skipped")
+    end
+    return 1
+    println("after return")
+end
+
+def fail():
+    throw RuntimeError("boom")
+    return 2
+end
+
+def fine(x):
+    if x:
+        return 1
+    end
+    return 0
+end
+
+first([1, 2, 3])
+fail()
+fine(true)
+`
+	got := check(t, source)
+	want := []string{
+		"main.pf:6:13: warning: unreachable code after break (unreachable-code)",
+		"main.pf:9:9: warning: unreachable code after continue (unreachable-code)",
+		"main.pf:12:5: warning: unreachable code after return (unreachable-code)",
+		"main.pf:17:5: warning: unreachable code after throw (unreachable-code)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestImports(t *testing.T) {
+	source := `
+import test.math
+import test.io
+import test.math
+import geometry { Point, Line }
+import shapes { Point }
+import strings
+
+def area(r):
+    return test.math.PI * r * r
+end
+
+println(area(2), Point(1, 2), strings)
+`
+	got := check(t, source)
+	want := []string{
+		"main.pf:3:1: warning: import 'test.io' is never used (unused-import)",
+		"main.pf:4:1: warning: 'test.math' is already imported on line 2 (duplicate-import)",
+		"main.pf:5:1: warning: 'Line' is imported from geometry but never used (unused-import)",
+		"main.pf:6:1: warning: 'Point' is already imported on line 5 (duplicate-import)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	source := `
+import math.vector { Vec, Dot }
+import text.format
+
+class Animal:
+    def speak():
+        return "..."
+    end
+    def name():
+        return "animal"
+    end
+end
+
+class Dog < Animal:
+    @Override
+    def speak():
+        return "woof"
+    end
+    def name():
+        return "dog"
+    end
+    def fetch():
+        return true
+    end
+end
+
+def scale(items, factor):
+    let count = items.length()
+    return items.map((item) => do
+        let factor = 2
+        return item * factor + count
+    end)
+end
+
+let v = Vec(1, 2)
+if scale([v], 1.0)[0] == 0.5:
+    println("half")
+end
+`
+	got := check(t, source, Rules...)
+	want := []string{
+		"main.pf:2:1: warning: 'Dot' is imported from math.vector but never used (unused-import)",
+		"main.pf:3:1: warning: import 'text.format' is never used (unused-import)",
+		"main.pf:19:5: warning: method 'name' overrides Animal.name but is not marked @Override (missing-override)",
+		"main.pf:30:9: warning: let 'factor' shadows the variable declared on line 27 (shadowed-variable)",
+		"main.pf:36:4: warning: comparing floats with == is unreliable; check that the difference is within a tolerance instead (float-equality)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestEnabledRules(t *testing.T) {
+	rules, err := EnabledRules(map[string]bool{RuleFloatEquality: false, RuleUnusedImport: true})
+	if err != nil {
+		t.Fatalf("EnabledRules: %v", err)
+	}
+	if strings.Join(rules, ",") != "unused-variable,unreachable-code,unused-import,duplicate-import,shadowed-variable,missing-override" {
+		t.Errorf("rules = %v", rules)
+	}
+	if _, err := EnabledRules(map[string]bool{"tabs": false}); err == nil || !strings.Contains(err.Error(), "unknown lint rule \"tabs\"") {
+		t.Errorf("expected an unknown rule error, got %v", err)
+	}
+}
+
+func TestImportStatements(t *testing.T) {
+	source := `
+import math.vector { Vec2 }
+def area(shape):
+    import geometry
+    if shape != nil:
+        import utils.format { round }
+        return round(geometry.area(shape))
+    end
+end
+import math.vector { Vec2 }
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(source))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var got []string
+	for _, im := range Imports(prog) {
+		got = append(got, strings.Join(im.Path, "."))
+	}
+	if strings.Join(got, ",") != "math.vector,geometry,utils.format,math.vector" {
+		t.Errorf("got imports %v", got)
+	}
+}
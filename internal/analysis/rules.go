@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+)
+
+// Rule names, as used in warnings and in the [lint.rules] table of polyloft.toml
+const (
+	RuleUnusedVariable   = "unused-variable"
+	RuleUnreachableCode  = "unreachable-code"
+	RuleUnusedImport     = "unused-import"
+	RuleDuplicateImport  = "duplicate-import"
+	RuleShadowedVariable = "shadowed-variable"
+	RuleMissingOverride  = "missing-override"
+	RuleFloatEquality    = "float-equality"
+)
+
+// Rules lists every rule Lint knows
+var Rules = []string{
+	RuleUnusedVariable,
+	RuleUnreachableCode,
+	RuleUnusedImport,
+	RuleDuplicateImport,
+	RuleShadowedVariable,
+	RuleMissingOverride,
+	RuleFloatEquality,
+}
+
+// DefaultRules are the rules Check applies when running a program with warnings
+var DefaultRules = []string{RuleUnusedVariable, RuleUnreachableCode, RuleUnusedImport, RuleDuplicateImport}
+
+// EnabledRules returns the Rules left on by settings, which maps rule names to
+// whether they apply. Rules missing from settings are on.
+func EnabledRules(settings map[string]bool) ([]string, error) {
+	var unknown []string
+	for name := range settings {
+		if !isRule(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown lint rule %q; available rules: %s", unknown[0], strings.Join(Rules, ", "))
+	}
+	var rules []string
+	for _, rule := range Rules {
+		if enabled, ok := settings[rule]; !ok || enabled {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func isRule(name string) bool {
+	for _, rule := range Rules {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOverrides reports methods that replace a method of a parent class
+// declared in the same file without being marked @Override
+func (c *checker) checkOverrides(class *ast.ClassDecl) {
+	for _, method := range class.Methods {
+		if method.IsOverride || hasModifier(method.Modifiers, "static") {
+			continue
+		}
+		if owner := c.parentDefining(class.Parent, method.Name); owner != "" {
+			c.warn(RuleMissingOverride, method.Start, "method '%s' overrides %s.%s but is not marked @Override", method.Name, owner, method.Name)
+		}
+	}
+}
+
+// parentDefining returns the nearest class from parent up that declares an
+// instance method called name, or "" when none does
+func (c *checker) parentDefining(parent, name string) string {
+	seen := make(map[string]bool)
+	for parent != "" && !seen[parent] {
+		seen[parent] = true
+		class, ok := c.classes[parent]
+		if !ok {
+			return ""
+		}
+		for _, method := range class.Methods {
+			if method.Name == name && !hasModifier(method.Modifiers, "static") {
+				return class.Name
+			}
+		}
+		parent = class.Parent
+	}
+	return ""
+}
+
+func hasModifier(modifiers []string, modifier string) bool {
+	for _, m := range modifiers {
+		if m == modifier {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFloatEquality reports == and != against a float literal, which rounding
+// makes unreliable
+func (c *checker) checkFloatEquality(x *ast.BinaryExpr) {
+	if x.Op != ast.OpEq && x.Op != ast.OpNeq {
+		return
+	}
+	if isFloatLiteral(x.Lhs) || isFloatLiteral(x.Rhs) {
+		op := "=="
+		if x.Op == ast.OpNeq {
+			op = "!="
+		}
+		c.warn(RuleFloatEquality, x.From, "comparing floats with %s is unreliable; check that the difference is within a tolerance instead", op)
+	}
+}
+
+func isFloatLiteral(e ast.Expr) bool {
+	switch x := e.(type) {
+	case *ast.NumberLit:
+		_, ok := x.Value.(float64)
+		return ok
+	case *ast.UnaryExpr:
+		return x.Op == ast.OpNeg && isFloatLiteral(x.X)
+	}
+	return false
+}
@@ -33,6 +33,9 @@ type Type struct {
 	IsEnum      bool     // Whether this is an enum type
 	IsRecord    bool     // Whether this is a record type
 	IsUnion     bool     // Whether this is a union type
+	IsFunction  bool     // Whether this is a function type like (Int) -> String
+	ParamTypes  []*Type  // Parameter types of a function type
+	ReturnType  *Type    // Return type of a function type
 }
 
 // Predefined built-in types
@@ -192,8 +195,11 @@ func TypeFromString(typeName string) *Type {
 
 	var result *Type
 
-	// Check if it's a union type (contains |)
-	if strings.Contains(typeName, "|") {
+	// Check if it's a function type (starts with '(' and has '->')
+	if params, ret, ok := SplitFunctionType(typeName); ok {
+		result = parseFunctionType(params, ret)
+	} else if strings.Contains(typeName, "|") {
+		// Check if it's a union type (contains |)
 		result = parseUnionType(typeName)
 	} else if strings.Contains(typeName, "<") && strings.Contains(typeName, ">") {
 		// Check if it's a generic type (contains < and >)
@@ -268,6 +274,20 @@ func parseGenericType(typeName string) *Type {
 	}
 }
 
+// parseFunctionType builds a function type from its parameter and return type names
+func parseFunctionType(params []string, ret string) *Type {
+	paramTypes := make([]*Type, len(params))
+	for i, param := range params {
+		paramTypes[i] = TypeFromString(param)
+	}
+	return &Type{
+		Name:       FormatFunctionType(params, ret),
+		ParamTypes: paramTypes,
+		ReturnType: TypeFromString(ret),
+		IsFunction: true,
+	}
+}
+
 // parseUnionType parses a union type string like "string | int" or "string | int | null"
 func parseUnionType(typeName string) *Type {
 	// Split by | but respect generic type brackets
@@ -481,8 +501,28 @@ type Expr interface {
 	expr()
 }
 
+// Spanned records the source range covered by an expression. It is embedded in
+// every expression node and filled in by the parser.
+type Spanned struct {
+	From Position // first character of the expression
+	To   Position // position just past the last character
+}
+
+// Span returns the source range covered by the node.
+func (s *Spanned) Span() (Position, Position) { return s.From, s.To }
+
+// SetSpan sets the source range covered by the node.
+func (s *Spanned) SetSpan(from, to Position) { s.From, s.To = from, to }
+
+// Spannable is implemented by nodes that embed Spanned.
+type Spannable interface {
+	Span() (Position, Position)
+	SetSpan(from, to Position)
+}
+
 // Identifier
 type Ident struct {
+	Spanned
 	Name string
 }
 
@@ -490,14 +530,27 @@ func (*Ident) node() {}
 func (*Ident) expr() {}
 
 // Literals
-type NumberLit struct{ Value any } // Can be int or float64
-type StringLit struct{ Value string }
-type BytesLit struct{ Value []byte }
+type NumberLit struct {
+	Spanned
+	Value any // Can be int or float64
+}
+type StringLit struct {
+	Spanned
+	Value string
+}
+type BytesLit struct {
+	Spanned
+	Value []byte
+}
 type InterpolatedStringLit struct {
+	Spanned
 	Parts []Expr // alternating string literals and expressions
 }
-type BoolLit struct{ Value bool }
-type NilLit struct{}
+type BoolLit struct {
+	Spanned
+	Value bool
+}
+type NilLit struct{ Spanned }
 
 func (*NumberLit) node()             {}
 func (*NumberLit) expr()             {}
@@ -513,24 +566,42 @@ func (*BytesLit) node()              {}
 func (*BytesLit) expr()              {}
 
 // Composite literals
-type ArrayLit struct{ Elems []Expr }
+type ArrayLit struct {
+	Spanned
+	Elems []Expr
+}
 type MapPair struct {
 	Key   string
 	Value Expr
 }
-type MapLit struct{ Pairs []MapPair }
+type MapLit struct {
+	Spanned
+	Pairs []MapPair
+}
 
 func (*ArrayLit) node() {}
 func (*ArrayLit) expr() {}
 func (*MapLit) node()   {}
 func (*MapLit) expr()   {}
 
+// ValueExpr holds an already evaluated value. The evaluator uses it when it
+// rewrites an expression so that a subexpression is not evaluated twice. It never
+// comes from source, so it has no span.
+type ValueExpr struct {
+	Value any
+}
+
+func (*ValueExpr) node() {}
+func (*ValueExpr) expr() {}
+
 // Unary and binary
 type UnaryExpr struct {
+	Spanned
 	Op int
 	X  Expr
 }
 type BinaryExpr struct {
+	Spanned
 	Op       int
 	Lhs, Rhs Expr
 }
@@ -542,15 +613,25 @@ func (*BinaryExpr) expr() {}
 
 // Call
 type CallExpr struct {
-	Callee Expr
-	Args   []Expr
+	Spanned
+	Callee    Expr
+	Args      []Expr
+	NamedArgs []NamedArg  // keyword arguments after the positional ones (e.g., connect(host: "x", port: 80))
+	TypeArgs  []TypeParam // explicit type arguments on method calls (e.g., obj.method<Int>(x))
 }
 
 func (*CallExpr) node() {}
 func (*CallExpr) expr() {}
 
+// NamedArg is a keyword argument at a call site: name: value
+type NamedArg struct {
+	Name  string
+	Value Expr
+}
+
 // Generic Call: List<Int>(), Map<String, Int>()
 type GenericCallExpr struct {
+	Spanned
 	Name       string      // Base type name (e.g., "List", "Set", "Map", "Lambda")
 	TypeParams []TypeParam // Type parameters (e.g., [TypeParam{Name: "Int"}] for List<Int>)
 	Args       []Expr      // Constructor arguments
@@ -569,8 +650,87 @@ type TypeParam struct {
 	Variance     string   // "in" (contravariance), "out" (covariance), or "" (invariant)
 }
 
+// String renders the type parameter as written in source (e.g., "Int", "out T", "? extends Number")
+func (tp TypeParam) String() string {
+	var name string
+	switch {
+	case tp.IsWildcard && len(tp.Bounds) > 0 && (tp.WildcardKind == "extends" || tp.WildcardKind == "super"):
+		name = "? " + tp.WildcardKind + " " + strings.Join(tp.Bounds, " & ")
+	case tp.IsWildcard:
+		name = "?"
+	default:
+		name = tp.Name
+	}
+	if tp.Variance != "" {
+		name = tp.Variance + " " + name
+	}
+	return name
+}
+
+// FormatGenericType renders a base type name with its type arguments (e.g., "Map<String, Int>")
+func FormatGenericType(base string, args []TypeParam) string {
+	if len(args) == 0 {
+		return base
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.String()
+	}
+	return base + "<" + strings.Join(parts, ", ") + ">"
+}
+
+// FormatFunctionType renders a function type from its parameter and return type names
+// (e.g., "(Int, Int) -> Int")
+func FormatFunctionType(params []string, ret string) string {
+	return "(" + strings.Join(params, ", ") + ") -> " + ret
+}
+
+// SplitFunctionType splits a function type name like "(Int, Int) -> Int" into its
+// parameter type names and return type name; ok is false for any other type name
+func SplitFunctionType(typeName string) (params []string, ret string, ok bool) {
+	typeName = strings.TrimSpace(typeName)
+	if !strings.HasPrefix(typeName, "(") {
+		return nil, "", false
+	}
+
+	depth := 0
+	start := 1
+	for i, ch := range typeName {
+		switch ch {
+		case '(', '<':
+			depth++
+		case '>':
+			// The '>' of a nested "->" does not close anything
+			if i > 0 && typeName[i-1] == '-' {
+				continue
+			}
+			depth--
+		case ')':
+			depth--
+			if depth == 0 {
+				if param := strings.TrimSpace(typeName[start:i]); param != "" {
+					params = append(params, param)
+				}
+				rest := strings.TrimSpace(typeName[i+1:])
+				if !strings.HasPrefix(rest, "->") {
+					return nil, "", false
+				}
+				ret = strings.TrimSpace(rest[2:])
+				return params, ret, ret != ""
+			}
+		case ',':
+			if depth == 1 {
+				params = append(params, strings.TrimSpace(typeName[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return nil, "", false
+}
+
 // Super call expression: super(args)
 type SuperExpr struct {
+	Spanned
 	Args []Expr
 }
 
@@ -579,6 +739,7 @@ func (*SuperExpr) expr() {}
 
 // Indexing: arr[idx] or map[key]
 type IndexExpr struct {
+	Spanned
 	X     Expr
 	Index Expr
 }
@@ -588,15 +749,36 @@ func (*IndexExpr) expr() {}
 
 // Field access: obj.field
 type FieldExpr struct {
-	X    Expr
-	Name string
+	Spanned
+	X        Expr
+	Name     string
+	Optional bool // x?.name: nil when X is nil instead of an error
 }
 
 func (*FieldExpr) node() {}
 func (*FieldExpr) expr() {}
 
+// Located records the source position where a statement begins.
+// It is embedded in statement and class member nodes and filled in by the parser.
+type Located struct {
+	Start Position
+}
+
+// StartPos returns the position where the node begins.
+func (l *Located) StartPos() Position { return l.Start }
+
+// SetStartPos sets the position where the node begins.
+func (l *Located) SetStartPos(pos Position) { l.Start = pos }
+
+// Locatable is implemented by nodes that embed Located.
+type Locatable interface {
+	StartPos() Position
+	SetStartPos(pos Position)
+}
+
 // Statements
 type LetStmt struct {
+	Located
 	Name      string   // Single variable name (for backward compatibility)
 	Names     []string // Multiple variable names for destructuring (e.g., let a, b = [1,2])
 	Value     Expr
@@ -604,41 +786,59 @@ type LetStmt struct {
 	Modifiers []string // optional modifiers: public/private/protected/static
 	Kind      string   // "let", "var", "const", "final"
 	Inferred  bool     // true if declared with ':=' (type inference)
+	Exported  bool     // true if declared with 'export'
 }
 
 // TypeAliasStmt represents type alias declaration: final type Age = Int
+// or typealias StringMap<V> = Map<String, V>
 type TypeAliasStmt struct {
-	Name      string   // Alias name (e.g., "Age")
-	BaseType  string   // Base type name (e.g., "Int")
-	IsFinal   bool     // true if declared with 'final type' (nominal type)
-	Modifiers []string // optional modifiers: public/private/protected
+	Located
+	Name       string   // Alias name (e.g., "Age")
+	TypeParams []string // Alias type parameters (e.g., ["V"]), nil for simple aliases
+	BaseType   string   // Base type name (e.g., "Int" or "Map<String, V>")
+	IsFinal    bool     // true if declared with 'final type' (nominal type)
+	Modifiers  []string // optional modifiers: public/private/protected
 }
 
 type AssignStmt struct {
-	Target Expr     // left side of assignment (could be identifier or field access)
-	Value  Expr     // right side of assignment
-	Pos    Position // position of the assignment operator
+	Located
+	Target   Expr     // left side of assignment (could be identifier or field access)
+	Value    Expr     // right side of assignment
+	Pos      Position // position of the assignment operator
+	Compound bool     // x op= y; Value is the BinaryExpr `Target op y`
+}
+type ReturnStmt struct {
+	Located
+	Value Expr
 }
-type ReturnStmt struct{ Value Expr }
-type ExprStmt struct{ X Expr }
+type ExprStmt struct {
+	Located
+	X Expr
+}
+
 type DefStmt struct {
+	Located
 	Name        string
 	Params      []Parameter // updated to support typed and variadic parameters
 	Body        []Stmt
-	ReturnType  *Type       // Return type using unified type system
-	AccessLevel string      // "public", "private", "protected"
-	Modifiers   []string    // all modifiers including access level
-	TypeParams  []TypeParam // generic type parameters (e.g., [T, K, V])
+	ReturnType  *Type        // Return type using unified type system
+	AccessLevel string       // "public", "private", "protected"
+	Modifiers   []string     // all modifiers including access level
+	TypeParams  []TypeParam  // generic type parameters (e.g., [T, K, V])
+	Exported    bool         // true if declared with 'export'
+	Annotations []Annotation // annotations like @Test placed before the function
 }
 type IfClause struct {
 	Cond Expr
 	Body []Stmt
 }
 type IfStmt struct {
+	Located
 	Clauses []IfClause
 	Else    []Stmt
 }
 type ForInStmt struct {
+	Located
 	Name     string   // deprecated: use Names for single or multiple vars
 	Names    []string // iteration variable names (supports destructuring)
 	Iterable Expr
@@ -650,6 +850,7 @@ type ForInStmt struct {
 // loop ... end (infinite loop)
 // loop condition ... end (while-like loop)
 type LoopStmt struct {
+	Located
 	Condition Expr   // optional: if nil, infinite loop
 	Body      []Stmt
 }
@@ -657,15 +858,17 @@ type LoopStmt struct {
 // DoLoopStmt represents a do-loop statement (do-while)
 // do ... loop condition
 type DoLoopStmt struct {
+	Located
 	Condition Expr   // required: loop condition
 	Body      []Stmt
 }
 
-type BreakStmt struct{}
-type ContinueStmt struct{}
+type BreakStmt struct{ Located }
+type ContinueStmt struct{ Located }
 
 // Import statement: import path.with.dots { Name, Name2 }
 type ImportStmt struct {
+	Located
 	Path  []string // e.g., ["math","vector"]
 	Names []string // specific symbols to import; if empty, import as namespace (future)
 }
@@ -679,6 +882,7 @@ type CatchClause struct {
 }
 
 type TryStmt struct {
+	Located
 	Body    []Stmt
 	Catches []CatchClause // can have multiple catch clauses
 	Finally []Stmt        // optional finally block
@@ -686,15 +890,30 @@ type TryStmt struct {
 
 // Throw statement: throw expr
 type ThrowStmt struct {
+	Located
 	Value Expr
 }
 
 // Defer statement: defer expr (usually a function call)
 type DeferStmt struct {
+	Located
 	Call Expr
 	Pos  Position
 }
 
+// Yield statement: yield expr. A def whose body contains one is a generator.
+type YieldStmt struct {
+	Located
+	Value Expr
+}
+
+// Assert statement: assert cond[, message]
+type AssertStmt struct {
+	Located
+	Cond    Expr
+	Message Expr // optional
+}
+
 func (*LetStmt) node()       {}
 func (*LetStmt) stmt()       {}
 func (*TypeAliasStmt) node() {}
@@ -727,9 +946,14 @@ func (*ThrowStmt) node()     {}
 func (*ThrowStmt) stmt()     {}
 func (*DeferStmt) node()     {}
 func (*DeferStmt) stmt()     {}
+func (*YieldStmt) node()     {}
+func (*YieldStmt) stmt()     {}
+func (*AssertStmt) node()    {}
+func (*AssertStmt) stmt()    {}
 
 // Interface declaration with method signatures
 type InterfaceDecl struct {
+	Located
 	Name        string
 	Methods     []MethodSignature
 	TypeParams  []TypeParam // generic type parameters (e.g., [T, K, V])
@@ -737,6 +961,7 @@ type InterfaceDecl struct {
 	Permits     []string    // names permitted to implement
 	Fields      []FieldDecl // static fields
 	AccessLevel string      // "public", "private", "protected"
+	Exported    bool        // true if declared with 'export'
 }
 
 func (*InterfaceDecl) node() {}
@@ -756,10 +981,12 @@ type Parameter struct {
 	Name       string
 	Type       *Type // Type annotation using unified type system
 	IsVariadic bool  // true if this parameter is variadic (args...)
+	Default    Expr  // default value (name: Type = expr), evaluated when the argument is omitted
 }
 
 // Class declaration with full OOP support
 type ClassDecl struct {
+	Located
 	Name             string
 	Parent           string           // parent class name for inheritance
 	ParentTypeParams []TypeParam      // parent's generic type parameters (e.g., Container<T> has [T])
@@ -772,6 +999,7 @@ type ClassDecl struct {
 	Methods          []MethodDecl     // class methods
 	Constructor      *ConstructorDecl // class constructor
 	TypeParams       []TypeParam      // generic type parameters (e.g., [T, K, V])
+	Exported         bool             // true if declared with 'export'
 }
 
 func (*ClassDecl) node() {}
@@ -779,6 +1007,7 @@ func (*ClassDecl) stmt() {}
 
 // Enum declaration similar to Java enums
 type EnumDecl struct {
+	Located
 	Name        string
 	AccessLevel string // "public", "private", "protected"
 	IsSealed    bool
@@ -787,6 +1016,7 @@ type EnumDecl struct {
 	Fields      []FieldDecl      // enum can have fields
 	Methods     []MethodDecl     // enum can have methods
 	Constructor *ConstructorDecl // enum constructor
+	Exported    bool             // true if declared with 'export'
 }
 
 func (*EnumDecl) node() {}
@@ -800,10 +1030,12 @@ type EnumValue struct {
 
 // Record declaration similar to Java records
 type RecordDecl struct {
+	Located
 	Name        string
 	AccessLevel string            // "public", "private", "protected"
 	Components  []RecordComponent // record components
 	Methods     []MethodDecl      // additional methods
+	Exported    bool              // true if declared with 'export'
 }
 
 func (*RecordDecl) node() {}
@@ -817,6 +1049,7 @@ type RecordComponent struct {
 
 // Field declaration within a class
 type FieldDecl struct {
+	Located
 	Name      string
 	Type      *Type    // Type annotation using unified type system
 	Modifiers []string // public, private, protected, static, final
@@ -831,11 +1064,14 @@ type Annotation struct {
 	Raw        string // original casing as written in source
 	Normalized string // canonical lowercase form for comparisons
 	Known      bool   // true if the annotation is registered in the runtime
+	Args       []Expr // arguments given in parentheses, as in @Params(cases)
 }
 
 // Method declaration within a class
 type MethodDecl struct {
+	Located
 	Name        string
+	TypeParams  []TypeParam // generic type parameters (e.g., def map<R>(...))
 	Params      []Parameter
 	ReturnType  *Type // Return type using unified type system
 	Body        []Stmt
@@ -850,6 +1086,7 @@ func (*MethodDecl) stmt() {}
 
 // Constructor declaration
 type ConstructorDecl struct {
+	Located
 	Params []Parameter
 	Body   []Stmt
 }
@@ -859,6 +1096,7 @@ func (*ConstructorDecl) stmt() {}
 
 // Instance of expression: obj instanceof Type with optional variable assignment
 type InstanceOfExpr struct {
+	Spanned
 	Expr     Expr   // the object to check
 	TypeName string // the type to check against
 	Variable string // optional variable to assign (e.g., "foo" in "10 instanceof int final foo")
@@ -870,6 +1108,7 @@ func (*InstanceOfExpr) expr() {}
 
 // Type expression for Sys.type(obj)
 type TypeExpr struct {
+	Spanned
 	Expr Expr // the object to get type of
 }
 
@@ -892,12 +1131,15 @@ const (
 	OpGte
 	OpAnd
 	OpOr
-	OpNot // unary
-	OpNeg // unary minus
+	OpNot         // unary
+	OpNeg         // unary minus
+	OpPow         // exponentiation (**)
+	OpNilCoalesce // a ?? b: a unless it is nil
 )
 
 // Lambda expression: (params) => expr or (params) => do ... end
 type LambdaExpr struct {
+	Spanned
 	Params     []Parameter // updated to support typed and variadic parameters
 	Body       Expr        // expression body (single expression)
 	BlockBody  []Stmt      // statement block body (multi-line lambda with do...end)
@@ -910,6 +1152,7 @@ func (*LambdaExpr) expr() {}
 
 // Thread expressions for concurrency
 type ThreadSpawnExpr struct {
+	Spanned
 	Body []Stmt // thread body statements
 }
 
@@ -917,15 +1160,18 @@ func (*ThreadSpawnExpr) node() {}
 func (*ThreadSpawnExpr) expr() {}
 
 type ThreadJoinExpr struct {
+	Spanned
 	Thread Expr // thread expression to join
 }
 
 func (*ThreadJoinExpr) node() {}
 func (*ThreadJoinExpr) expr() {}
 
-// Channel creation: channel[Type]()
+// Channel creation: channel[Type]() or channel[Type](capacity)
 type ChannelExpr struct {
+	Spanned
 	ElemType string // Type of elements in the channel
+	Capacity Expr   // Buffer size; nil for an unbuffered channel
 }
 
 func (*ChannelExpr) node() {}
@@ -933,6 +1179,7 @@ func (*ChannelExpr) expr() {}
 
 // Select statement for channel operations
 type SelectStmt struct {
+	Located
 	Cases []SelectCase
 	Pos   Position
 }
@@ -950,6 +1197,7 @@ func (*SelectStmt) stmt() {}
 
 // Switch statement for value/type/enum matching
 type SwitchStmt struct {
+	Located
 	Expr    Expr         // expression to switch on (can be nil for type switches)
 	Cases   []SwitchCase // switch cases
 	Default []Stmt       // default case body (optional)
@@ -969,6 +1217,10 @@ type SwitchCase struct {
 	// Values field is used with enum member access expressions
 
 	Body []Stmt // statements to execute if this case matches
+
+	// Fallthrough is set when the body ends with `fallthrough`: the next case's
+	// body runs as well, without testing its values
+	Fallthrough bool
 }
 
 func (*SwitchStmt) node() {}
@@ -976,6 +1228,7 @@ func (*SwitchStmt) stmt() {}
 
 // Ternary expression: condition ? trueBranch : falseBranch
 type TernaryExpr struct {
+	Spanned
 	Condition   Expr
 	TrueBranch  Expr
 	FalseBranch Expr
@@ -986,6 +1239,7 @@ func (*TernaryExpr) expr() {}
 
 // RangeExpr represents range expressions like 1...10 or arr[1...3]
 type RangeExpr struct {
+	Spanned
 	Start     Expr
 	End       Expr
 	Inclusive bool // true for ..., false for ..
@@ -993,3 +1247,13 @@ type RangeExpr struct {
 
 func (*RangeExpr) node() {}
 func (*RangeExpr) expr() {}
+
+// SpreadExpr expands an array in place: [...a, x] or f(...args). It only appears
+// as an array literal element or a call argument.
+type SpreadExpr struct {
+	Spanned
+	X Expr
+}
+
+func (*SpreadExpr) node() {}
+func (*SpreadExpr) expr() {}
@@ -216,3 +216,44 @@ if len(nestedMap.TypeParams) != 2 {
 t.Errorf("Nested map should have 2 type params, got %d", len(nestedMap.TypeParams))
 }
 }
+
+// TestTypeFromString_FunctionType tests parsing of function type annotations
+func TestTypeFromString_FunctionType(t *testing.T) {
+	tests := []struct {
+		typeName   string
+		params     []string
+		returnType string
+	}{
+		{"() -> Int", nil, "Int"},
+		{"(Int, Int) -> Int", []string{"Int", "Int"}, "Int"},
+		{"(List<Int>) -> Map<String, Int>", []string{"List<Int>"}, "Map<String, Int>"},
+		{"((Int) -> Int, Int) -> (Int) -> Int", []string{"(Int) -> Int", "Int"}, "(Int) -> Int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			typ := TypeFromString(tt.typeName)
+			if typ == nil || !typ.IsFunction {
+				t.Fatalf("expected a function type, got %+v", typ)
+			}
+			if typ.Name != tt.typeName {
+				t.Errorf("Name = %q, want %q", typ.Name, tt.typeName)
+			}
+			if len(typ.ParamTypes) != len(tt.params) {
+				t.Fatalf("got %d parameter types, want %d", len(typ.ParamTypes), len(tt.params))
+			}
+			for i, param := range tt.params {
+				if got := GetTypeNameString(typ.ParamTypes[i]); got != param {
+					t.Errorf("param %d = %q, want %q", i, got, param)
+				}
+			}
+			if got := GetTypeNameString(typ.ReturnType); got != tt.returnType {
+				t.Errorf("ReturnType = %q, want %q", got, tt.returnType)
+			}
+		})
+	}
+
+	if _, _, ok := SplitFunctionType("(Int)"); ok {
+		t.Errorf("expected (Int) without '->' not to be a function type")
+	}
+}
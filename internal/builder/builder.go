@@ -5,15 +5,21 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/ArubikU/polyloft/internal/analysis"
 	"github.com/ArubikU/polyloft/internal/config"
+	"github.com/ArubikU/polyloft/internal/engine"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
 )
 
 // Builder handles the compilation of Hy source to executables
 type Builder struct {
 	Config     *config.Config
 	OutputPath string
+	Target     Target // platform to build for; the zero value builds for the host
 }
 
 // New creates a new Builder with the given configuration
@@ -42,6 +48,9 @@ func (b *Builder) Build() error {
 	}
 	b.OutputPath = absOutput
 	fmt.Printf("[build] Output: %s\n", b.OutputPath)
+	if b.Target != (Target{}) {
+		fmt.Printf("[build] Target: %s\n", b.Target)
+	}
 
 	// Create a temporary directory for build artifacts
 	tmpDir, err := os.MkdirTemp("", "polyloft-build-*")
@@ -50,14 +59,32 @@ func (b *Builder) Build() error {
 	}
 	defer os.RemoveAll(tmpDir)
 
+	// Find the asset files to embed
+	assets, err := b.collectAssets()
+	if err != nil {
+		return fmt.Errorf("failed to collect assets: %w", err)
+	}
+	if len(assets) > 0 {
+		fmt.Printf("[build] Embedding %d asset file(s)\n", len(assets))
+	}
+
+	// Trace the modules the program imports so they can be bundled
+	modules, err := b.collectModules()
+	if err != nil {
+		return fmt.Errorf("failed to collect imported modules: %w", err)
+	}
+	if len(modules) > 0 {
+		fmt.Printf("[build] Bundling %d imported module(s)\n", len(modules))
+	}
+
 	// Generate Go wrapper code
 	wrapperPath := filepath.Join(tmpDir, "main.go")
-	if err := b.generateGoWrapper(wrapperPath); err != nil {
+	if err := b.generateGoWrapper(wrapperPath, modules, len(assets) > 0); err != nil {
 		return fmt.Errorf("failed to generate Go wrapper: %w", err)
 	}
 
-	// Copy polyloft source and dependencies
-	if err := b.copySourceFiles(tmpDir); err != nil {
+	// Copy polyloft source, dependencies and assets
+	if err := b.copySourceFiles(tmpDir, assets); err != nil {
 		return fmt.Errorf("failed to copy source files: %w", err)
 	}
 
@@ -70,8 +97,10 @@ func (b *Builder) Build() error {
 	return nil
 }
 
-// generateGoWrapper creates a Go main.go that embeds and runs the Hy code
-func (b *Builder) generateGoWrapper(outputPath string) error {
+// generateGoWrapper creates a Go main.go that embeds and runs the Hy code,
+// along with the source of the modules it imports. With embedAssets set, it
+// also embeds the assets directory copied next to it.
+func (b *Builder) generateGoWrapper(outputPath string, modules engine.ModuleBundle, embedAssets bool) error {
 	entryPoint := b.Config.Project.EntryPoint
 	
 	// Read the entry point source
@@ -83,32 +112,174 @@ func (b *Builder) generateGoWrapper(outputPath string) error {
 	// Escape the source for embedding in Go string
 	escapedSource := strings.ReplaceAll(string(sourceData), "`", "` + \"`\" + `")
 
+	// Modules are written in sorted order so builds are reproducible
+	keys := make([]string, 0, len(modules))
+	for key := range modules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var moduleEntries strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&moduleEntries, "\t%q: %q,\n", key, modules[key])
+	}
+
+	imports, assetsDecl, assetsArg := "", "", "nil"
+	if embedAssets {
+		imports = "\t\"embed\"\n\t\"io/fs\"\n"
+		assetsDecl = fmt.Sprintf("\n//go:embed all:%s\nvar assetFiles embed.FS\n", assetsDir)
+		assetsArg = "assets"
+	}
+
 	goCode := fmt.Sprintf(`package main
 
 import (
-	"os"
+%s	"os"
 	"github.com/ArubikU/polyloft/pkg/runtime"
 )
 
 const embeddedSource = %s
 
+// bundledModules holds the imported modules, so the executable never reads
+// the project's source tree
+var bundledModules = map[string]string{
+%s}
+%s
 func main() {
-	if err := runtime.ExecuteSource(embeddedSource, "%s"); err != nil {
+%s	err := runtime.Run(runtime.Artifact{
+		Source:     embeddedSource,
+		EntryPoint: %q,
+		Modules:    bundledModules,
+		Assets:     %s,
+	})
+	if err != nil {
 		os.Exit(1)
 	}
 }
-`, "`"+escapedSource+"`", entryPoint)
+`, imports, "`"+escapedSource+"`", moduleEntries.String(), assetsDecl, assetsSub(embedAssets), entryPoint, assetsArg)
 
 	return os.WriteFile(outputPath, []byte(goCode), 0644)
 }
 
+// assetsSub is the wrapper code that roots the embedded assets at the project
+func assetsSub(embedAssets bool) string {
+	if !embedAssets {
+		return ""
+	}
+	return fmt.Sprintf("\tassets, _ := fs.Sub(assetFiles, %q)\n", assetsDir)
+}
+
+// collectModules follows the imports of the entry point, and of every module
+// it imports in turn, and returns the source of each module they load. Imports
+// resolve as they do at runtime, including those inside functions; one that
+// cannot be resolved fails the build.
+func (b *Builder) collectModules() (engine.ModuleBundle, error) {
+	modules := engine.ModuleBundle{}
+	queue := []string{b.Config.Project.EntryPoint}
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		lx := &lexer.Lexer{}
+		prog, err := parser.NewWithSource(lx.Scan(source), file, string(source)).Parse()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for _, im := range analysis.Imports(prog) {
+			files, err := engine.ResolveImport(file, im.Path)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", file, im.Start.Line, err)
+			}
+			for _, path := range files {
+				key := engine.BundleKey(path)
+				if _, seen := modules[key]; seen {
+					continue
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return nil, err
+				}
+				modules[key] = string(data)
+				queue = append(queue, path)
+			}
+		}
+	}
+	return modules, nil
+}
+
+// assetsDir is the directory of the build that holds the embedded assets
+const assetsDir = "assets"
+
 // copySourceFiles copies necessary source files to the build directory
-func (b *Builder) copySourceFiles(buildDir string) error {
-	// For now, we embed the source directly in the wrapper
-	// In the future, we could copy library files here
+func (b *Builder) copySourceFiles(buildDir string, assets []string) error {
+	// The entry point and its modules are embedded directly in the wrapper;
+	// assets go under assetsDir, keeping their paths relative to the project
+	for _, asset := range assets {
+		data, err := os.ReadFile(filepath.FromSlash(asset))
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(buildDir, assetsDir, filepath.FromSlash(asset))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// collectAssets expands the [build] assets of the config into the sorted,
+// slash-separated paths of the files to embed. Each entry is a path or glob
+// pattern relative to the project root, and a directory brings in every file
+// beneath it. An entry that matches nothing, or points outside the project, is
+// an error.
+func (b *Builder) collectAssets() ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		name := filepath.ToSlash(filepath.Clean(path))
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+
+	for _, pattern := range b.Config.Build.Assets {
+		clean := filepath.Clean(pattern)
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("asset %q is outside the project", pattern)
+		}
+		matches, err := filepath.Glob(clean)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("asset %q matches no files", pattern)
+		}
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 // compileGoWrapper compiles the generated Go code to an executable
 func (b *Builder) compileGoWrapper(buildDir string) error {
 	fmt.Println("[build] Compiling Go executable...")
@@ -191,6 +362,10 @@ func (b *Builder) compileGoWrapper(buildDir string) error {
 	fmt.Println("[build] Building executable...")
 	buildCmd := exec.Command("go", "build", "-o", b.OutputPath, ".")
 	buildCmd.Dir = buildDir
+	if b.Target != (Target{}) && b.Target != HostTarget() {
+		// Cross-compile without cgo, which would need a C toolchain for the target
+		buildCmd.Env = append(os.Environ(), "GOOS="+b.Target.OS, "GOARCH="+b.Target.Arch, "CGO_ENABLED=0")
+	}
 	buildCmd.Stdout = os.Stdout
 	buildCmd.Stderr = os.Stderr
 	
@@ -0,0 +1,125 @@
+package builder
+
+import (
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/config"
+)
+
+func TestCollectAssets(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"public/css/site.css", "public/.well-known/security.txt", "templates/index.html", "templates/notes.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	b := New(&config.Config{Build: config.BuildConfig{Assets: []string{"templates/*.html", "public", "./public/css/site.css"}}}, "app.pfx")
+	assets, err := b.collectAssets()
+	if err != nil {
+		t.Fatalf("collectAssets: %v", err)
+	}
+	want := "public/.well-known/security.txt,public/css/site.css,templates/index.html"
+	if strings.Join(assets, ",") != want {
+		t.Errorf("got %v, want %s", assets, want)
+	}
+
+	buildDir := t.TempDir()
+	if err := b.copySourceFiles(buildDir, assets); err != nil {
+		t.Fatalf("copySourceFiles: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(buildDir, assetsDir, "templates", "index.html")); err != nil || string(data) != "templates/index.html" {
+		t.Errorf("asset not copied: %q (%v)", data, err)
+	}
+
+	for _, bad := range []string{"missing/*.png", "../secrets", "/etc/passwd"} {
+		b.Config.Build.Assets = []string{bad}
+		if _, err := b.collectAssets(); err == nil {
+			t.Errorf("collectAssets(%q) should fail", bad)
+		}
+	}
+}
+
+func TestCollectModules(t *testing.T) {
+	dir := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	files := map[string]string{
+		"src/main.pf":         "import helper { greet }\nimport shapes\ndef later():\n    import globals\nend\nimport helper { greet }\n",
+		"src/helper.pf":       "import util.strings { wrap }\ndef greet(n):\n    return wrap(n)\nend\n",
+		"src/util/strings.pf": "def wrap(s):\n    return s\nend\n",
+		"libs/shapes/a.pf":    "def area(r):\n    return r * r\nend\n",
+		"libs/shapes/b.pf":    "def side(r):\n    return r\nend\n",
+		"src/unused.pf":       "def unused():\nend\n",
+	}
+	for name, source := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	globalLib := filepath.Join(home, ".polyloft", "libs")
+	if err := os.MkdirAll(globalLib, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(globalLib, "globals.pf"), []byte("let x = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	b := New(&config.Config{Project: config.ProjectConfig{EntryPoint: "src/main.pf"}}, "app.pfx")
+	modules, err := b.collectModules()
+	if err != nil {
+		t.Fatalf("collectModules: %v", err)
+	}
+	var keys []string
+	for key := range modules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	want := "libs/shapes/a.pf,libs/shapes/b.pf,src/helper.pf,src/util/strings.pf,~/.polyloft/libs/globals.pf"
+	if strings.Join(keys, ",") != want {
+		t.Errorf("got modules %v, want %s", keys, want)
+	}
+	if modules["src/util/strings.pf"] != files["src/util/strings.pf"] {
+		t.Errorf("unexpected source %q", modules["src/util/strings.pf"])
+	}
+
+	wrapper := filepath.Join(t.TempDir(), "main.go")
+	if err := b.generateGoWrapper(wrapper, modules, true); err != nil {
+		t.Fatalf("generateGoWrapper: %v", err)
+	}
+	if _, err := goparser.ParseFile(token.NewFileSet(), wrapper, nil, 0); err != nil {
+		t.Errorf("generated wrapper is not valid Go: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "src", "main.pf"), []byte("import missing.mod\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.collectModules(); err == nil || !strings.Contains(err.Error(), "src/main.pf:1: module not found") {
+		t.Errorf("expected an unresolved import to fail, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package builder
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Target is the operating system and architecture an executable is built for
+type Target struct {
+	OS   string
+	Arch string
+}
+
+// SupportedTargets lists the platforms the embedded runtime is known to build for
+var SupportedTargets = []Target{
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+	{"linux", "arm"},
+	{"windows", "amd64"},
+	{"windows", "arm64"},
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"freebsd", "amd64"},
+}
+
+// HostTarget returns the platform polyloft itself is running on
+func HostTarget() Target {
+	return Target{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+// ParseTarget parses a target written as "os/arch", such as "linux/amd64"
+func ParseTarget(s string) (Target, error) {
+	goos, goarch, ok := strings.Cut(strings.TrimSpace(s), "/")
+	target := Target{OS: strings.ToLower(goos), Arch: strings.ToLower(goarch)}
+	if ok {
+		for _, supported := range SupportedTargets {
+			if supported == target {
+				return target, nil
+			}
+		}
+	}
+	names := make([]string, len(SupportedTargets))
+	for idx, supported := range SupportedTargets {
+		names[idx] = supported.String()
+	}
+	return Target{}, fmt.Errorf("unknown build target %q; supported targets: %s", s, strings.Join(names, ", "))
+}
+
+// String renders the target as "os/arch"
+func (t Target) String() string {
+	return t.OS + "/" + t.Arch
+}
+
+// Suffix is the "-os-arch" part added to output names built for the target
+func (t Target) Suffix() string {
+	return "-" + t.OS + "-" + t.Arch
+}
@@ -0,0 +1,27 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	target, err := ParseTarget(" Linux/AMD64 ")
+	if err != nil {
+		t.Fatalf("ParseTarget: %v", err)
+	}
+	if target != (Target{OS: "linux", Arch: "amd64"}) || target.Suffix() != "-linux-amd64" {
+		t.Errorf("got %v (suffix %q)", target, target.Suffix())
+	}
+
+	for _, bad := range []string{"linux", "plan9/386", "windows/", ""} {
+		_, err := ParseTarget(bad)
+		if err == nil {
+			t.Errorf("ParseTarget(%q) should fail", bad)
+			continue
+		}
+		if !strings.Contains(err.Error(), "darwin/arm64") {
+			t.Errorf("error should list the supported targets: %v", err)
+		}
+	}
+}
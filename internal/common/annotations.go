@@ -48,4 +48,8 @@ func LookupAnnotation(name string) (AnnotationInfo, bool) {
 
 func init() {
 	RegisterAnnotation("override", AnnotationFlags{IsOverride: true})
+	// Test lifecycle annotations, read by the test runner
+	for _, name := range []string{"Test", "Params", "BeforeEach", "AfterEach", "BeforeAll", "AfterAll"} {
+		RegisterAnnotation(name, AnnotationFlags{})
+	}
 }
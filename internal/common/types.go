@@ -29,9 +29,39 @@ func SelectConstructorOverload(constructors []ConstructorInfo, argCount int) *Co
 		}
 	}
 
+	// Try omitting parameters that have default values
+	for i := range constructors {
+		if AcceptsArgCount(constructors[i].Params, argCount) {
+			return &constructors[i]
+		}
+	}
+
 	return nil
 }
 
+// OverloadsOfKind narrows methods to the static or the instance overloads, so a
+// class can declare both under one name, like String.format and "...".format.
+// When none are of that kind methods is returned unchanged, leaving callers to
+// report the mismatch.
+func OverloadsOfKind(methods []MethodInfo, static bool) []MethodInfo {
+	matching := 0
+	for _, m := range methods {
+		if m.IsStatic == static {
+			matching++
+		}
+	}
+	if matching == 0 || matching == len(methods) {
+		return methods
+	}
+	filtered := make([]MethodInfo, 0, matching)
+	for _, m := range methods {
+		if m.IsStatic == static {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
 func SelectMethodOverload(methods []MethodInfo, argCount int) *MethodInfo {
 	// Try exact match first
 	for i := range methods {
@@ -52,9 +82,31 @@ func SelectMethodOverload(methods []MethodInfo, argCount int) *MethodInfo {
 		}
 	}
 
+	// Try omitting parameters that have default values
+	for i := range methods {
+		if AcceptsArgCount(methods[i].Params, argCount) {
+			return &methods[i]
+		}
+	}
+
 	return nil
 }
 
+// AcceptsArgCount reports whether a call with argCount arguments can bind params,
+// counting parameters with default values as optional
+func AcceptsArgCount(params []ast.Parameter, argCount int) bool {
+	required := 0
+	for _, param := range params {
+		if param.IsVariadic {
+			return argCount >= required
+		}
+		if param.Default == nil {
+			required++
+		}
+	}
+	return argCount >= required && argCount <= len(params)
+}
+
 // PrebuildedDefinition represents a forward reference to a class or interface
 // Used for permits declarations where the target may not be defined yet
 type PrebuildedDefinition struct {
@@ -264,6 +316,7 @@ type RecordInstance struct {
 // MethodInfo contains method metadata
 type MethodInfo struct {
 	Name        string
+	TypeParams  []ast.TypeParam // Method-level generic type parameters (def map<R>(...))
 	Params      []ast.Parameter
 	ReturnType  *ast.Type // Return type using unified type system
 	Body        []ast.Stmt
@@ -271,6 +324,7 @@ type MethodInfo struct {
 	IsAbstract  bool
 	IsStatic    bool
 	IsPrivate   bool
+	IsGenerator bool // Body contains yield; calls return a Generator
 	BuiltinImpl Func // Optional builtin implementation
 }
 
@@ -349,12 +403,23 @@ func (c *Channel) IsClosed() bool {
 	return c.closed
 }
 
+// Len returns the number of values queued in the channel's buffer
+func (c *Channel) Len() int {
+	return len(c.Ch)
+}
+
+// Cap returns the channel's buffer size; 0 for an unbuffered channel
+func (c *Channel) Cap() int {
+	return cap(c.Ch)
+}
+
 // Env is a simple lexical environment for variables and functions.
 type Env struct {
 	Parent           *Env
 	Vars             map[string]any
 	Consts           map[string]bool
 	Finals           map[string]bool
+	Types            map[string]string   // declared generic types of variables, checked on reassignment
 	Defers           []func() error      // stack of deferred calls (LIFO)
 	FileName         string              // current file being executed
 	PackageName      string              // current package/directory
@@ -365,6 +430,8 @@ type Env struct {
 	PositionStack    []PositionInfo      // stack of positions for better stack traces
 	ImportedClasses  map[string]string   // className -> packageName, tracks imported classes
 	ImportedPackages map[string]struct{} // packageName -> struct{}, tracks imported packages
+	Captured         bool                // referenced by a closure or thread, so it outlives its call
+	Hooks            any                 // per-run settings of the program, set by the engine on top-level envs
 
 	// Fast variable slots for common loop variables (0-9 represent i, j, k, etc.)
 	// Uses array access instead of map lookup for ~2-3x faster access
@@ -761,6 +828,14 @@ func (e *Env) GetRoot() *Env {
 	return root
 }
 
+// DeclareType records the declared type of a variable in this scope
+func (e *Env) DeclareType(k, typeName string) {
+	if e.Types == nil {
+		e.Types = make(map[string]string)
+	}
+	e.Types[k] = typeName
+}
+
 // Define defines a new variable, optionally as a constant
 func (e *Env) Define(k string, v any, kind string) {
 	e.Vars[k] = v
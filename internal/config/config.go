@@ -11,6 +11,8 @@ import (
 type Config struct {
 	Project      ProjectConfig      `toml:"project"`
 	Dependencies DependenciesConfig `toml:"dependencies"`
+	Lint         LintConfig         `toml:"lint"`
+	Build        BuildConfig        `toml:"build"`
 }
 
 // ProjectConfig contains project-level settings
@@ -20,6 +22,18 @@ type ProjectConfig struct {
 	Version    string `toml:"version"`
 }
 
+// LintConfig controls polyloft lint
+type LintConfig struct {
+	Rules map[string]bool `toml:"rules"` // rule name to whether it is reported; rules left out are on
+}
+
+// BuildConfig controls polyloft build
+type BuildConfig struct {
+	// Assets lists files to embed in the executable, as paths or glob patterns
+	// relative to the project root; a directory includes every file under it
+	Assets []string `toml:"assets"`
+}
+
 // DependenciesConfig contains both Go and Polyloft library dependencies
 type DependenciesConfig struct {
 	Go []GoDependency `toml:"go"`
@@ -71,6 +85,9 @@ name = "my-polyloft-project"
 version = "0.1.0"
 entry_point = "src/main.pf"
 
+[build]
+assets = ["public", "templates/*.html"]
+
 [[dependencies.go]]
 name = "github.com/example/library"
 version = "v1.0.0"
@@ -23,6 +23,12 @@ version = "v1.0.0"
 [[dependencies.pf]]
 name = "math.vector"
 version = "1.0.0"
+
+[lint.rules]
+float-equality = false
+
+[build]
+assets = ["public", "templates/*.html"]
 `
 	
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
@@ -51,6 +57,14 @@ version = "1.0.0"
 	if len(cfg.Dependencies.Pf) != 1 {
 		t.Errorf("Expected 1 Polyloft dependency, got %d", len(cfg.Dependencies.Pf))
 	}
+
+	if enabled, ok := cfg.Lint.Rules["float-equality"]; !ok || enabled {
+		t.Errorf("Expected lint rule float-equality to be off, got %v", cfg.Lint.Rules)
+	}
+
+	if len(cfg.Build.Assets) != 2 || cfg.Build.Assets[1] != "templates/*.html" {
+		t.Errorf("Expected 2 build assets, got %v", cfg.Build.Assets)
+	}
 }
 
 func TestLoadMissingEntryPoint(t *testing.T) {
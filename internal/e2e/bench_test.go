@@ -0,0 +1,82 @@
+package e2e
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ArubikU/polyloft/internal/engine"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+func TestBench_RunsBenchFunctionsInOrder(t *testing.T) {
+	code := `var calls = 0
+def helper():
+    calls = calls + 1
+end
+def benchSum():
+    let total = 1 + 2
+end
+def benchHelper():
+    helper()
+end
+`
+	engine.ResetGlobalRegistries()
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(code))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	cfg := engine.BenchConfig{Time: 5 * time.Millisecond, Rounds: 3}
+	results, err := engine.RunBenchmarks(prog, engine.Options{}, "", "", code, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "benchSum" || results[1].Name != "benchHelper" {
+		t.Fatalf("expected benchSum and benchHelper, got %+v", results)
+	}
+	for _, r := range results {
+		if r.Iterations < 1 || len(r.NsPerOp) != 3 || r.Mean() <= 0 {
+			t.Errorf("unexpected measurements for %s: %+v", r.Name, r)
+		}
+		if r.Min() > r.Mean() || r.Max() < r.Mean() {
+			t.Errorf("inconsistent statistics for %s: min %.1f mean %.1f max %.1f", r.Name, r.Min(), r.Mean(), r.Max())
+		}
+	}
+
+	out := &bytes.Buffer{}
+	engine.FormatBenchResults(out, results)
+	if !strings.Contains(out.String(), "benchHelper") || !strings.Contains(out.String(), "ns/op") {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+}
+
+func TestBench_FilterAndErrors(t *testing.T) {
+	code := `def benchOk():
+    let x = 1
+end
+def benchBroken():
+    undefinedName
+end
+`
+	engine.ResetGlobalRegistries()
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(code))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	cfg := engine.BenchConfig{Time: time.Millisecond, Rounds: 1, Filter: "Ok"}
+	results, err := engine.RunBenchmarks(prog, engine.Options{}, "", "", code, cfg)
+	if err != nil || len(results) != 1 || results[0].Name != "benchOk" {
+		t.Fatalf("expected only benchOk to run, got %+v (err %v)", results, err)
+	}
+
+	cfg.Filter = "Broken"
+	if _, err := engine.RunBenchmarks(prog, engine.Options{}, "", "", code, cfg); err == nil {
+		t.Fatalf("expected error from failing benchmark")
+	}
+}
@@ -471,3 +471,52 @@ println("Even count: " + count.toString())
 		t.Fatal("test timeout")
 	}
 }
+
+func TestChannel_Buffered(t *testing.T) {
+	// A buffered channel accepts sends without a receiver until it is full
+	src := `
+let ch = channel[Int](3)
+ch.send(1)
+ch.send(2)
+println("len=" + len(ch).toString() + " cap=" + ch.cap().toString())
+println("first=" + ch.recv().toString())
+println("len=" + len(ch).toString())
+
+let unbuffered = channel[Int](0)
+println("unbuffered cap=" + unbuffered.cap().toString())
+
+try
+    channel[Int](-1)
+catch e: ValueError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	items := lx.Scan([]byte(src))
+	p := parser.New(items)
+	prog, err := p.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	done := make(chan bool, 1)
+	buf := &bytes.Buffer{}
+
+	go func() {
+		_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+		done <- true
+	}()
+
+	select {
+	case <-done:
+		if err != nil {
+			t.Fatalf("eval error: %v", err)
+		}
+		want := "len=2 cap=3\nfirst=1\nlen=1\nunbuffered cap=0\nchannel capacity must be non-negative, got -1\n"
+		if got := buf.String(); got != want {
+			t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("test timeout - send on a buffered channel blocked")
+	}
+}
@@ -0,0 +1,59 @@
+package e2e
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/engine"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+func TestCoverage_CountsExecutedLines(t *testing.T) {
+	code := `def classify(x):
+    if x > 10:
+        return "big"
+    else:
+        return "small"
+    end
+end
+for i in [1, 2, 3]:
+    println(classify(i))
+end
+`
+	engine.ResetGlobalRegistries()
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan([]byte(code)), "cov.pf", code).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	cov := engine.NewCoverage()
+	out := &bytes.Buffer{}
+	if _, err := engine.EvalWithContextAndSource(prog, engine.Options{Stdout: out, Coverage: cov}, "cov.pf", "", code); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if hits := cov.Hits("cov.pf", 9); hits != 3 {
+		t.Errorf("expected line 9 to run 3 times, got %d", hits)
+	}
+	if hits := cov.Hits("cov.pf", 3); hits != 0 {
+		t.Errorf("expected line 3 to never run, got %d", hits)
+	}
+
+	summary := cov.Summary()
+	if len(summary) != 1 {
+		t.Fatalf("expected one file in summary, got %d", len(summary))
+	}
+	fc := summary[0]
+	if fc.Total != 6 || fc.Covered != 5 || len(fc.Uncovered) != 1 || fc.Uncovered[0] != 3 {
+		t.Errorf("unexpected summary: %+v", fc)
+	}
+
+	report := &bytes.Buffer{}
+	cov.Report(report)
+	if !strings.Contains(report.String(), "83.3% (5/6 lines)  uncovered: 3") {
+		t.Errorf("unexpected report:\n%s", report.String())
+	}
+}
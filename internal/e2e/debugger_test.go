@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/engine"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+func runDebugSession(t *testing.T, code, commands string, breakpoints ...string) (string, error) {
+	t.Helper()
+	engine.ResetGlobalRegistries()
+
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan([]byte(code)), "main.pf", code).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	dbg := engine.NewDebugger(strings.NewReader(commands), out)
+	if len(breakpoints) == 0 {
+		dbg.StepOnStart()
+	}
+	for _, bp := range breakpoints {
+		if err := dbg.AddBreakpoint(bp); err != nil {
+			t.Fatalf("AddBreakpoint(%q): %v", bp, err)
+		}
+	}
+	_, err = engine.EvalWithContextAndSource(prog, engine.Options{Stdout: out, Debugger: dbg}, "main.pf", "", code)
+	return out.String(), err
+}
+
+func TestDebugger_BreakpointInspectAndContinue(t *testing.T) {
+	code := `def total(items):
+    let sum = 0
+    for x in items:
+        sum = sum + x
+    end
+    return sum
+end
+let values = [1, 2, 3]
+println(total(values))
+`
+	out, err := runDebugSession(t, code, "locals\np sum + 100\nc\n", "main.pf:6")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"paused at main.pf:6\n",
+		"     6 |     return sum\n",
+		"  sum = 6\n",
+		"  values = [1, 2, 3]\n",
+		"106\n",
+		"(debug) 6\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestDebugger_StepAndQuit(t *testing.T) {
+	code := `let a = 1
+let b = a + 1
+println(b)
+`
+	out, err := runDebugSession(t, code, "s\np a\nq\n")
+	if !errors.Is(err, engine.ErrDebugQuit) {
+		t.Fatalf("expected ErrDebugQuit, got %v", err)
+	}
+	if !strings.Contains(out, "paused at main.pf:1\n") || !strings.Contains(out, "paused at main.pf:2\n") {
+		t.Errorf("expected to step from line 1 to line 2, got:\n%s", out)
+	}
+	if !strings.Contains(out, "(debug) 1\n") {
+		t.Errorf("expected print of a, got:\n%s", out)
+	}
+	if strings.Contains(out, "paused at main.pf:3") {
+		t.Errorf("program should stop after quit, got:\n%s", out)
+	}
+}
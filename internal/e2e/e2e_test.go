@@ -151,6 +151,68 @@ println(names[0], values[1].name, Mode.size())
 	}
 }
 
+func TestEval_EnumNameAndOrdinalMethods(t *testing.T) {
+	engine.ResetGlobalRegistries()
+	src := `
+enum Suit
+    CLUBS
+    HEARTS
+    SPADES
+end
+
+enum Level
+    LOW
+    HIGH
+
+    def name():
+        return "level " + this.ordinal
+    end
+end
+
+class Person
+    var nick
+    Person(n):
+        this.nick = n
+    end
+    def name():
+        return this.nick
+    end
+end
+
+let calls = [0]
+def pick():
+    calls[0] = calls[0] + 1
+    return Suit.SPADES
+end
+
+let menu = Suit.values().map((s) => "#{s.ordinal() + 1}. #{s.name()}")
+println(menu)
+println(Suit.HEARTS.name, Suit.HEARTS.ordinal, Suit.valueOf("CLUBS").ordinal())
+println(pick().name(), calls[0], Level.HIGH.name(), Level.HIGH.name, Person("ana").name())
+try
+    Suit.valueOf("DIAMONDS")
+catch e: ValueError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if _, err := engine.Eval(prog, engine.Options{Stdout: buf}); err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "[1. CLUBS, 2. HEARTS, 3. SPADES]\n" +
+		"HEARTS 1 0\n" +
+		"SPADES 1 level 1 HIGH ana\n" +
+		"enum value 'DIAMONDS' not found in enum 'Suit'\n"
+	if got := buf.String(); got != expected {
+		t.Fatalf("unexpected output\nwant %q\n got %q", expected, got)
+	}
+}
+
 func TestEval_ClassTypeAndInstanceOf(t *testing.T) {
 	src := `
 class Creature
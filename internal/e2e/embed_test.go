@@ -0,0 +1,284 @@
+package e2e
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ArubikU/polyloft/pkg/polyloft"
+)
+
+func TestEmbed_RunWithNativeFunctions(t *testing.T) {
+	var out bytes.Buffer
+	interp := polyloft.New(polyloft.Options{Stdout: &out})
+	interp.RegisterFunc("lookup", func(args []any) (any, error) {
+		key, _ := args[0].(string)
+		if key == "missing" {
+			return nil, errors.New("no such key")
+		}
+		return map[string]any{"key": key, "tags": []any{"a", 2, 1.5, true}}, nil
+	})
+	interp.Set("limit", 3)
+
+	result, err := interp.Run(`
+let entry = lookup("answer")
+println(entry["key"], entry["tags"].length(), limit + 1)
+try
+    lookup("missing")
+catch e: RuntimeError
+    println("caught:", e.message)
+end
+def double(x):
+    return x * 2
+end
+entry["tags"]
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "answer 4 4\ncaught: no such key\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+	if !reflect.DeepEqual(result, []any{"a", 2, 1.5, true}) {
+		t.Errorf("unexpected result %#v", result)
+	}
+
+	// Definitions stay visible to later runs
+	result, err = interp.Run(`double(limit)`)
+	if err != nil || result != 6 {
+		t.Errorf("second run: %#v, %v", result, err)
+	}
+	if entry, ok := interp.Get("entry"); !ok || entry.(map[string]any)["key"] != "answer" {
+		t.Errorf("Get(entry) = %#v, %v", entry, ok)
+	}
+}
+
+type embedCounter struct {
+	name  string
+	count int
+}
+
+func TestEmbed_NativeTypes(t *testing.T) {
+	var out bytes.Buffer
+	interp := polyloft.New(polyloft.Options{Stdout: &out})
+	err := interp.RegisterType(polyloft.Type{
+		Name:   "EmbedCounter",
+		Sample: (*embedCounter)(nil),
+		New: func(args []any) (any, error) {
+			if len(args) != 1 {
+				return nil, errors.New("EmbedCounter expects a name")
+			}
+			name, _ := args[0].(string)
+			return &embedCounter{name: name}, nil
+		},
+		Methods: map[string]func(self any, args []any) (any, error){
+			"add": func(self any, args []any) (any, error) {
+				c := self.(*embedCounter)
+				n, ok := args[0].(int)
+				if !ok {
+					return nil, errors.New("add expects an Int")
+				}
+				c.count += n
+				return c.count, nil
+			},
+			"label": func(self any, args []any) (any, error) {
+				c := self.(*embedCounter)
+				return c.name + "=" + strings.Repeat("*", c.count), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterType: %v", err)
+	}
+	shared := &embedCounter{name: "shared", count: 1}
+	interp.Set("shared", shared)
+	interp.RegisterFunc("total", func(args []any) (any, error) {
+		sum := 0
+		for _, arg := range args {
+			sum += arg.(*embedCounter).count
+		}
+		return sum, nil
+	})
+
+	result, err := interp.Run(`
+let c = EmbedCounter("hits")
+c.add(2)
+println(c.add(1), c.label(), c instanceof EmbedCounter)
+shared.add(4)
+println(shared.label(), total(c, shared))
+try
+    c.add("x")
+catch e: RuntimeError
+    println("caught:", e.message)
+end
+c
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "3 hits=*** true\nshared=***** 8\ncaught: add expects an Int\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+	if c, ok := result.(*embedCounter); !ok || c.count != 3 {
+		t.Errorf("unexpected result %#v", result)
+	}
+	if shared.count != 5 {
+		t.Errorf("shared counter not updated: %d", shared.count)
+	}
+
+	if err := interp.RegisterType(polyloft.Type{Name: "OtherCounter", Sample: &embedCounter{}}); err == nil {
+		t.Error("expected an error registering a Go type under a second name")
+	}
+}
+
+func TestEmbed_ErrorsAndLimits(t *testing.T) {
+	_, err := polyloft.Eval("let x = 1\nprintln(y)\n", polyloft.Options{Stdout: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(polyloft.FormatError(err), "y") {
+		t.Fatalf("expected a name error, got %v", err)
+	}
+
+	_, err = polyloft.Eval(`
+try
+    loop:
+    end
+catch e: Exception
+    println("caught")
+end
+`, polyloft.Options{Stdout: &bytes.Buffer{}, MaxSteps: 1000})
+	if !polyloft.IsLimitError(err) {
+		t.Errorf("expected a step limit error, got %v", err)
+	}
+
+	start := time.Now()
+	_, err = polyloft.Eval("loop:\nend\n", polyloft.Options{Stdout: &bytes.Buffer{}, Timeout: 50 * time.Millisecond})
+	if !polyloft.IsLimitError(err) || time.Since(start) > 5*time.Second {
+		t.Errorf("expected a time limit error, got %v after %s", err, time.Since(start))
+	}
+}
+
+func TestEmbed_Sandbox(t *testing.T) {
+	// A normal run first, so the restricted classes are registered process-wide
+	if _, err := polyloft.Eval(`IO.exists(".")`, polyloft.Options{Stdout: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("unsandboxed run: %v", err)
+	}
+
+	for _, name := range []string{"IO", "File", "Process", "Net", "Http", "Socket"} {
+		_, err := polyloft.Eval(name+".toString()", polyloft.Options{Stdout: &bytes.Buffer{}, Sandbox: true})
+		if err == nil || !strings.Contains(polyloft.FormatError(err), "not available in sandbox mode") {
+			t.Errorf("%s: expected a sandbox name error, got %v", name, err)
+		}
+	}
+
+	var out bytes.Buffer
+	_, err := polyloft.Eval(`
+println(Math.max(2, 3), Crypto.md5("").length())
+try
+    Sys.env("HOME")
+catch e: AccessError
+    println("denied:", e.message)
+end
+`, polyloft.Options{Stdout: &out, Sandbox: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "3 32\ndenied: cannot access sandboxed member 'env' of 'Sys'\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	// Runaway recursion stops at the default depth limit instead of overflowing
+	_, err = polyloft.Eval(`
+def down(n):
+    return down(n + 1)
+end
+down(0)
+`, polyloft.Options{Stdout: &bytes.Buffer{}, Sandbox: true})
+	if !polyloft.IsLimitError(err) || !strings.Contains(err.Error(), "call depth") {
+		t.Errorf("expected a depth limit error, got %v", err)
+	}
+
+	_, err = polyloft.Eval("loop:\nend\n", polyloft.Options{Stdout: &bytes.Buffer{}, Sandbox: true, MaxSteps: 500})
+	if !polyloft.IsLimitError(err) {
+		t.Errorf("expected a step limit error, got %v", err)
+	}
+}
+
+func TestEmbed_ResourceLimits(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	_, err := polyloft.Eval(`
+let dir = "`+filepath.ToSlash(dir)+`"
+let a = IO.open(dir + "/a.txt", "w")
+let b = IO.open(dir + "/b.txt", "w")
+try
+    IO.open(dir + "/c.txt", "w")
+catch e: ResourceError
+    println(e.message)
+end
+a.close()
+a.close()
+let c = IO.open(dir + "/c.txt", "w")
+println(c.isClosed())
+`, polyloft.Options{Stdout: &out, MaxHandles: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "too many open files and sockets (limit 2)\nfalse\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	// Any real heap is over a one-byte ceiling, so the first check that
+	// collects raises; checks wait 100ms after another check collected
+	out.Reset()
+	_, err = polyloft.Eval(`
+let items = []
+try
+    for i in 0...1000000:
+        items.push(i)
+    end
+catch e: ResourceError
+    println("caught:", e.message.startsWith("memory limit of 1 bytes exceeded"))
+end
+`, polyloft.Options{Stdout: &out, MaxMemory: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "caught: true\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestEmbed_DeterministicOutput(t *testing.T) {
+	source := `
+let m = {"b": 1, "a": 2}
+m[10] = "ten"
+m[2] = "two"
+println(m)
+for k, v in m:
+    print(k, "")
+end
+println()
+println(JSON.stringify({"z": [1], "y": {"d": 1, "c": 2}}))
+println(Set("q", "p"))
+`
+	var out bytes.Buffer
+	if _, err := polyloft.Eval(source, polyloft.Options{Stdout: &out}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "{b: 1, a: 2, 10: ten, 2: two}\nb a 10 2 \n{\"z\":[1],\"y\":{\"d\":1,\"c\":2}}\nSet(q, p)\n"
+	if got := out.String(); got != want {
+		t.Errorf("insertion order: got %q", got)
+	}
+
+	out.Reset()
+	if _, err := polyloft.Eval(source, polyloft.Options{Stdout: &out, Deterministic: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = "{2: two, 10: ten, a: 2, b: 1}\n2 10 a b \n{\"y\":{\"c\":2,\"d\":1},\"z\":[1]}\nSet(p, q)\n"
+	if got := out.String(); got != want {
+		t.Errorf("deterministic order: got %q", got)
+	}
+}
@@ -0,0 +1,216 @@
+package e2e
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/engine"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+func runCodeForException(t *testing.T, code string) *engine.HyException {
+	t.Helper()
+	_, err := runCodeWithOutput(code)
+	if err == nil {
+		t.Fatalf("expected a runtime error")
+	}
+	var exc *engine.HyException
+	if !errors.As(err, &exc) {
+		t.Fatalf("expected HyException, got %T: %v", err, err)
+	}
+	return exc
+}
+
+func TestErrorLine_TopLevelStatement(t *testing.T) {
+	code := `let a = 1
+let b = 2
+println(a + b)
+let c = undefinedName + 1
+println(c)
+`
+	exc := runCodeForException(t, code)
+	if exc.Line != 4 {
+		t.Errorf("expected error on line 4, got %d (%s)", exc.Line, exc.Message)
+	}
+}
+
+func TestErrorLine_InsideFunction(t *testing.T) {
+	code := `def divide(a, b):
+    let x = a
+    throw ValueError("bad divisor")
+end
+
+let ok = 1
+divide(1, 0)
+`
+	exc := runCodeForException(t, code)
+	if exc.Line != 3 {
+		t.Errorf("expected error on line 3, got %d (%s)", exc.Line, exc.Message)
+	}
+}
+
+func TestErrorLine_InsideLoop(t *testing.T) {
+	code := `let items = [1, 2, 3]
+for i in items:
+    if i == 3:
+        missing(i)
+    end
+end
+`
+	exc := runCodeForException(t, code)
+	if exc.Line != 4 {
+		t.Errorf("expected error on line 4, got %d (%s)", exc.Line, exc.Message)
+	}
+}
+
+func TestErrorSpan_UnderlinesFailingExpression(t *testing.T) {
+	code := `let name = "Ana"
+let total = 10 + name.missingMethod()
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan([]byte(code)), "span.pf", code).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	_, err = engine.EvalWithContextAndSource(prog, engine.Options{}, "span.pf", ".", code)
+	if err == nil {
+		t.Fatalf("expected a runtime error")
+	}
+	var exc *engine.HyException
+	if !errors.As(err, &exc) {
+		t.Fatalf("expected HyException, got %T: %v", err, err)
+	}
+	if exc.Line != 2 || exc.Column != 18 || exc.EndColumn != 36 {
+		t.Errorf("expected span 2:18-36, got %d:%d-%d", exc.Line, exc.Column, exc.EndColumn)
+	}
+
+	formatted := engine.FormatErrorPlain(err)
+	expected := "     2 | let total = 10 + name.missingMethod()\n" +
+		"       |                  ^~~~~~~~~~~~~~~~~~\n"
+	if !strings.Contains(formatted, expected) {
+		t.Errorf("expected underlined source in output, got:\n%s", formatted)
+	}
+}
+
+func TestErrorStackTrace_RecordsCallFrames(t *testing.T) {
+	code := `class Repo:
+    def find(id):
+        return lookup(id)
+    end
+end
+def lookup(id):
+    if id > 5:
+        throw RuntimeError("missing #{id}")
+    end
+    return id
+end
+def handler(id):
+    let repo = Repo()
+    for attempt in [1]:
+        repo.find(id)
+    end
+end
+
+handler(1)
+handler(9)
+`
+	exc := runCodeForException(t, code)
+	if exc.Message != "missing 9" {
+		t.Fatalf("unexpected error: %s", exc.Message)
+	}
+	expected := []string{
+		"lookup (line 8)",
+		"Repo.find (line 3)",
+		"handler (line 15)",
+		"<main> (line 20)",
+	}
+	if strings.Join(exc.StackTrace, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected frames %q, got %q", expected, exc.StackTrace)
+	}
+	plain := engine.FormatErrorPlain(exc)
+	if !strings.Contains(plain, "Stack trace (most recent call first):\n  1. lookup (line 8)\n") {
+		t.Errorf("expected the trace in the formatted error, got:\n%s", plain)
+	}
+
+	caught := `def fail():
+    throw RuntimeError("x")
+end
+try
+    fail()
+catch e: RuntimeError
+    println("caught")
+end
+println(1 / nil)
+`
+	if exc := runCodeForException(t, caught); len(exc.StackTrace) != 0 {
+		t.Errorf("expected no frames for a top-level error, got %q", exc.StackTrace)
+	}
+}
+
+func TestArityError_ListsOverloads(t *testing.T) {
+	code := `class Point:
+    var x: Int = 0
+    def move(dx: Int):
+        this.x = this.x + dx
+    end
+    def move(dx: Int, dy: Int, scale: Int = 1):
+        this.x = this.x + dx * scale
+    end
+end
+
+let p = Point()
+try
+    p.move()
+catch e: ArityError
+    println(e.message)
+end
+p.move(1, 2, 3, 4)
+`
+	exc := runCodeForException(t, code)
+	if exc.Type != "ArityError" || exc.Message != "no overload of Point.move takes 4 arguments; expected 1 or 2 to 3" {
+		t.Fatalf("unexpected error: %s: %s", exc.Type, exc.Message)
+	}
+	plain := engine.FormatErrorPlain(exc)
+	for _, want := range []string{"move(dx: Int)", "move(dx: Int, dy: Int, scale: Int = ...)"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("expected %q in the formatted error, got:\n%s", want, plain)
+		}
+	}
+}
+
+func TestErrorHints_DidYouMean(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		message string
+	}{
+		{"local variable", "def compute(x):\n    let total = 1\n    return totl + x\nend\ncompute(1)\n",
+			"name 'totl' is not defined; did you mean 'total'?"},
+		{"builtin method", "let items = [1, 2]\nitems.lenght()\n",
+			"'Array' instance' object has no attribute 'lenght'; did you mean 'length'?"},
+		{"inherited method", "class Shape:\n    def describe():\n        return 1\n    end\nend\nclass Circle < Shape:\nend\nCircle().descirbe()\n",
+			"no attribute 'descirbe'; did you mean 'describe'?"},
+		{"nothing close", "let a = 1\nprintln(b)\n", "name 'b' is not defined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exc := runCodeForException(t, tt.code)
+			if !strings.HasSuffix(exc.Message, tt.message) {
+				t.Errorf("expected a message ending in %q, got %q", tt.message, exc.Message)
+			}
+		})
+	}
+
+	out, err := runCodeWithOutput(`let counter = 1
+try
+    println(countr)
+catch e: NameError
+    println(e.message)
+end
+`)
+	if err != nil || out != "name 'countr' is not defined; did you mean 'counter'?\n" {
+		t.Errorf("unexpected output %q, %v", out, err)
+	}
+}
@@ -0,0 +1,156 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpect_PassingMatchers(t *testing.T) {
+	code := `
+class Point:
+    var x: Int
+    var y: Int
+    Point(x: Int, y: Int):
+        this.x = x
+        this.y = y
+    end
+end
+class AppError < Exception:
+end
+class NotFound < AppError:
+end
+
+expect([1, [2, 3], {"a": 1}]).toEqual([1, [2, 3], {"a": 1}])
+expect(Point(1, 2)).toEqual(Point(1, 2))
+expect("polyloft").toContain("loft")
+expect([1, 2, 3]).toContain(2)
+expect({"name": "Ana"}).toContain("name")
+expect(nil).toBeNil()
+expect(() => 1 / 0).toThrow()
+expect(() => undefinedName).toThrow("NameError")
+expect(() => do throw NotFound("x") end).toThrow(AppError)
+expect(() => do throw NotFound("x") end).toThrow(Exception)
+println("ok")
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "ok\n" {
+		t.Errorf("expected %q, got %q", "ok\n", out)
+	}
+}
+
+func TestExpect_FailureDescribesValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		message string
+	}{
+		{"toEqual", `expect([1, 2]).toEqual([1, 3])`, "expected [1, 2] to equal [1, 3]"},
+		{"toEqualMap", `expect({"a": [1]}).toEqual({"a": [2]})`, "to equal"},
+		{"toContain", `expect("abc").toContain("z")`, `expected "abc" to contain "z"`},
+		{"toBeNil", `expect(5).toBeNil()`, "expected nil, got 5"},
+		{"toThrow", `expect(() => 1).toThrow()`, "expected function to throw, but it returned normally"},
+		{"toThrowType", "class AppError < Exception:\nend\nexpect(() => undefinedName).toThrow(AppError)", "expected function to throw AppError, but it threw NameError"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exc := runCodeForException(t, tt.code)
+			if exc.Type != "AssertionError" {
+				t.Errorf("expected AssertionError, got %s", exc.Type)
+			}
+			if !strings.Contains(exc.Message, tt.message) {
+				t.Errorf("expected message containing %q, got %q", tt.message, exc.Message)
+			}
+		})
+	}
+}
+
+func TestExpect_CatchAssertionError(t *testing.T) {
+	code := `
+try
+    expect(1).toEqual(2)
+catch e: AssertionError
+    println(e.getMessage())
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "expected 1 to equal 2\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestExpect_CyclicValues(t *testing.T) {
+	code := `
+let a = [1]
+a.push(a)
+let b = [1]
+b.push(b)
+expect(a).toEqual(b)
+println(a)
+
+let m = {}
+m["self"] = m
+println(m)
+
+var deep = [0]
+for i in range(300):
+    deep = [deep]
+end
+expect(deep.toString()).toContain("[...]")
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "[1, [...]]\n{self: {...}}\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestToString_ConcurrentRenders(t *testing.T) {
+	code := `
+let shared = [[1, 2], [3, 4], [5, 6]]
+def render(name):
+    var bad = ""
+    for i in range(300):
+        let s = str(shared)
+        if s != "[[1, 2], [3, 4], [5, 6]]":
+            bad = name + " got " + s
+        end
+    end
+    return bad
+end
+let t1 = thread spawn do
+    return render("T1")
+end
+let t2 = thread spawn do
+    return render("T2")
+end
+println("[" + (thread join t1) + "][" + (thread join t2) + "]")
+
+class Node:
+    let next
+    Node():
+        this.next = nil
+    end
+    def toString():
+        return "Node(" + this.next + ")"
+    end
+end
+let n = Node()
+n.next = n
+println(n)
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "[][]\nNode(Node(...))\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
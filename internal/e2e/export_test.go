@@ -0,0 +1,148 @@
+package e2e
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/engine"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+// runFileWithModules writes the given files to a temp directory and runs main.pf from there
+func runFileWithModules(t *testing.T, files map[string]string) (string, error) {
+	t.Helper()
+	engine.ResetGlobalRegistries()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	mainPath := filepath.Join(dir, "main.pf")
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithFile(lx.Scan([]byte(files["main.pf"])), mainPath).Parse()
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.EvalWithContext(prog, engine.Options{Stdout: buf}, mainPath, dir)
+	return buf.String(), err
+}
+
+func TestExport_ExplicitExportsHideOtherNames(t *testing.T) {
+	files := map[string]string{
+		"shapes.pf": `
+let counter = 0
+def helper(x):
+    return x * 2
+end
+export def double(x):
+    return helper(x)
+end
+export class Box:
+    var v: Int
+    Box(v: Int):
+        this.v = v
+    end
+end
+export let VERSION = "1.0"
+`,
+		"main.pf": `
+import shapes { double, Box, VERSION }
+println(double(4))
+println(Box(3).v)
+println(VERSION)
+import shapes { helper }
+`,
+	}
+	out, err := runFileWithModules(t, files)
+	if err == nil || !strings.Contains(err.Error(), "helper") {
+		t.Fatalf("expected an error importing the unexported helper, got %v", err)
+	}
+	expected := "8\n3\n1.0\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestExport_ModulesWithoutExportExposeEverything(t *testing.T) {
+	files := map[string]string{
+		"util.pf": `
+let greeting = "hi"
+def shout(s):
+    return s.toUpperCase()
+end
+`,
+		"main.pf": `
+import util { greeting, shout }
+println(shout(greeting))
+`,
+	}
+	out, err := runFileWithModules(t, files)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "HI\n" {
+		t.Errorf("expected %q, got %q", "HI\n", out)
+	}
+}
+
+func TestExport_RequiresDeclaration(t *testing.T) {
+	lx := &lexer.Lexer{}
+	_, err := parser.New(lx.Scan([]byte("export println(1)\n"))).Parse()
+	if err == nil || !strings.Contains(err.Error(), "after 'export'") {
+		t.Fatalf("expected a parse error for export without a declaration, got %v", err)
+	}
+}
+
+func TestImport_FromModuleBundle(t *testing.T) {
+	engine.ResetGlobalRegistries()
+	code := `import bundled_helper { greet }
+import bundled_shapes
+def shout():
+    import bundled_loud { loud }
+    return loud("hi")
+end
+println(greet("pf"))
+println(bundled_shapes.area(3), bundled_shapes.side(2))
+println(shout())
+`
+	modules := engine.ModuleBundle{
+		"app/bundled_helper.pf":            "import util.bundled_wrap { wrap }\ndef greet(n):\n    return wrap(n)\nend\n",
+		"app/util/bundled_wrap.pf":         "def wrap(s):\n    return \"[\" + s + \"]\"\nend\n",
+		"libs/bundled_shapes/a.pf":         "def area(r):\n    return r * r\nend\n",
+		"libs/bundled_shapes/b.pf":         "def side(r):\n    return r * 4\nend\n",
+		"~/.polyloft/libs/bundled_loud.pf": "def loud(s):\n    return s.toUpperCase()\nend\n",
+	}
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithFile(lx.Scan([]byte(code)), "app/main.pf").Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if _, err := engine.EvalWithContext(prog, engine.Options{Stdout: buf, Modules: modules}, "app/main.pf", "app"); err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	if got, want := buf.String(), "[pf]\n9 8\nHI\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// A bundle is the only place imports are read from
+	prog, err = parser.NewWithFile(lx.Scan([]byte("import helper\n")), "main.pf").Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "helper.pf"), []byte("let x = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = engine.EvalWithContext(prog, engine.Options{Modules: engine.ModuleBundle{}}, filepath.Join(dir, "main.pf"), dir)
+	if err == nil || !strings.Contains(err.Error(), "module not found: helper") {
+		t.Errorf("expected the import to miss the empty bundle, got %v", err)
+	}
+}
@@ -301,3 +301,123 @@ func asInt(v any) (int, bool) {
 }
 
 // runCode is defined in generics_async_test.go
+
+func TestFunctionTypeAnnotation_ValidatesArity(t *testing.T) {
+	code := `
+def apply(f: (Int) -> String, x: Int) -> String:
+    return f(x)
+end
+def show(n):
+    return "n=" + n.toString()
+end
+def higher(h: ((Int) -> Int, Int) -> Int):
+    return h((x) => x + 1, 5)
+end
+
+println(apply(show, 4))
+println(apply((v) => "v" + v.toString(), 1))
+println(higher((fn, v) => fn(v)))
+try
+    apply((a, b) => "x", 1)
+catch e: TypeError
+    println("arity")
+end
+try
+    apply(5, 1)
+catch e: TypeError
+    println(e.message)
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "n=4\nv1\n6\narity\nexpected (Int) -> String, got Integer\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestFunctionalInterfaces_CompositionAndStructuralTyping(t *testing.T) {
+	code := `
+let inc = (x) => x + 1
+let dbl = (x) => x * 2
+let even = (x) => x % 2 == 0
+let big = (x) => x > 5
+let nums = [1, 2, 6, 7, 8]
+println(inc.andThen(dbl)(3))
+println(inc.compose(dbl)(3))
+println(nums.filter(even.negate()))
+println(nums.filter(even.and(big)))
+println(nums.filter(even.or(big)))
+
+let sup = () => 42
+let add = (a, b) => a + b
+println(sup.get())
+println(add.apply(2, 3))
+println(Sys.instanceof(sup, "Supplier"))
+println(Sys.instanceof(inc, "Supplier"))
+println(Sys.instanceof(add, "BiFunction"))
+println(Sys.instanceof(even, "Predicate"))
+
+def check(p: Predicate, x):
+    return p.test(x)
+end
+println(check(even, 4))
+try
+    check(sup, 4)
+catch e: TypeError
+    println("rejected")
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "8\n7\n[1, 7]\n[6, 8]\n[2, 6, 7, 8]\n42\n5\ntrue\nfalse\ntrue\ntrue\ntrue\nrejected\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestPartialAndCurry(t *testing.T) {
+	code := `
+def add3(a, b, c):
+    return a + b + c
+end
+def twice(f: (Int) -> Int, x: Int) -> Int:
+    return f(f(x))
+end
+let add = (x, y) => x + y
+
+println(partial(add3, 1)(2, 3))
+println(add3.partial(1, 2)(10))
+println([1, 2, 3].map(add.partial(1)))
+println(twice(add.partial(5), 1))
+
+let c = curry(add3)
+println(c(1)(2)(3))
+println(c(1, 2)(3))
+println(c(1)(2, 3))
+println(add.curry()(5)(6))
+
+try
+    partial(add, 1, 2, 3)
+catch e: ArityError
+    println("too many bound")
+end
+try
+    add3(1)
+catch e: ArityError
+    println("plain calls still checked")
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "6\n13\n[2, 3, 4]\n11\n6\n6\n6\n11\ntoo many bound\nplain calls still checked\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
@@ -191,3 +191,50 @@ return [result1, result2]
 		t.Errorf("Expected 3.5, got %v", arr[1])
 	}
 }
+
+func TestGenericMethod_ExplicitTypeArguments(t *testing.T) {
+	src := `
+class Converter:
+    def wrap<R>(value: R) -> R:
+        return value
+    end
+    static def pair<A, B>(a: A, b: B):
+        return a.toString() + ":" + b.toString()
+    end
+    def plain(x):
+        return x
+    end
+end
+
+let c = Converter()
+println(c.wrap<Int>(5))
+println(c.wrap(3.5))
+println(Converter.pair<String, Int>("a", 1))
+println(c.plain(2) < 4)
+try
+    c.wrap<Int>("five")
+catch e: TypeError
+    println("TypeError")
+end
+try
+    c.wrap<Int, String>(5)
+catch e
+    println(e.message)
+end
+try
+    c.plain<Int>(1)
+catch e
+    println(e.message)
+end
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "5\n3.5\na:1\ntrue\nTypeError\n" +
+		"method Converter.wrap expects 1 type arguments, got 2\n" +
+		"method Converter.plain is not generic but was called with 1 type arguments\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
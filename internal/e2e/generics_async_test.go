@@ -220,3 +220,46 @@ func runCode(code string) (any, error) {
 
 	return engine.Eval(prog, engine.Options{})
 }
+
+func TestPromise_AllAndRace(t *testing.T) {
+	code := `
+def delayed(ms, value):
+    return async(() => do
+        Time.sleep(ms)
+        return value
+    end)
+end
+def failing(ms):
+    return async(() => do
+        Time.sleep(ms)
+        throw RuntimeError("boom")
+    end)
+end
+
+println(Promise.all([delayed(50, 1), delayed(10, 2), delayed(30, 3)]).await())
+println(Promise.all([]).await())
+println(Promise.race([delayed(80, "slow"), delayed(10, "fast")]).await())
+
+let start = Time.now()
+try
+    Promise.all([delayed(2000, 1), failing(10)]).await()
+catch e
+    println("rejected")
+end
+println(Time.now().diffMillis(start) < 1000)
+
+try
+    Promise.all([1, 2])
+catch e: TypeError
+    println("not promises")
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "[1, 2, 3]\n[]\nfast\nrejected\ntrue\nnot promises\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
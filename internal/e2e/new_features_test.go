@@ -299,6 +299,39 @@ println("Count: " + count.toString())
 	}
 }
 
+func TestArray_MapFilterReduce(t *testing.T) {
+	code := `
+let a = [1, 2, 3, 4]
+println(a.map((x) => x * 2))
+println(a.filter((x) => x % 2 == 0))
+println(a.reduce((acc, x) => acc + x, 10))
+println(a.reduce((acc, x) => acc * x))
+try
+    a.map((x) => do
+        if x == 3:
+            throw RuntimeError("bad " + x.toString())
+        end
+        return x
+    end)
+catch e: RuntimeError
+    println("caught " + e.message)
+end
+try
+    [].reduce((acc, x) => acc + x)
+catch e
+    println(e.message)
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "[2, 4, 6, 8]\n[2, 4]\n20\n24\ncaught bad 3\nreduce of empty Array with no initial value\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
 func TestSet_Uniqueness(t *testing.T) {
 	src := `
 let set = Set(1, 2, 2, 3, 3, 3, 4)
@@ -336,6 +369,201 @@ println("After remove, contains 3: " + set.contains(3).toString())
 	}
 }
 
+func TestSet_InsertionOrder(t *testing.T) {
+	src := `
+let set = Set("pear", "apple", "fig")
+set.add("kiwi")
+set.add("apple")
+set.remove("pear")
+set.add("pear")
+println(set.toArray())
+for fruit in set:
+    print(fruit + " ")
+end
+println("")
+println(set)
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "[apple, fig, kiwi, pear]\napple fig kiwi pear \nSet(apple, fig, kiwi, pear)\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestMap_KeyEquality(t *testing.T) {
+	src := `
+record Cell(row: Int, col: Int)
+end
+
+class Name:
+    var value: String
+    Name(value: String):
+        this.value = value
+    end
+    def hash():
+        return this.value.length()
+    end
+    def equals(other):
+        return this.value == other.value
+    end
+end
+
+let m = Map()
+m.set(1, "one")
+println(m.get(1.0))
+m.set(2.0, "two")
+println(m.get(2))
+m.set(1.5, "one and a half")
+println(m.get(1.5))
+println(m.get("1"))
+
+m.set(nil, "nil key")
+m.set("null", "null string")
+println(m.get(nil))
+println(m.get("null"))
+
+m.set(true, "yes")
+println(m.get(true))
+println(m.get(1))
+
+m.set(Cell(1, 2), "B1")
+println(m.get(Cell(1, 2)))
+println(m.get(Cell(2, 1)))
+
+m.set(Name("ada"), "user")
+println(m.get(Name("ada")))
+println(m.get(Name("bob")))
+
+let items = [1, 2]
+m.set(items, "array")
+println(m.get(items))
+println(m.get([1, 2]))
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "one\ntwo\none and a half\nnil\nnil key\nnull string\nyes\none\nB1\nnil\nuser\nnil\narray\nnil\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestMap_NumericKeysAreOneKey(t *testing.T) {
+	src := `
+let m = {}
+m[1] = "int"
+m[1.0] = "float"
+m[2.5] = "half"
+println(m.size())
+println(m[1])
+println(m[1.0])
+println(m[2.5])
+for key, value in m:
+    println(key.toString() + " -> " + value)
+end
+
+let s = Set(1, 1.0, 2)
+println(s.size())
+println(s.contains(2.0))
+println(s.add(2.0))
+println(s.remove(1.0))
+println(s)
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "2\nfloat\nfloat\nhalf\n1 -> float\n2.5 -> half\n2\ntrue\nfalse\ntrue\nSet(2)\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestCapacityHints(t *testing.T) {
+	src := `
+let arr = Array(100)
+println(arr.length())
+println(arr.capacity())
+for i in range(99):
+    arr.push(i)
+end
+println(arr.length())
+println(arr.capacity())
+println(Array().length())
+
+let m = Map(100)
+m["a"] = 1
+m["b"] = 2
+println(m.size())
+println(m["b"])
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "0\n100\n100\n100\n0\n2\n2\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	exc := runCodeForException(t, "let arr = Array(-1)")
+	if exc.Type != "ValueError" {
+		t.Errorf("expected ValueError for a negative capacity, got %s", exc.Type)
+	}
+}
+
+func TestLen_StringsArraysAndMaps(t *testing.T) {
+	src := `
+println(len([1, 2, 3]) / 2)
+println(len("abc"))
+println(len("héllo"))
+let s = "xy"
+println(len(s) + len({"a": 1, "b": 2}))
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "1.5\n3\n6\n4\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestRange_LazyLength(t *testing.T) {
+	src := `
+let big = range(1, 50000000)
+println(len(big))
+println(big.size())
+let down = range(10, 0, -3)
+println(len(down))
+println(down.toArray())
+println(range(5, 1, 1).size())
+var total = 0
+for i in range(0, 9, 3):
+    total = total + i
+end
+println(total)
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "50000000\n50000000\n4\n[10, 7, 4, 1]\n0\n18\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	exc := runCodeForException(t, "let r = range(0, 10, 0)")
+	if exc.Type != "ValueError" {
+		t.Errorf("expected ValueError for a zero step, got %s", exc.Type)
+	}
+}
+
 func TestDeque_BasicOperations(t *testing.T) {
 	src := `
 let deque = Deque()
@@ -411,3 +639,1092 @@ println("Even set size: " + evenSet.size().toString())
 		t.Errorf("expected even set size to be 5, got: %s", got)
 	}
 }
+
+func TestPipeOperator(t *testing.T) {
+	code := `
+def keep(xs, pred):
+    return xs.filter(pred)
+end
+def scale(xs, factor):
+    return xs.map((x) => x * factor)
+end
+def total(xs):
+    return xs.reduce((a, b) => a + b, 0)
+end
+let inc = (x) => x + 1
+
+let result = [1, 2, 3, 4, 5, 6]
+    |> keep((x) => x % 2 == 0)
+    |> scale(10)
+    |> total
+println(result)
+println(1 |> inc |> inc)
+println(2 + 3 |> ((x) => x * 2))
+println(5 |> Math.max(9))
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "120\n3\n10\n9\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestSwitch_RangeCasesAndFallthrough(t *testing.T) {
+	src := `
+def grade(score):
+    switch score:
+        case 90...100:
+            return "A"
+        case 80...89:
+            return "B"
+        case 0...79:
+            return "C"
+        default:
+            return "invalid"
+    end
+end
+println(grade(95), grade(80), grade(79), grade(101), grade("85"))
+
+def describe(n):
+    let parts = ""
+    switch n:
+        case 1:
+            parts = parts + "one,"
+            fallthrough
+        case 2:
+            parts = parts + "two or less,"
+            fallthrough
+        case 10...20:
+            parts = parts + "small"
+        case 3: parts = parts + "three"
+        case 4: fallthrough
+        default:
+            parts = parts + "other"
+    end
+    return parts
+end
+println(describe(1))
+println(describe(2))
+println(describe(15))
+println(describe(3))
+println(describe(4))
+`
+	lx := &lexer.Lexer{}
+	p := parser.New(lx.Scan([]byte(src)))
+	prog, err := p.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "A B C invalid invalid\none,two or less,small\ntwo or less,small\nsmall\nthree\nother\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	src := `
+class Counter:
+    var count = 0
+    Counter():
+        this.count = 0
+    end
+    def add(n):
+        this.count += n
+        return this
+    end
+end
+let c = Counter()
+c.add(5).add(2)
+println(c.count)
+
+let calls = 0
+def nextIndex():
+    calls += 1
+    return 1
+end
+let arr = [10, 20, 30]
+arr[nextIndex()] += 5
+arr[0] -= 4
+arr[2] *= 2
+println(arr, calls)
+
+def counter():
+    calls += 1
+    return c
+end
+counter().count *= 3
+println(c.count, calls)
+
+let x = 17
+x %= 5
+let y = 9
+y /= 2
+println(x, y)
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "7\n[6, 25, 60] 1\n21 2\n2 4\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// Compound assignment still respects final
+	prog, err = parser.New(lx.Scan([]byte("final total = 1\ntotal += 1\n"))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	_, err = engine.Eval(prog, engine.Options{Stdout: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "cannot assign to final variable 'total'") {
+		t.Errorf("expected final assignment error, got %v", err)
+	}
+}
+
+func TestOptionalChaining(t *testing.T) {
+	src := `
+class Node:
+    var next = nil
+    var value = 0
+    Node(v):
+        this.value = v
+    end
+    def label():
+        return "node " + this.value
+    end
+end
+let head = Node(1)
+head.next = Node(2)
+println(head?.next?.value, head.next?.next?.value)
+
+let none = nil
+let calls = 0
+def arg():
+    calls += 1
+    return 1
+end
+println(none?.value, none?.next?.value, none?.label(arg()), calls)
+
+def find(v):
+    calls += 1
+    return v == 1 ? head : nil
+end
+println(find(1)?.label(), find(2)?.label(), calls)
+println({port: 8080}?.port)
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "2 nil\nnil nil nil 0\nnode 1 nil 2\n8080\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// Plain field access on nil still fails
+	prog, err = parser.New(lx.Scan([]byte("let none = nil\nprintln(none.value)\n"))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if _, err = engine.Eval(prog, engine.Options{Stdout: &bytes.Buffer{}}); err == nil {
+		t.Errorf("expected an error for field access on nil")
+	}
+}
+
+func TestArray_BinarySearchAndInsertSorted(t *testing.T) {
+	src := `
+let xs = [1, 3, 3, 3, 7, 9]
+println(xs.binarySearch(3), xs.binarySearch(7), xs.binarySearch(0), xs.binarySearch(4), xs.binarySearch(10))
+println(xs.insertSorted(3), xs.insertSorted(5), xs.insertSorted(100), xs)
+println(["apple", "kiwi", "pear"].binarySearch("banana"))
+let desc = [9, 5, 2]
+println(desc.binarySearch(5, (a, b) => b - a), desc.insertSorted(4, (a, b) => b - a), desc)
+class Version:
+    var n = 0
+    Version(n):
+        this.n = n
+    end
+    def compareTo(other):
+        return this.n - other.n
+    end
+    def toString():
+        return "v" + this.n
+    end
+end
+let vs = [Version(1), Version(4)]
+println(vs.insertSorted(Version(2)), vs)
+println([1.5, 2, 3.25].binarySearch(2.0))
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "1 4 -1 -5 -7\n4 5 8 [1, 3, 3, 3, 3, 5, 7, 9, 100]\n-2\n1 2 [9, 5, 4, 2]\n1 [v1, v2, v4]\n1\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestArray_SortAndSorted(t *testing.T) {
+	src := `
+let nums = [3, 1.5, 10, 2]
+nums.sort()
+println(nums)
+let words = ["pear", "fig", "apple", "kiwi"]
+println(words.sorted((a, b) => a.length() - b.length()), words)
+let people = [["bo", 30], ["al", 25], ["cy", 30], ["di", 25]]
+people.sort((p, q) => p[1] - q[1])
+println(people)
+try
+    [1, "a", 2].sort()
+catch e: TypeError
+    println("mixed")
+end
+def failing(a, b):
+    throw RuntimeError("comparator failed")
+end
+try
+    [3, 2, 1].sorted(failing)
+catch e: RuntimeError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "[1.5, 2, 3, 10]\n[fig, pear, kiwi, apple] [pear, fig, apple, kiwi]\n[[al, 25], [di, 25], [bo, 30], [cy, 30]]\nmixed\ncomparator failed\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestNilCoalescing(t *testing.T) {
+	src := `
+let config = nil
+println(config?.port ?? 8080)
+println(false ?? 1, 0 ?? 1, nil ?? nil ?? 3)
+let calls = 0
+def side():
+    calls += 1
+    return 5
+end
+println(1 ?? side(), calls, nil ?? side(), calls)
+println(nil ?? 1 + 2, true ? nil ?? 4 : 0)
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "8080\nfalse 0 3\n1 0 5 1\n3 4\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestString_SearchMethods(t *testing.T) {
+	src := `
+let s = "héllo wörld"
+println(s.indexOf("l"), s.lastIndexOf("l"), s.indexOf("w"), s.indexOf("z"), s.lastIndexOf("z"))
+println(s.charAt(s.indexOf("w")), s.substring(s.lastIndexOf(" ") + 1, s.length()))
+println(s.indexOf(""), s.lastIndexOf(""), "".indexOf(""), "".indexOf("a"))
+println("banana".count("a"), "aaaa".count("aa"), "abc".count(""), "".count("x"))
+println(s.startsWith(""), s.endsWith(""), s.contains(""), "".contains("a"), s.startsWith("hé"), s.endsWith("rld"))
+let n = s.indexOf("w") + 1
+println(n, Sys.type(n))
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "2 9 6 -1 -1\nw wörld\n0 11 0 -1\n3 2 4 0\ntrue true true false true true\n7 Integer\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestString_CaseAndTrimming(t *testing.T) {
+	src := `
+println("ärger".capitalize(), "".capitalize(), "émile zola".toUpperCase(), "ÇA VA".toLowerCase())
+println("[" + "  \t hi  ".trimStart() + "]", "[" + "  hi \n".trimEnd() + "]", "[" + " hi ".trim() + "]")
+println("7".padStart(3, "0"), "ñ".padStart(3, "*"), "ab".padEnd(7, "xy"), "long".padStart(2, "-"))
+println("é".padEnd(3, "ü").length())
+try
+    "x".padStart(3, "")
+catch e: ValueError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "Ärger  ÉMILE ZOLA ça va\n[hi  ] [  hi] [hi]\n007 **ñ abxyxyx long\n3\npad string must not be empty\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestMap_KeysValuesEntriesInInsertionOrder(t *testing.T) {
+	src := `
+let m = {"b": 2, "a": 1}
+m.set("c", 3)
+m.put("d", 4)
+m["e"] = 5
+m.b = 20
+m.remove("a")
+m.set("a", 10)
+println(m.keys(), m.values(), m)
+let total = 0
+for p in m.entries():
+    total = total + p.value
+    print(p.key, "")
+end
+println(total)
+for k, v in m:
+    print(k)
+end
+println()
+println(m.has("b"), m.has("zz"), m.hasKey("e"), Sys.type(m.has("b")))
+m.delete("e")
+println(m.keys().length(), m.size())
+m.clear()
+m.set(1, "one")
+for k, v in m.entries():
+    println(m.keys(), k, v)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "[b, c, d, e, a] [20, 3, 4, 5, 10] {b: 20, c: 3, d: 4, e: 5, a: 10}\nb c d e a 42\nbcdea\ntrue false true Bool\n4 4\n[1] 1 one\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestForIn_VariadicArgsMapsAndErrors(t *testing.T) {
+	src := `
+def total(nums: Int...):
+    let t = 0
+    for n in nums:
+        t = t + n
+    end
+    return t
+end
+println(total(1, 2, 3), total())
+let m = {"a": 1, "b": 2}
+m.set("c", 3)
+for k in m:
+    print(k)
+end
+println()
+for k, v in m:
+    print(k, v, "")
+end
+println()
+try
+    for x in 5:
+        println(x)
+    end
+catch e: TypeError
+    println(e.message)
+end
+class Box:
+    var v = 1
+end
+try
+    for x in Box():
+        println(x)
+    end
+catch e: TypeError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "6 0\nabc\na 1 b 2 c 3 \nexpected an iterable value, got Integer\nexpected an iterable value, got Box\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestString_CharsAndLines(t *testing.T) {
+	src := `
+let cs = "añ😀b".chars()
+println(cs.length(), cs)
+let word = ""
+for c in "héllo".chars():
+    word = c + word
+end
+println(word)
+println("one\ntwo\r\nthree".lines(), "a\n".lines().length(), "a\n\n".lines().length(), "\n".lines().length(), "".lines().length())
+println("x\r\n".lines()[0].length(), "".chars().length())
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "4 [a, ñ, 😀, b]\nolléh\n[one, two, three] 1 2 1 0\n1 0\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestExceptions_UserHierarchy(t *testing.T) {
+	src := `
+class AppError < Exception:
+end
+class NotFound < AppError:
+    let resource
+    NotFound(resource):
+        super("missing " + resource)
+        this.resource = resource
+    end
+end
+class ValidationError < Exception:
+    let message
+    ValidationError(msg):
+        this.message = msg
+    end
+end
+def fail(kind):
+    if kind == 0:
+        throw NotFound("user")
+    end
+    if kind == 1:
+        throw AppError("bad")
+    end
+    if kind == 2:
+        throw ValidationError("invalid")
+    end
+    Number.parse("soon")
+end
+for kind in [0, 1, 2, 3]:
+    try
+        fail(kind)
+    catch e: AppError
+        println("app", e.type, e.message)
+    catch e: Exception
+        println("exception", e.type)
+    end
+end
+try
+    throw NotFound("page")
+catch e: ValidationError
+    println("wrong clause")
+catch e: NotFound
+    println(e.resource, e instanceof AppError, e instanceof Throwable)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "app NotFound missing user\napp AppError bad\nexception ValidationError\nexception ValueError\npage true true\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestExceptions_BuiltinErrorsMatchOnlyTheirType(t *testing.T) {
+	src := `
+def attempt(kind):
+    try
+        if kind == 0:
+            println(missing)
+        end
+        if kind == 1:
+            throw RuntimeError("plain")
+        end
+        Number.parse("soon")
+    catch e: RuntimeError
+        println("runtime", e.type)
+    catch e: NameError
+        println("name", e.type)
+    catch e: Exception
+        println("exception", e.type)
+    end
+end
+for kind in [0, 1, 2]:
+    attempt(kind)
+end
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "name NameError\nruntime RuntimeError\nexception ValueError\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestClasses_ConstructorInheritedOnlyByExceptions(t *testing.T) {
+	src := `
+class Base:
+    var label = "default"
+    Base(label):
+        this.label = label
+    end
+end
+class Child < Base:
+end
+class Failure < Exception:
+end
+println(Child("given").label)
+println(Failure("boom").message)
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "default\nboom\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestFunctions_DefaultParameters(t *testing.T) {
+	src := `
+def greet(name: String, greeting: String = "Hello", punct = greeting.length() > 3 ? "!" : "."):
+    return greeting + ", " + name + punct
+end
+println(greet("Ann"))
+println(greet("Ann", "Hi"))
+println(greet("Ann", "Hey", "?"))
+
+class StepCounter:
+    var value: Int
+    var step: Int
+    StepCounter(value: Int = 0, step: Int = 1):
+        this.value = value
+        this.step = step
+    end
+    def advance(times: Int = 1):
+        this.value = this.value + this.step * times
+        return this.value
+    end
+end
+let c = StepCounter()
+println(c.advance(), c.advance(3))
+println(StepCounter(10, 5).advance())
+
+def sum(first: Int = 0, rest: Int...):
+    let total = first
+    for r in rest:
+        total = total + r
+    end
+    return total
+end
+println(sum(), sum(1, 2, 3))
+
+try
+    greet()
+catch e: ArityError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "Hello, Ann!\nHi, Ann.\nHey, Ann?\n1 4\n15\n0 6\narity mismatch: expected 1, got 0\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestFunctions_NamedArguments(t *testing.T) {
+	src := `
+def connect(host: String, port: Int = 80, secure: Bool = false):
+    return host + ":" + port + (secure ? " (tls)" : "")
+end
+println(connect(host: "x"))
+println(connect("x", secure: true))
+println(connect(port: 8080, host: "y"))
+
+let sub = (a: Int, b: Int) => a - b
+println(sub(b: 1, a: 10))
+
+class Panel:
+    var w: Int
+    var h: Int
+    Panel(w: Int, h: Int = 1):
+        this.w = w
+        this.h = h
+    end
+    def area(scale: Int = 1, offset: Int = 0):
+        return this.w * this.h * scale + offset
+    end
+    static def square(size: Int, scale: Int = 1):
+        return Panel(size * scale, size * scale)
+    end
+end
+println(Panel(h: 3, w: 2).area(offset: 1))
+println(Panel.square(scale: 2, size: 3).area())
+
+try
+    connect(hots: "x")
+catch e: RuntimeError
+    println(e.message)
+end
+try
+    connect(port: 1)
+catch e: RuntimeError
+    println(e.message)
+end
+try
+    connect("x", host: "y")
+catch e: RuntimeError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "x:80\nx:80 (tls)\ny:8080\n9\n7\n36\n" +
+		"unknown parameter 'hots' in call to connect\n" +
+		"missing argument for parameter 'host' in call to connect\n" +
+		"parameter 'host' of connect is already given positionally\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestSpread_ArrayLiteralsAndCalls(t *testing.T) {
+	src := `
+let a = [1, 2]
+let b = [5]
+let c = [...a, 3, ...b, ...[]]
+println(c, a)
+def sum(xs: Int...):
+    let total = 0
+    for x in xs:
+        total = total + x
+    end
+    return total
+end
+def forward(args: Int...):
+    return sum(...args)
+end
+println(sum(...c), forward(1, 2, 3), sum(10, ...a))
+def pair(x, y):
+    return x + "-" + y
+end
+println(pair(..."ab".split("")))
+try
+    sum(...5)
+catch e: TypeError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "[1, 2, 3, 5] [1, 2]\n11 6 13\na-b\nexpected Array to spread, got Integer\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestGenerators_YieldIsLazy(t *testing.T) {
+	src := `
+def count(n):
+    let i = 0
+    loop i < n:
+        println("produce", i)
+        yield i
+        i = i + 1
+    end
+end
+
+def evens(src):
+    for x in src where x % 2 == 0:
+        yield x * 10
+    end
+end
+
+for v in evens(count(3)):
+    println("consume", v)
+end
+
+def naturals():
+    let n = 1
+    try
+        loop:
+            yield n
+            n += 1
+        end
+    finally
+        println("cleanup at", n)
+    end
+end
+let g = naturals()
+println(g.next(), g.next(), g.hasNext(), g.next())
+g.close()
+println(g.hasNext(), count(0).toArray().length())
+
+def pairs():
+    yield ["a", 1]
+    yield ["b", 2]
+end
+for k, v in pairs():
+    println(k, v)
+end
+
+def failing():
+    yield 1
+    throw RuntimeError("boom")
+end
+try
+    for x in failing():
+        println("got", x)
+    end
+catch e: RuntimeError
+    println("caught", e.message)
+end
+try
+    g.next()
+catch e: StateError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "produce 0\nconsume 0\nproduce 1\nproduce 2\nconsume 20\n" +
+		"1 2 true 3\ncleanup at 3\nfalse 0\na 1\nb 2\ngot 1\ncaught boom\ngenerator naturals has no more values\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestGenerators_LoopExitCloses(t *testing.T) {
+	src := `
+def numbers(label):
+    try
+        let n = 1
+        loop:
+            yield n
+            n += 1
+        end
+    finally
+        println("closed", label)
+    end
+end
+
+for x in numbers("break"):
+    if x == 2:
+        break
+    end
+end
+
+def firstOver(limit):
+    for x in numbers("return"):
+        if x > limit:
+            return x
+        end
+    end
+end
+println(firstOver(3))
+
+try
+    for x in numbers("error"):
+        throw RuntimeError("stop at #{x}")
+    end
+catch e: RuntimeError
+    println(e.message)
+end
+
+def squares(src):
+    for x in src:
+        yield x * x
+    end
+end
+for s in squares(numbers("nested")):
+    if s > 5:
+        break
+    end
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "closed break\nclosed return\n4\nclosed error\nstop at 1\nclosed nested\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestGenerators_MethodsAndLambdas(t *testing.T) {
+	src := `
+class Bag:
+    let items
+    Bag(items):
+        this.items = items
+    end
+    def each():
+        for x in this.items:
+            yield x
+        end
+    end
+    static def upTo(n):
+        let i = 0
+        loop i < n:
+            yield i
+            i += 1
+        end
+    end
+end
+
+let bag = Bag([3, 1, 2])
+println(bag.each().toArray(), Bag.upTo(3).toArray())
+
+let evens = (n) => do
+    let i = 0
+    loop i < n:
+        yield i * 2
+        i += 1
+    end
+end
+for e in evens(3):
+    println(e)
+end
+
+try
+    yield 1
+catch e: RuntimeError
+    println(e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "[3, 1, 2] [0, 1, 2]\n0\n2\n4\n" +
+		"yield can only be used in the body of a function, method or block lambda\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestString_Format(t *testing.T) {
+	src := `
+println(String.format("%d/%d", 3, 4), "%-6s|%6.2f|".format("ab", 3.14159), "#%06X".format(48879))
+println("%x %x %o %b %c %+d %.3s %e %d%%".format(255, "hi", 8, 5, 65, 7, "abcdef", 1234.5, 75))
+println("plain".format(), String.format("%5.1f", 2))
+try
+    String.format("%d and %d", 1)
+catch e: ArityError
+    println("arity:", e.message)
+end
+try
+    "%d".format(1, 2)
+catch e: ArityError
+    println("arity:", e.message)
+end
+try
+    "%d".format("x")
+catch e: TypeError
+    println("type:", e.message)
+end
+try
+    "%q".format(1)
+catch e: ValueError
+    println("value:", e.message)
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(src))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	_, err = engine.Eval(prog, engine.Options{Stdout: buf})
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "3/4 ab    |  3.14| #00BEEF\n" +
+		"ff 6869 10 101 A +7 abc 1.234500e+03 75%\n" +
+		"plain   2.0\n" +
+		"arity: arity mismatch: expected 2, got 1\n" +
+		"arity: arity mismatch: expected 1, got 2\n" +
+		"type: expected Int, got String\n" +
+		"value: unknown format directive \"%q\"\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestSet_Operations(t *testing.T) {
+	src := `
+let a = Set("apple", "fig", "kiwi")
+let b = Set("kiwi", "pear", "apple")
+println(a.union(b))
+println(a.intersection(b))
+println(a.difference(b))
+println(a.symmetricDifference(b))
+println(a.intersection(b).isSubsetOf(a), a.isSubsetOf(b))
+println(a)
+
+let n = Set(1, 2, 3)
+let m = Set(3.0, 4, "3")
+println(n.union(m).size())
+println(n.intersection(m))
+println(n.difference(m))
+println(n.contains(2.0))
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "Set(apple, fig, kiwi, pear)\nSet(apple, kiwi)\nSet(fig)\nSet(fig, pear)\ntrue false\nSet(apple, fig, kiwi)\n" +
+		"5\nSet(3)\nSet(1, 2)\ntrue\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestSet_OperationsWithEmptySets(t *testing.T) {
+	src := `
+let empty = Set()
+let n = Set(1, 2)
+println(empty.union(n), n.union(empty))
+println(empty.intersection(n).isEmpty(), n.intersection(empty).isEmpty())
+println(n.difference(empty), empty.difference(n).isEmpty())
+println(empty.symmetricDifference(n))
+println(empty.isSubsetOf(n), empty.isSubsetOf(empty), n.isSubsetOf(empty))
+println(empty.union(empty).size())
+`
+	got, err := runCodeWithOutput(src)
+	if err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	expected := "Set(1, 2) Set(1, 2)\ntrue true\nSet(1, 2) true\nSet(1, 2)\ntrue true false\n0\n"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	_, err = runCodeWithOutput(`Set(1).union([1])`)
+	if err == nil || !strings.Contains(err.Error(), "expected Set") {
+		t.Errorf("expected a TypeError for a non-Set operand, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package e2e
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestProcess_RunAndErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	code := `
+let r = Process.run("sh", "-c", "echo out; echo err >&2; exit 3")
+println([r.exitCode, r.stdout.trim(), r.stderr.trim(), r.ok])
+println(Process.run("echo", "hi").ok)
+try
+    Process.run("polyloft-no-such-command")
+catch e: CommandNotFoundError
+    println(e.message)
+end
+try
+    Process.exec("sh", ["-c", "echo bad >&2; exit 2"], {check: true})
+catch e: ProcessError
+    println(e.message)
+end
+try
+    Process.exec("sleep", ["5"], {timeout: 50})
+catch e: ProcessError
+    println(e.message)
+end
+println(Process.exec("pwd", [], {dir: "/"}).stdout.trim())
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "[3, out, err, false]\ntrue\ncommand not found: polyloft-no-such-command\n" +
+		"command 'sh' exited with status 2: bad\ncommand 'sleep' timed out after 50 ms\n/\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestProcess_Stream(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	code := `
+let code = Process.stream("sh", ["-c", "echo one; echo two; exit 1"], (line, source) => println(source + ": " + line))
+println(code)
+Process.stream("printf", ["a\\nb\\n"], (line) => println("> " + line))
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "stdout: one\nstdout: two\n1\n> a\n> b\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
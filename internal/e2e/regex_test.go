@@ -0,0 +1,47 @@
+package e2e
+
+import "testing"
+
+func TestRegex_MatchFindReplace(t *testing.T) {
+	code := `
+let re = Regex("(\\w+)@(\\w+)\\.com")
+println(re.match("mail bob@site.com now"))
+println(re.match("nothing"))
+println(re.find("mail bob@site.com now"))
+println(re.groups())
+println(re.findAll("a@b.com, c@d.com"))
+println(re.replace("a@b.com", "$2 at $1"))
+println(re.find("zzz"))
+println(re.groups())
+
+let optional = Regex("a(x)?b")
+println(optional.find("ab"))
+println(optional.groups())
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "true\nfalse\nbob@site.com\n[bob, site]\n[a@b.com, c@d.com]\nb at a\nnil\n[]\nab\n[nil]\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestRegex_InvalidPatternThrowsValueError(t *testing.T) {
+	code := `
+try
+    Regex("(")
+catch e: ValueError
+    println(e.message)
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "error parsing regexp: missing closing ): `(`\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
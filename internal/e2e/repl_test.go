@@ -0,0 +1,44 @@
+package e2e
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/repl"
+)
+
+func TestREPL_MultiLineInputAndSession(t *testing.T) {
+	input := strings.Join([]string{
+		"def square(x):",
+		"    return x * x",
+		"end",
+		"square(7)",
+		"class Counter:",
+		"    let n = 0",
+		"    def inc():",
+		"        this.n = this.n + 1",
+		"        return this.n",
+		"    end",
+		"end",
+		"let c = Counter()",
+		"c.inc()",
+		"x )",
+		"if true:",
+	}, "\n") + "\n"
+
+	out := &bytes.Buffer{}
+	repl.Start(strings.NewReader(input), out, ">>> ")
+	got := out.String()
+
+	for _, want := range []string{
+		">>> ... ... >>> = 49\n",
+		">>> ... ... ... ... ... ... >>> >>> = 1\n",
+		"error: <repl>:1:3: unexpected ')'",
+		">>> ... \nerror: <repl>:1:9: unexpected EOF",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSerialize_ClassInstance(t *testing.T) {
+	code := `
+class Address:
+    var city: String
+    var zip: Int
+    Address(c: String, z: Int):
+        this.city = c
+        this.zip = z
+    end
+end
+
+class Person:
+    var name: String
+    var tags: Array
+    var address: Address
+    private var secret: String
+    Person(n: String, a: Address):
+        this.name = n
+        this.tags = ["admin", "dev"]
+        this.address = a
+        this.secret = "hidden"
+    end
+end
+
+let data = serialize(Person("Ana", Address("Lima", 15001)))
+println(data["name"])
+println(data["tags"].length())
+println(data["address"]["city"])
+println(data["address"]["zip"])
+println(data.hasKey("secret"))
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Ana\n2\nLima\n15001\nfalse\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestSerialize_CustomHook(t *testing.T) {
+	code := `
+class Money:
+    var cents: Int
+    Money(c: Int):
+        this.cents = c
+    end
+    def __serialize__():
+        return { "amount": this.cents / 100, "currency": "USD" }
+    end
+end
+
+let data = serialize([Money(250), Money(100)])
+println(data[0]["currency"])
+println(data[1]["amount"])
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "USD\n1\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestSerialize_CycleDetected(t *testing.T) {
+	code := `
+class Node:
+    var next: Any
+    Node():
+        this.next = nil
+    end
+end
+
+let a = Node()
+a.next = a
+serialize(a)
+`
+	_, err := runCodeWithOutput(code)
+	if err == nil || !strings.Contains(err.Error(), "cyclic") {
+		t.Fatalf("expected cyclic reference error, got %v", err)
+	}
+}
+
+func TestFromJson_NestedClass(t *testing.T) {
+	code := `
+class Address:
+    var city: String
+    Address(c: String):
+        this.city = c
+    end
+end
+
+class User:
+    var name: String
+    var age: Int
+    var score: Float
+    var address: Address
+    User(n: String):
+        this.name = n
+    end
+    def describe():
+        return this.name + " (" + this.age.toString() + ") from " + this.address.city
+    end
+end
+
+let u = fromJson('{"name": "Bob", "age": 30, "score": 7, "address": {"city": "Quito"}, "extra": true}', User)
+println(u.describe())
+println(u.score / 2)
+println(u instanceof User)
+
+let m = fromJson({ "name": "Eve", "age": 22, "address": { "city": "Cusco" } }, User)
+println(m.describe())
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Bob (30) from Quito\n3.5\ntrue\nEve (22) from Cusco\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestFromJson_TypeMismatch(t *testing.T) {
+	code := `
+class Item:
+    var qty: Int
+    Item():
+    end
+end
+
+fromJson('{"qty": "many"}', Item)
+`
+	_, err := runCodeWithOutput(code)
+	if err == nil || !strings.Contains(err.Error(), "Item.qty") {
+		t.Fatalf("expected type error mentioning Item.qty, got %v", err)
+	}
+}
+
+func TestJSON_ParseStringifyRoundTrip(t *testing.T) {
+	code := `
+let data = JSON.parse('{"name": "Ana", "scores": [1, 2.0, 3.5], "meta": {"ok": true, "note": null}}')
+println(data["scores"][0] + 1)
+println(data["scores"][1] / 4)
+println(JSON.stringify(data))
+println(JSON.stringify(JSON.parse(JSON.stringify(data))) == JSON.stringify(data))
+println(JSON.stringify([1, {"a": "x"}], 2))
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "2\n0.5\n" +
+		`{"name":"Ana","scores":[1,2.0,3.5],"meta":{"ok":true,"note":null}}` + "\n" +
+		"true\n" +
+		"[\n  1,\n  {\n    \"a\": \"x\"\n  }\n]\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestJSON_StringifyCycleAndInvalidInput(t *testing.T) {
+	code := `
+let m = Map()
+m.set("self", m)
+try
+    JSON.stringify(m)
+catch e: ValueError
+    println(e.message)
+end
+try
+    JSON.parse("[1, 2")
+catch e: ValueError
+    println(e.message)
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "cannot convert a cyclic structure to JSON\ninvalid JSON: unexpected end of JSON input\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
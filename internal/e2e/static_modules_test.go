@@ -2,11 +2,25 @@ package e2e
 
 import (
 	"bytes"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/ArubikU/polyloft/internal/engine"
 	"github.com/ArubikU/polyloft/internal/lexer"
 	"github.com/ArubikU/polyloft/internal/parser"
+	"github.com/gorilla/websocket"
 )
 
 // runCodeWithOutput runs code and returns the printed output
@@ -98,6 +112,921 @@ func TestMath_StaticMethods(t *testing.T) {
 	}
 }
 
+// syncBuffer is a bytes.Buffer that can be written from a signal handler goroutine
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestSys_OnSignal tests that a handler registered with Sys.onSignal runs when the signal arrives
+func TestSys_OnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP cannot be sent to the current process on Windows")
+	}
+	run := func(code string, out *syncBuffer) error {
+		lx := &lexer.Lexer{}
+		prog, err := parser.New(lx.Scan([]byte(code))).Parse()
+		if err != nil {
+			return err
+		}
+		_, err = engine.Eval(prog, engine.Options{Stdout: out})
+		return err
+	}
+
+	out := &syncBuffer{}
+	code := `
+Sys.onSignal("SIGHUP", (name) => println("received " + name))
+Sys.onSignal("hup", () => println("second handler"))
+try
+    Sys.onSignal("SIGNOPE", () => nil)
+catch e: ValueError
+    println("unsupported")
+end
+`
+	if err := run(code, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer run(`Sys.offSignal("SIGHUP")`, &syncBuffer{})
+
+	self, _ := os.FindProcess(os.Getpid())
+	if err := self.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("failed to send SIGHUP: %v", err)
+	}
+	expected := "unsupported\nreceived SIGHUP\nsecond handler\n"
+	deadline := time.Now().Add(2 * time.Second)
+	for out.String() != expected && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := out.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+// TestInput_ReadsStdin tests that input() prompts on stdout and reads trimmed lines from Options.Stdin
+func TestInput_ReadsStdin(t *testing.T) {
+	code := `
+let name = input("name? ")
+println("hello " + name)
+let line = input()
+loop line != nil:
+    println("[" + line + "]")
+    line = input()
+end
+`
+	engine.ResetGlobalRegistries()
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(code))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	out := &bytes.Buffer{}
+	stdin := strings.NewReader("  Ada  \r\nfirst\n\nlast")
+	if _, err := engine.Eval(prog, engine.Options{Stdout: out, Stdin: stdin}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "name? hello Ada\n[first]\n[]\n[last]\n"
+	if got := out.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// Without a configured Stdin, input() behaves like exhausted input
+	output, err := runCodeWithOutput(`println(input("? ") == nil)`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output != "? true\n" {
+		t.Errorf("expected %q, got %q", "? true\n", output)
+	}
+}
+
+// TestSys_EnvAndArgs tests environment variable access and the script arguments from Options.Args
+func TestSys_EnvAndArgs(t *testing.T) {
+	t.Setenv("POLYLOFT_TEST_HOME", "/tmp/pl")
+	code := `
+println(Sys.env("POLYLOFT_TEST_HOME"), Sys.env("POLYLOFT_TEST_UNSET") == nil)
+println(Sys.env("POLYLOFT_TEST_UNSET") ?? "default")
+Sys.setEnv("POLYLOFT_TEST_UNSET", 42)
+println(Sys.env("POLYLOFT_TEST_UNSET"))
+let args = Sys.args()
+println(args.length(), args, args[0].toUpperCase())
+for a in args:
+    print(a.length())
+end
+println()
+`
+	t.Cleanup(func() { os.Unsetenv("POLYLOFT_TEST_UNSET") })
+	engine.ResetGlobalRegistries()
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(code))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	out := &bytes.Buffer{}
+	if _, err := engine.Eval(prog, engine.Options{Stdout: out, Args: []string{"--name", "Ada"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "/tmp/pl true\ndefault\n42\n2 [--name, Ada] --NAME\n63\n"
+	if got := out.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+// TestIO_OpenFileHandles tests IO.open with read, write and append modes, defer and IOError
+func TestIO_OpenFileHandles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.ToSlash(filepath.Join(dir, "notes.txt"))
+	code := `
+let path = "` + path + `"
+def save():
+    let f = IO.open(path, "w")
+    defer f.close()
+    let n = f.writeLine("one")
+    println(n, f.write("two\r\nthree"))
+end
+save()
+let a = IO.open(path, "a")
+a.writeLine("")
+a.close()
+a.close()
+println(a.isClosed())
+let r = IO.open(path)
+loop !r.eof():
+    print("[" + r.readLine() + "]")
+end
+println()
+println(r.readLine() == nil, r.eof())
+r.close()
+try
+    r.read()
+catch e: IOError
+    println("closed")
+end
+try
+    IO.open(path, "a").read()
+catch e: IOError
+    println("write-only")
+end
+try
+    IO.open(path + ".missing")
+catch e: IOError
+    println("missing")
+end
+try
+    IO.readFile(path + ".missing")
+catch e: IOError
+    println("missing")
+end
+try
+    IO.open(path, "x")
+catch e: ValueError
+    println(e.message)
+end
+println(IO.open(path, "r").read() == IO.readFile(path))
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "4 10\ntrue\n[one][two][three]\ntrue true\nclosed\nwrite-only\nmissing\nmissing\ninvalid file mode 'x' (expected \"r\", \"w\" or \"a\")\ntrue\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "one\ntwo\r\nthree\n" {
+		t.Errorf("unexpected file contents %q", data)
+	}
+}
+
+// TestHttp_ClientHeaders tests passing request headers to the short Http helpers
+func TestHttp_ClientHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + r.Header.Get("Authorization") + " " + r.Header.Get("Content-Type") + " " + string(body)))
+	}))
+	defer server.Close()
+
+	code := `
+let url = "` + server.URL + `"
+let auth = {Authorization: "Bearer abc"}
+println(Http.get(url).body)
+println(Http.get(url, auth).body)
+println(Http.get(url, 5, auth).body)
+println(Http.post(url, {a: 1}, auth).body)
+println(Http.put(url, "x", 5, {Authorization: "t", "Content-Type": "text/plain"}).body)
+println(Http.delete(url, auth).body)
+println(Http.request("PATCH", url, nil, 5, auth).body)
+try
+    Http.get(url, 5, "nope")
+catch e: TypeError
+    println("bad headers")
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "GET   \nGET Bearer abc  \nGET Bearer abc  \nPOST Bearer abc application/json {\"a\":1}\nPUT t text/plain x\nDELETE Bearer abc  \nPATCH Bearer abc  \nbad headers\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+// TestHttp_DownloadAndMaxResponseSize tests streaming downloads and the response size cap
+func TestHttp_DownloadAndMaxResponseSize(t *testing.T) {
+	payload := strings.Repeat("polyloft", 4096)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	path := filepath.ToSlash(filepath.Join(t.TempDir(), "payload.bin"))
+	code := `
+let url = "` + server.URL + `"
+println(Http.download(url, "` + path + `"))
+println(IO.readFile("` + path + `").length())
+try
+    Http.download(url + "/missing", "` + path + `.missing")
+catch e: RuntimeError
+    println(e.message.contains("404"), IO.exists("` + path + `.missing"))
+end
+Http.setMaxResponseSize(1024)
+try
+    Http.get(url)
+catch e: RuntimeError
+    println(e.message.contains("exceeds the maximum size of 1024 bytes"))
+end
+println(Http.download(url, "` + path + `"))
+println(Http.get(url + "/missing").status)
+Http.setMaxResponseSize(0)
+println(Http.get(url).body.length())
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "32768\n32768\ntrue false\ntrue\n32768\n404\n32768\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+// TestHttp_ConfigureProxyAndTLS tests routing client requests through a proxy and trusting a custom CA
+func TestHttp_ConfigureTimeoutAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(300 * time.Millisecond)
+		}
+		w.Write([]byte(r.Header.Get("User-Agent") + "|" + r.Header.Get("X-Api-Key")))
+	}))
+	defer server.Close()
+
+	code := `
+let url = "` + server.URL + `"
+println(Http.get(url).body.startsWith("Polyloft/"))
+Http.configure({headers: {"X-Api-Key": "k1"}, timeout: 0.1})
+println(Http.get(url).body.startsWith("Polyloft/"), Http.get(url).body.endsWith("|k1"))
+println(Http.get(url, 5, {"User-Agent": "custom", "X-Api-Key": "k2"}).body)
+try
+    Http.get(url + "/slow")
+catch e
+    println("timed out")
+end
+println(Http.get(url + "/slow", 5).body.endsWith("|k1"))
+Http.configure({headers: {"User-Agent": "bot/2"}, timeout: nil})
+println(Http.get(url + "/slow").body)
+try
+    Http.configure({timeout: -1})
+catch e: ValueError
+    println(e.message)
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "true\ntrue true\ncustom|k2\ntimed out\ntrue\nbot/2|\ntimeout must be a positive number of seconds, got -1\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestHttp_ConfigureProxyAndTLS(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied " + r.URL.String()))
+	}))
+	defer proxy.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secure"))
+	}))
+	defer secure.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: secure.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code := `
+try
+    Http.get("` + secure.URL + `")
+catch e
+    println("untrusted")
+end
+Http.configure({caFile: "` + filepath.ToSlash(caFile) + `"})
+println(Http.get("` + secure.URL + `").body)
+Http.configure({proxy: "` + proxy.URL + `"})
+println(Http.get("http://polyloft.invalid/status").body)
+Http.configure({proxy: nil})
+println(Http.get("` + secure.URL + `").body)
+for bad in [{proxy: "not a url"}, {timeouts: 5}]:
+    try
+        Http.configure(bad)
+    catch e: ValueError
+        println(e.message)
+    end
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "untrusted\nsecure\nproxied http://polyloft.invalid/status\nsecure\n" +
+		"invalid proxy URL 'not a url' (expected http://, https:// or socks5://host:port)\n" +
+		"unknown Http option 'timeouts' (expected proxy, caFile, insecure, timeout or headers)\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+
+	// Settings do not leak into the next program
+	out, err = runCodeWithOutput(`
+try
+    Http.get("` + secure.URL + `")
+catch e
+    println("untrusted")
+end
+`)
+	if err != nil || out != "untrusted\n" {
+		t.Errorf("expected the default configuration, got %q (%v)", out, err)
+	}
+}
+
+// TestHttp_SessionKeepsCookies tests that an Http.session() carries cookies across requests
+func TestHttp_SessionKeepsCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "abc123", Path: "/"})
+			w.Write([]byte("logged in"))
+			return
+		}
+		sid, err := r.Cookie("sid")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("anonymous " + r.Header.Get("X-Trace")))
+			return
+		}
+		w.Write([]byte(r.Method + " as " + sid.Value + " " + r.Header.Get("X-Trace")))
+	}))
+	defer server.Close()
+
+	code := `
+let url = "` + server.URL + `"
+let session = Http.session()
+println(session.get(url + "/me").status)
+println(session.post(url + "/login", {user: "ada"}).body)
+println(session.get(url + "/me").body)
+println(session.get(url + "/me", 5, {"X-Trace": "t1"}).body)
+println(session.delete(url + "/me", {"X-Trace": "t2"}).body)
+println(session.request("PATCH", url + "/me").body)
+println(session.cookies(url))
+println(Http.get(url + "/me").body)
+println(Http.session().get(url + "/me").status)
+session.clearCookies()
+println(session.get(url + "/me").status)
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "401\nlogged in\nGET as abc123 \nGET as abc123 t1\nDELETE as abc123 t2\nPATCH as abc123 \n{sid: abc123}\nanonymous \n401\n401\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestHttp_SessionInterceptors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " " + r.Header.Get("Authorization") + " " + r.Header.Get("X-Trace") + " " + string(body)))
+	}))
+	defer server.Close()
+
+	code := `
+let url = "` + server.URL + `"
+let log = []
+def auth(req):
+    req.headers.set("Authorization", "Bearer tok")
+    log.add(req.method + " " + req.headers.get("X-Trace"))
+end
+def paginate(req):
+    req.url = req.url + "?page=2"
+end
+def countStatus(res):
+    log.add(res.status)
+end
+let session = Http.session().onRequest(auth).onRequest(paginate).onResponse(countStatus)
+println(session.get(url + "/a", {"X-Trace": "t1"}).body)
+println(session.post(url + "/b", {n: 1}).body)
+session.onResponse((res) => {status: res.status, body: res.body.toUpperCase()})
+println(session.get(url + "/c").body)
+println(log)
+println(Http.get(url + "/d").body)
+try
+    session.onRequest("nope")
+catch e: TypeError
+    println("not a function")
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "GET /a?page=2 Bearer tok t1 \n" +
+		"POST /b?page=2 Bearer tok  {\"n\":1}\n" +
+		"GET /C?PAGE=2 BEARER TOK  \n" +
+		"[GET t1, 200, POST nil, 200, GET nil, 200]\n" +
+		"GET /d   \n" +
+		"not a function\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestHttp_PostForm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(r.Header.Get("Content-Type") + " user=" + r.PostForm.Get("user") + " scope=" + strings.Join(r.PostForm["scope"], ",") + " x=" + r.Header.Get("X-Client")))
+	}))
+	defer server.Close()
+
+	code := `
+let url = "` + server.URL + `"
+println(Http.postForm(url, {user: "ada lovelace", scope: ["read", "write"]}).body)
+println(Http.postForm(url, "user=raw&scope=a", 5, {"X-Client": "cli"}).body)
+println(Http.session().postForm(url, {user: "s"}).body)
+try
+    Http.postForm(url, 42)
+catch e: TypeError
+    println("bad fields")
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	form := "application/x-www-form-urlencoded"
+	expected := form + " user=ada lovelace scope=read,write x=\n" +
+		form + " user=raw scope=a x=cli\n" +
+		form + " user=s scope= x=\n" +
+		"bad fields\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestHttpServer_StaticFilesAndCookies(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log(1)"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "private"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	code := `
+let server = Http.createServer()
+server.static("/assets/", "` + filepath.ToSlash(dir) + `")
+server.get("/assets/:name/meta", (req, res) => res.send("meta " + req.params.name))
+server.get("/*rest", (req, res) => res.send("fallback"))
+server.get("/login", (req, res) => res.cookie("sid", "abc", {maxAge: 60, httpOnly: true}).send("ok"))
+server.get("/me", (req, res) => res.send(req.cookie("sid") ?? "anonymous"))
+try
+    server.static("/x", "` + filepath.ToSlash(filepath.Join(dir, "missing")) + `")
+catch e: IOError
+    println("missing dir")
+end
+server.listen("` + addr + `")
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "missing dir\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	get := func(path string, cookies ...*http.Cookie) (*http.Response, string) {
+		req, _ := http.NewRequest("GET", "http://"+addr+path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		var resp *http.Response
+		var err error
+		for attempt := 0; attempt < 50; attempt++ {
+			if resp, err = http.DefaultClient.Do(req); err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	if resp, body := get("/assets/app.js"); resp.StatusCode != 200 || body != "console.log(1)" {
+		t.Errorf("static file: %d %q", resp.StatusCode, body)
+	}
+	if resp, _ := get("/assets/nope.js"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing static file: expected 404, got %d", resp.StatusCode)
+	}
+	if resp, _ := get("/assets/private/"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("directory listing: expected 404, got %d", resp.StatusCode)
+	}
+	if _, body := get("/assets/app.js/meta"); body != "meta app.js" {
+		t.Errorf("more specific route: got %q", body)
+	}
+	if _, body := get("/elsewhere"); body != "fallback" {
+		t.Errorf("catch-all route: got %q", body)
+	}
+
+	resp, body := get("/login")
+	cookies := resp.Cookies()
+	if body != "ok" || len(cookies) != 1 || cookies[0].Name != "sid" || cookies[0].MaxAge != 60 || !cookies[0].HttpOnly || cookies[0].Path != "/" {
+		t.Errorf("login cookie: %q %+v", body, cookies)
+	}
+	if _, body := get("/me"); body != "anonymous" {
+		t.Errorf("without cookie: got %q", body)
+	}
+	if _, body := get("/me", &http.Cookie{Name: "sid", Value: "abc"}); body != "abc" {
+		t.Errorf("with cookie: got %q", body)
+	}
+}
+
+func TestHttpServer_CorsMiddleware(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	code := `
+let server = Http.createServer()
+server.use(Http.cors({origins: ["https://app.example"], methods: ["get", "post"], credentials: true}))
+server.get("/data", (req, res) => res.send("data"))
+try
+    Http.cors({origin: "*"})
+catch e: ValueError
+    println(e.message)
+end
+server.listen("` + addr + `")
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "unknown cors option 'origin' (expected origins, methods, headers or credentials)\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	do := func(method, path string, headers map[string]string) (*http.Response, string) {
+		req, _ := http.NewRequest(method, "http://"+addr+path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		var resp *http.Response
+		var err error
+		for attempt := 0; attempt < 50; attempt++ {
+			if resp, err = http.DefaultClient.Do(req); err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := do("GET", "/data", map[string]string{"Origin": "https://app.example"})
+	if body != "data" || resp.Header.Get("Access-Control-Allow-Origin") != "https://app.example" || resp.Header.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("allowed origin: %q %v", body, resp.Header)
+	}
+	resp, body = do("GET", "/data", map[string]string{"Origin": "https://evil.example"})
+	if body != "data" || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("other origin: %q %v", body, resp.Header)
+	}
+
+	preflight := map[string]string{
+		"Origin":                         "https://app.example",
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "Content-Type",
+	}
+	resp, body = do("OPTIONS", "/data", preflight)
+	if resp.StatusCode != http.StatusNoContent || body != "" {
+		t.Errorf("preflight: expected an empty 204, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Access-Control-Allow-Methods") != "GET, POST" || resp.Header.Get("Access-Control-Allow-Headers") != "Content-Type" {
+		t.Errorf("preflight headers: %v", resp.Header)
+	}
+	if resp, _ := do("OPTIONS", "/data", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("plain OPTIONS: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+// TestNumber_ParseAndFormat tests locale-independent parsing and formatting
+func TestHttp_ConnectWs(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(message) == "bye" {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte("echo: "+string(message)))
+		}
+	}))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	code := `
+let ws = Http.connectWs("` + wsURL + `")
+ws.send("hello")
+println(ws.receive())
+ws.send("bye")
+println(ws.receive() == nil)
+
+let live = Http.connectWs("` + wsURL + `")
+let ch = channel[String]()
+live.onMessage((msg) => ch.send(msg))
+live.send("ping")
+println(ch.recv())
+try
+    live.receive()
+catch e: StateError
+    println("receive after onMessage rejected")
+end
+live.close()
+println(live.receive() == nil)
+
+try
+    Http.connectWs("ws://127.0.0.1:1/none")
+catch e: IOError
+    println("connect failed")
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "echo: hello\ntrue\necho: ping\nreceive after onMessage rejected\ntrue\nconnect failed\n"
+	if out != want {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestCrypto_HashHmacAndEncoding(t *testing.T) {
+	code := `
+println(Crypto.sha256("abc"))
+println(Crypto.sha256(0b01100001_01100010_01100011) == Crypto.sha256("abc"))
+println(Crypto.md5(""), Crypto.sha1("abc"))
+println(Crypto.hmacSha256("key", "The quick brown fox jumps over the lazy dog"))
+println(Crypto.base64Encode(0b00000000_11111111), Crypto.base64Decode("aGk="))
+println(Crypto.hexEncode("hi"), Crypto.hexDecode("6869"))
+try
+    Crypto.base64Decode("not base64!")
+catch e: ValueError
+    println("base64:", e.message)
+end
+try
+    Crypto.hexDecode("zz")
+catch e: ValueError
+    println("hex:", e.message)
+end
+try
+    Crypto.sha256(42)
+catch e: TypeError
+    println("type error")
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n" +
+		"true\n" +
+		"d41d8cd98f00b204e9800998ecf8427e a9993e364706816aba3e25717850c26c9cd0d89d\n" +
+		"f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8\n" +
+		"AP8= hi\n" +
+		"6869 hi\n" +
+		"base64: invalid base64 data: illegal base64 data at input byte 3\n" +
+		"hex: invalid hex data: encoding/hex: invalid byte: U+007A 'z'\n" +
+		"type error\n"
+	if out != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestNumber_ParseAndFormat(t *testing.T) {
+	code := `
+println(Number.parse("42") + 1, Number.parse(" -3.5 "), Number.parse(".5"), Number.parse("2E2"), Sys.type(Number.parse("7")), Sys.type(Number.parse("7.")))
+println(Number.format(3.14159, 2), Number.format(7, 3), Number.format(0.1), Number.format(-12), Number.format(1234567.5, 1))
+for bad in ["1,5", "", "1.2.3", "abc", "1 000"]:
+    try
+        Number.parse(bad)
+    catch e: ValueError
+        print("x")
+    end
+end
+println()
+try
+    Number.format(1.5, -1)
+catch e: ValueError
+    println(e.message)
+end
+`
+	output, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "43 -3.5 0.5 200 Integer Float\n3.14 7.000 0.1 -12 1234567.5\nxxxxx\ndecimals must not be negative, got -1\n"
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+// TestTerminal_Styles tests that Terminal only emits ANSI codes when colors are enabled
+func TestTerminal_Styles(t *testing.T) {
+	code := `
+println(Terminal.isTTY(), Terminal.colorEnabled())
+println(Terminal.red("plain") + " " + Terminal.bold(42))
+Terminal.setColorEnabled(true)
+let styled = Terminal.green("ok")
+println(styled)
+println(Terminal.strip(Terminal.underline(Terminal.cyan("both"))))
+`
+	output, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "false false\nplain 42\n\x1b[32mok\x1b[0m\nboth\n"
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+// TestRandom_Collections tests Random.shuffle, shuffled, choice, sample and normal
+func TestRandom_Collections(t *testing.T) {
+	code := `
+let deck = [1, 2, 3, 4, 5, 6, 7, 8]
+println(Random.shuffle(deck) == deck, deck.length(), deck.reduce((a, b) => a + b, 0))
+let copy = Random.shuffled(Set(1, 2, 3))
+println(copy instanceof Array, copy.length(), copy.contains(1) && copy.contains(2) && copy.contains(3))
+println(["x", "y"].contains(Random.choice(["x", "y"])))
+let picked = Random.sample([1, 2, 3, 4, 5], 3)
+let distinct = Set()
+for p in picked:
+    distinct.add(p)
+end
+println(picked.length(), distinct.size())
+println(Random.normal(2.5, 0))
+try
+    Random.choice([])
+catch e: ValueError
+    println(e.message)
+end
+try
+    Random.sample([1, 2], 3)
+catch e: ValueError
+    println(e.message)
+end
+`
+	output, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "true 8 36\ntrue 3 true\ntrue\n3 3\n2.5\ncannot choose from an empty collection\nsample size must be between 0 and 2, got 3\n"
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+// TestRandom_NumbersSeedAndUuid tests Random.int, float, seed and uuid
+func TestRandom_NumbersSeedAndUuid(t *testing.T) {
+	code := `
+let inRange = true
+let seen = Set()
+for i in 0...200:
+    let n = Random.int(-2, 2)
+    inRange = inRange && n >= -2 && n <= 2
+    seen.add(n)
+    let f = Random.float()
+    inRange = inRange && f >= 0 && f < 1
+end
+println(inRange, seen.size(), Random.int(7, 7))
+Random.seed(42)
+let first = [Random.int(1, 1000), Random.float(), Random.choice(["a", "b", "c", "d"])]
+Random.seed(42)
+let second = [Random.int(1, 1000), Random.float(), Random.choice(["a", "b", "c", "d"])]
+println(first[0] == second[0] && first[1] == second[1] && first[2] == second[2])
+let id = Random.uuid()
+println(id.length(), id[14], ["8", "9", "a", "b"].contains(id[19]), id != Random.uuid())
+try
+    Random.int(3, 1)
+catch e: ValueError
+    println(e.message)
+end
+`
+	output, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "true 5 7\ntrue\n36 4 true true\nmin must not be greater than max, got 3 > 1\n"
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+// TestMath_PowerOperator tests the ** operator and its Int/Float results
+func TestMath_PowerOperator(t *testing.T) {
+	code := `
+println(2 ** 10)
+println(2 ** 10 instanceof Int)
+println(2.0 ** 0.5 instanceof Float)
+println(2 ** 3 ** 2)
+println(-2 ** 2)
+println(3 * 2 ** 2)
+println(2 ** -1)
+println(Math.pow(2, 8) instanceof Int)
+println(Math.abs(3 - 8) instanceof Int)
+try
+    0 ** -1
+catch e: RuntimeError
+    println("zero to a negative power")
+end
+`
+	result, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "1024\ntrue\ntrue\n512\n-4\n12\n0.5\ntrue\ntrue\nzero to a negative power\n"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
 // TestSys_StaticMethods tests Sys module static methods
 func TestSys_StaticMethods(t *testing.T) {
 	tests := []struct {
@@ -140,6 +1069,27 @@ func TestSys_StaticMethods(t *testing.T) {
 	}
 }
 
+// TestSys_RuntimeIntrospection tests the platform, CPU and memory helpers
+func TestSys_RuntimeIntrospection(t *testing.T) {
+	code := `
+println(Sys.platform == Sys.os + "/" + Sys.arch)
+println(Sys.cpuCount() > 0)
+let before = Sys.memStats()
+println(before.size())
+println(before["heapAlloc"] > 0)
+Sys.gc()
+println(Sys.memStats()["numGC"] > before["numGC"])
+`
+	result, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "true\ntrue\n6\ntrue\ntrue\n"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
 // TestStaticModules_NotConstructable tests that static modules cannot be instantiated
 func TestStaticModules_NotConstructable(t *testing.T) {
 	tests := []struct {
@@ -171,3 +1121,42 @@ func TestStaticModules_NotConstructable(t *testing.T) {
 		})
 	}
 }
+
+func TestAssets_ReadsEmbeddedFiles(t *testing.T) {
+	code := `println(Assets.isEmbedded())
+println(Assets.read("./templates/index.html"))
+println(Assets.readBytes("public/logo.png").size())
+println(Assets.list("public"))
+println(Assets.list("missing"))
+println(Assets.exists("templates/index.html"), Assets.exists("templates"), Assets.exists("nope.txt"))
+try
+    Assets.read("nope.txt")
+catch e: IOError
+    println(e.getMessage())
+end
+try
+    Assets.read("../secrets.txt")
+catch e: ValueError
+    println(e.getMessage())
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.New(lx.Scan([]byte(code))).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	assets := fstest.MapFS{
+		"templates/index.html": {Data: []byte("<h1>hi</h1>")},
+		"public/logo.png":      {Data: []byte{0x89, 'P', 'N', 'G'}},
+		"public/css/site.css":  {Data: []byte("body {}")},
+	}
+	buf := &bytes.Buffer{}
+	if _, err := engine.Eval(prog, engine.Options{Stdout: buf, Assets: assets}); err != nil {
+		t.Fatalf("eval error: %v", err)
+	}
+	want := "true\n<h1>hi</h1>\n4\n[public/css/site.css, public/logo.png]\n[]\ntrue false false\n" +
+		"asset not found: nope.txt\nasset path must be relative to the project: ../secrets.txt\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
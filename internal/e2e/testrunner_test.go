@@ -0,0 +1,391 @@
+package e2e
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/engine"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+func TestTestRunner_RunsTestFunctionsAndReportsFailures(t *testing.T) {
+	code := `def add(a, b):
+    return a + b
+end
+def test_add():
+    assert add(1, 2) == 3
+end
+def test_message():
+    assert add(1, 2) == 4, "sum was #{add(1, 2)}"
+end
+def test_expect():
+    expect(add(2, 2)).toEqual(5)
+end
+def test_default_message():
+    assert nil
+end
+def check():
+    assert false
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan([]byte(code)), "math_test.pf", code).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	results, err := engine.RunTests(prog, engine.Options{}, "math_test.pf", ".", code, engine.TestConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	if strings.Join(names, ",") != "test_add,test_message,test_expect,test_default_message" {
+		t.Fatalf("unexpected tests %v", names)
+	}
+	if !results[0].Passed() || results[1].Passed() || results[2].Passed() || results[3].Passed() {
+		t.Fatalf("unexpected outcomes %+v", results)
+	}
+	for i, want := range []string{"sum was 3", "expected 4 to equal 5", "assertion failed"} {
+		exc, ok := results[i+1].Err.(*engine.HyException)
+		if !ok || exc.Type != "AssertionError" || exc.Message != want {
+			t.Errorf("%s: expected AssertionError %q, got %v", results[i+1].Name, want, results[i+1].Err)
+		}
+	}
+
+	out := &bytes.Buffer{}
+	engine.FormatTestResults(out, "math_test.pf", results)
+	report := out.String()
+	if !strings.Contains(report, "--- FAIL: test_message") || !strings.Contains(report, "assert add(1, 2) == 4") ||
+		!strings.HasSuffix(report, "FAIL math_test.pf: 1 passed, 3 failed\n") {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+
+	results, err = engine.RunTests(prog, engine.Options{}, "math_test.pf", ".", code, engine.TestConfig{Filter: "add"})
+	if err != nil || len(results) != 1 || !results[0].Passed() {
+		t.Errorf("expected only test_add to run, got %+v (err %v)", results, err)
+	}
+}
+
+func TestTestRunner_AnnotatedTestsAndLifecycle(t *testing.T) {
+	code := `var log = ""
+var counter = 0
+@BeforeAll
+def connect():
+    log = log + "connect,"
+end
+@AfterAll
+def disconnect():
+    log = log + "disconnect,"
+end
+@BeforeEach
+def reset():
+    counter = 0
+    log = log + "reset,"
+end
+@AfterEach
+def trace():
+    log = log + "record #{counter},"
+end
+@Test
+def increments():
+    counter = counter + 1
+    assert counter == 1
+end
+@Test
+def starts_clean():
+    assert counter == 0
+    counter = 5
+    assert false, "boom"
+end
+def test_by_name():
+    assert counter == 0
+end
+def helper():
+    assert false
+end
+def test_log():
+    expect(log).toEqual("connect,reset,record 1,reset,record 5,reset,record 0,reset,")
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan([]byte(code)), "lifecycle_test.pf", code).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	results, err := engine.RunTests(prog, engine.Options{}, "lifecycle_test.pf", ".", code, engine.TestConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var outcomes []string
+	for _, r := range results {
+		outcomes = append(outcomes, r.Name+"="+map[bool]string{true: "pass", false: "fail"}[r.Passed()])
+	}
+	if strings.Join(outcomes, ",") != "increments=pass,starts_clean=fail,test_by_name=pass,test_log=pass" {
+		t.Fatalf("unexpected results %v (%+v)", outcomes, results)
+	}
+
+	code = `@BeforeAll
+def setup():
+    throw RuntimeError("no database")
+end
+@AfterAll
+def teardown():
+    println("teardown")
+end
+@Test
+def never_runs():
+    assert true
+end
+`
+	prog, err = parser.NewWithSource(lx.Scan([]byte(code)), "setup_test.pf", code).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	out := &bytes.Buffer{}
+	results, err = engine.RunTests(prog, engine.Options{Stdout: out}, "setup_test.pf", ".", code, engine.TestConfig{})
+	if err != nil || len(results) != 1 || results[0].Name != "setup" || results[0].Passed() {
+		t.Fatalf("expected only the failing setup to be reported, got %+v (err %v)", results, err)
+	}
+	if out.String() != "teardown\n" {
+		t.Errorf("expected @AfterAll to run after a failed @BeforeAll, got %q", out.String())
+	}
+}
+
+func TestTestRunner_ParameterizedTests(t *testing.T) {
+	code := `def sums():
+    return [[1, 2, 3], [2, 2, 5]]
+end
+@Test
+@Params(sums)
+def adds(a, b, total):
+    assert a + b == total
+end
+@Params(["a", "bb"])
+def test_length(s):
+    assert s.length() > 0
+end
+@Test
+@Params([{"name": "amy"}, {"greeting": "hi", "name": "bob"}])
+def greets(name, greeting = "hello"):
+    println("#{greeting} #{name}")
+end
+@Test
+@Params([{"greeting": "hi"}])
+def needs_name(name, greeting = "hello"):
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan([]byte(code)), "params_test.pf", code).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	results, err := engine.RunTests(prog, engine.Options{Stdout: out}, "params_test.pf", ".", code, engine.TestConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var outcomes []string
+	for _, r := range results {
+		outcomes = append(outcomes, r.Name+"="+map[bool]string{true: "pass", false: "fail"}[r.Passed()])
+	}
+	want := []string{
+		"adds[0](1, 2, 3)=pass",
+		"adds[1](2, 2, 5)=fail",
+		`test_length[0]("a")=pass`,
+		`test_length[1]("bb")=pass`,
+		`greets[0]("amy")=pass`,
+		`greets[1]("bob", "hi")=pass`,
+		"needs_name=fail",
+	}
+	if strings.Join(outcomes, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected results:\n%s", strings.Join(outcomes, "\n"))
+	}
+	if out.String() != "hello amy\nhi bob\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if msg := results[6].Err.Error(); !strings.Contains(msg, "missing argument for parameter 'name'") {
+		t.Errorf("unexpected error for needs_name: %s", msg)
+	}
+
+	results, err = engine.RunTests(prog, engine.Options{Stdout: out}, "params_test.pf", ".", code, engine.TestConfig{Filter: "adds"})
+	if err != nil || len(results) != 2 {
+		t.Errorf("expected both cases of adds to run, got %+v (err %v)", results, err)
+	}
+}
+
+func TestTestRunner_Snapshots(t *testing.T) {
+	code := `var items = ["a", "b"]
+@Test
+def renders():
+    var text = ""
+    for item in items:
+        text = text + "- " + item + "\n"
+    end
+    expect(text).toMatchSnapshot()
+    expect({"z": 1, "a": [1, nil]}).toMatchSnapshot()
+    expect("<b>a & b</b>").toMatchSnapshot()
+end
+`
+	file := filepath.Join(t.TempDir(), "view_test.pf")
+	run := func(code string, cfg engine.TestConfig) []engine.TestResult {
+		t.Helper()
+		lx := &lexer.Lexer{}
+		prog, err := parser.NewWithSource(lx.Scan([]byte(code)), file, code).Parse()
+		if err != nil {
+			t.Fatalf("parse error: %v", err)
+		}
+		results, err := engine.RunTests(prog, engine.Options{}, file, ".", code, cfg)
+		if err != nil || len(results) != 1 {
+			t.Fatalf("unexpected results %+v (err %v)", results, err)
+		}
+		return results
+	}
+
+	if results := run(code, engine.TestConfig{}); !results[0].Passed() {
+		t.Fatalf("expected the first run to record the snapshots, got %v", results[0].Err)
+	}
+	data, err := os.ReadFile(engine.SnapshotPath(file))
+	if err != nil {
+		t.Fatalf("snapshot file not written: %v", err)
+	}
+	want := `{
+  "renders 1": "- a\n- b\n",
+  "renders 2": "{\n  \"a\": [\n    1,\n    null\n  ],\n  \"z\": 1\n}",
+  "renders 3": "<b>a & b</b>"
+}
+`
+	if string(data) != want {
+		t.Errorf("unexpected snapshot file:\n%s", data)
+	}
+
+	if results := run(code, engine.TestConfig{}); !results[0].Passed() {
+		t.Fatalf("expected an unchanged value to match, got %v", results[0].Err)
+	}
+
+	changed := strings.Replace(code, `["a", "b"]`, `["a", "c"]`, 1)
+	results := run(changed, engine.TestConfig{})
+	exc, ok := results[0].Err.(*engine.HyException)
+	if !ok || exc.Type != "AssertionError" || !strings.Contains(exc.Message, "  - a\n- - b\n+ - c\n") {
+		t.Fatalf("expected a snapshot diff, got %v", results[0].Err)
+	}
+
+	if results := run(changed, engine.TestConfig{UpdateSnapshots: true}); !results[0].Passed() {
+		t.Fatalf("expected -update-snapshots to pass, got %v", results[0].Err)
+	}
+	if results := run(changed, engine.TestConfig{}); !results[0].Passed() {
+		t.Fatalf("expected the updated snapshot to match, got %v", results[0].Err)
+	}
+}
+
+func TestTestRunner_DescribeAndIt(t *testing.T) {
+	code := `var items = []
+@BeforeEach
+def reset():
+    items = []
+end
+def test_plain():
+    assert true
+end
+describe("Stack", () => do
+    describe("push", () => do
+        it("adds an item", () => do
+            items.push(1)
+            expect(items.length()).toEqual(1)
+        end)
+        it("starts empty", () => do
+            expect(items.length()).toEqual(1)
+        end)
+    end)
+    it("is a list", () => do
+        assert true
+    end)
+end)
+it("runs at the top level", () => do
+    describe("late", () => do
+    end)
+end)
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan([]byte(code)), "stack_test.pf", code).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	results, err := engine.RunTests(prog, engine.Options{}, "stack_test.pf", ".", code, engine.TestConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var outcomes []string
+	for _, r := range results {
+		outcomes = append(outcomes, r.FullName()+"="+map[bool]string{true: "pass", false: "fail"}[r.Passed()])
+	}
+	want := []string{
+		"test_plain=pass",
+		"Stack > push > adds an item=pass",
+		"Stack > push > starts empty=fail",
+		"Stack > is a list=pass",
+		"runs at the top level=fail",
+	}
+	if strings.Join(outcomes, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected results:\n%s", strings.Join(outcomes, "\n"))
+	}
+	if msg := results[4].Err.Error(); !strings.Contains(msg, "describe() must be called while the test file loads") {
+		t.Errorf("unexpected error for a nested describe: %s", msg)
+	}
+
+	out := &bytes.Buffer{}
+	engine.FormatTestResults(out, "stack_test.pf", results[:4])
+	report := out.String()
+	if !strings.HasPrefix(report, "Stack\n  push\n    ok   adds an item\n    FAIL starts empty (") ||
+		!strings.Contains(report, "\n        AssertionError: expected 0 to equal 1\n") ||
+		!strings.HasSuffix(report, "\n  ok   is a list\nFAIL stack_test.pf: 3 passed, 1 failed\n") {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+
+	results, err = engine.RunTests(prog, engine.Options{}, "stack_test.pf", ".", code, engine.TestConfig{Filter: "Stack > push"})
+	if err != nil || len(results) != 2 {
+		t.Errorf("expected the tests under Stack > push to run, got %+v (err %v)", results, err)
+	}
+}
+
+func TestTestRunner_Coverage(t *testing.T) {
+	code := `def sign(x):
+    if x < 0:
+        return -1
+    end
+    return 1
+end
+def test_positive():
+    assert sign(5) == 1
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan([]byte(code)), "sign_test.pf", code).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	cov := engine.NewCoverage()
+	results, err := engine.RunTests(prog, engine.Options{Coverage: cov}, "sign_test.pf", ".", code, engine.TestConfig{})
+	if err != nil || len(results) != 1 || !results[0].Passed() {
+		t.Fatalf("expected test_positive to pass, got %+v (err %v)", results, err)
+	}
+	if hits := cov.Hits("sign_test.pf", 8); hits != 1 {
+		t.Errorf("expected the test body to run once, got %d", hits)
+	}
+
+	report := &bytes.Buffer{}
+	cov.Report(report)
+	if !strings.Contains(report.String(), "sign_test.pf") || !strings.Contains(report.String(), "uncovered: 3") {
+		t.Errorf("unexpected report:\n%s", report.String())
+	}
+}
@@ -0,0 +1,49 @@
+package e2e
+
+import "testing"
+
+func TestTime_FieldsFormatAndArithmetic(t *testing.T) {
+	code := `
+let t = Time.fromUnix(1700000000).utc()
+println(t)
+println(t.format("%Y-%m-%d %H:%M:%S"))
+println(t.format("%a %b %e %I:%M %p %Z %j %%"))
+println([t.year(), t.month(), t.day(), t.hour(), t.minute(), t.second(), t.weekday()])
+println(t.unix())
+let later = t.addSeconds(90)
+println(later.format("%H:%M:%S"))
+println(later.diffMillis(t))
+println(t.addDays(1).day())
+println(later.isAfter(t))
+try
+    t.format("%Q")
+catch e: ValueError
+    println("bad layout")
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "2023-11-14T22:13:20Z\n2023-11-14 22:13:20\nTue Nov 14 10:13 PM UTC 318 %\n" +
+		"[2023, 11, 14, 22, 13, 20, 2]\n1700000000\n22:14:50\n90000\n15\ntrue\nbad layout\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestTime_NowAndSleep(t *testing.T) {
+	code := `
+let start = Time.now()
+Time.sleep(20)
+println(Time.now().diffMillis(start) >= 20)
+println(Time.now().year() >= 2024)
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "true\ntrue\n" {
+		t.Errorf("expected %q, got %q", "true\ntrue\n", out)
+	}
+}
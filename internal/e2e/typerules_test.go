@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ArubikU/polyloft/internal/engine"
@@ -415,6 +416,98 @@ celebrateBirthday(30)
 	}
 }
 
+// Parameters annotated with aliases of builtin and user types are validated against the base type
+func TestTypeRules_TypeAliasParameterValidation(t *testing.T) {
+	code := `
+class Point:
+    var x: Int
+    Point(x: Int):
+        this.x = x
+    end
+end
+
+typealias Pt = Point
+typealias Loc = Pt
+typealias Count = Int
+final type N = Count
+
+def shift(p: Loc, n: N):
+    return p.x + n
+end
+println(shift(Point(1), 2))
+
+try
+    shift(Point(1), "two")
+catch e: TypeError
+    println(e.message)
+end
+try
+    shift(3, 2)
+catch e: TypeError
+    println(e.message)
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "3\nexpected N, got String\nexpected Loc, got Integer\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestTypeRules_TypeAliasCycle(t *testing.T) {
+	code := `
+typealias First = Second
+typealias Second = First
+`
+	_, err := runCodeWithOutput(code)
+	if err == nil || !strings.Contains(err.Error(), "Second -> First -> Second") {
+		t.Fatalf("expected a circular alias error, got %v", err)
+	}
+}
+
+// Type aliases with type parameters substitute their arguments when used
+func TestTypeRules_GenericTypeAliases(t *testing.T) {
+	code := `
+typealias StringMap<V> = Map<String, V>
+typealias Bag<T> = List<T>
+
+let m: StringMap<Int> = {"a": 1, "b": 2}
+println(m["b"])
+println(Sys.instanceof(m, "StringMap"))
+
+let b: Bag<Int> = List<Int>(1, 2)
+println(Sys.instanceof(b, "Bag<Int>"))
+println(Sys.instanceof(b, "Bag<String>"))
+
+def first(items: Bag<Int>) -> Int:
+    return items.get(0)
+end
+println(first(b))
+
+try
+    let wrong: Bag<String> = b
+catch e: TypeError
+    println(e.message)
+end
+try
+    let arity: StringMap<Int, Int> = {"a": 1}
+catch e
+    println(e.message)
+end
+`
+	out, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "2\ntrue\ntrue\nfalse\n1\nexpected Bag<String>, got List<Int>\ntype alias StringMap expects 1 type arguments, got 2\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
 // Section 11: Built-in types
 func TestTypeRules_Section11_BuiltinTypes(t *testing.T) {
 	code := `
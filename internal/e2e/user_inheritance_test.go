@@ -340,3 +340,55 @@ return check1 && check2 && check3
 		t.Fatalf("Expected true for multi-level inheritance checks, got %v", val)
 	}
 }
+
+func TestInheritance_InstanceOfInterfaces(t *testing.T) {
+	// instanceof against user and builtin interfaces, inherited implementations
+	// and the binding form, whose variable keeps the narrowed type
+	code := `
+interface ShapeI:
+    area() -> Float
+end
+
+class SquareI implements ShapeI:
+    var side
+    SquareI(s):
+        this.side = s
+    end
+    def area() -> Float:
+        return this.side * this.side * 1.0
+    end
+end
+
+class BigSquareI < SquareI:
+    BigSquareI():
+        super(10)
+    end
+end
+
+let big = BigSquareI()
+let text = "ab"
+let shapes = "#{SquareI(2) instanceof ShapeI} #{big instanceof ShapeI} #{3 instanceof ShapeI} #{big.side instanceof ShapeI}"
+let iterables = "#{[1, 2] instanceof Iterable} #{text instanceof Iterable} #{List() instanceof Iterable} #{5 instanceof Iterable}"
+let indexables = "#{[1] instanceof Indexable} #{text instanceof Sliceable} #{Set() instanceof Indexable}"
+
+var bound = ""
+if big instanceof ShapeI var s:
+    bound = "#{s.area()}"
+    try
+        s = 5
+    catch e: TypeError
+        bound = bound + " " + e.message
+    end
+end
+return shapes + "|" + iterables + "|" + indexables + "|" + bound
+`
+	result, err := runCode(code)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "true true false false|true true true false|true true false|100 expected ShapeI, got Integer"
+	if str := utils.ToString(result); str != want {
+		t.Fatalf("Expected %q, got %q", want, str)
+	}
+}
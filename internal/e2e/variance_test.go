@@ -277,3 +277,80 @@ return list.size()
 		t.Fatalf("Expected size 2, got %v", result)
 	}
 }
+
+func TestVariance_Assignability(t *testing.T) {
+	code := `
+class Animal:
+    Animal():
+    end
+end
+class Cat < Animal:
+    Cat():
+        super()
+    end
+end
+class Producer<out T>:
+    Producer():
+    end
+end
+class Consumer<in T>:
+    Consumer():
+    end
+end
+class Box<T>:
+    Box():
+    end
+end
+
+let p: Producer<Animal> = Producer<Cat>()
+let c: Consumer<Cat> = Consumer<Animal>()
+let wide: Box<? extends Animal> = Box<Cat>()
+let narrow: Box<? super Cat> = Box<Animal>()
+println("ok")
+
+try
+    let b: Box<Animal> = Box<Cat>()
+catch e: TypeError
+    println(e.message)
+end
+try
+    let p2: Producer<Cat> = Producer<Animal>()
+catch e: TypeError
+    println(e.message)
+end
+try
+    let c2: Consumer<Animal> = Consumer<Cat>()
+catch e: TypeError
+    println(e.message)
+end
+
+var cats: Box<Cat> = Box<Cat>()
+try
+    cats = Box<Animal>()
+catch e: TypeError
+    println(e.message)
+end
+
+def feed(box: Box<Animal>):
+    return "fed"
+end
+try
+    feed(Box<Cat>())
+catch e: TypeError
+    println(e.message)
+end
+`
+	got, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "ok\n" +
+		"expected Box<Animal>, got Box<Cat>\n" +
+		"expected Producer<Cat>, got Producer<Animal>\n" +
+		"expected Consumer<Animal>, got Consumer<Cat>\n" +
+		"expected Box<Cat>, got Box<Animal>\n" +
+		"expected Box<Animal>, got Box<Cat>\n"
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
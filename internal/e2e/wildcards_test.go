@@ -249,3 +249,60 @@ return list.toString()
 		t.Fatalf("Expected '? super Integer' in toString, got %s", str)
 	}
 }
+
+func TestWildcard_Capture(t *testing.T) {
+	code := `
+class Box<T>:
+    private var item: T
+    Box(i: T):
+        this.item = i
+    end
+    def get() -> T:
+        return this.item
+    end
+    def set(i: T):
+        this.item = i
+    end
+end
+
+let numbers = Box<? extends Number>(1)
+numbers.set(2.5)
+println(numbers.get())
+try
+    numbers.set("two")
+catch e: TypeError
+    println(e.message)
+end
+let broken = Box<? extends Number>("one")
+try
+    broken.get()
+catch e: TypeError
+    println(e.message)
+end
+
+let ints = Box<? super Int>(1)
+ints.set(5)
+println(ints.get())
+try
+    ints.set("five")
+catch e: TypeError
+    println(e.message)
+end
+
+let anything = Box<?>("x")
+anything.set(true)
+println(anything.get())
+
+println(Sys.instanceof(numbers, "Box<? extends Number>"))
+println(Sys.instanceof(numbers, "Box<Number>"))
+println(Sys.instanceof(ints, "Box<? super Int>"))
+`
+	got, err := runCodeWithOutput(code)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "2.5\nexpected Number, got String\nexpected Number, got String\n5\nexpected Integer, got String\ntrue\ntrue\nfalse\ntrue\n"
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
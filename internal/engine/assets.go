@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// InstallAssetsModule installs the Assets class, which reads the files listed
+// under [build] assets in polyloft.toml. Built executables read them from
+// opts.Assets, where polyloft build embeds them; otherwise they are read from
+// the working directory, so the same code runs from the project root during
+// development. Sandboxed runs without embedded assets cannot read any.
+func InstallAssetsModule(env *Env, opts Options) error {
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	boolType := common.BuiltinTypeBool.GetTypeDefinition(env)
+	arrayType := common.BuiltinTypeArray.GetTypeDefinition(env)
+	bytesType := common.BuiltinTypeBytes.GetTypeDefinition(env)
+
+	files := opts.Assets
+	if files == nil && !opts.Sandbox {
+		files = os.DirFS(".")
+	}
+
+	assetsClass := NewClassBuilder("Assets").
+		// read(path: String) -> String
+		AddStaticMethod("read", stringType, []ast.Parameter{{Name: "path", Type: stringType}}, Func(func(e *Env, args []any) (any, error) {
+			data, err := readAsset(e, files, utils.ToString(args[0]))
+			if err != nil {
+				return nil, err
+			}
+			return string(data), nil
+		})).
+		// readBytes(path: String) -> Bytes
+		AddStaticMethod("readBytes", bytesType, []ast.Parameter{{Name: "path", Type: stringType}}, Func(func(e *Env, args []any) (any, error) {
+			data, err := readAsset(e, files, utils.ToString(args[0]))
+			if err != nil {
+				return nil, err
+			}
+			return CreateBytesInstance(e, data)
+		})).
+		// exists(path: String) -> Bool
+		AddStaticMethod("exists", boolType, []ast.Parameter{{Name: "path", Type: stringType}}, Func(func(_ *Env, args []any) (any, error) {
+			name, ok := assetName(utils.ToString(args[0]))
+			if !ok || files == nil {
+				return false, nil
+			}
+			info, err := fs.Stat(files, name)
+			return err == nil && !info.IsDir(), nil
+		})).
+		// list(dir: String) -> Array - paths of the files under dir, sorted
+		AddStaticMethod("list", arrayType, []ast.Parameter{{Name: "dir", Type: stringType}}, Func(func(e *Env, args []any) (any, error) {
+			dir, ok := assetName(utils.ToString(args[0]))
+			if !ok {
+				return nil, ThrowValueError(e, "asset path must be relative to the project: "+utils.ToString(args[0]))
+			}
+			paths := []any{}
+			if files == nil {
+				return paths, nil
+			}
+			err := fs.WalkDir(files, dir, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() {
+					paths = append(paths, p)
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, ThrowIOError(e, err.Error())
+			}
+			return paths, nil
+		})).
+		// isEmbedded() -> Bool - true when the assets are embedded in the executable
+		AddStaticMethod("isEmbedded", boolType, []ast.Parameter{}, Func(func(_ *Env, _ []any) (any, error) {
+			return opts.Assets != nil, nil
+		}))
+
+	_, err := assetsClass.BuildStatic(env)
+	return err
+}
+
+// assetName turns a script path into a name for fs.FS: slash separated,
+// without a leading "./" and not escaping the project
+func assetName(p string) (string, bool) {
+	name := path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	if name == "" {
+		name = "."
+	}
+	return name, fs.ValidPath(name)
+}
+
+func readAsset(env *Env, files fs.FS, p string) ([]byte, error) {
+	name, ok := assetName(p)
+	if !ok {
+		return nil, ThrowValueError(env, "asset path must be relative to the project: "+p)
+	}
+	if files == nil {
+		return nil, ThrowIOError(env, "asset not found: "+p)
+	}
+	data, err := fs.ReadFile(files, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ThrowIOError(env, "asset not found: "+p)
+	}
+	if err != nil {
+		return nil, ThrowIOError(env, err.Error())
+	}
+	return data, nil
+}
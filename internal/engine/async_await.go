@@ -107,7 +107,8 @@ func buildPromiseClass(promiseClass *ClassBuilder, env *Env) (*ClassDefinition,
 				return nil, nil
 			})
 
-			// Execute the executor function asynchronously
+			// Execute the executor function asynchronously, within the current run's limits
+			executorEnv := spawnEnv(callEnv)
 			go func() {
 				defer func() {
 					if r := recover(); r != nil {
@@ -115,7 +116,7 @@ func buildPromiseClass(promiseClass *ClassBuilder, env *Env) (*ClassDefinition,
 					}
 				}()
 
-				_, err := executor(callEnv, []any{resolve, reject})
+				_, err := executor(executorEnv, []any{resolve, reject})
 				if err != nil {
 					promise.reject(err)
 				}
@@ -151,6 +152,8 @@ func buildPromiseClass(promiseClass *ClassBuilder, env *Env) (*ClassDefinition,
 		if !ok {
 			return nil, ThrowTypeError((*Env)(callEnv), "function", args[0])
 		}
+		// The handler may run after the run that added it, within that run's limits
+		handlerEnv := spawnEnv(callEnv)
 
 		// Create a new promise for chaining
 		newPromise := &Promise{
@@ -166,7 +169,7 @@ func buildPromiseClass(promiseClass *ClassBuilder, env *Env) (*ClassDefinition,
 			// Original promise already fulfilled
 			promise.mu.Unlock()
 			go func() {
-				result, err := handler(callEnv, []any{promise.value})
+				result, err := handler(handlerEnv, []any{promise.value})
 				if err != nil {
 					newPromise.reject(err)
 				} else {
@@ -176,7 +179,7 @@ func buildPromiseClass(promiseClass *ClassBuilder, env *Env) (*ClassDefinition,
 		} else if promise.state == "pending" {
 			// Original promise still pending
 			promise.thenHandlers = append(promise.thenHandlers, func(val any) (any, error) {
-				result, err := handler(callEnv, []any{val})
+				result, err := handler(handlerEnv, []any{val})
 				if err != nil {
 					newPromise.reject(err)
 					return nil, err
@@ -224,14 +227,16 @@ func buildPromiseClass(promiseClass *ClassBuilder, env *Env) (*ClassDefinition,
 		if !ok {
 			return nil, ThrowTypeError((*Env)(callEnv), "function", args[0])
 		}
+		// The handler may run after the run that added it, within that run's limits
+		handlerEnv := spawnEnv(callEnv)
 
 		promise.mu.Lock()
 		if promise.state == "rejected" {
 			promise.mu.Unlock()
-			handler(callEnv, []any{promise.err.Error()})
+			handler(handlerEnv, []any{promise.err.Error()})
 		} else if promise.state == "pending" {
 			promise.catchHandlers = append(promise.catchHandlers, func(e error) (any, error) {
-				return handler(callEnv, []any{e.Error()})
+				return handler(handlerEnv, []any{e.Error()})
 			})
 			promise.mu.Unlock()
 		} else {
@@ -257,14 +262,16 @@ func buildPromiseClass(promiseClass *ClassBuilder, env *Env) (*ClassDefinition,
 		if !ok {
 			return nil, ThrowTypeError((*Env)(callEnv), "function", args[0])
 		}
+		// The handler may run after the run that added it, within that run's limits
+		handlerEnv := spawnEnv(callEnv)
 
 		promise.mu.Lock()
 		if promise.state != "pending" {
 			promise.mu.Unlock()
-			handler(callEnv, []any{})
+			handler(handlerEnv, []any{})
 		} else {
 			promise.finallyHandlers = append(promise.finallyHandlers, func() {
-				handler(callEnv, []any{})
+				handler(handlerEnv, []any{})
 			})
 			promise.mu.Unlock()
 		}
@@ -316,6 +323,31 @@ func buildPromiseClass(promiseClass *ClassBuilder, env *Env) (*ClassDefinition,
 		return fmt.Sprintf("Promise{state=%s}", promise.state), nil
 	}, []string{})
 
+	// Promise.all(promises: Array) -> Promise<Array>
+	promiseClass.AddStaticMethod("all", &ast.Type{Name: "Promise", IsBuiltin: true}, []ast.Parameter{
+		{Name: "promises", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		promises, err := promiseList((*Env)(callEnv), "Promise.all", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return newPromiseInstance((*Env)(callEnv), promiseAll((*Env)(callEnv), promises))
+	})
+
+	// Promise.race(promises: Array) -> Promise<T>
+	promiseClass.AddStaticMethod("race", &ast.Type{Name: "Promise", IsBuiltin: true}, []ast.Parameter{
+		{Name: "promises", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		promises, err := promiseList((*Env)(callEnv), "Promise.race", args[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(promises) == 0 {
+			return nil, ThrowValueError((*Env)(callEnv), "Promise.race requires at least one promise")
+		}
+		return newPromiseInstance((*Env)(callEnv), promiseRace(promises))
+	})
+
 	_, err := promiseClass.Build(env)
 	if err != nil {
 		return nil, err
@@ -566,6 +598,7 @@ func installAsyncFunction(env *common.Env) {
 			done:            make(chan struct{}),
 		}
 
+		asyncEnv := spawnEnv(callEnv)
 		go func() {
 			defer func() {
 				if r := recover(); r != nil {
@@ -573,7 +606,7 @@ func installAsyncFunction(env *common.Env) {
 				}
 			}()
 
-			result, err := fn(callEnv, []any{})
+			result, err := fn(asyncEnv, []any{})
 			if err != nil {
 				promise.reject(err)
 			} else {
@@ -672,3 +705,124 @@ func (p *Promise) reject(err error) {
 
 	close(p.done)
 }
+
+func newPendingPromise() *Promise {
+	return &Promise{
+		state:           "pending",
+		thenHandlers:    []func(any) (any, error){},
+		catchHandlers:   []func(error) (any, error){},
+		finallyHandlers: []func(){},
+		done:            make(chan struct{}),
+	}
+}
+
+// newPromiseInstance wraps promise in a Promise class instance
+func newPromiseInstance(env *Env, promise *Promise) (any, error) {
+	promiseClassDef := common.BuiltinTypePromise.GetClassDefinition(env)
+	if promiseClassDef == nil {
+		return nil, ThrowInitializationError(env, "Promise class")
+	}
+	instance, err := createClassInstanceDirect(promiseClassDef, env)
+	if err != nil {
+		return nil, err
+	}
+	instance.(*ClassInstance).Fields["_promise"] = promise
+	return instance, nil
+}
+
+// promiseList extracts the promises of the collection passed to a combinator
+func promiseList(env *Env, combinator string, value any) ([]*Promise, error) {
+	items, ok := collectionItems(value)
+	if !ok {
+		return nil, ThrowTypeError(env, "Array of Promise for "+combinator, value)
+	}
+	promises := make([]*Promise, len(items))
+	for i, item := range items {
+		inst, isInstance := item.(*ClassInstance)
+		var promise *Promise
+		if isInstance {
+			promise, _ = inst.Fields["_promise"].(*Promise)
+		}
+		if promise == nil {
+			return nil, ThrowTypeError(env, "Promise", item)
+		}
+		promises[i] = promise
+	}
+	return promises, nil
+}
+
+// settled reports the outcome of one promise awaited by a combinator
+type settled struct {
+	index int
+	value any
+	err   error
+}
+
+// awaitAll waits on every promise in its own goroutine and sends each outcome
+// on the returned channel. Closing stop makes the remaining waiters give up.
+func awaitAll(promises []*Promise, stop <-chan struct{}) <-chan settled {
+	results := make(chan settled, len(promises))
+	for i, p := range promises {
+		go func(index int, p *Promise) {
+			select {
+			case <-p.done:
+			case <-stop:
+				return
+			}
+			p.mu.Lock()
+			outcome := settled{index: index, value: p.value, err: p.err}
+			if p.state != "rejected" {
+				outcome.err = nil
+			}
+			p.mu.Unlock()
+			results <- outcome
+		}(i, p)
+	}
+	return results
+}
+
+// promiseAll resolves to an Array of the results in input order, or rejects
+// with the first rejection, at which point it stops waiting on the others
+func promiseAll(env *Env, promises []*Promise) *Promise {
+	combined := newPendingPromise()
+	stop := make(chan struct{})
+	results := awaitAll(promises, stop)
+
+	go func() {
+		defer close(stop)
+		values := make([]any, len(promises))
+		for range promises {
+			outcome := <-results
+			if outcome.err != nil {
+				combined.reject(outcome.err)
+				return
+			}
+			values[outcome.index] = outcome.value
+		}
+		array, err := CreateArrayInstance(env, values)
+		if err != nil {
+			combined.reject(err)
+			return
+		}
+		combined.resolve(array)
+	}()
+	return combined
+}
+
+// promiseRace settles like the first of promises to settle
+func promiseRace(promises []*Promise) *Promise {
+	combined := newPendingPromise()
+	stop := make(chan struct{})
+	results := awaitAll(promises, stop)
+
+	go func() {
+		defer close(stop)
+		outcome := <-results
+		if outcome.err != nil {
+			combined.reject(outcome.err)
+		} else {
+			combined.resolve(outcome.value)
+		}
+	}()
+	return combined
+}
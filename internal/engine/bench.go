@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+)
+
+// BenchConfig controls how benchmark functions are run
+type BenchConfig struct {
+	Time   time.Duration // target duration of each measured round (default 200ms)
+	Rounds int           // number of measured rounds (default 5)
+	Filter string        // only run benchmarks whose name contains Filter
+}
+
+// BenchResult holds the measurements of one benchmark function
+type BenchResult struct {
+	Name       string
+	Iterations int       // iterations per round, found by calibration
+	NsPerOp    []float64 // ns/op of each measured round
+}
+
+// Mean returns the average ns/op across rounds
+func (r BenchResult) Mean() float64 {
+	total := 0.0
+	for _, ns := range r.NsPerOp {
+		total += ns
+	}
+	return total / float64(len(r.NsPerOp))
+}
+
+// StdDev returns the standard deviation of ns/op across rounds
+func (r BenchResult) StdDev() float64 {
+	mean := r.Mean()
+	variance := 0.0
+	for _, ns := range r.NsPerOp {
+		variance += (ns - mean) * (ns - mean)
+	}
+	return math.Sqrt(variance / float64(len(r.NsPerOp)))
+}
+
+// Min returns the fastest round's ns/op
+func (r BenchResult) Min() float64 {
+	fastest := r.NsPerOp[0]
+	for _, ns := range r.NsPerOp[1:] {
+		fastest = math.Min(fastest, ns)
+	}
+	return fastest
+}
+
+// Max returns the slowest round's ns/op
+func (r BenchResult) Max() float64 {
+	slowest := r.NsPerOp[0]
+	for _, ns := range r.NsPerOp[1:] {
+		slowest = math.Max(slowest, ns)
+	}
+	return slowest
+}
+
+// RunBenchmarks evaluates prog and then measures every top-level function whose
+// name starts with "bench", in declaration order. Each function is called with
+// no arguments; the iteration count is calibrated like Go's testing.B so that
+// one round lasts about cfg.Time.
+func RunBenchmarks(prog *ast.Program, opts Options, fileName, packageName, source string, cfg BenchConfig) ([]BenchResult, error) {
+	if cfg.Time <= 0 {
+		cfg.Time = 200 * time.Millisecond
+	}
+	if cfg.Rounds <= 0 {
+		cfg.Rounds = 5
+	}
+
+	env := newProgramEnv(opts, fileName, packageName, source)
+	installHooks(env, opts, fileName, prog)
+	if _, err := runProgram(env, prog); err != nil {
+		return nil, err
+	}
+
+	var results []BenchResult
+	for _, st := range prog.Stmts {
+		def, ok := st.(*ast.DefStmt)
+		if !ok || !strings.HasPrefix(def.Name, "bench") || !strings.Contains(def.Name, cfg.Filter) {
+			continue
+		}
+		value, _ := env.Get(def.Name)
+		fn, ok := common.ExtractFunc(value)
+		if !ok {
+			continue
+		}
+
+		result, err := runBenchmark(env, def.Name, fn, cfg)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func runBenchmark(env *common.Env, name string, fn common.Func, cfg BenchConfig) (BenchResult, error) {
+	result := BenchResult{Name: name}
+
+	// Calibrate: grow n until a round takes at least cfg.Time
+	n := 1
+	for {
+		elapsed, err := timeIterations(env, fn, n)
+		if err != nil {
+			return result, err
+		}
+		if elapsed >= cfg.Time || n >= 1e9 {
+			break
+		}
+		n = predictIterations(n, elapsed, cfg.Time)
+	}
+	result.Iterations = n
+
+	for i := 0; i < cfg.Rounds; i++ {
+		elapsed, err := timeIterations(env, fn, n)
+		if err != nil {
+			return result, err
+		}
+		result.NsPerOp = append(result.NsPerOp, float64(elapsed.Nanoseconds())/float64(n))
+	}
+	return result, nil
+}
+
+// timeIterations calls fn n times and returns the elapsed monotonic time
+func timeIterations(env *common.Env, fn common.Func, n int) (time.Duration, error) {
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		if _, err := fn(env, []any{}); err != nil {
+			return 0, err
+		}
+	}
+	return time.Since(start), nil
+}
+
+// predictIterations estimates the iteration count needed to reach goal, growing
+// by at most 100x per step and always by at least one, like testing.B.
+func predictIterations(n int, elapsed, goal time.Duration) int {
+	next := n * 100
+	if elapsed > 0 {
+		next = int(float64(n) * 1.2 * float64(goal) / float64(elapsed))
+	}
+	if next > n*100 {
+		next = n * 100
+	}
+	if next <= n {
+		next = n + 1
+	}
+	if next > 1e9 {
+		next = 1e9
+	}
+	return next
+}
+
+// FormatBenchResults writes one line per benchmark with ns/op statistics
+func FormatBenchResults(w io.Writer, results []BenchResult) {
+	width := 0
+	for _, r := range results {
+		if len(r.Name) > width {
+			width = len(r.Name)
+		}
+	}
+	for _, r := range results {
+		mean := r.Mean()
+		spread := 0.0
+		if mean > 0 {
+			spread = r.StdDev() * 100 / mean
+		}
+		fmt.Fprintf(w, "%-*s %10d %14.1f ns/op  ±%.1f%%  (min %.1f, max %.1f)\n",
+			width, r.Name, r.Iterations, mean, spread, r.Min(), r.Max())
+	}
+}
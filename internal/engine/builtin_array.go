@@ -37,6 +37,40 @@ func InstallArrayBuiltin(env *Env) error {
 
 	arrayClass.AddField("_items", arrayType, []string{"private"})
 
+	// Constructor: Array() - empty array
+	arrayClass.AddBuiltinConstructor([]ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		instance.Fields["_items"] = []any{}
+		return nil, nil
+	})
+
+	// Constructor: Array(capacity: Int) - empty array with room for capacity items,
+	// so pushing up to capacity items does not reallocate
+	arrayClass.AddBuiltinConstructor([]ast.Parameter{
+		{Name: "capacity", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		capacity, ok := utils.AsInt(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "int", args[0])
+		}
+		if capacity < 0 {
+			return nil, ThrowValueError((*Env)(callEnv), fmt.Sprintf("Array capacity must not be negative, got %d", capacity))
+		}
+		instance.Fields["_items"] = make([]any, 0, capacity)
+		return nil, nil
+	})
+
+	// capacity() -> Int - number of items the array can hold before it reallocates
+	arrayClass.AddBuiltinMethod("capacity", intType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		items := instance.Fields["_items"].([]any)
+		return cap(items), nil
+	}, []string{})
+
 	//Iterable interface methods , __length, __get, __get_step
 	arrayClass.AddBuiltinMethod("__length", ast.ANY, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
@@ -244,7 +278,7 @@ func InstallArrayBuiltin(env *Env) error {
 		items := instance.Fields["_items"].([]any)
 
 		for i, item := range items {
-			if equals(item, args[0]) {
+			if equals(callEnv, item, args[0]) {
 				return i, nil
 			}
 		}
@@ -260,7 +294,7 @@ func InstallArrayBuiltin(env *Env) error {
 		items := instance.Fields["_items"].([]any)
 
 		for _, item := range items {
-			if equals(item, args[0]) {
+			if equals(callEnv, item, args[0]) {
 				return true, nil
 			}
 		}
@@ -310,7 +344,7 @@ func InstallArrayBuiltin(env *Env) error {
 		sep := utils.ToString(args[0])
 		parts := make([]string, len(items))
 		for i, item := range items {
-			parts[i] = utils.ToString(item)
+			parts[i] = utils.ToStringWithEnv(item, callEnv)
 		}
 		return strings.Join(parts, sep), nil
 	}, []string{})
@@ -327,17 +361,104 @@ func InstallArrayBuiltin(env *Env) error {
 		return nil, nil
 	}, []string{})
 
-	// sort() -> Void
-	arrayClass.AddBuiltinMethod("sort", ast.NIL, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+	// sort() -> Void - stable, in place, in natural order or by comparator
+	sortInPlace := func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
 		items := instance.Fields["_items"].([]any)
 
-		sort.Slice(items, func(i, j int) bool {
-			return utils.ToString(items[i]) < utils.ToString(items[j])
-		})
-		return nil, nil
-	}, []string{})
+		cmp, err := arrayComparator((*Env)(callEnv), args)
+		if err != nil {
+			return nil, err
+		}
+		return nil, sortItems(items, cmp)
+	}
+	arrayClass.AddBuiltinMethod("sort", ast.NIL, []ast.Parameter{}, sortInPlace, []string{})
+	// sort(comparator: Function) -> Void
+	arrayClass.AddBuiltinMethod("sort", ast.NIL, []ast.Parameter{
+		{Name: "comparator", Type: nil},
+	}, sortInPlace, []string{})
+
+	// sorted() -> Array - a sorted copy; the array itself is left unchanged
+	sortedCopy := func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		items := instance.Fields["_items"].([]any)
+
+		cmp, err := arrayComparator((*Env)(callEnv), args)
+		if err != nil {
+			return nil, err
+		}
+		result := append([]any(nil), items...)
+		if err := sortItems(result, cmp); err != nil {
+			return nil, err
+		}
+		return CreateArrayInstance((*Env)(callEnv), result)
+	}
+	arrayClass.AddBuiltinMethod("sorted", arrayClass.GetType(), []ast.Parameter{}, sortedCopy, []string{})
+	// sorted(comparator: Function) -> Array
+	arrayClass.AddBuiltinMethod("sorted", arrayClass.GetType(), []ast.Parameter{
+		{Name: "comparator", Type: nil},
+	}, sortedCopy, []string{})
+
+	// binarySearch(value: any) -> Int - index of value in a sorted array, or
+	// -(insertion point) - 1 when it is missing; with duplicates, the first match
+	binarySearch := func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		items := instance.Fields["_items"].([]any)
+
+		cmp, err := arrayComparator((*Env)(callEnv), args[1:])
+		if err != nil {
+			return nil, err
+		}
+		index, found, err := searchSorted(items, args[0], cmp, false)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return -index - 1, nil
+		}
+		return index, nil
+	}
+	arrayClass.AddBuiltinMethod("binarySearch", intType, []ast.Parameter{
+		{Name: "value", Type: nil},
+	}, binarySearch, []string{})
+	// binarySearch(value: any, comparator: Function) -> Int
+	arrayClass.AddBuiltinMethod("binarySearch", intType, []ast.Parameter{
+		{Name: "value", Type: nil},
+		{Name: "comparator", Type: nil},
+	}, binarySearch, []string{})
+
+	// insertSorted(value: any) -> Int - insert value into a sorted array, after any
+	// equal elements, and return its index
+	insertSorted := func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		items := instance.Fields["_items"].([]any)
+
+		cmp, err := arrayComparator((*Env)(callEnv), args[1:])
+		if err != nil {
+			return nil, err
+		}
+		index, _, err := searchSorted(items, args[0], cmp, true)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, nil)
+		copy(items[index+1:], items[index:])
+		items[index] = args[0]
+		instance.Fields["_items"] = items
+		return index, nil
+	}
+	arrayClass.AddBuiltinMethod("insertSorted", intType, []ast.Parameter{
+		{Name: "value", Type: nil},
+	}, insertSorted, []string{})
+	// insertSorted(value: any, comparator: Function) -> Int
+	arrayClass.AddBuiltinMethod("insertSorted", intType, []ast.Parameter{
+		{Name: "value", Type: nil},
+		{Name: "comparator", Type: nil},
+	}, insertSorted, []string{})
 
 	// filter(fn: Function) -> Array
 	arrayClass.AddBuiltinMethod("filter", arrayClass.GetType(), []ast.Parameter{
@@ -436,6 +557,33 @@ func InstallArrayBuiltin(env *Env) error {
 		return accumulator, nil
 	}, []string{})
 
+	// reduce(fn: Function) -> any - the first element is the initial accumulator
+	arrayClass.AddBuiltinMethod("reduce", ast.ANY, []ast.Parameter{
+		{Name: "fn", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		items := instance.Fields["_items"].([]any)
+
+		fn, ok := common.ExtractFunc(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "function", args[0])
+		}
+		if len(items) == 0 {
+			return nil, ThrowValueError((*Env)(callEnv), "reduce of empty Array with no initial value")
+		}
+
+		accumulator := items[0]
+		for _, item := range items[1:] {
+			val, err := fn(callEnv, []any{accumulator, item})
+			if err != nil {
+				return nil, err
+			}
+			accumulator = val
+		}
+		return accumulator, nil
+	}, []string{})
+
 	// find(fn: Function) -> any
 	arrayClass.AddBuiltinMethod("find", ast.ANY, []ast.Parameter{
 		{Name: "fn", Type: nil},
@@ -575,7 +723,7 @@ func InstallArrayBuiltin(env *Env) error {
 
 		parts := make([]string, len(items))
 		for i, item := range items {
-			parts[i] = utils.ToString(item)
+			parts[i] = utils.ToStringWithEnv(item, callEnv)
 		}
 		return CreateStringInstance((*Env)(callEnv), "["+strings.Join(parts, ", ")+"]")
 	}, []string{})
@@ -841,3 +989,122 @@ func ClassInstanceToArrayTypeString(instance *ClassInstance) (string, error) {
 	typeString := strings.Join(types, " | ")
 	return fmt.Sprintf("Array<%s>", typeString), nil
 }
+
+// compareNatural orders two values: numbers by value, strings lexicographically,
+// and objects through their compareTo(other) method. It returns a negative,
+// zero or positive Int like a comparator.
+func compareNatural(env *Env, a, b any) (int, error) {
+	pa, pb := extractPrimitiveValue(a), extractPrimitiveValue(b)
+	if sa, ok := pa.(string); ok {
+		if sb, ok := pb.(string); ok {
+			return strings.Compare(sa, sb), nil
+		}
+	}
+	if ia, ok := pa.(int); ok {
+		if ib, ok := pb.(int); ok {
+			switch {
+			case ia < ib:
+				return -1, nil
+			case ia > ib:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	_, aString := pa.(string)
+	_, bString := pb.(string)
+	if !aString && !bString {
+		fa, oka := utils.AsFloat(pa)
+		fb, okb := utils.AsFloat(pb)
+		if oka && okb {
+			switch {
+			case fa < fb:
+				return -1, nil
+			case fa > fb:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if instance, ok := a.(*ClassInstance); ok {
+		if overloads, exists := instance.ParentClass.Methods["compareTo"]; exists {
+			if method := common.SelectMethodOverload(overloads, 1); method != nil {
+				result, err := CallInstanceMethod(instance, *method, env, []any{b})
+				if err != nil {
+					return 0, err
+				}
+				order, ok := utils.AsInt(result)
+				if !ok {
+					return 0, ThrowTypeError(env, "Int from compareTo", result)
+				}
+				return order, nil
+			}
+		}
+	}
+	return 0, ThrowTypeError(env, "values with a natural order (numbers, strings or objects with compareTo)", a, b)
+}
+
+// arrayComparator returns the comparator passed as an optional method argument,
+// falling back to the natural order
+func arrayComparator(env *Env, args []any) (func(a, b any) (int, error), error) {
+	if len(args) == 0 {
+		return func(a, b any) (int, error) { return compareNatural(env, a, b) }, nil
+	}
+	fn, ok := common.ExtractFunc(args[0])
+	if !ok {
+		return nil, ThrowTypeError(env, "function", args[0])
+	}
+	return func(a, b any) (int, error) {
+		result, err := fn((*common.Env)(env), []any{a, b})
+		if err != nil {
+			return 0, err
+		}
+		order, ok := utils.AsInt(result)
+		if !ok {
+			return 0, ThrowTypeError(env, "Int from comparator", result)
+		}
+		return order, nil
+	}, nil
+}
+
+// sortItems stably sorts items with cmp. The first comparison error stops the
+// sort and is returned; items are then left in an unspecified order.
+func sortItems(items []any, cmp func(a, b any) (int, error)) error {
+	var sortErr error
+	sort.SliceStable(items, func(i, j int) bool {
+		if sortErr != nil {
+			return false
+		}
+		order, err := cmp(items[i], items[j])
+		if err != nil {
+			sortErr = err
+			return false
+		}
+		return order < 0
+	})
+	return sortErr
+}
+
+// searchSorted binary-searches sorted items for value. It returns the first index
+// whose element is not less than value (or, with after, greater than value) and
+// whether an equal element was found.
+func searchSorted(items []any, value any, cmp func(a, b any) (int, error), after bool) (int, bool, error) {
+	lo, hi := 0, len(items)
+	found := false
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		order, err := cmp(items[mid], value)
+		if err != nil {
+			return 0, false, err
+		}
+		if order == 0 {
+			found = true
+		}
+		if order < 0 || (after && order == 0) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, found, nil
+}
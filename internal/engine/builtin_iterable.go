@@ -5,6 +5,7 @@ import (
 
 	"github.com/ArubikU/polyloft/internal/ast"
 	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
 )
 
 // These 2 should be used on "for ... in ..." constructs
@@ -118,7 +119,7 @@ func InstallPairBuiltin(env *Env) error {
 		instance := thisVal.(*ClassInstance)
 		key := instance.Fields["key"]
 		value := instance.Fields["value"]
-		return fmt.Sprintf("%v=%v", key, value), nil
+		return fmt.Sprintf("%s=%s", utils.ToStringWithEnv(key, callEnv), utils.ToStringWithEnv(value, callEnv)), nil
 	}, []string{})
 
 	// Unstructured interface methods for destructuring
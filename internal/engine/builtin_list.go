@@ -292,7 +292,7 @@ func InstallListBuiltin(env *Env) error {
 
 		strs := make([]string, len(*itemsPtr))
 		for i, item := range *itemsPtr {
-			strs[i] = utils.ToString(item)
+			strs[i] = utils.ToStringWithEnv(item, callEnv)
 		}
 
 		// Build type string with generic parameters if present
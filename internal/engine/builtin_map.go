@@ -1,9 +1,14 @@
 package engine
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"hash/fnv"
+	"math"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/ArubikU/polyloft/internal/ast"
@@ -13,71 +18,183 @@ import (
 
 type mapEntry = ast.MapEntry
 
-// hashValue computes a hash for a value
+// hashValue computes the bucket hash of a Map key or Set element. It agrees with
+// equals: values that are equal always hash equal.
+//
+//   - nil hashes as its own key, distinct from the string "null"
+//   - Strings and Bools hash by value
+//   - Integers and Floats hash by numeric value, so 1 and 1.0 are the same key
+//   - Bytes, Tuples and Records hash by content
+//   - Objects whose class defines hash() use its result
+//   - everything else, including mutable Arrays, Lists, Maps and Sets, hashes by
+//     identity; mutable collections should not be used as keys
 func hashValue(env *Env, v any) uint64 {
 	h := fnv.New64a()
-	switch val := v.(type) {
+	writeHashKey(env, h, v)
+	return h.Sum64()
+}
+
+func writeHashKey(env *Env, h hash.Hash64, v any) {
+	switch val := extractPrimitiveValue(v).(type) {
+	case nil:
+		h.Write([]byte("nil"))
 	case string:
-		h.Write([]byte(val))
+		h.Write([]byte("s:" + val))
+	case bool:
+		h.Write([]byte(fmt.Sprintf("b:%t", val)))
 	case int:
-		h.Write([]byte(fmt.Sprintf("%d", val)))
+		h.Write([]byte("n:" + formatHashFloat(float64(val))))
+	case int64:
+		h.Write([]byte("n:" + formatHashFloat(float64(val))))
 	case float64:
-		h.Write([]byte(fmt.Sprintf("%f", val)))
+		h.Write([]byte("n:" + formatHashFloat(val)))
+	case float32:
+		h.Write([]byte("n:" + formatHashFloat(float64(val))))
+	case *common.EnumValueInstance:
+		h.Write([]byte(fmt.Sprintf("e:%p", val)))
+	case *common.RecordInstance:
+		h.Write([]byte(fmt.Sprintf("r:%p(", val.Definition)))
+		for _, component := range val.Definition.Components {
+			writeHashKey(env, h, val.Values[component.Name])
+			h.Write([]byte(","))
+		}
+		h.Write([]byte(")"))
+	case *ClassInstance:
+		switch val.ClassName {
+		case "Bytes":
+			if data, ok := val.Fields["_data"].([]byte); ok {
+				h.Write([]byte("y:"))
+				h.Write(data)
+				return
+			}
+		case "Tuple":
+			if elements, ok := val.Fields["_elements"].([]any); ok {
+				h.Write([]byte("t:("))
+				for _, element := range elements {
+					writeHashKey(env, h, element)
+					h.Write([]byte(","))
+				}
+				h.Write([]byte(")"))
+				return
+			}
+		}
+		if val.ParentClass != nil {
+			if method := common.SelectMethodOverload(val.ParentClass.GetMethods("hash"), 0); method != nil {
+				hashResult, err := CallInstanceMethod(val, *method, env, []any{})
+				if err == nil {
+					h.Write([]byte("h:"))
+					writeHashKey(env, h, hashResult)
+					return
+				}
+			}
+		}
+		h.Write([]byte(fmt.Sprintf("p:%p", val)))
+	default:
+		h.Write([]byte(fmt.Sprintf("p:%p", v)))
+	}
+}
+
+// formatHashFloat renders integral numbers without a fraction so that 1 and 1.0 hash alike
+func formatHashFloat(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1e18 {
+		return fmt.Sprintf("%d", int64(f))
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// equals reports whether two Map keys or Set elements are the same key.
+// Numbers compare by value across Integer and Float, Strings and Bools by value,
+// Bytes, Tuples and Records by content, objects through their equals(other)
+// method when the class defines one and by identity otherwise.
+func equals(env *Env, a, b any) bool {
+	a, b = extractPrimitiveValue(a), extractPrimitiveValue(b)
+	if ai, ok := a.(int); ok {
+		if bi, ok := b.(int); ok {
+			return ai == bi
+		}
+	}
+	if an, ok := hashNumber(a); ok {
+		bn, ok := hashNumber(b)
+		return ok && an == bn
+	}
+
+	switch av := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
 	case bool:
-		if val {
-			h.Write([]byte("true"))
-		} else {
-			h.Write([]byte("false"))
+		bv, ok := b.(bool)
+		return ok && av == bv
+	case *common.RecordInstance:
+		bv, ok := b.(*common.RecordInstance)
+		if !ok || av.Definition != bv.Definition {
+			return false
+		}
+		for _, component := range av.Definition.Components {
+			if !equals(env, av.Values[component.Name], bv.Values[component.Name]) {
+				return false
+			}
 		}
+		return true
 	case *ClassInstance:
-		// Extract primitive value from ClassInstance
-		if val.ClassName == "String" {
-			if strVal, ok := val.Fields["_value"].(string); ok {
-				h.Write([]byte(strVal))
-				return h.Sum64()
-			}
-		} else if val.ClassName == "Int" {
-			if intVal, ok := val.Fields["_value"].(int); ok {
-				h.Write([]byte(fmt.Sprintf("%d", intVal)))
-				return h.Sum64()
+		bv, ok := b.(*ClassInstance)
+		if !ok {
+			return false
+		}
+		if av == bv {
+			return true
+		}
+		switch av.ClassName {
+		case "Bytes":
+			aData, aOk := av.Fields["_data"].([]byte)
+			bData, bOk := bv.Fields["_data"].([]byte)
+			if aOk && bOk && bv.ClassName == "Bytes" {
+				return bytes.Equal(aData, bData)
 			}
-		} else if val.ClassName == "Float" {
-			if floatVal, ok := val.Fields["_value"].(float64); ok {
-				h.Write([]byte(fmt.Sprintf("%f", floatVal)))
-				return h.Sum64()
+		case "Tuple":
+			aElems, aOk := av.Fields["_elements"].([]any)
+			bElems, bOk := bv.Fields["_elements"].([]any)
+			if !aOk || !bOk || bv.ClassName != "Tuple" || len(aElems) != len(bElems) {
+				return false
 			}
-		} else if val.ClassName == "Bool" {
-			if boolVal, ok := val.Fields["_value"].(bool); ok {
-				if boolVal {
-					h.Write([]byte("true"))
-				} else {
-					h.Write([]byte("false"))
+			for i := range aElems {
+				if !equals(env, aElems[i], bElems[i]) {
+					return false
 				}
-				return h.Sum64()
 			}
+			return true
 		}
-		methods := val.ParentClass.GetMethods("hash")
-		method := common.SelectMethodOverload(methods, 0)
-		if method == nil {
-			h.Write([]byte(fmt.Sprintf("%p", v)))
-			return h.Sum64()
-		}
-		hashResult, err := CallInstanceMethod(val, *method, env, []any{})
-		if err == nil {
-			intval, _ := utils.AsInt(hashResult)
-			return uint64(intval)
+		if av.ParentClass != nil {
+			if method := common.SelectMethodOverload(av.ParentClass.GetMethods("equals"), 1); method != nil {
+				result, err := CallInstanceMethod(av, *method, env, []any{bv})
+				return err == nil && utils.AsBool(result)
+			}
 		}
-		return hashValue(env, hashResult)
-	default:
-		h.Write([]byte(fmt.Sprintf("%v", v)))
+		return false
 	}
-	return h.Sum64()
+
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb || !ta.Comparable() {
+		return false
+	}
+	return a == b
 }
 
-// equals checks if two values are equal
-func equals(a, b any) bool {
-	// Simple equality check - can be enhanced
-	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+// hashNumber returns the numeric value of int and float keys
+func hashNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	return 0, false
 }
 
 func InstallSerializableInterface(env *Env) error {
@@ -116,7 +233,7 @@ func InstallMapBuiltin(env *Env) error {
 		hash := hashValue(callEnv, args[0])
 		if entries, exists := data[hash]; exists {
 			for _, entry := range entries {
-				if equals(entry.Key, args[0]) {
+				if equals(callEnv, entry.Key, args[0]) {
 					return entry.Value, nil
 				}
 			}
@@ -131,21 +248,7 @@ func InstallMapBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
-
-		hash := hashValue(callEnv, args[0])
-		if entries, exists := data[hash]; exists {
-			for i, entry := range entries {
-				if equals(entry.Key, args[0]) {
-					entries[i].Value = args[1]
-					return nil, nil
-				}
-			}
-			// Key not found in this bucket, add it
-			data[hash] = append(entries, &mapEntry{Key: args[0], Value: args[1]})
-		} else {
-			data[hash] = []*mapEntry{{Key: args[0], Value: args[1]}}
-		}
+		setMapEntry((*Env)(callEnv), instance, args[0], args[1])
 		return nil, nil
 	}, []string{})
 
@@ -157,6 +260,24 @@ func InstallMapBuiltin(env *Env) error {
 		return nil, nil
 	})
 
+	// Map(capacity: Int) - empty map pre-sized for about capacity entries
+	mapClass.AddBuiltinConstructor([]ast.Parameter{
+		{Name: "capacity", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		capacity, ok := utils.AsInt(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "int", args[0])
+		}
+		if capacity < 0 {
+			return nil, ThrowValueError((*Env)(callEnv), fmt.Sprintf("Map capacity must not be negative, got %d", capacity))
+		}
+		instance.Fields["_data"] = make(map[uint64][]*mapEntry, capacity)
+		instance.Fields["_entries"] = make([]*mapEntry, 0, capacity)
+		return nil, nil
+	})
+
 	// put(key: any, value: Any) -> Void (alias for set)
 	mapClass.AddBuiltinMethod("put", &ast.Type{Name: "void", IsBuiltin: true}, []ast.Parameter{
 		{Name: "key", Type: nil},
@@ -164,21 +285,7 @@ func InstallMapBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
-
-		hash := hashValue(callEnv, args[0])
-		if entries, exists := data[hash]; exists {
-			for i, entry := range entries {
-				if equals(entry.Key, args[0]) {
-					entries[i].Value = args[1]
-					return nil, nil
-				}
-			}
-			// Key not found in this bucket, add it
-			data[hash] = append(entries, &mapEntry{Key: args[0], Value: args[1]})
-		} else {
-			data[hash] = []*mapEntry{{Key: args[0], Value: args[1]}}
-		}
+		setMapEntry((*Env)(callEnv), instance, args[0], args[1])
 		return nil, nil
 	}, []string{})
 
@@ -188,17 +295,7 @@ func InstallMapBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
-
-		hash := hashValue(callEnv, args[0])
-		if entries, exists := data[hash]; exists {
-			for _, entry := range entries {
-				if equals(entry.Key, args[0]) {
-					return true, nil
-				}
-			}
-		}
-		return false, nil
+		return CreateBoolInstance((*Env)(callEnv), findMapEntry((*Env)(callEnv), instance, args[0]) != nil)
 	}, []string{})
 
 	// hasKey(key: K) -> Bool (alias for has)
@@ -207,21 +304,10 @@ func InstallMapBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
-
-		hash := hashValue(callEnv, args[0])
-		if entries, exists := data[hash]; exists {
-			for _, entry := range entries {
-				if equals(entry.Key, args[0]) {
-					return true, nil
-				}
-			}
-		}
-		return false, nil
+		return CreateBoolInstance((*Env)(callEnv), findMapEntry((*Env)(callEnv), instance, args[0]) != nil)
 	}, []string{})
 
 	// __get(key: K) -> V (Indexable interface)
-	// Also supports numeric index for iteration (returns Pair<K, V>)
 	mapClass.AddBuiltinMethod("__get", &common.VBound.Name, []ast.Parameter{
 		{Name: "key", Type: &common.KBound.Name},
 	}, func(callEnv *common.Env, args []any) (any, error) {
@@ -229,35 +315,10 @@ func InstallMapBuiltin(env *Env) error {
 		instance := thisVal.(*ClassInstance)
 		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
 
-		// Check if the argument is a numeric index (for iteration)
-		if idx, ok := utils.AsInt(args[0]); ok {
-			// Use stable _entries slice for iteration
-			entries, hasEntries := instance.Fields["_entries"].([]*mapEntry)
-			if hasEntries && idx >= 0 && idx < len(entries) {
-				entry := entries[idx]
-				// Create a Pair instance
-				pairClass, exists := lookupClass("Pair", "")
-				if !exists {
-					// Fallback to array if Pair not available
-					return []any{entry.Key, entry.Value}, nil
-				}
-
-				// Construct Pair
-				pairInstance, err := constructPairInstance(pairClass, entry.Key, entry.Value, (*Env)(callEnv))
-				if err != nil {
-					// Fallback to array on error
-					return []any{entry.Key, entry.Value}, nil
-				}
-				return pairInstance, nil
-			}
-			return nil, nil // Index out of bounds
-		}
-
-		// Otherwise, treat as key lookup - return value directly
 		hash := hashValue(callEnv, args[0])
 		if entries, exists := data[hash]; exists {
 			for _, entry := range entries {
-				if equals(entry.Key, args[0]) {
+				if equals(callEnv, entry.Key, args[0]) {
 					return entry.Value, nil
 				}
 			}
@@ -265,6 +326,39 @@ func InstallMapBuiltin(env *Env) error {
 		return nil, nil
 	}, []string{})
 
+	// __entry(index: Int) -> Pair<K, V> (used by for-in)
+	// Iteration is positional and kept apart from __get so that numeric keys
+	// are never mistaken for entry indexes
+	mapClass.AddBuiltinMethod("__entry", ast.ANY, []ast.Parameter{
+		{Name: "index", Type: ast.ANY},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+
+		// Use stable _entries slice for iteration
+		idx, _ := utils.AsInt(args[0])
+		entries, hasEntries := instance.Fields["_entries"].([]*mapEntry)
+		if isDeterministic(callEnv) {
+			entries, hasEntries = orderedMapEntries(instance, true), true
+		}
+		if !hasEntries || idx < 0 || idx >= len(entries) {
+			return nil, nil // Index out of bounds
+		}
+		entry := entries[idx]
+
+		pairClass, exists := lookupClass("Pair", "")
+		if !exists {
+			// Fallback to array if Pair not available
+			return []any{entry.Key, entry.Value}, nil
+		}
+		pairInstance, err := constructPairInstance(pairClass, entry.Key, entry.Value, (*Env)(callEnv))
+		if err != nil {
+			// Fallback to array on error
+			return []any{entry.Key, entry.Value}, nil
+		}
+		return pairInstance, nil
+	}, []string{})
+
 	// __set(key: K, value: V) -> Void (Indexable interface)
 	mapClass.AddBuiltinMethod("__set", &ast.Type{Name: "void", IsBuiltin: true}, []ast.Parameter{
 		{Name: "key", Type: &common.KBound.Name},
@@ -272,42 +366,7 @@ func InstallMapBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
-		entries, hasEntries := instance.Fields["_entries"].([]*mapEntry)
-
-		hash := hashValue(callEnv, args[0])
-		if bucketEntries, exists := data[hash]; exists {
-			// Check if key already exists
-			for i, entry := range bucketEntries {
-				if equals(entry.Key, args[0]) {
-					// Update existing entry value
-					bucketEntries[i].Value = args[1]
-					// Also update in _entries if it exists
-					if hasEntries {
-						for j, e := range entries {
-							if equals(e.Key, args[0]) {
-								entries[j].Value = args[1]
-								break
-							}
-						}
-					}
-					return nil, nil
-				}
-			}
-			// Key not found in this bucket, add it
-			newEntry := &mapEntry{Key: args[0], Value: args[1]}
-			data[hash] = append(bucketEntries, newEntry)
-			if hasEntries {
-				instance.Fields["_entries"] = append(entries, newEntry)
-			}
-		} else {
-			// New bucket
-			newEntry := &mapEntry{Key: args[0], Value: args[1]}
-			data[hash] = []*mapEntry{newEntry}
-			if hasEntries {
-				instance.Fields["_entries"] = append(entries, newEntry)
-			}
-		}
+		setMapEntry((*Env)(callEnv), instance, args[0], args[1])
 		return nil, nil
 	}, []string{})
 
@@ -322,7 +381,7 @@ func InstallMapBuiltin(env *Env) error {
 		hash := hashValue(callEnv, args[0])
 		if entries, exists := data[hash]; exists {
 			for _, entry := range entries {
-				if equals(entry.Key, args[0]) {
+				if equals(callEnv, entry.Key, args[0]) {
 					return true, nil
 				}
 			}
@@ -356,22 +415,7 @@ func InstallMapBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
-
-		hash := hashValue(callEnv, args[0])
-		if entries, exists := data[hash]; exists {
-			for i, entry := range entries {
-				if equals(entry.Key, args[0]) {
-					// Remove the entry from the slice
-					data[hash] = append(entries[:i], entries[i+1:]...)
-					// If the slice is empty, remove the hash entry
-					if len(data[hash]) == 0 {
-						delete(data, hash)
-					}
-					break
-				}
-			}
-		}
+		removeMapEntry((*Env)(callEnv), instance, args[0])
 		return nil, nil
 	}, []string{})
 
@@ -381,22 +425,7 @@ func InstallMapBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
-
-		hash := hashValue(callEnv, args[0])
-		if entries, exists := data[hash]; exists {
-			for i, entry := range entries {
-				if equals(entry.Key, args[0]) {
-					// Remove the entry from the slice
-					data[hash] = append(entries[:i], entries[i+1:]...)
-					// If the slice is empty, remove the hash entry
-					if len(data[hash]) == 0 {
-						delete(data, hash)
-					}
-					break
-				}
-			}
-		}
+		removeMapEntry((*Env)(callEnv), instance, args[0])
 		return nil, nil
 	}, []string{})
 
@@ -405,53 +434,55 @@ func InstallMapBuiltin(env *Env) error {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
 		instance.Fields["_data"] = make(map[uint64][]*mapEntry)
+		instance.Fields["_entries"] = make([]*mapEntry, 0)
 		return nil, nil
 	}, []string{})
 
-	// keys() -> Array
+	// keys() -> Array - keys in insertion order
 	mapClass.AddBuiltinMethod("keys", &ast.Type{Name: "array", IsBuiltin: true}, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
 
-		keys := make([]any, 0, len(data))
-		for _, entries := range data {
-			for _, entry := range entries {
-				keys = append(keys, entry.Key)
-			}
+		entries := orderedMapEntries(instance, isDeterministic(callEnv))
+		keys := make([]any, len(entries))
+		for i, entry := range entries {
+			keys[i] = entry.Key
 		}
-		return keys, nil
+		return CreateArrayInstance((*Env)(callEnv), keys)
 	}, []string{})
 
-	// values() -> Array
+	// values() -> Array - values in the order of keys()
 	mapClass.AddBuiltinMethod("values", &ast.Type{Name: "array", IsBuiltin: true}, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
 
-		values := make([]any, 0, len(data))
-		for _, entries := range data {
-			for _, entry := range entries {
-				values = append(values, entry.Value)
-			}
+		entries := orderedMapEntries(instance, isDeterministic(callEnv))
+		values := make([]any, len(entries))
+		for i, entry := range entries {
+			values[i] = entry.Value
 		}
-		return values, nil
+		return CreateArrayInstance((*Env)(callEnv), values)
 	}, []string{})
 
-	// entries() -> Array
+	// entries() -> Array<Pair<K, V>> - key/value pairs in the order of keys()
 	mapClass.AddBuiltinMethod("entries", &ast.Type{Name: "array", IsBuiltin: true}, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
 
-		entries := make([]any, 0, len(data))
-		for _, entrySlice := range data {
-			for _, entry := range entrySlice {
-				entryArr := []any{entry.Key, entry.Value}
-				entries = append(entries, entryArr)
+		pairClass, exists := lookupClass("Pair", "")
+		if !exists {
+			return nil, ThrowInitializationError((*Env)(callEnv), "Pair class")
+		}
+		entries := orderedMapEntries(instance, isDeterministic(callEnv))
+		pairs := make([]any, len(entries))
+		for i, entry := range entries {
+			pair, err := constructPairInstance(pairClass, entry.Key, entry.Value, (*Env)(callEnv))
+			if err != nil {
+				return nil, err
 			}
+			pairs[i] = pair
 		}
-		return entries, nil
+		return CreateArrayInstance((*Env)(callEnv), pairs)
 	}, []string{})
 
 	// size() -> Int
@@ -509,15 +540,11 @@ func InstallMapBuiltin(env *Env) error {
 		}
 
 		result := "{"
-		first := true
-		for _, entries := range data {
-			for _, entry := range entries {
-				if !first {
-					result += ", "
-				}
-				first = false
-				result += utils.ToString(entry.Key) + ": " + utils.ToString(entry.Value)
+		for i, entry := range orderedMapEntries(instance, isDeterministic(callEnv)) {
+			if i > 0 {
+				result += ", "
 			}
+			result += utils.ToStringWithEnv(entry.Key, callEnv) + ": " + utils.ToStringWithEnv(entry.Value, callEnv)
 		}
 		result += "}"
 
@@ -582,7 +609,6 @@ func InstallMapBuiltin(env *Env) error {
 	mapClass.AddBuiltinMethod("getEntries", &ast.Type{Name: "List", IsBuiltin: true}, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		data := instance.Fields["_data"].(map[uint64][]*mapEntry)
 
 		// Get MapEntry class definition
 		mapEntryClassDef, ok := builtinClasses["MapEntry"]
@@ -590,27 +616,20 @@ func InstallMapBuiltin(env *Env) error {
 			return nil, ThrowRuntimeError((*Env)(callEnv), "MapEntry class not found")
 		}
 
-		size := 0
-		for _, entries := range data {
-			size += len(entries)
-		}
-
-		// Create a slice of MapEntry instances
-		entries := make([]any, 0, size)
-		for _, entrySlice := range data {
-			for _, entry := range entrySlice {
-				// Create MapEntry instance
-				mapEntryInstance := &ClassInstance{
-					ClassName: "MapEntry",
-					Fields: map[string]any{
-						"key":   entry.Key,
-						"value": entry.Value,
-					},
-					Methods:     make(map[string]common.Func),
-					ParentClass: mapEntryClassDef,
-				}
-				entries = append(entries, mapEntryInstance)
+		// Create a slice of MapEntry instances in the order of keys()
+		ordered := orderedMapEntries(instance, isDeterministic(callEnv))
+		entries := make([]any, 0, len(ordered))
+		for _, entry := range ordered {
+			mapEntryInstance := &ClassInstance{
+				ClassName: "MapEntry",
+				Fields: map[string]any{
+					"key":   entry.Key,
+					"value": entry.Value,
+				},
+				Methods:     make(map[string]common.Func),
+				ParentClass: mapEntryClassDef,
 			}
+			entries = append(entries, mapEntryInstance)
 		}
 
 		// Create List instance containing the entries
@@ -711,11 +730,8 @@ func MapToData(env *Env, value any) (map[string]any, bool) {
 // ConvertMapKey converts a key to the appropriate type for use in a Map
 func ConvertMapKey(env *Env, key any) any {
 	if key == nil {
-		// Create a String instance for "null"
-		if strInst, err := CreateInstanceFor(env, "String", "null"); err == nil {
-			return strInst
-		}
-		return "null"
+		// nil is a key of its own, distinct from the string "null"
+		return nil
 	}
 
 	// If already a ClassInstance, return as is
@@ -909,3 +925,64 @@ func constructPairInstance(pairClass *ClassDefinition, key, value any, env *Env)
 
 	return classInstance, nil
 }
+
+// putMapEntry sets key in a Map instance, keeping the first position of duplicated keys
+func putMapEntry(env *Env, mapInstance *ClassInstance, key string, value any) {
+	setMapEntry(env, mapInstance, ConvertMapKey(env, key), value)
+}
+
+// findMapEntry returns the entry of key in a Map instance, or nil
+func findMapEntry(env *Env, mapInstance *ClassInstance, key any) *mapEntry {
+	data := mapInstance.Fields["_data"].(map[uint64][]*mapEntry)
+	for _, entry := range data[hashValue(env, key)] {
+		if equals(env, entry.Key, key) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// setMapEntry sets key in a Map instance. New keys are appended to _entries so
+// iteration follows insertion order; existing keys keep their position.
+func setMapEntry(env *Env, mapInstance *ClassInstance, key any, value any) {
+	if entry := findMapEntry(env, mapInstance, key); entry != nil {
+		entry.Value = value
+		return
+	}
+	data := mapInstance.Fields["_data"].(map[uint64][]*mapEntry)
+	hash := hashValue(env, key)
+	entry := &mapEntry{Key: key, Value: value}
+	data[hash] = append(data[hash], entry)
+	entries, _ := mapInstance.Fields["_entries"].([]*mapEntry)
+	mapInstance.Fields["_entries"] = append(entries, entry)
+}
+
+// removeMapEntry deletes key from a Map instance, if present
+func removeMapEntry(env *Env, mapInstance *ClassInstance, key any) {
+	entry := findMapEntry(env, mapInstance, key)
+	if entry == nil {
+		return
+	}
+	data := mapInstance.Fields["_data"].(map[uint64][]*mapEntry)
+	hash := hashValue(env, key)
+	bucket := data[hash]
+	for i, e := range bucket {
+		if e == entry {
+			bucket = append(bucket[:i:i], bucket[i+1:]...)
+			break
+		}
+	}
+	if len(bucket) == 0 {
+		delete(data, hash)
+	} else {
+		data[hash] = bucket
+	}
+	if entries, ok := mapInstance.Fields["_entries"].([]*mapEntry); ok {
+		for i, e := range entries {
+			if e == entry {
+				mapInstance.Fields["_entries"] = append(entries[:i:i], entries[i+1:]...)
+				break
+			}
+		}
+	}
+}
@@ -3,7 +3,9 @@ package engine
 import (
 	"fmt"
 	"math"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/ArubikU/polyloft/internal/ast"
 	"github.com/ArubikU/polyloft/internal/common"
@@ -51,6 +53,10 @@ func installNumberInterface(env *Env) error {
 		HasDefault: false,
 	}}
 
+	// Static helpers: Number.parse and Number.format
+	numberInterface.StaticFields["parse"] = common.Func(numberParse)
+	numberInterface.StaticFields["format"] = common.Func(numberFormat)
+
 	// Register the Number interface
 	interfaceRegistry["Number"] = numberInterface
 	env.Set("Number", numberInterface)
@@ -59,6 +65,63 @@ func installNumberInterface(env *Env) error {
 	return nil
 }
 
+// decimalNumber matches the text Number.parse accepts: an optional sign, digits with
+// an optional '.' fraction and an optional exponent. There are no grouping separators.
+var decimalNumber = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+// numberParse implements Number.parse(text) -> Int or Float. Parsing never depends on
+// the machine's locale: '.' is the only decimal separator.
+func numberParse(e *common.Env, args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, ThrowArityError((*Env)(e), 1, len(args))
+	}
+	text, ok := extractPrimitiveValue(args[0]).(string)
+	if !ok {
+		return nil, ThrowTypeError((*Env)(e), "String", args[0])
+	}
+	trimmed := strings.TrimSpace(text)
+	if !decimalNumber.MatchString(trimmed) {
+		return nil, ThrowValueError((*Env)(e), fmt.Sprintf("invalid number '%s' (expected digits with '.' as the decimal separator)", text))
+	}
+	if !strings.ContainsAny(trimmed, ".eE") {
+		if i, err := strconv.Atoi(trimmed); err == nil {
+			return CreateIntInstance(e, i)
+		}
+	}
+	f, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil {
+		return nil, ThrowValueError((*Env)(e), fmt.Sprintf("number '%s' is out of range", text))
+	}
+	return CreateFloatInstance(e, f)
+}
+
+// numberFormat implements Number.format(value, decimals?) -> String, always with '.'
+// as the decimal separator. Without decimals the shortest exact form is used.
+func numberFormat(e *common.Env, args []any) (any, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, ThrowArityError((*Env)(e), 1, len(args))
+	}
+	value := extractPrimitiveValue(args[0])
+	f, ok := utils.AsFloat(value)
+	if _, isString := value.(string); !ok || isString {
+		return nil, ThrowTypeError((*Env)(e), "Number", args[0])
+	}
+	if len(args) == 1 {
+		if i, isInt := value.(int); isInt {
+			return CreateStringInstance(e, strconv.Itoa(i))
+		}
+		return CreateStringInstance(e, strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	decimals, ok := utils.AsInt(args[1])
+	if !ok {
+		return nil, ThrowTypeError((*Env)(e), "Int", args[1])
+	}
+	if decimals < 0 {
+		return nil, ThrowValueError((*Env)(e), fmt.Sprintf("decimals must not be negative, got %d", decimals))
+	}
+	return CreateStringInstance(e, strconv.FormatFloat(f, 'f', decimals, 64))
+}
+
 // installIntClass installs the Integer builtin type as a class
 func installIntClass(env *Env) error {
 	// Get Number interface
@@ -24,22 +24,10 @@ func InstallRangeBuiltin(env *Env) error {
 		AddField("_end", ast.ANY, []string{"private"}).
 		AddField("_step", ast.ANY, []string{"private"}).
 
-		// __length() -> Int - Get the length of the range
+		// __length() -> Int - Get the length of the range, computed without materializing it
 		AddBuiltinMethod("__length", ast.ANY, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 			thisVal, _ := callEnv.This()
-			instance := thisVal.(*ClassInstance)
-			start, _ := utils.AsInt(instance.Fields["_start"])
-			end, _ := utils.AsInt(instance.Fields["_end"])
-			step, _ := utils.AsInt(instance.Fields["_step"])
-			// Calculate length
-			length := end - start
-			if step != 0 {
-				length = length/step + 1
-			} else {
-				return 0, fmt.Errorf("step cannot be zero")
-			}
-
-			return CreateIntInstance(env, length)
+			return CreateIntInstance(env, rangeLength(thisVal.(*ClassInstance)))
 		}, []string{})
 		// __get(index: Int) -> Int - Get the value at the given index
 	rangeClass.AddBuiltinMethod("__get", ast.ANY, []ast.Parameter{
@@ -48,62 +36,32 @@ func InstallRangeBuiltin(env *Env) error {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
 
-		index, _ := utils.AsInt(args[0])
-		start, _ := utils.AsInt(instance.Fields["_start"])
-		end, _ := utils.AsInt(instance.Fields["_end"])
-		step, _ := utils.AsInt(instance.Fields["_step"])
-
-		// Calculate the value at the given index
-		var value int
-		if step > 0 {
-			value = start + index*step
-			if value > end {
-				return 0, fmt.Errorf("index out of bounds")
-			}
-		} else {
-			value = start + index*step
-			if value < end {
-				return 0, fmt.Errorf("index out of bounds")
-			}
+		index, ok := utils.AsInt(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "int", args[0])
+		}
+		length := rangeLength(instance)
+		if index < 0 || index >= length {
+			return nil, ThrowIndexError((*Env)(callEnv), index, length, "Range")
 		}
 
-		return CreateIntInstance(env, value)
+		// Elements are computed on demand, never stored
+		start, _, step := rangeBounds(instance)
+		return CreateIntInstance(env, start+index*step)
 	}, []string{})
 
 	// toArray() -> Array - Convert range to array
 	rangeClass.AddBuiltinMethod("toArray", &ast.Type{Name: "Array", IsBuiltin: true}, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		start, _ := utils.AsInt(instance.Fields["_start"])
-		end, _ := utils.AsInt(instance.Fields["_end"])
-		step, _ := utils.AsInt(instance.Fields["_step"])
-
-		// Calculate size
-		var size int
-		if step > 0 {
-			size = (end-start)/step + 1
-		} else {
-			size = (start-end)/(-step) + 1
-		}
-
-		if size < 0 {
-			size = 0
-		}
+		start, _, step := rangeBounds(instance)
 
 		// Build array
-		items := make([]any, 0, size)
-		for i := start; ; i += step {
-			if step > 0 && i > end {
-				break
-			}
-			if step < 0 && i < end {
-				break
-			}
-			e, _ := CreateIntInstance(env, i)
-			items = append(items, e)
-			if i == end {
-				break
-			}
+		size := rangeLength(instance)
+		items := make([]any, size)
+		for i := range items {
+			e, _ := CreateIntInstance(env, start+i*step)
+			items[i] = e
 		}
 
 		return CreateArrayInstance((*Env)(callEnv), items)
@@ -113,24 +71,7 @@ func InstallRangeBuiltin(env *Env) error {
 	rangeClass.AddBuiltinMethod("size", intType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		start, _ := utils.AsInt(instance.Fields["_start"])
-		end, _ := utils.AsInt(instance.Fields["_end"])
-		step, _ := utils.AsInt(instance.Fields["_step"])
-
-		var size int
-		if step > 0 {
-			if end >= start {
-				size = (end-start)/step + 1
-			} else {
-				size = 0
-			}
-		} else {
-			if start >= end {
-				size = (start-end)/(-step) + 1
-			} else {
-				size = 0
-			}
-		}
+		size := rangeLength(instance)
 
 		return CreateIntInstance((*Env)(callEnv), size)
 	}, []string{})
@@ -178,6 +119,9 @@ func CreateRangeInstance(env *Env, start, end int, step ...int) (*ClassInstance,
 	if len(step) == 0 && start > end {
 		stepValue = -1
 	}
+	if stepValue == 0 {
+		return nil, ThrowValueError(env, "Range step cannot be zero")
+	}
 
 	// Create instance
 	instance, err := createClassInstance(rangeClass, env, []any{})
@@ -193,3 +137,25 @@ func CreateRangeInstance(env *Env, start, end int, step ...int) (*ClassInstance,
 
 	return classInstance, nil
 }
+
+// rangeBounds returns the start, inclusive end and step of a Range instance
+func rangeBounds(instance *ClassInstance) (start, end, step int) {
+	start, _ = utils.AsInt(instance.Fields["_start"])
+	end, _ = utils.AsInt(instance.Fields["_end"])
+	step, _ = utils.AsInt(instance.Fields["_step"])
+	return start, end, step
+}
+
+// rangeLength returns how many values a Range yields, computed arithmetically
+// so that len() and indexing never materialize the range
+func rangeLength(instance *ClassInstance) int {
+	start, end, step := rangeBounds(instance)
+	switch {
+	case step > 0 && end >= start:
+		return (end-start)/step + 1
+	case step < 0 && start >= end:
+		return (start-end)/(-step) + 1
+	default:
+		return 0
+	}
+}
@@ -30,7 +30,7 @@ func InstallSetBuiltin(env *Env) error {
 		AddTypeParameters(common.TBound.AsGenericType().AsArray()).
 		AddInterface(iterableInterface).
 		AddInterface(collectionInterface).
-		AddField("_items", mapType, []string{"private"}).                                  // Using map for O(1) lookups
+		AddField("_items", mapType, []string{"private"}).                                  // Hash buckets for O(1) lookups
 		AddField("_keys", &ast.Type{Name: "array", IsBuiltin: true}, []string{"private"}). // Track insertion order
 		AddField("_currentIndex", intType, []string{"private"})
 
@@ -38,7 +38,7 @@ func InstallSetBuiltin(env *Env) error {
 	setClass.AddBuiltinConstructor([]ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		items := make(map[string]bool)
+		items := make(map[uint64][]any)
 		keys := make([]any, 0)
 		instance.Fields["_items"] = &items
 		instance.Fields["_keys"] = &keys
@@ -52,13 +52,11 @@ func InstallSetBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		items := make(map[string]bool)
+		items := make(map[uint64][]any)
 		keys := make([]any, 0)
 
 		for _, item := range args {
-			key := fmt.Sprintf("%v", item)
-			if !items[key] {
-				items[key] = true
+			if setAdd((*Env)(callEnv), items, item) {
 				keys = append(keys, item)
 			}
 		}
@@ -73,16 +71,16 @@ func InstallSetBuiltin(env *Env) error {
 	setClass.AddBuiltinMethod("size", intType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		itemsPtr := instance.Fields["_items"].(*map[string]bool)
-		return CreateIntInstance(callEnv, len(*itemsPtr))
+		keysPtr := instance.Fields["_keys"].(*[]any)
+		return CreateIntInstance(callEnv, len(*keysPtr))
 	}, []string{})
 
 	// isEmpty() -> Bool
 	setClass.AddBuiltinMethod("isEmpty", boolType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		itemsPtr := instance.Fields["_items"].(*map[string]bool)
-		return CreateBoolInstance(callEnv, len(*itemsPtr) == 0)
+		keysPtr := instance.Fields["_keys"].(*[]any)
+		return CreateBoolInstance(callEnv, len(*keysPtr) == 0)
 	}, []string{})
 
 	// add(item: T) -> Bool - returns true if item was added (wasn't already present)
@@ -91,15 +89,12 @@ func InstallSetBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		itemsPtr := instance.Fields["_items"].(*map[string]bool)
+		itemsPtr := instance.Fields["_items"].(*map[uint64][]any)
 		keysPtr := instance.Fields["_keys"].(*[]any)
 
-		key := fmt.Sprintf("%v", args[0])
-		if (*itemsPtr)[key] {
+		if !setAdd((*Env)(callEnv), *itemsPtr, args[0]) {
 			return CreateBoolInstance(callEnv, false)
 		}
-
-		(*itemsPtr)[key] = true
 		*keysPtr = append(*keysPtr, args[0])
 		return CreateBoolInstance(callEnv, true)
 	}, []string{})
@@ -110,10 +105,8 @@ func InstallSetBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		itemsPtr := instance.Fields["_items"].(*map[string]bool)
-
-		key := fmt.Sprintf("%v", args[0])
-		return CreateBoolInstance(callEnv, (*itemsPtr)[key])
+		itemsPtr := instance.Fields["_items"].(*map[uint64][]any)
+		return CreateBoolInstance(callEnv, setContains((*Env)(callEnv), *itemsPtr, args[0]))
 	}, []string{})
 
 	// remove(item: T) -> Bool - returns true if item was removed
@@ -122,19 +115,16 @@ func InstallSetBuiltin(env *Env) error {
 	}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		itemsPtr := instance.Fields["_items"].(*map[string]bool)
+		itemsPtr := instance.Fields["_items"].(*map[uint64][]any)
 		keysPtr := instance.Fields["_keys"].(*[]any)
 
-		key := fmt.Sprintf("%v", args[0])
-		if !(*itemsPtr)[key] {
+		if !setRemove((*Env)(callEnv), *itemsPtr, args[0]) {
 			return CreateBoolInstance(callEnv, false)
 		}
 
-		delete(*itemsPtr, key)
-
 		// Remove from keys array
 		for i, k := range *keysPtr {
-			if fmt.Sprintf("%v", k) == key {
+			if equals((*Env)(callEnv), k, args[0]) {
 				*keysPtr = append((*keysPtr)[:i], (*keysPtr)[i+1:]...)
 				break
 			}
@@ -143,11 +133,83 @@ func InstallSetBuiltin(env *Env) error {
 		return CreateBoolInstance(callEnv, true)
 	}, []string{})
 
+	// union(other: Set) -> Set - elements of either set, this set's first
+	setClass.AddBuiltinMethod("union", &ast.Type{Name: "Set", IsBuiltin: true}, []ast.Parameter{
+		{Name: "other", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		other, err := setOperand((*Env)(callEnv), args[0])
+		if err != nil {
+			return nil, err
+		}
+		keys := append(append([]any{}, *instance.Fields["_keys"].(*[]any)...), *other.Fields["_keys"].(*[]any)...)
+		return newSetFrom((*Env)(callEnv), instance, keys, false)
+	}, []string{})
+
+	// intersection(other: Set) -> Set - elements of this set that are also in other
+	setClass.AddBuiltinMethod("intersection", &ast.Type{Name: "Set", IsBuiltin: true}, []ast.Parameter{
+		{Name: "other", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		other, err := setOperand((*Env)(callEnv), args[0])
+		if err != nil {
+			return nil, err
+		}
+		keys := setFilter((*Env)(callEnv), *instance.Fields["_keys"].(*[]any), *other.Fields["_items"].(*map[uint64][]any), true)
+		return newSetFrom((*Env)(callEnv), instance, keys, true)
+	}, []string{})
+
+	// difference(other: Set) -> Set - elements of this set that are not in other
+	setClass.AddBuiltinMethod("difference", &ast.Type{Name: "Set", IsBuiltin: true}, []ast.Parameter{
+		{Name: "other", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		other, err := setOperand((*Env)(callEnv), args[0])
+		if err != nil {
+			return nil, err
+		}
+		keys := setFilter((*Env)(callEnv), *instance.Fields["_keys"].(*[]any), *other.Fields["_items"].(*map[uint64][]any), false)
+		return newSetFrom((*Env)(callEnv), instance, keys, true)
+	}, []string{})
+
+	// symmetricDifference(other: Set) -> Set - elements in exactly one of the sets
+	setClass.AddBuiltinMethod("symmetricDifference", &ast.Type{Name: "Set", IsBuiltin: true}, []ast.Parameter{
+		{Name: "other", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		other, err := setOperand((*Env)(callEnv), args[0])
+		if err != nil {
+			return nil, err
+		}
+		keys := setFilter((*Env)(callEnv), *instance.Fields["_keys"].(*[]any), *other.Fields["_items"].(*map[uint64][]any), false)
+		keys = append(keys, setFilter((*Env)(callEnv), *other.Fields["_keys"].(*[]any), *instance.Fields["_items"].(*map[uint64][]any), false)...)
+		return newSetFrom((*Env)(callEnv), instance, keys, false)
+	}, []string{})
+
+	// isSubsetOf(other: Set) -> Bool - true if every element of this set is in other
+	setClass.AddBuiltinMethod("isSubsetOf", boolType, []ast.Parameter{
+		{Name: "other", Type: nil},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		other, err := setOperand((*Env)(callEnv), args[0])
+		if err != nil {
+			return nil, err
+		}
+		keys := *instance.Fields["_keys"].(*[]any)
+		missing := setFilter((*Env)(callEnv), keys, *other.Fields["_items"].(*map[uint64][]any), false)
+		return CreateBoolInstance(callEnv, len(missing) == 0)
+	}, []string{})
+
 	// clear() -> Void
 	setClass.AddBuiltinMethod("clear", voidType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		items := make(map[string]bool)
+		items := make(map[uint64][]any)
 		keys := make([]any, 0)
 		instance.Fields["_items"] = &items
 		instance.Fields["_keys"] = &keys
@@ -170,16 +232,44 @@ func InstallSetBuiltin(env *Env) error {
 		keysPtr := instance.Fields["_keys"].(*[]any)
 		return createArrayFromKeys(keysPtr, callEnv)
 	}, []string{})
+	// __length() -> Int (Iterable interface)
+	setClass.AddBuiltinMethod("__length", intType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		keysPtr := instance.Fields["_keys"].(*[]any)
+		return len(*keysPtr), nil
+	}, []string{})
+
+	// __get(index: Int) -> T (Iterable interface) - items are visited in insertion order
+	setClass.AddBuiltinMethod("__get", ast.ANY, []ast.Parameter{
+		{Name: "index", Type: intType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		keysPtr := instance.Fields["_keys"].(*[]any)
+		idx, ok := utils.AsInt(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "int", args[0])
+		}
+		if idx < 0 || idx >= len(*keysPtr) {
+			return nil, ThrowIndexError((*Env)(callEnv), idx, len(*keysPtr), "Set")
+		}
+		return (*keysPtr)[idx], nil
+	}, []string{})
+
 	// toString() -> String
 	stringType := &ast.Type{Name: "string", IsBuiltin: true}
 	setClass.AddBuiltinMethod("toString", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
-		keysPtr := instance.Fields["_keys"].(*[]any)
+		keys := *instance.Fields["_keys"].(*[]any)
+		if isDeterministic(callEnv) {
+			keys = canonicalItems(keys)
+		}
 
-		strs := make([]string, len(*keysPtr))
-		for i, item := range *keysPtr {
-			strs[i] = utils.ToString(item)
+		strs := make([]string, len(keys))
+		for i, item := range keys {
+			strs[i] = utils.ToStringWithEnv(item, callEnv)
 		}
 		return CreateStringInstance(callEnv, fmt.Sprintf("Set(%s)", strings.Join(strs, ", ")))
 	}, []string{})
@@ -188,3 +278,90 @@ func InstallSetBuiltin(env *Env) error {
 	_, err := setClass.Build(env)
 	return err
 }
+
+// setContains reports whether item is in the hash buckets of a Set. Elements
+// follow the same equality as Map keys, so 1 and 1.0 are one element.
+func setContains(env *Env, items map[uint64][]any, item any) bool {
+	for _, existing := range items[hashValue(env, item)] {
+		if equals(env, existing, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// setAdd inserts item into the buckets and reports whether it was not present
+func setAdd(env *Env, items map[uint64][]any, item any) bool {
+	if setContains(env, items, item) {
+		return false
+	}
+	hash := hashValue(env, item)
+	items[hash] = append(items[hash], item)
+	return true
+}
+
+// setRemove deletes item from the buckets and reports whether it was present
+func setRemove(env *Env, items map[uint64][]any, item any) bool {
+	hash := hashValue(env, item)
+	bucket := items[hash]
+	for i, existing := range bucket {
+		if equals(env, existing, item) {
+			if len(bucket) == 1 {
+				delete(items, hash)
+			} else {
+				items[hash] = append(bucket[:i], bucket[i+1:]...)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// setOperand returns the Set passed to a set operation
+func setOperand(env *Env, v any) (*ClassInstance, error) {
+	if inst, ok := v.(*ClassInstance); ok {
+		_, hasItems := inst.Fields["_items"].(*map[uint64][]any)
+		_, hasKeys := inst.Fields["_keys"].(*[]any)
+		if hasItems && hasKeys {
+			return inst, nil
+		}
+	}
+	return nil, ThrowTypeError(env, "Set", v)
+}
+
+// setFilter returns the elements of keys that are (keep true) or are not (keep
+// false) in the buckets of another set, in the order of keys
+func setFilter(env *Env, keys []any, items map[uint64][]any, keep bool) []any {
+	result := make([]any, 0, len(keys))
+	for _, key := range keys {
+		if setContains(env, items, key) == keep {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// newSetFrom creates a Set of the same class as base holding keys, dropping
+// duplicates. The result keeps the type arguments of base only when typed is
+// set, as for operations whose elements all come from base.
+func newSetFrom(env *Env, base *ClassInstance, keys []any, typed bool) (*ClassInstance, error) {
+	value, err := createClassInstance(base.ParentClass, env, []any{})
+	if err != nil {
+		return nil, err
+	}
+	instance := value.(*ClassInstance)
+	items := make(map[uint64][]any)
+	unique := make([]any, 0, len(keys))
+	for _, key := range keys {
+		if setAdd(env, items, key) {
+			unique = append(unique, key)
+		}
+	}
+	instance.Fields["_items"] = &items
+	instance.Fields["_keys"] = &unique
+	instance.Fields["_currentIndex"] = 0
+	if typed {
+		instance.GenericTypes = base.GenericTypes
+	}
+	return instance, nil
+}
@@ -1,8 +1,10 @@
 package engine
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/ArubikU/polyloft/internal/ast"
@@ -144,7 +146,7 @@ func InstallStringBuiltin(env *Env) error {
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
 		str := instance.Fields["_value"].(string)
-		return len(str) == 0, nil
+		return CreateBoolInstance((*Env)(callEnv), len(str) == 0)
 	}, []string{})
 
 	// length() -> Int (public method)
@@ -185,8 +187,31 @@ func InstallStringBuiltin(env *Env) error {
 		str := instance.Fields["_value"].(string)
 
 		substr := StringValue(args[0])
-		index := strings.Index(str, substr)
-		return index, nil
+		return CreateIntInstance((*Env)(callEnv), runeIndex(str, strings.Index(str, substr)))
+	}, []string{})
+
+	// lastIndexOf(substr: String) -> Int
+	stringClass.AddBuiltinMethod("lastIndexOf", intType, []ast.Parameter{
+		{Name: "substr", Type: stringType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		str := instance.Fields["_value"].(string)
+
+		substr := StringValue(args[0])
+		return CreateIntInstance((*Env)(callEnv), runeIndex(str, strings.LastIndex(str, substr)))
+	}, []string{})
+
+	// count(substr: String) -> Int
+	stringClass.AddBuiltinMethod("count", intType, []ast.Parameter{
+		{Name: "substr", Type: stringType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		str := instance.Fields["_value"].(string)
+
+		// Non-overlapping occurrences; an empty substring matches around every character
+		return CreateIntInstance((*Env)(callEnv), strings.Count(str, StringValue(args[0])))
 	}, []string{})
 
 	// substring(start: Int, end: Int) -> String
@@ -257,6 +282,34 @@ func InstallStringBuiltin(env *Env) error {
 		return CreateStringInstance((*Env)(callEnv), strings.TrimSpace(str))
 	}, []string{})
 
+	// trimStart() -> String
+	stringClass.AddBuiltinMethod("trimStart", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		str := instance.Fields["_value"].(string)
+		return CreateStringInstance((*Env)(callEnv), strings.TrimLeftFunc(str, unicode.IsSpace))
+	}, []string{})
+
+	// trimEnd() -> String
+	stringClass.AddBuiltinMethod("trimEnd", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		str := instance.Fields["_value"].(string)
+		return CreateStringInstance((*Env)(callEnv), strings.TrimRightFunc(str, unicode.IsSpace))
+	}, []string{})
+
+	// capitalize() -> String - first character upper case, the rest unchanged
+	stringClass.AddBuiltinMethod("capitalize", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		str := instance.Fields["_value"].(string)
+		first, size := utf8.DecodeRuneInString(str)
+		if size == 0 {
+			return CreateStringInstance((*Env)(callEnv), str)
+		}
+		return CreateStringInstance((*Env)(callEnv), string(unicode.ToTitle(first))+str[size:])
+	}, []string{})
+
 	// startsWith(prefix: String) -> Bool
 	stringClass.AddBuiltinMethod("startsWith", boolType, []ast.Parameter{
 		{Name: "prefix", Type: stringType},
@@ -265,7 +318,7 @@ func InstallStringBuiltin(env *Env) error {
 		instance := thisVal.(*ClassInstance)
 		str := instance.Fields["_value"].(string)
 		prefix := StringValue(args[0])
-		return strings.HasPrefix(str, prefix), nil
+		return CreateBoolInstance((*Env)(callEnv), strings.HasPrefix(str, prefix))
 	}, []string{})
 
 	// endsWith(suffix: String) -> Bool
@@ -276,7 +329,7 @@ func InstallStringBuiltin(env *Env) error {
 		instance := thisVal.(*ClassInstance)
 		str := instance.Fields["_value"].(string)
 		suffix := StringValue(args[0])
-		return strings.HasSuffix(str, suffix), nil
+		return CreateBoolInstance((*Env)(callEnv), strings.HasSuffix(str, suffix))
 	}, []string{})
 
 	// contains(substr: String) -> Bool
@@ -287,7 +340,7 @@ func InstallStringBuiltin(env *Env) error {
 		instance := thisVal.(*ClassInstance)
 		str := instance.Fields["_value"].(string)
 		substr := StringValue(args[0])
-		return strings.Contains(str, substr), nil
+		return CreateBoolInstance((*Env)(callEnv), strings.Contains(str, substr))
 	}, []string{})
 
 	// replace(old: String, new: String) -> String
@@ -323,6 +376,42 @@ func InstallStringBuiltin(env *Env) error {
 		return CreateArrayInstance((*Env)(callEnv), result)
 	}, []string{})
 
+	// chars() -> Array - one String per character
+	stringClass.AddBuiltinMethod("chars", &ast.Type{Name: "array", IsBuiltin: true}, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		str := instance.Fields["_value"].(string)
+		result := make([]any, 0, utf8.RuneCountInString(str))
+		for _, r := range str {
+			strInstance, err := CreateStringInstance((*Env)(callEnv), string(r))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, strInstance)
+		}
+		return CreateArrayInstance((*Env)(callEnv), result)
+	}, []string{})
+
+	// lines() -> Array - split on "\n" or "\r\n"; a trailing line break does not add an empty line
+	stringClass.AddBuiltinMethod("lines", &ast.Type{Name: "array", IsBuiltin: true}, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		str := instance.Fields["_value"].(string)
+		if str == "" {
+			return CreateArrayInstance((*Env)(callEnv), []any{})
+		}
+		parts := strings.Split(strings.TrimSuffix(str, "\n"), "\n")
+		result := make([]any, len(parts))
+		for i, p := range parts {
+			strInstance, err := CreateStringInstance((*Env)(callEnv), strings.TrimSuffix(p, "\r"))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = strInstance
+		}
+		return CreateArrayInstance((*Env)(callEnv), result)
+	}, []string{})
+
 	// utils.ToString() -> String
 	stringClass.AddBuiltinMethod("toString", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
 		thisVal, _ := callEnv.This()
@@ -359,11 +448,11 @@ func InstallStringBuiltin(env *Env) error {
 		}
 		pad := StringValue(args[1])
 
-		if len(str) >= length {
-			return CreateStringInstance((*Env)(callEnv), str)
+		padding, err := stringPadding((*Env)(callEnv), str, length, pad)
+		if err != nil {
+			return nil, err
 		}
-		padding := strings.Repeat(pad, (length-len(str)+len(pad)-1)/len(pad))
-		return CreateStringInstance((*Env)(callEnv), padding[:length-len(str)]+str)
+		return CreateStringInstance((*Env)(callEnv), padding+str)
 	}, []string{})
 
 	// padEnd(length: Int, pad: String) -> String
@@ -380,11 +469,11 @@ func InstallStringBuiltin(env *Env) error {
 		}
 		pad := StringValue(args[1])
 
-		if len(str) >= length {
-			return CreateStringInstance((*Env)(callEnv), str)
+		padding, err := stringPadding((*Env)(callEnv), str, length, pad)
+		if err != nil {
+			return nil, err
 		}
-		padding := strings.Repeat(pad, (length-len(str)+len(pad)-1)/len(pad))
-		return CreateStringInstance((*Env)(callEnv), str+padding[:length-len(str)])
+		return CreateStringInstance((*Env)(callEnv), str+padding)
 	}, []string{})
 
 	// serialize() -> String
@@ -395,11 +484,136 @@ func InstallStringBuiltin(env *Env) error {
 		return CreateStringInstance((*Env)(callEnv), strconv.Quote(str))
 	}, []string{})
 
+	// format(args...) -> String
+	stringClass.AddBuiltinMethod("format", stringType, []ast.Parameter{
+		{Name: "args", Type: ast.ANY, IsVariadic: true},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		str, err := formatString((*Env)(callEnv), instance.Fields["_value"].(string), args)
+		if err != nil {
+			return nil, err
+		}
+		return CreateStringInstance((*Env)(callEnv), str)
+	}, []string{})
+
+	// String.format(template, args...) -> String
+	stringClass.AddStaticMethod("format", stringType, []ast.Parameter{
+		{Name: "template", Type: stringType},
+		{Name: "args", Type: ast.ANY, IsVariadic: true},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		str, err := formatString((*Env)(callEnv), StringValue(args[0]), args[1:])
+		if err != nil {
+			return nil, err
+		}
+		return CreateStringInstance((*Env)(callEnv), str)
+	})
+
 	// Build the class
 	_, err := stringClass.Build(env)
 	return err
 }
 
+// formatString fills the printf-style directives of template with args. It
+// supports the flags -, +, space, 0 and #, a width and a precision, and the
+// verbs %d, %f, %e, %g, %s, %x, %X, %o, %b, %c and %%. A count of args that
+// does not match the directives throws an ArityError.
+func formatString(env *Env, template string, args []any) (string, error) {
+	var b strings.Builder
+	used := 0
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			b.WriteByte(template[i])
+			continue
+		}
+		// Scan the directive: flags, width, precision and verb
+		j := i + 1
+		for j < len(template) && strings.IndexByte("-+ 0#", template[j]) >= 0 {
+			j++
+		}
+		for j < len(template) && template[j] >= '0' && template[j] <= '9' {
+			j++
+		}
+		if j < len(template) && template[j] == '.' {
+			j++
+			for j < len(template) && template[j] >= '0' && template[j] <= '9' {
+				j++
+			}
+		}
+		if j >= len(template) {
+			return "", ThrowValueError(env, "format string ends with an incomplete directive")
+		}
+		directive, verb := template[i:j+1], template[j]
+		i = j
+		if verb == '%' {
+			b.WriteByte('%')
+			continue
+		}
+		if strings.IndexByte("dfeEgGsxXobc", verb) < 0 {
+			return "", ThrowValueError(env, fmt.Sprintf("unknown format directive %q", directive))
+		}
+		if used >= len(args) {
+			return "", ThrowArityError(env, countDirectives(template), len(args))
+		}
+		value, err := formatArg(env, verb, args[used])
+		if err != nil {
+			return "", err
+		}
+		used++
+		fmt.Fprintf(&b, directive, value)
+	}
+	if used < len(args) {
+		return "", ThrowArityError(env, used, len(args))
+	}
+	return b.String(), nil
+}
+
+// formatArg converts a Polyloft value to the Go value verb expects
+func formatArg(env *Env, verb byte, arg any) (any, error) {
+	value := extractPrimitiveValue(arg)
+	switch verb {
+	case 's':
+		return StringValue(arg), nil
+	case 'f', 'e', 'E', 'g', 'G':
+		switch value.(type) {
+		case int, int64, float64:
+			f, _ := utils.AsFloat(value)
+			return f, nil
+		}
+		return nil, ThrowTypeError(env, "Float", arg)
+	case 'x', 'X':
+		// Hex also applies to strings, byte by byte
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	}
+	switch v := value.(type) {
+	case int, int64:
+		return v, nil
+	case float64:
+		if utils.CanBeInt(v) {
+			return int(v), nil
+		}
+	}
+	return nil, ThrowTypeError(env, "Int", arg)
+}
+
+// countDirectives returns how many arguments template consumes
+func countDirectives(template string) int {
+	count := 0
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			continue
+		}
+		if i+1 < len(template) && template[i+1] == '%' {
+			i++
+			continue
+		}
+		count++
+	}
+	return count
+}
+
 // CreateStringInstance creates a String instance from a Go string
 // This is used when evaluating string literals
 func CreateStringInstance(env *Env, value string) (*ClassInstance, error) {
@@ -432,3 +646,30 @@ func StringValue(v any) string {
 		return utils.ToString(v)
 	}
 }
+
+// stringPadding returns the text padStart/padEnd add to str to make it length
+// characters long, repeating pad and cutting the last repetition short
+func stringPadding(env *Env, str string, length int, pad string) (string, error) {
+	missing := length - utf8.RuneCountInString(str)
+	if missing <= 0 {
+		return "", nil
+	}
+	if pad == "" {
+		return "", ThrowValueError(env, "pad string must not be empty")
+	}
+	padRunes := []rune(pad)
+	padding := make([]rune, missing)
+	for i := range padding {
+		padding[i] = padRunes[i%len(padRunes)]
+	}
+	return string(padding), nil
+}
+
+// runeIndex converts a byte offset into str to a character index, so string
+// positions agree with charAt and substring. Negative offsets (not found) are kept.
+func runeIndex(str string, byteIndex int) int {
+	if byteIndex < 0 {
+		return byteIndex
+	}
+	return utf8.RuneCountInString(str[:byteIndex])
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ArubikU/polyloft/internal/ast"
 	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
 )
 
 // InstallChannelBuiltin creates the builtin Channel class
@@ -50,6 +51,15 @@ func InstallChannelBuiltin(env *Env) error {
 			return nil, nil
 		}, []string{})
 
+	// cap() -> Int
+	channelClass.AddBuiltinMethod("cap", &ast.Type{Name: "int", IsBuiltin: true}, []ast.Parameter{},
+		func(callEnv *common.Env, args []any) (any, error) {
+			thisVal, _ := callEnv.This()
+			instance := thisVal.(*ClassInstance)
+			ch := instance.Fields["_channel"].(*common.Channel)
+			return CreateIntInstance(callEnv, ch.Cap())
+		}, []string{})
+
 	// Build the class
 	_, err := channelClass.Build(env)
 	if err != nil {
@@ -67,8 +77,23 @@ func evalChannelExpr(env *Env, expr *ast.ChannelExpr) (any, error) {
 		return nil, ThrowInitializationError(env, "Channel class")
 	}
 
-	// Create a new channel with buffer size 0 (unbuffered by default)
-	ch := common.NewChannel(0)
+	// Unbuffered by default; channel[Type](n) buffers up to n values
+	capacity := 0
+	if expr.Capacity != nil {
+		capVal, err := evalExpr(env, expr.Capacity)
+		if err != nil {
+			return nil, err
+		}
+		n, ok := utils.AsInt(capVal)
+		if !ok {
+			return nil, ThrowTypeError(env, "int", capVal)
+		}
+		if n < 0 {
+			return nil, ThrowValueError(env, fmt.Sprintf("channel capacity must be non-negative, got %d", n))
+		}
+		capacity = n
+	}
+	ch := common.NewChannel(capacity)
 
 	// Create instance using the constructor
 	instance, err := createClassInstance(ctor.Definition, env, []any{})
@@ -43,6 +43,7 @@ var (
 // TypeAlias represents a type alias definition
 type TypeAlias struct {
 	Name        string
+	TypeParams  []string // parameters substituted into BaseType when the alias is used with type arguments
 	BaseType    string
 	IsFinal     bool // if true, this is a nominal type (distinct from base type)
 	PackageName string
@@ -275,13 +276,15 @@ func evalClassDecl(env *Env, s *ast.ClassDecl) (any, error) {
 	// Process methods
 	for _, method := range s.Methods {
 		methodInfo := MethodInfo{
-			Name:       method.Name,
-			ReturnType: method.ReturnType,
-			Body:       method.Body,
-			Modifiers:  method.Modifiers,
-			IsAbstract: method.IsAbstract,
-			IsStatic:   contains(method.Modifiers, "static"),
-			IsPrivate:  contains(method.Modifiers, "private"),
+			Name:        method.Name,
+			TypeParams:  method.TypeParams,
+			ReturnType:  method.ReturnType,
+			Body:        method.Body,
+			Modifiers:   method.Modifiers,
+			IsAbstract:  method.IsAbstract,
+			IsStatic:    contains(method.Modifiers, "static"),
+			IsPrivate:   contains(method.Modifiers, "private"),
+			IsGenerator: containsYield(method.Body),
 		}
 
 		// Convert parameters
@@ -419,6 +422,17 @@ func createClassInstance(classDef *ClassDefinition, env *Env, args []any) (any,
 		return nil, err
 	}
 
+	// An exception class without a constructor inherits the nearest ancestor's,
+	// when one accepts the given arguments (e.g. class MyError < Exception: end).
+	// Other classes keep running no constructor at all.
+	if len(classDef.Constructors) == 0 && isThrowableClass(classDef) {
+		if ancestor := constructorAncestor(classDef.Parent); ancestor != nil && common.SelectConstructorOverload(ancestor.Constructors, len(args)) != nil {
+			if _, err := callParentConstructor(instance, ancestor, env, args); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Call constructor if exists (with overload resolution)
 	if len(classDef.Constructors) > 0 {
 		// Select appropriate constructor based on argument count
@@ -467,7 +481,7 @@ func createClassInstance(classDef *ClassDefinition, env *Env, args []any) (any,
 			for _, stmt := range constructor.Body {
 				_, ret, err := evalStmt(constructorEnv, stmt)
 				if err != nil {
-					return nil, err
+					return nil, addStackFrame(err, classDef.Name)
 				}
 				if ret {
 					break
@@ -482,7 +496,10 @@ func createClassInstance(classDef *ClassDefinition, env *Env, args []any) (any,
 // callParentConstructor calls the constructor of the parent class
 func callParentConstructor(instance *ClassInstance, parentClass *ClassDefinition, env *Env, args []any) (any, error) {
 	if len(parentClass.Constructors) == 0 {
-		// If parent has no constructor, just return nil
+		// An exception parent without a constructor passes the call on to its own ancestors
+		if ancestor := constructorAncestor(parentClass.Parent); ancestor != nil && isThrowableClass(parentClass) && (len(args) > 0 || common.SelectConstructorOverload(ancestor.Constructors, 0) != nil) {
+			return callParentConstructor(instance, ancestor, env, args)
+		}
 		return nil, nil
 	}
 
@@ -522,7 +539,7 @@ func callParentConstructor(instance *ClassInstance, parentClass *ClassDefinition
 		for _, stmt := range constructor.Body {
 			_, ret, err := evalStmt(parentEnv, stmt)
 			if err != nil {
-				return nil, err
+				return nil, addStackFrame(err, parentClass.Name)
 			}
 			if ret {
 				break
@@ -533,6 +550,17 @@ func callParentConstructor(instance *ClassInstance, parentClass *ClassDefinition
 	return nil, nil
 }
 
+// constructorAncestor returns the first class from classDef up the hierarchy that
+// declares a constructor
+func constructorAncestor(classDef *ClassDefinition) *ClassDefinition {
+	for ; classDef != nil; classDef = classDef.Parent {
+		if len(classDef.Constructors) > 0 {
+			return classDef
+		}
+	}
+	return nil
+}
+
 // callParentMethod calls a specific method from the parent class
 func callParentMethod(instance *ClassInstance, parentClass *ClassDefinition, methodInfo MethodInfo, env *Env, args []any) (any, error) {
 	// Create parent method environment
@@ -550,6 +578,9 @@ func callParentMethod(instance *ClassInstance, parentClass *ClassDefinition, met
 	}
 
 	// Execute parent method body
+	if methodInfo.IsGenerator {
+		return newGenerator(parentMethodEnv, parentClass.Name+"."+methodInfo.Name, parentMethodEnv, methodInfo.Body)
+	}
 	var lastValue any
 	for _, stmt := range methodInfo.Body {
 		val, ret, err := evalStmt(parentMethodEnv, stmt)
@@ -599,7 +630,7 @@ func addParentMethods(superObj map[string]any, instance *ClassInstance, classDef
 			// Select appropriate method based on argument count
 			method := common.SelectMethodOverload(overloads, len(args))
 			if method == nil {
-				return nil, ThrowRuntimeError((*Env)(callEnv), fmt.Sprintf("no overload found for super.%s with %d arguments", methodName, len(args)))
+				return nil, ThrowOverloadError((*Env)(callEnv), "super", methodName, overloads, len(args))
 			}
 			if !method.IsStatic {
 				return callParentMethod(instance, classDef, *method, callEnv, args)
@@ -667,12 +698,12 @@ func bindMethods(instance *ClassInstance, classDef *ClassDefinition, env *Env) e
 
 	// Bind this class's methods (with overload resolution)
 	for name, methodOverloads := range classDef.Methods {
-		overloads := methodOverloads // Copy for closure
+		overloads := common.OverloadsOfKind(methodOverloads, false)
 		method := Func(func(callEnv *Env, args []any) (any, error) {
 			// Select appropriate method based on argument count
 			selectedMethod := common.SelectMethodOverload(overloads, len(args))
 			if selectedMethod == nil {
-				return nil, ThrowRuntimeError((*Env)(callEnv), fmt.Sprintf("no overload found for %s.%s with %d arguments", instance.ClassName, name, len(args)))
+				return nil, ThrowOverloadError((*Env)(callEnv), instance.ClassName, name, overloads, len(args))
 			}
 			if selectedMethod.IsStatic || selectedMethod.IsAbstract {
 				return nil, ThrowRuntimeError((*Env)(callEnv), fmt.Sprintf("cannot call static or abstract method %s via instance", name))
@@ -771,6 +802,11 @@ func CallInstanceMethod(instance *ClassInstance, methodInfo MethodInfo, env *Env
 		methodEnv.Set("super", superObj)
 	}
 
+	// Bind explicit method type arguments (obj.method<Int>(x)) before the parameters that use them
+	if err := bindMethodTypeArgs(env, methodEnv, methodInfo, instance.ClassName); err != nil {
+		return nil, err
+	}
+
 	// Bind method parameters and validate centrally
 	if err := bindParametersWithVariadic(methodEnv, methodInfo.Params, args); err != nil {
 		return nil, err
@@ -787,12 +823,21 @@ func CallInstanceMethod(instance *ClassInstance, methodInfo MethodInfo, env *Env
 			return nil, err
 		}
 	} else {
+		if methodInfo.IsGenerator {
+			return newGenerator(methodEnv, instance.ClassName+"."+methodInfo.Name, methodEnv, methodInfo.Body)
+		}
+		if limits := limitsOf(methodEnv); limits != nil {
+			if err := limits.enter(); err != nil {
+				return nil, err
+			}
+			defer limits.leave()
+		}
 		// Execute Polyloft method body
 		var lastValue any
 		for _, stmt := range methodInfo.Body {
 			val, ret, errStmt := evalStmt(methodEnv, stmt)
 			if errStmt != nil {
-				return nil, errStmt
+				return nil, addStackFrame(errStmt, instance.ClassName+"."+methodInfo.Name)
 			}
 			if ret {
 				result = val
@@ -806,6 +851,11 @@ func CallInstanceMethod(instance *ClassInstance, methodInfo MethodInfo, env *Env
 	}
 
 	returnTypeName := ast.GetTypeNameString(methodInfo.ReturnType)
+	if concreteType, ok := methodGenericTypes(methodEnv)[returnTypeName]; ok {
+		returnTypeName = concreteType
+	} else if bound, ok := capturedUpperBound(instance, returnTypeName); ok {
+		returnTypeName = bound
+	}
 	if returnTypeName != "" && returnTypeName != "Any" && returnTypeName != "Void" {
 		if err := validateReturnType(instance, returnTypeName, result, methodEnv); err != nil {
 			return nil, err
@@ -820,6 +870,78 @@ func CallInstanceMethod(instance *ClassInstance, methodInfo MethodInfo, env *Env
 	return result, nil
 }
 
+// capturedUpperBound returns the bound B when the class type parameter typeName was
+// instantiated with "? extends B", so values read through it are known to be Bs
+func capturedUpperBound(instance *ClassInstance, typeName string) (string, bool) {
+	if instance.ParentClass == nil || !isGenericTypeParameter(typeName) {
+		return "", false
+	}
+	for i, tp := range instance.ParentClass.TypeParams {
+		if i >= len(instance.GenericTypes) || len(tp.Bounds) == 0 || tp.Bounds[0].Name.Name != typeName {
+			continue
+		}
+		if gt := instance.GenericTypes[i]; len(gt.Bounds) > 0 && gt.Bounds[0].Name.Name == "?" {
+			if kind, bound := captureWildcard(gt.Bounds[0]); kind == "extends" && bound != "" {
+				return bound, true
+			}
+		}
+	}
+	return "", false
+}
+
+// methodTypeArgsVar holds the explicit type arguments of a call like obj.method<Int>(x)
+// in the call environment; methodGenericTypesVar maps the method's type parameters to
+// those arguments inside the method environment
+const (
+	methodTypeArgsVar     = "__method_type_args__"
+	methodGenericTypesVar = "__method_generic_types__"
+)
+
+// bindMethodTypeArgs checks the explicit type arguments passed in callEnv against the
+// method's type parameters and binds each parameter to its concrete type in methodEnv.
+// Builtin methods without declared type parameters accept and ignore type arguments.
+func bindMethodTypeArgs(callEnv, methodEnv *Env, method MethodInfo, className string) error {
+	if callEnv == nil {
+		return nil
+	}
+	typeArgs, ok := callEnv.Vars[methodTypeArgsVar].([]ast.TypeParam)
+	if !ok || len(typeArgs) == 0 {
+		return nil
+	}
+	if len(method.TypeParams) == 0 {
+		if method.BuiltinImpl != nil {
+			return nil
+		}
+		return ThrowRuntimeError(callEnv, fmt.Sprintf("method %s.%s is not generic but was called with %d type arguments",
+			className, method.Name, len(typeArgs)))
+	}
+	if len(typeArgs) != len(method.TypeParams) {
+		return ThrowRuntimeError(callEnv, fmt.Sprintf("method %s.%s expects %d type arguments, got %d",
+			className, method.Name, len(method.TypeParams), len(typeArgs)))
+	}
+
+	generics := make(map[string]string, len(typeArgs))
+	for i, tp := range method.TypeParams {
+		concrete := typeArgs[i].Name
+		if typeArgs[i].IsWildcard {
+			concrete = "?"
+		}
+		generics[tp.Name] = concrete
+		methodEnv.Set("__type_"+tp.Name, concrete)
+	}
+	methodEnv.Set(methodGenericTypesVar, generics)
+	return nil
+}
+
+// methodGenericTypes returns the method type parameter bindings of a method environment
+func methodGenericTypes(env *Env) map[string]string {
+	if env == nil {
+		return nil
+	}
+	generics, _ := env.Vars[methodGenericTypesVar].(map[string]string)
+	return generics
+}
+
 // callDefaultInterfaceMethod calls a default method from an interface
 func callDefaultInterfaceMethod(instance *ClassInstance, signature MethodSignature, env *Env, args []any) (any, error) {
 	// Create method environment
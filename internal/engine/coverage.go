@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+)
+
+// Coverage counts how many times each statement line executes, keyed by file and line.
+// Lines are registered as executable when a program is added, so lines that never
+// run are reported as uncovered.
+type Coverage struct {
+	mu    sync.Mutex
+	files map[string]map[int]int // file -> line -> hits
+}
+
+// NewCoverage creates an empty coverage collector
+func NewCoverage() *Coverage {
+	return &Coverage{files: make(map[string]map[int]int)}
+}
+
+// AddProgram registers every statement line of prog as executable code in file
+func (c *Coverage) AddProgram(file string, prog *ast.Program) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lines := c.fileLines(file)
+	addStmtLines(lines, prog.Stmts)
+}
+
+func (c *Coverage) fileLines(file string) map[int]int {
+	lines, ok := c.files[file]
+	if !ok {
+		lines = make(map[int]int)
+		c.files[file] = lines
+	}
+	return lines
+}
+
+func (c *Coverage) hit(file string, line int) {
+	c.mu.Lock()
+	c.fileLines(file)[line]++
+	c.mu.Unlock()
+}
+
+// Hits returns how many times the statement on file:line executed
+func (c *Coverage) Hits(file string, line int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.files[file][line]
+}
+
+// FileCoverage summarizes the coverage of a single file
+type FileCoverage struct {
+	File      string
+	Covered   int
+	Total     int
+	Uncovered []int // uncovered line numbers, ascending
+}
+
+// Percent returns the share of executable lines that ran
+func (f FileCoverage) Percent() float64 {
+	if f.Total == 0 {
+		return 100
+	}
+	return float64(f.Covered) * 100 / float64(f.Total)
+}
+
+// Summary returns per-file coverage sorted by file name
+func (c *Coverage) Summary() []FileCoverage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]FileCoverage, 0, len(c.files))
+	for file, lines := range c.files {
+		fc := FileCoverage{File: file, Total: len(lines)}
+		for line, hits := range lines {
+			if hits > 0 {
+				fc.Covered++
+			} else {
+				fc.Uncovered = append(fc.Uncovered, line)
+			}
+		}
+		sort.Ints(fc.Uncovered)
+		result = append(result, fc)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].File < result[j].File })
+	return result
+}
+
+// Report writes a per-file coverage table with uncovered line ranges
+func (c *Coverage) Report(w io.Writer) {
+	summary := c.Summary()
+	covered, total := 0, 0
+	fmt.Fprintln(w, "Coverage:")
+	for _, fc := range summary {
+		covered += fc.Covered
+		total += fc.Total
+		name := fc.File
+		if name == "" {
+			name = "<main>"
+		}
+		fmt.Fprintf(w, "  %-30s %6.1f%% (%d/%d lines)", name, fc.Percent(), fc.Covered, fc.Total)
+		if len(fc.Uncovered) > 0 {
+			fmt.Fprintf(w, "  uncovered: %s", formatLineRanges(fc.Uncovered))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "  %-30s %6.1f%% (%d/%d lines)\n", "total", FileCoverage{Covered: covered, Total: total}.Percent(), covered, total)
+}
+
+// formatLineRanges collapses sorted line numbers into "3, 7-9" form
+func formatLineRanges(lines []int) string {
+	var parts []string
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, fmt.Sprint(lines[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// addStmtLines marks the start line of each statement, including nested bodies
+func addStmtLines(lines map[int]int, stmts []ast.Stmt) {
+	for _, st := range stmts {
+		if located, ok := st.(ast.Locatable); ok {
+			if pos := located.StartPos(); pos.Line > 0 {
+				if _, seen := lines[pos.Line]; !seen {
+					lines[pos.Line] = 0
+				}
+			}
+		}
+
+		switch s := st.(type) {
+		case *ast.DefStmt:
+			addStmtLines(lines, s.Body)
+		case *ast.IfStmt:
+			for _, clause := range s.Clauses {
+				addStmtLines(lines, clause.Body)
+			}
+			addStmtLines(lines, s.Else)
+		case *ast.ForInStmt:
+			addStmtLines(lines, s.Body)
+		case *ast.LoopStmt:
+			addStmtLines(lines, s.Body)
+		case *ast.DoLoopStmt:
+			addStmtLines(lines, s.Body)
+		case *ast.TryStmt:
+			addStmtLines(lines, s.Body)
+			for _, catch := range s.Catches {
+				addStmtLines(lines, catch.Body)
+			}
+			addStmtLines(lines, s.Finally)
+		case *ast.SelectStmt:
+			for _, c := range s.Cases {
+				addStmtLines(lines, c.Body)
+			}
+		case *ast.SwitchStmt:
+			for _, c := range s.Cases {
+				addStmtLines(lines, c.Body)
+			}
+			addStmtLines(lines, s.Default)
+		case *ast.ClassDecl:
+			if s.Constructor != nil {
+				addStmtLines(lines, s.Constructor.Body)
+			}
+			for _, m := range s.Methods {
+				addStmtLines(lines, m.Body)
+			}
+		case *ast.EnumDecl:
+			if s.Constructor != nil {
+				addStmtLines(lines, s.Constructor.Body)
+			}
+			for _, m := range s.Methods {
+				addStmtLines(lines, m.Body)
+			}
+		case *ast.RecordDecl:
+			for _, m := range s.Methods {
+				addStmtLines(lines, m.Body)
+			}
+		case *ast.InterfaceDecl:
+			for _, m := range s.Methods {
+				addStmtLines(lines, m.DefaultBody)
+			}
+		}
+	}
+}
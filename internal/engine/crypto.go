@@ -1,107 +1,89 @@
 package engine
 
 import (
+	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
+	"fmt"
 
 	"github.com/ArubikU/polyloft/internal/ast"
 	"github.com/ArubikU/polyloft/internal/common"
 	"github.com/ArubikU/polyloft/internal/engine/utils"
 )
 
-// InstallCryptoModule installs the complete Crypto module with cryptographic functions
+// InstallCryptoModule installs the complete Crypto module with cryptographic functions.
+// Hashing and encoding functions accept a String or Bytes.
 func InstallCryptoModule(env *Env, opts Options) error {
 	// Get type references from already-installed builtin types
 	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	dataParam := []ast.Parameter{{Name: "data", Type: ast.ANY}}
 
 	cryptoClass := NewClassBuilder("Crypto").
-		AddStaticMethod("md5", stringType, []ast.Parameter{
-			{Name: "data", Type: stringType},
-		}, Func(func(env *Env, args []any) (any, error) {
-			if len(args) < 1 {
-				return nil, ThrowArityError(env, 1, len(args))
-			}
-			dataStr := utils.ToString(args[0])
-			hash := md5.Sum([]byte(dataStr))
-			return hex.EncodeToString(hash[:]), nil
+		AddStaticMethod("md5", stringType, dataParam, hashFunc(func(data []byte) []byte {
+			hash := md5.Sum(data)
+			return hash[:]
 		})).
-		AddStaticMethod("sha1", stringType, []ast.Parameter{
-			{Name: "data", Type: stringType},
-		}, Func(func(env *Env, args []any) (any, error) {
-			if len(args) < 1 {
-				return nil, ThrowArityError(env, 1, len(args))
-			}
-			dataStr := utils.ToString(args[0])
-			hash := sha1.Sum([]byte(dataStr))
-			return hex.EncodeToString(hash[:]), nil
+		AddStaticMethod("sha1", stringType, dataParam, hashFunc(func(data []byte) []byte {
+			hash := sha1.Sum(data)
+			return hash[:]
 		})).
-		AddStaticMethod("sha256", stringType, []ast.Parameter{
-			{Name: "data", Type: stringType},
-		}, Func(func(env *Env, args []any) (any, error) {
-			if len(args) < 1 {
-				return nil, ThrowArityError(env, 1, len(args))
-			}
-			dataStr := utils.ToString(args[0])
-			hash := sha256.Sum256([]byte(dataStr))
-			return hex.EncodeToString(hash[:]), nil
+		AddStaticMethod("sha256", stringType, dataParam, hashFunc(func(data []byte) []byte {
+			hash := sha256.Sum256(data)
+			return hash[:]
 		})).
-		AddStaticMethod("sha512", stringType, []ast.Parameter{
-			{Name: "data", Type: stringType},
+		AddStaticMethod("sha512", stringType, dataParam, hashFunc(func(data []byte) []byte {
+			hash := sha512.Sum512(data)
+			return hash[:]
+		})).
+		AddStaticMethod("hmacSha256", stringType, []ast.Parameter{
+			{Name: "key", Type: ast.ANY},
+			{Name: "message", Type: ast.ANY},
 		}, Func(func(env *Env, args []any) (any, error) {
-			if len(args) < 1 {
-				return nil, ThrowArityError(env, 1, len(args))
+			key, err := cryptoInput(env, args[0])
+			if err != nil {
+				return nil, err
 			}
-			dataStr := utils.ToString(args[0])
-			hash := sha512.Sum512([]byte(dataStr))
-			return hex.EncodeToString(hash[:]), nil
+			message, err := cryptoInput(env, args[1])
+			if err != nil {
+				return nil, err
+			}
+			mac := hmac.New(sha256.New, key)
+			mac.Write(message)
+			return hex.EncodeToString(mac.Sum(nil)), nil
 		})).
-		AddStaticMethod("base64Encode", stringType, []ast.Parameter{
-			{Name: "data", Type: stringType},
-		}, Func(func(env *Env, args []any) (any, error) {
-			if len(args) < 1 {
-				return nil, ThrowArityError(env, 1, len(args))
+		AddStaticMethod("base64Encode", stringType, dataParam, Func(func(env *Env, args []any) (any, error) {
+			data, err := cryptoInput(env, args[0])
+			if err != nil {
+				return nil, err
 			}
-			dataStr := utils.ToString(args[0])
-			encoded := base64.StdEncoding.EncodeToString([]byte(dataStr))
-			return encoded, nil
+			return base64.StdEncoding.EncodeToString(data), nil
 		})).
 		AddStaticMethod("base64Decode", stringType, []ast.Parameter{
 			{Name: "data", Type: stringType},
 		}, Func(func(env *Env, args []any) (any, error) {
-			if len(args) < 1 {
-				return nil, ThrowArityError(env, 1, len(args))
-			}
-			dataStr := utils.ToString(args[0])
-			decoded, err := base64.StdEncoding.DecodeString(dataStr)
+			decoded, err := base64.StdEncoding.DecodeString(utils.ToString(args[0]))
 			if err != nil {
-				return nil, err
+				return nil, ThrowValueError(env, fmt.Sprintf("invalid base64 data: %v", err))
 			}
 			return string(decoded), nil
 		})).
-		AddStaticMethod("hexEncode", stringType, []ast.Parameter{
-			{Name: "data", Type: stringType},
-		}, Func(func(env *Env, args []any) (any, error) {
-			if len(args) < 1 {
-				return nil, ThrowArityError(env, 1, len(args))
+		AddStaticMethod("hexEncode", stringType, dataParam, Func(func(env *Env, args []any) (any, error) {
+			data, err := cryptoInput(env, args[0])
+			if err != nil {
+				return nil, err
 			}
-			dataStr := utils.ToString(args[0])
-			encoded := hex.EncodeToString([]byte(dataStr))
-			return encoded, nil
+			return hex.EncodeToString(data), nil
 		})).
 		AddStaticMethod("hexDecode", stringType, []ast.Parameter{
 			{Name: "data", Type: stringType},
 		}, Func(func(env *Env, args []any) (any, error) {
-			if len(args) < 1 {
-				return nil, ThrowArityError(env, 1, len(args))
-			}
-			dataStr := utils.ToString(args[0])
-			decoded, err := hex.DecodeString(dataStr)
+			decoded, err := hex.DecodeString(utils.ToString(args[0]))
 			if err != nil {
-				return nil, err
+				return nil, ThrowValueError(env, fmt.Sprintf("invalid hex data: %v", err))
 			}
 			return string(decoded), nil
 		}))
@@ -112,3 +94,28 @@ func InstallCryptoModule(env *Env, opts Options) error {
 	}
 	return nil
 }
+
+// hashFunc returns a Crypto method that hashes its argument with sum and returns
+// the digest as lowercase hex
+func hashFunc(sum func(data []byte) []byte) Func {
+	return func(env *Env, args []any) (any, error) {
+		data, err := cryptoInput(env, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return hex.EncodeToString(sum(data)), nil
+	}
+}
+
+// cryptoInput returns the bytes of a String (UTF-8) or Bytes argument
+func cryptoInput(env *Env, value any) ([]byte, error) {
+	if inst, ok := value.(*ClassInstance); ok && inst.ClassName == "Bytes" {
+		if data, ok := inst.Fields["_data"].([]byte); ok {
+			return data, nil
+		}
+	}
+	if isStringValue(value) {
+		return []byte(StringValue(value)), nil
+	}
+	return nil, ThrowTypeError(env, "String or Bytes", value)
+}
@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+// ErrDebugQuit is returned by Eval when the user quits a debug session
+var ErrDebugQuit = errors.New("debug session terminated")
+
+// Debugger pauses execution at breakpoints and lets the user step through
+// statements and inspect variables of the paused environment.
+// Commands:
+//
+//	c, continue        resume until the next breakpoint
+//	s, step            run the next statement and pause again
+//	p, print <expr>    evaluate an expression in the paused scope
+//	l, locals          list variables visible from the paused scope
+//	b, break <file:line>  add a breakpoint
+//	w, where           show the current position
+//	q, quit            stop the program
+type Debugger struct {
+	in          *bufio.Scanner
+	out         io.Writer
+	breakpoints map[string]map[int]bool // file (or "" for any file) -> lines
+	stepping    bool
+	prompting   bool
+	lastFile    string
+	lastLine    int
+}
+
+// NewDebugger creates a debugger reading commands from in and writing to out
+func NewDebugger(in io.Reader, out io.Writer) *Debugger {
+	return &Debugger{
+		in:          bufio.NewScanner(in),
+		out:         out,
+		breakpoints: make(map[string]map[int]bool),
+	}
+}
+
+// StepOnStart makes the debugger pause before the first statement
+func (d *Debugger) StepOnStart() { d.stepping = true }
+
+// AddBreakpoint registers a breakpoint. spec is "file:line" or just "line",
+// which matches that line in any file.
+func (d *Debugger) AddBreakpoint(spec string) error {
+	file := ""
+	lineStr := spec
+	if idx := strings.LastIndex(spec, ":"); idx >= 0 {
+		file = filepath.Clean(spec[:idx])
+		lineStr = spec[idx+1:]
+	}
+	line, err := strconv.Atoi(lineStr)
+	if err != nil || line <= 0 {
+		return fmt.Errorf("invalid breakpoint %q, expected file:line", spec)
+	}
+	if d.breakpoints[file] == nil {
+		d.breakpoints[file] = make(map[int]bool)
+	}
+	d.breakpoints[file][line] = true
+	return nil
+}
+
+func (d *Debugger) hasBreakpoint(file string, line int) bool {
+	if d.breakpoints[""][line] {
+		return true
+	}
+	if file == "" {
+		return false
+	}
+	clean := filepath.Clean(file)
+	for bpFile, lines := range d.breakpoints {
+		if bpFile == "" || !lines[line] {
+			continue
+		}
+		if bpFile == clean || filepath.Base(bpFile) == bpFile && filepath.Base(clean) == bpFile {
+			return true
+		}
+	}
+	return false
+}
+
+// beforeStmt is called by evalStmt before each statement while debugging
+func (d *Debugger) beforeStmt(env *Env, st ast.Stmt) error {
+	if d.prompting {
+		return nil
+	}
+	located, ok := st.(ast.Locatable)
+	if !ok {
+		return nil
+	}
+	pos := located.StartPos()
+	if pos.Line == 0 {
+		return nil
+	}
+	file := env.GetFileName()
+	// Several statements can start on one line; only pause once per line
+	if file == d.lastFile && pos.Line == d.lastLine {
+		return nil
+	}
+	d.lastFile, d.lastLine = file, pos.Line
+
+	if !d.stepping && !d.hasBreakpoint(file, pos.Line) {
+		return nil
+	}
+	return d.prompt(env, pos)
+}
+
+func (d *Debugger) prompt(env *Env, pos ast.Position) error {
+	d.prompting = true
+	defer func() { d.prompting = false }()
+
+	d.printPosition(env, pos)
+	for {
+		fmt.Fprint(d.out, "(debug) ")
+		if !d.in.Scan() {
+			fmt.Fprintln(d.out)
+			return ErrDebugQuit
+		}
+		line := strings.TrimSpace(d.in.Text())
+		cmd, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+
+		switch cmd {
+		case "":
+			continue
+		case "c", "continue":
+			d.stepping = false
+			return nil
+		case "s", "step":
+			d.stepping = true
+			return nil
+		case "p", "print":
+			if arg == "" {
+				fmt.Fprintln(d.out, "usage: print <expr>")
+				continue
+			}
+			d.printExpr(env, arg)
+		case "l", "locals":
+			d.printLocals(env)
+		case "b", "break":
+			if err := d.AddBreakpoint(arg); err != nil {
+				fmt.Fprintln(d.out, err)
+				continue
+			}
+			fmt.Fprintf(d.out, "breakpoint set at %s\n", arg)
+		case "w", "where":
+			d.printPosition(env, pos)
+		case "q", "quit":
+			return ErrDebugQuit
+		case "h", "help":
+			fmt.Fprintln(d.out, "Commands: continue (c), step (s), print <expr> (p), locals (l), break <file:line> (b), where (w), quit (q)")
+		default:
+			fmt.Fprintf(d.out, "unknown command %q (type 'help')\n", cmd)
+		}
+	}
+}
+
+func (d *Debugger) printPosition(env *Env, pos ast.Position) {
+	file := env.GetFileName()
+	if file == "" {
+		file = "<main>"
+	}
+	fmt.Fprintf(d.out, "paused at %s:%d\n", file, pos.Line)
+	if lines := env.GetSourceLines(); pos.Line <= len(lines) {
+		fmt.Fprintf(d.out, "  %4d | %s\n", pos.Line, lines[pos.Line-1])
+	}
+}
+
+func (d *Debugger) printExpr(env *Env, src string) {
+	lx := &lexer.Lexer{}
+	expr, err := parser.New(lx.Scan([]byte(src))).ParseExpression()
+	if err != nil {
+		fmt.Fprintln(d.out, "error:", err)
+		return
+	}
+	val, err := evalExpr(env, expr)
+	if err != nil {
+		fmt.Fprintln(d.out, "error:", err)
+		return
+	}
+	fmt.Fprintln(d.out, utils.ToStringWithEnv(val, env))
+}
+
+// printLocals lists user variables from the innermost scope outwards,
+// skipping builtins, classes and functions.
+func (d *Debugger) printLocals(env *Env) {
+	seen := make(map[string]bool)
+	for scope := env; scope != nil; scope = scope.Parent {
+		names := make([]string, 0, len(scope.Vars))
+		for name, val := range scope.Vars {
+			if seen[name] || strings.HasPrefix(name, "$") || strings.HasPrefix(name, "__") || !isInspectable(val) {
+				continue
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			seen[name] = true
+			fmt.Fprintf(d.out, "  %s = %s\n", name, utils.ToStringWithEnv(scope.Vars[name], scope))
+		}
+	}
+}
+
+func isInspectable(val any) bool {
+	switch val.(type) {
+	case *ClassDefinition, *common.ClassConstructor, *common.InterfaceDefinition:
+		return false
+	}
+	_, isFunc := common.ExtractFunc(val)
+	return !isFunc
+}
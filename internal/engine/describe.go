@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/common"
+)
+
+// specCollector gathers the it() tests a test file declares while its
+// top-level code runs. describe() pushes its name onto groups for as long as
+// its body runs, so each test records the groups it is nested in.
+type specCollector struct {
+	groups []string
+	specs  []testFunc
+	closed bool // set once the file has run; describe and it are rejected afterwards
+}
+
+// InstallDescribeBuiltins installs describe(name, body) and it(name, body),
+// which declare grouped tests for polyloft test. describe calls its body right
+// away, while it only records the test; the runner calls it once the file has
+// run.
+func InstallDescribeBuiltins(env *Env) error {
+	describe := NewFunctionBuilder("describe").
+		SetParamsFromNames([]string{"name", "body"}, nil).
+		SetImplementation(func(callEnv *common.Env, args []any) (any, error) {
+			specs, name, body, err := specArgs((*Env)(callEnv), "describe", args)
+			if err != nil {
+				return nil, err
+			}
+			specs.groups = append(specs.groups, name)
+			defer func() { specs.groups = specs.groups[:len(specs.groups)-1] }()
+			_, err = body(callEnv, []any{})
+			return nil, err
+		})
+	if _, err := describe.Build(env); err != nil {
+		return err
+	}
+
+	it := NewFunctionBuilder("it").
+		SetParamsFromNames([]string{"name", "body"}, nil).
+		SetImplementation(func(callEnv *common.Env, args []any) (any, error) {
+			specs, name, body, err := specArgs((*Env)(callEnv), "it", args)
+			if err != nil {
+				return nil, err
+			}
+			specs.specs = append(specs.specs, testFunc{
+				name:   name,
+				fn:     body,
+				groups: append([]string{}, specs.groups...),
+				spec:   true,
+			})
+			return nil, nil
+		})
+	_, err := it.Build(env)
+	return err
+}
+
+// specArgs checks the arguments of describe or it and that the call is made
+// while a test file is being loaded, returning the file's spec collector
+func specArgs(env *Env, fnName string, args []any) (*specCollector, string, common.Func, error) {
+	if len(args) != 2 {
+		return nil, "", nil, ThrowArityError(env, 2, len(args))
+	}
+	var specs *specCollector
+	if hooks := hooksOf(env); hooks != nil {
+		specs = hooks.specs
+	}
+	if specs == nil {
+		return nil, "", nil, ThrowRuntimeError(env, fnName+"() only works in tests run by polyloft test")
+	}
+	if specs.closed {
+		return nil, "", nil, ThrowRuntimeError(env, fnName+"() must be called while the test file loads, not from a test")
+	}
+	name, ok := extractPrimitiveValue(args[0]).(string)
+	if !ok {
+		return nil, "", nil, ThrowTypeError(env, "String name for "+fnName, args[0])
+	}
+	body, ok := common.ExtractFunc(args[1])
+	if !ok {
+		return nil, "", nil, ThrowTypeError(env, "function body for "+fnName, args[1])
+	}
+	return specs, name, body, nil
+}
+
+// specName joins the groups of an it() test and its own name
+func specName(groups []string, name string) string {
+	return strings.Join(append(append([]string{}, groups...), name), " > ")
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// canonicalRank groups values for canonicalLess: nil, Bools, numbers, Strings,
+// then everything else
+func canonicalRank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case int, float64:
+		return 2
+	case string:
+		return 3
+	}
+	return 4
+}
+
+// canonicalLess orders keys the same way on every run: numbers by value, Strings
+// byte-wise and other values by their printed form. Unlike compareNatural it never
+// calls script code and accepts keys of mixed types.
+func canonicalLess(a, b any) bool {
+	pa, pb := extractPrimitiveValue(a), extractPrimitiveValue(b)
+	ra, rb := canonicalRank(pa), canonicalRank(pb)
+	if ra != rb {
+		return ra < rb
+	}
+	switch va := pa.(type) {
+	case bool:
+		return !va && pb.(bool)
+	case int, float64:
+		fa, _ := utils.AsFloat(va)
+		fb, _ := utils.AsFloat(pb)
+		return fa < fb
+	case string:
+		return va < pb.(string)
+	case nil:
+		return false
+	}
+	return strings.Compare(utils.ToString(a), utils.ToString(b)) < 0
+}
+
+// canonicalItems returns items sorted with canonicalLess, leaving items untouched
+func canonicalItems(items []any) []any {
+	sorted := append([]any(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool { return canonicalLess(sorted[i], sorted[j]) })
+	return sorted
+}
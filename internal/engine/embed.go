@@ -0,0 +1,226 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// EvalSource runs prog in the session's environment as the source file fileName,
+// so errors point into source and relative imports resolve from its directory
+func (s *Session) EvalSource(prog *ast.Program, fileName, packageName, source string) (any, error) {
+	s.env.FileName = fileName
+	s.env.PackageName = packageName
+	s.env.SetSourceLines(strings.Split(source, "\n"))
+	installHooks(s.env, s.opts, fileName, prog)
+	return runProgram(s.env, prog)
+}
+
+// Define binds name in the session's top-level scope. Go values are converted
+// with ScriptValue.
+func (s *Session) Define(name string, value any) {
+	s.env.Set(name, ScriptValue(s.env, value))
+}
+
+// Lookup returns the value bound to name in the session's top-level scope
+func (s *Session) Lookup(name string) (any, bool) {
+	return s.env.Get(name)
+}
+
+// NativeFunc wraps a Go function so scripts can call it. Arguments are converted
+// with NativeValue and the result with ScriptValue; a returned error is raised as
+// a RuntimeError.
+func NativeFunc(name string, fn func(args []any) (any, error)) *common.FunctionDefinition {
+	return &common.FunctionDefinition{
+		Name: name,
+		Func: func(env *common.Env, args []any) (any, error) {
+			result, err := fn(nativeArgs(args))
+			if err != nil {
+				return nil, nativeError(env, err)
+			}
+			return ScriptValue(env, result), nil
+		},
+		Params:     []ast.Parameter{{Name: "args", Type: ast.ANY, IsVariadic: true}},
+		ReturnType: ast.ANY,
+	}
+}
+
+// NativeType describes a Go type exposed to scripts as a class. Scripts create
+// instances by calling the class, which calls New; methods receive the Go value
+// as self. Values of the type returned to scripts become instances as well.
+type NativeType struct {
+	Name    string
+	Sample  any                                                // any value of the Go type, e.g. (*Conn)(nil)
+	New     func(args []any) (any, error)                      // nil when scripts cannot create instances
+	Methods map[string]func(self any, args []any) (any, error) // called with converted arguments
+}
+
+var (
+	nativeTypesMu sync.RWMutex
+	nativeTypes   = map[reflect.Type]*ClassDefinition{} // Go type -> class wrapping its values
+)
+
+// nativeClassFor returns the class registered for the Go type of v
+func nativeClassFor(v any) *ClassDefinition {
+	if v == nil {
+		return nil
+	}
+	nativeTypesMu.RLock()
+	defer nativeTypesMu.RUnlock()
+	return nativeTypes[reflect.TypeOf(v)]
+}
+
+// DefineType registers t as a class in the session's top-level scope
+func (s *Session) DefineType(t NativeType) error {
+	if t.Name == "" || t.Sample == nil {
+		return fmt.Errorf("native type needs a Name and a Sample value")
+	}
+	goType := reflect.TypeOf(t.Sample)
+	nativeTypesMu.RLock()
+	existing := nativeTypes[goType]
+	nativeTypesMu.RUnlock()
+	if existing != nil && existing.Name != t.Name {
+		return fmt.Errorf("Go type %s is already registered as %s", goType, existing.Name)
+	}
+
+	variadic := []ast.Parameter{{Name: "args", Type: ast.ANY, IsVariadic: true}}
+	builder := NewClassBuilder(t.Name).AddField("_native", ast.ANY, []string{"private"})
+	builder.AddBuiltinConstructor(variadic, func(callEnv *common.Env, args []any) (any, error) {
+		if t.New == nil {
+			return nil, ThrowRuntimeError((*Env)(callEnv), fmt.Sprintf("%s cannot be created from scripts", t.Name))
+		}
+		value, err := t.New(nativeArgs(args))
+		if err != nil {
+			return nil, nativeError(callEnv, err)
+		}
+		if reflect.TypeOf(value) != goType {
+			return nil, ThrowTypeError((*Env)(callEnv), goType.String(), value)
+		}
+		thisVal, _ := callEnv.This()
+		thisVal.(*ClassInstance).Fields["_native"] = value
+		return nil, nil
+	})
+	for name, method := range t.Methods {
+		method := method
+		builder.AddBuiltinMethod(name, ast.ANY, variadic, func(callEnv *common.Env, args []any) (any, error) {
+			thisVal, _ := callEnv.This()
+			instance, ok := thisVal.(*ClassInstance)
+			if !ok {
+				return nil, ThrowTypeError((*Env)(callEnv), t.Name, thisVal)
+			}
+			result, err := method(instance.Fields["_native"], nativeArgs(args))
+			if err != nil {
+				return nil, nativeError(callEnv, err)
+			}
+			return ScriptValue(callEnv, result), nil
+		}, []string{})
+	}
+	classDef, err := builder.Build(s.env)
+	if err != nil {
+		return err
+	}
+
+	nativeTypesMu.Lock()
+	nativeTypes[goType] = classDef
+	nativeTypesMu.Unlock()
+	return nil
+}
+
+// wrapNative creates an instance of a native type's class around value without
+// calling New
+func wrapNative(env *common.Env, classDef *ClassDefinition, value any) any {
+	instance := &ClassInstance{
+		ClassName:   classDef.Name,
+		Fields:      map[string]any{"_native": value},
+		Methods:     make(map[string]Func),
+		ParentClass: classDef,
+	}
+	if err := bindMethods(instance, classDef, env); err != nil {
+		return value
+	}
+	return instance
+}
+
+// nativeArgs converts script arguments for a Go callback
+func nativeArgs(args []any) []any {
+	native := make([]any, len(args))
+	for i, arg := range args {
+		native[i] = NativeValue(arg)
+	}
+	return native
+}
+
+// nativeError raises an error returned by a Go callback as a RuntimeError;
+// Polyloft exceptions pass through unchanged
+func nativeError(env *common.Env, err error) error {
+	if _, isException := err.(*HyException); isException {
+		return err
+	}
+	return ThrowRuntimeError((*Env)(env), err.Error())
+}
+
+// NativeValue converts a Polyloft value to plain Go: Int to int, Float to float64,
+// String to string, Bool to bool, Bytes to []byte, Arrays to []any and Maps to
+// map[string]any, recursively. Instances of native types give back their Go
+// value. Other values are returned unchanged.
+func NativeValue(v any) any {
+	if inst, ok := v.(*ClassInstance); ok {
+		if value, isNative := inst.Fields["_native"]; isNative && nativeClassFor(value) == inst.ParentClass {
+			return value
+		}
+	}
+	if inst, ok := v.(*ClassInstance); ok && inst.ClassName == "Map" {
+		entries := orderedMapEntries(inst, false)
+		result := make(map[string]any, len(entries))
+		for _, entry := range entries {
+			result[utils.ToString(NativeValue(entry.Key))] = NativeValue(entry.Value)
+		}
+		return result
+	}
+	if inst, ok := v.(*ClassInstance); ok && inst.ClassName == "Bytes" {
+		if data, ok := inst.Fields["_data"].([]byte); ok {
+			return data
+		}
+	}
+	if items, ok := collectionItems(v); ok {
+		result := make([]any, len(items))
+		for i, item := range items {
+			result[i] = NativeValue(item)
+		}
+		return result
+	}
+	return extractPrimitiveValue(v)
+}
+
+// ScriptValue converts a Go value to a Polyloft value: numbers, strings, bools,
+// []byte, slices and maps become Int, Float, String, Bool, Bytes, Array and Map.
+// Go functions with the NativeFunc signature become callable functions and values
+// of native types become instances of their class; anything else is passed
+// through as an opaque value.
+func ScriptValue(env *common.Env, v any) any {
+	if classDef := nativeClassFor(v); classDef != nil {
+		return wrapNative(env, classDef, v)
+	}
+	switch value := v.(type) {
+	case func(args []any) (any, error):
+		return NativeFunc("native", value)
+	case map[string]any:
+		converted := make(map[string]any, len(value))
+		for k, item := range value {
+			converted[k] = ScriptValue(env, item)
+		}
+		return ConvertToClassInstance(env, converted)
+	case []any:
+		converted := make([]any, len(value))
+		for i, item := range value {
+			converted[i] = ScriptValue(env, item)
+		}
+		return ConvertToClassInstance(env, converted)
+	}
+	return ConvertToClassInstance(env, v)
+}
@@ -1,8 +1,11 @@
 package engine
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"math/bits"
 	"os"
 	"path/filepath"
@@ -42,6 +45,10 @@ func isGenericTypeParameter(typeName string) bool {
 	if strings.EqualFold(typeName, "any") {
 		return true
 	}
+	// Type aliases name concrete types, even when they look like type parameters
+	if _, isAlias := lookupTypeAlias(typeName); isAlias {
+		return false
+	}
 	// Check if it's a single uppercase letter (T, K, V, E, etc.)
 	if len(typeName) == 1 && typeName[0] >= 'A' && typeName[0] <= 'Z' {
 		return true
@@ -54,8 +61,9 @@ func isGenericTypeParameter(typeName string) bool {
 }
 
 func bindParametersWithVariadic(env *common.Env, params []ast.Parameter, args []any) error {
-	// Check minimum required parameters (non-variadic)
+	// Check minimum required parameters (non-variadic, without a default value)
 	requiredParams := 0
+	positionalParams := 0
 	variadicParam := -1
 	var variadicType string
 
@@ -65,7 +73,10 @@ func bindParametersWithVariadic(env *common.Env, params []ast.Parameter, args []
 			variadicType = ast.GetTypeNameString(param.Type)
 			break
 		}
-		requiredParams++
+		positionalParams++
+		if param.Default == nil {
+			requiredParams++
+		}
 	}
 
 	// Check if we have enough arguments for required parameters
@@ -102,10 +113,28 @@ func bindParametersWithVariadic(env *common.Env, params []ast.Parameter, args []
 		}
 	}
 
+	// Method type arguments (obj.method<Int>(x)) take precedence over class type arguments
+	methodTypes := methodGenericTypes(env)
+
 	// Bind regular parameters with type validation
-	for i := 0; i < requiredParams; i++ {
+	for i := 0; i < positionalParams; i++ {
 		paramTypeName := ast.GetTypeNameString(params[i].Type)
 
+		// Omitted arguments (trailing, or skipped by keyword arguments) take their
+		// default, evaluated in the call's environment so it can refer to the
+		// parameters bound before it
+		var arg any = omittedArg{}
+		if i < len(args) {
+			arg = args[i]
+		}
+		if _, omitted := arg.(omittedArg); omitted {
+			value, err := evalExpr(env, params[i].Default)
+			if err != nil {
+				return err
+			}
+			arg = value
+		}
+
 		// Check variance constraints - covariant (out) type parameters cannot appear in parameter positions
 		if paramTypeName != "" && isGenericTypeParameter(paramTypeName) && varianceMap != nil {
 			if variance, found := varianceMap[paramTypeName]; found && variance == "out" {
@@ -117,7 +146,9 @@ func bindParametersWithVariadic(env *common.Env, params []ast.Parameter, args []
 
 		// Resolve generic type parameter to concrete type if possible
 		resolvedType := paramTypeName
-		if paramTypeName != "" && isGenericTypeParameter(paramTypeName) && genericTypes != nil {
+		if concreteType, found := methodTypes[paramTypeName]; found {
+			resolvedType = concreteType
+		} else if paramTypeName != "" && isGenericTypeParameter(paramTypeName) && genericTypes != nil {
 			if concreteType, found := genericTypes[paramTypeName]; found {
 				resolvedType = concreteType
 			}
@@ -126,16 +157,19 @@ func bindParametersWithVariadic(env *common.Env, params []ast.Parameter, args []
 		// Validate type if we have a concrete type (not a generic parameter)
 		// Skip validation for wildcards as they have special semantics
 		if resolvedType != "" && !isGenericTypeParameter(resolvedType) && !isWildcardType(resolvedType) {
-			if err := ValidateArgumentType(args[i], resolvedType); err != nil {
+			if err := ValidateArgumentType(arg, resolvedType); err != nil {
 				return err
 			}
 		}
-		env.Set(params[i].Name, args[i])
+		env.Set(params[i].Name, arg)
 	}
 
 	// Handle variadic parameter if present
 	if variadicParam >= 0 {
-		variadicArgs := args[requiredParams:]
+		var variadicArgs []any
+		if len(args) > positionalParams {
+			variadicArgs = args[positionalParams:]
+		}
 
 		// Check variance constraints for variadic parameters
 		if variadicType != "" && isGenericTypeParameter(variadicType) && varianceMap != nil {
@@ -148,7 +182,9 @@ func bindParametersWithVariadic(env *common.Env, params []ast.Parameter, args []
 
 		// Resolve generic type parameter for variadic type
 		resolvedVariadicType := variadicType
-		if variadicType != "" && isGenericTypeParameter(variadicType) && genericTypes != nil {
+		if concreteType, found := methodTypes[variadicType]; found {
+			resolvedVariadicType = concreteType
+		} else if variadicType != "" && isGenericTypeParameter(variadicType) && genericTypes != nil {
 			if concreteType, found := genericTypes[variadicType]; found {
 				resolvedVariadicType = concreteType
 			}
@@ -166,8 +202,8 @@ func bindParametersWithVariadic(env *common.Env, params []ast.Parameter, args []
 			// For unresolved generic variadic types or wildcards, just bind the args as-is
 			env.Set(params[variadicParam].Name, variadicArgs)
 		}
-	} else if len(args) > requiredParams {
-		return ThrowArityError((*Env)(env), requiredParams, len(args))
+	} else if len(args) > positionalParams {
+		return ThrowArityError((*Env)(env), positionalParams, len(args))
 	}
 
 	return nil
@@ -203,6 +239,13 @@ func extractVarianceFromGenericTypes(classInst *common.ClassInstance, genericTyp
 			concreteType := gt.Bounds[0].Name.Name
 			variance := typeParam.Bounds[0].Variance
 
+			// Capture a bounded wildcard: values written through T must satisfy its bound
+			if concreteType == "?" {
+				if _, bound := captureWildcard(gt.Bounds[0]); bound != "" {
+					concreteType = bound
+				}
+			}
+
 			// Only update maps if we have valid names
 			if paramName != "" && concreteType != "" {
 				genericTypes[paramName] = concreteType
@@ -237,6 +280,37 @@ func EvalWithContext(prog *ast.Program, opts Options, fileName, packageName stri
 }
 
 func EvalWithContextAndSource(prog *ast.Program, opts Options, fileName, packageName, source string) (any, error) {
+	env := newProgramEnv(opts, fileName, packageName, source)
+	installHooks(env, opts, fileName, prog)
+	return runProgram(env, prog)
+}
+
+// Session evaluates programs one after another in a shared top-level environment,
+// so variables, functions and classes defined by one program stay visible to the next.
+// The REPL evaluates each entry in the same Session.
+type Session struct {
+	env  *common.Env
+	opts Options
+}
+
+// NewSession creates a Session with all builtins installed
+func NewSession(opts Options) *Session {
+	return &Session{env: newProgramEnv(opts, "", "", ""), opts: opts}
+}
+
+// Eval runs prog in the session's environment and returns the value of its last statement
+func (s *Session) Eval(prog *ast.Program) (any, error) {
+	installHooks(s.env, s.opts, "", prog)
+	return runProgram(s.env, prog)
+}
+
+// Format renders a value the way println does
+func (s *Session) Format(v any) string {
+	return utils.ToStringWithEnv(v, s.env)
+}
+
+// newProgramEnv creates the top-level environment of a program with all builtins installed
+func newProgramEnv(opts Options, fileName, packageName, source string) *common.Env {
 	var env *common.Env
 	if fileName != "" {
 		env = common.NewEnvWithContext(fileName, packageName)
@@ -250,7 +324,11 @@ func EvalWithContextAndSource(prog *ast.Program, opts Options, fileName, package
 		env.SetSourceLines(sourceLines)
 	}
 
+	env.Hooks = &hookSlot{}
 	installBuiltins(env, opts)
+	if opts.Sandbox {
+		env.Set("$sandbox", true)
+	}
 	InstallSysModule(env, opts)         // Install enhanced Sys module
 	InstallMathModule(env)              // Install Math module
 	InstallExceptionBuiltins(env)       // Install exception system
@@ -268,11 +346,19 @@ func EvalWithContextAndSource(prog *ast.Program, opts Options, fileName, package
 		env.Set("$package", packageName)                                                                     // e.g., "src"
 		env.Set("$stem", strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(filepath.Base(fileName)))) // e.g., "main"
 	}
+	return env
+}
 
+// runProgram evaluates the top-level statements of prog in env
+func runProgram(env *common.Env, prog *ast.Program) (any, error) {
 	var last any
 	for _, st := range prog.Stmts {
 		v, ret, err := evalStmtWithSource(env, st, env.GetSourceLines())
 		if err != nil {
+			var exc *HyException
+			if errors.As(err, &exc) && len(exc.StackTrace) > 0 {
+				addStackFrame(err, "<main>")
+			}
 			return nil, err
 		}
 		if ret {
@@ -288,7 +374,53 @@ func evalStmtWithSource(env *common.Env, st ast.Stmt, sourceLines []string) (val
 	return evalStmt(env, st)
 }
 
+// evalStmt evaluates a statement, keeping env's current position in sync with the
+// statement being executed so runtime errors report the line that failed.
 func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error) {
+	hooks := hooksOf(env)
+	if located, ok := st.(ast.Locatable); ok {
+		if pos := located.StartPos(); pos.Line > 0 {
+			env.CurrentLine = pos.Line
+			env.CurrentColumn = pos.Col
+			if hooks != nil && hooks.coverage != nil {
+				hooks.coverage.hit(env.GetFileName(), pos.Line)
+			}
+		}
+	}
+	if hooks != nil {
+		if hooks.debugger != nil {
+			if err := hooks.debugger.beforeStmt(env, st); err != nil {
+				return nil, false, err
+			}
+		}
+		if hooks.limits != nil {
+			if err := hooks.limits.step(env); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	val, returned, err = evalStmtNode(env, st)
+	if err != nil {
+		annotateErrorPosition(env, err)
+		noteTracePosition(env, err)
+	}
+	return val, returned, err
+}
+
+// annotateErrorPosition fills in the location of exceptions that were raised without an env
+func annotateErrorPosition(env *common.Env, err error) {
+	var exc *HyException
+	if !errors.As(err, &exc) || exc.Line != 0 || env.CurrentLine == 0 {
+		return
+	}
+	exc.Line = env.CurrentLine
+	exc.Column = env.CurrentColumn
+	if exc.File == "" {
+		exc.File = env.GetFileName()
+	}
+}
+
+func evalStmtNode(env *common.Env, st ast.Stmt) (val any, returned bool, err error) {
 	switch s := st.(type) {
 	case *ast.ImportStmt:
 		err := handleImport(env, s)
@@ -342,122 +474,116 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 			return nil, false, err
 		}
 
-		instance, ok := it.(*ClassInstance)
-		if !ok {
-			return nil, false, ThrowTypeError(env, "iterable", it)
-		}
+		// Arrays, Tuples and plain Go slices that leak out of builtins (e.g. variadic
+		// arguments) are iterated directly, bypassing the Iterable interface
+		if items, ok := forInItems(it); ok {
+			useDestructuring := len(s.Names) > 1
+			for _, el := range items {
+				if useDestructuring {
+					// Destructuring logic
+					switch elVal := el.(type) {
+					case *ClassInstance:
+						unstructuredInterfaceDef := common.BuiltinInterfaceUnstructured.GetInterfaceDefinition(env)
+						isUnstructured := elVal.ParentClass != nil &&
+							elVal.ParentClass.ImplementsInterface(unstructuredInterfaceDef)
+
+						if isUnstructured {
+							piecesFunc, _ := common.ExtractFunc(elVal.Methods["__pieces"])
+							getPieceFunc, _ := common.ExtractFunc(elVal.Methods["__get_piece"])
+
+							numPiecesVal, err := piecesFunc(env, nil)
+							if err != nil {
+								return nil, false, err
+							}
+							numPieces, ok := utils.AsInt(numPiecesVal)
+							if !ok {
+								return nil, false, fmt.Errorf("pieces() must return integer")
+							}
 
-		// Optimization: Native iteration for Array, Tuple, and Range
-		// This bypasses the overhead of the Iterable interface and method calls
-		if instance.ClassName == "Array" || instance.ClassName == "Tuple" {
-			var items []any
-			if instance.ClassName == "Array" {
-				if val, ok := instance.Fields["_items"].([]any); ok {
-					items = val
-				}
-			} else {
-				if val, ok := instance.Fields["_elements"].([]any); ok {
-					items = val
-				}
-			}
+							if len(s.Names) != numPieces {
+								return nil, false, fmt.Errorf("destructuring mismatch: expected %d vars, got %d", len(s.Names), numPieces)
+							}
 
-			if items != nil {
-				useDestructuring := len(s.Names) > 1
-				for _, el := range items {
-					if useDestructuring {
-						// Destructuring logic
-						switch elVal := el.(type) {
-						case *ClassInstance:
-							unstructuredInterfaceDef := common.BuiltinInterfaceUnstructured.GetInterfaceDefinition(env)
-							isUnstructured := elVal.ParentClass != nil &&
-								elVal.ParentClass.ImplementsInterface(unstructuredInterfaceDef)
-
-							if isUnstructured {
-								piecesFunc, _ := common.ExtractFunc(elVal.Methods["__pieces"])
-								getPieceFunc, _ := common.ExtractFunc(elVal.Methods["__get_piece"])
-
-								numPiecesVal, err := piecesFunc(env, nil)
+							for i, name := range s.Names {
+								piece, err := getPieceFunc(env, []any{i})
 								if err != nil {
 									return nil, false, err
 								}
-								numPieces, ok := utils.AsInt(numPiecesVal)
-								if !ok {
-									return nil, false, fmt.Errorf("pieces() must return integer")
-								}
-
-								if len(s.Names) != numPieces {
-									return nil, false, fmt.Errorf("destructuring mismatch: expected %d vars, got %d", len(s.Names), numPieces)
-								}
-
-								for i, name := range s.Names {
-									piece, err := getPieceFunc(env, []any{i})
-									if err != nil {
-										return nil, false, err
-									}
-									env.Set(name, piece)
-								}
-							} else {
-								// Fallback: no Unstructured interface
-								for i, name := range s.Names {
-									if i == 0 {
-										env.Set(name, elVal)
-									} else {
-										env.Set(name, nil)
-									}
-								}
+								env.Set(name, piece)
 							}
-						case []any:
-							for i, name := range s.Names {
-								if i < len(elVal) {
-									env.Set(name, elVal[i])
-								} else {
-									env.Set(name, nil)
-								}
-							}
-						default:
+						} else {
+							// Fallback: no Unstructured interface
 							for i, name := range s.Names {
 								if i == 0 {
-									env.Set(name, el)
+									env.Set(name, elVal)
 								} else {
 									env.Set(name, nil)
 								}
 							}
 						}
-					} else {
-						varName := s.Name
-						if len(s.Names) > 0 {
-							varName = s.Names[0]
-						}
-						env.Set(varName, el)
-					}
-
-					// Optional where clause
-					if s.Where != nil {
-						whereResult, err := evalExpr(env, s.Where)
-						if err != nil {
-							return nil, false, err
+					case []any:
+						for i, name := range s.Names {
+							if i < len(elVal) {
+								env.Set(name, elVal[i])
+							} else {
+								env.Set(name, nil)
+							}
 						}
-						if !utils.AsBool(whereResult) {
-							continue
+					default:
+						for i, name := range s.Names {
+							if i == 0 {
+								env.Set(name, el)
+							} else {
+								env.Set(name, nil)
+							}
 						}
 					}
+				} else {
+					varName := s.Name
+					if len(s.Names) > 0 {
+						varName = s.Names[0]
+					}
+					env.Set(varName, el)
+				}
 
-					brk, cont, ret, val, err := runBlock(env, s.Body)
+				// Optional where clause
+				if s.Where != nil {
+					whereResult, err := evalExpr(env, s.Where)
 					if err != nil {
 						return nil, false, err
 					}
-					if ret {
-						return val, true, nil
-					}
-					if brk {
-						break
-					}
-					if cont {
+					if !utils.AsBool(whereResult) {
 						continue
 					}
 				}
-				return nil, false, nil
+
+				brk, cont, ret, val, err := runBlock(env, s.Body)
+				if err != nil {
+					return nil, false, err
+				}
+				if ret {
+					return val, true, nil
+				}
+				if brk {
+					break
+				}
+				if cont {
+					continue
+				}
 			}
+			return nil, false, nil
+		}
+
+		instance, ok := it.(*ClassInstance)
+		if !ok {
+			return nil, false, ThrowTypeError(env, "an iterable value", it)
+		}
+
+		// Generators and other values without a length are read one at a time
+		hasNextFn, _ := common.ExtractFunc(instance.Methods["__hasNext"])
+		nextFn, _ := common.ExtractFunc(instance.Methods["__next"])
+		if hasNextFn != nil && nextFn != nil {
+			return forInIterator(env, s, instance, hasNextFn, nextFn)
 		}
 
 		if instance.ClassName == "Range" {
@@ -533,7 +659,7 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 		}
 
 		if !instance.ParentClass.ImplementsInterface(iterableInterfaceDef) {
-			return nil, false, fmt.Errorf("object of type %s does not implement Iterable", instance.ClassName)
+			return nil, false, ThrowTypeError(env, "an iterable value", instance)
 		}
 
 		// Pre-resolve __length() and __get()
@@ -545,6 +671,13 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 		if !ok {
 			return nil, false, fmt.Errorf("Iterable missing valid __get()")
 		}
+		// Collections that are also keyed (Map) iterate through __entry() instead.
+		// Entries are Pairs: a single loop variable gets the key, two get key and value.
+		byEntry := false
+		if entryFunc, ok := instance.Methods["__entry"]; ok && entryFunc != nil {
+			__getFunc = entryFunc
+			byEntry = true
+		}
 
 		lengthVal, err := __lengthFunc(env, nil)
 		if err != nil {
@@ -562,6 +695,9 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 			if err != nil {
 				return nil, false, err
 			}
+			if byEntry && !useDestructuring {
+				el = entryKey(el)
+			}
 
 			if useDestructuring {
 				switch elVal := el.(type) {
@@ -821,11 +957,24 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 			return v, false, nil
 		}
 
+		// Generic declarations are checked for assignability, honoring variance
+		if typeName := ast.GetTypeNameString(s.Type); strings.Contains(typeName, "<") {
+			if err := checkAssignable(env, typeName, v); err != nil {
+				return nil, false, err
+			}
+			env.DeclareType(s.Name, typeName)
+		}
+
 		// Single variable assignment (backward compatible)
 		env.Define(s.Name, v, s.Kind)
 		return v, false, nil
 	case *ast.AssignStmt:
 		// Handle assignment statements like x = value or this.field = value
+		if s.Compound {
+			if s, err = hoistCompoundTarget(env, s); err != nil {
+				return nil, false, err
+			}
+		}
 		value, err := evalExpr(env, s.Value)
 		if err != nil {
 			return nil, false, err
@@ -843,6 +992,11 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 					if cur.Consts[target.Name] {
 						return nil, false, ThrowRuntimeError(env, fmt.Sprintf("cannot assign to constant '%s'", target.Name))
 					}
+					if typeName, declared := cur.Types[target.Name]; declared {
+						if err := checkAssignable(env, typeName, value); err != nil {
+							return nil, false, err
+						}
+					}
 					cur.Vars[target.Name] = value
 					return value, false, nil
 				}
@@ -878,31 +1032,14 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 			if instance, ok := obj.(*ClassInstance); ok {
 				// Special handling for Map instances - set data in _data map
 				if instance.ClassName == "Map" {
-					if hashData, ok := instance.Fields["_data"].(map[uint64][]*mapEntry); ok {
-						hash := hashValue(env, target.Name)
-						// Check if hash already exists
-						if entries, exists := hashData[hash]; exists {
-							// Look for existing key
-							found := false
-							for i, entry := range entries {
-								if equals(entry.Key, target.Name) {
-									hashData[hash][i] = &mapEntry{Key: target.Name, Value: value}
-									found = true
-									break
-								}
-							}
-							if !found {
-								hashData[hash] = append(entries, &mapEntry{Key: target.Name, Value: value})
-							}
-						} else {
-							hashData[hash] = []*mapEntry{{Key: target.Name, Value: value}}
-						}
+					if _, ok := instance.Fields["_data"].(map[uint64][]*mapEntry); ok {
+						setMapEntry(env, instance, target.Name, value)
 						return value, false, nil
 					}
 				}
 
 				//cur.Parent.Vars get any of Final and check if is a ClassInstance and if its the same class
-				if instance.Fields[target.Name] == nil {
+				if instance.Fields[target.Name] == nil || cur.Parent == nil {
 					instance.Fields[target.Name] = value
 				} else {
 					for k := range cur.Parent.Finals {
@@ -925,7 +1062,7 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 
 			} else if recordVal, ok := obj.(*common.RecordInstance); ok {
 
-				if recordVal.Values[target.Name] == nil {
+				if recordVal.Values[target.Name] == nil || cur.Parent == nil {
 					recordVal.Values[target.Name] = value
 				} else {
 					for k := range cur.Parent.Finals {
@@ -979,7 +1116,7 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 				}
 				method := common.SelectMethodOverload(setOverloads, 2)
 				if method == nil {
-					return nil, false, ThrowRuntimeError(env, fmt.Sprintf("no overload found for %s.__set with %d arguments", instance.ClassName, 2))
+					return nil, false, ThrowOverloadError(env, instance.ClassName, "__set", setOverloads, 2)
 				}
 				_, err = CallInstanceMethod(instance, *method, env, []any{index, value})
 				if err != nil {
@@ -1002,12 +1139,25 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 	case *ast.DefStmt:
 		// Check if this is a generic function
 		isGeneric := len(s.TypeParams) > 0
+		// A function containing yield returns a Generator that runs the body lazily
+		isGenerator := containsYield(s.Body)
 
 		// Capture current env for closure
+		CaptureEnv(env)
 		fn := common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			hooks := hooksForCall(callEnv, env)
+			if hooks != nil && hooks.limits != nil {
+				if err := hooks.limits.enter(); err != nil {
+					return nil, err
+				}
+				defer hooks.limits.leave()
+			}
 			// Use pooled environment for better performance (2-3x faster function calls)
 			local := GetPooledEnv(env)
 			defer ReleaseEnv(local)
+			if hooks != nil {
+				local.Hooks = hooks
+			}
 
 			// For generic functions, we need to handle type parameters
 			// In a simple implementation, we just make them available as types in the local scope
@@ -1025,6 +1175,9 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 			if err != nil {
 				return nil, err
 			}
+			if isGenerator {
+				return newGenerator(local, s.Name, local, s.Body)
+			}
 
 			// Ensure defers are executed even if function returns early or errors
 			defer func() {
@@ -1038,7 +1191,7 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 			for _, st := range s.Body {
 				v, ret, err := evalStmt(local, st)
 				if err != nil {
-					return nil, err
+					return nil, addStackFrame(err, s.Name)
 				}
 				if ret {
 					return v, nil
@@ -1049,7 +1202,9 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 
 		// Infer return type if not explicitly specified
 		returnType := s.ReturnType
-		if returnType == nil {
+		if returnType == nil && isGenerator {
+			returnType = &ast.Type{Name: "Generator"}
+		} else if returnType == nil {
 			returnType = common.InferReturnType(s.Body, env)
 		}
 		// Wrap function in FunctionDefinition with metadata
@@ -1081,11 +1236,16 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 		// Create and register the alias
 		alias := &TypeAlias{
 			Name:        s.Name,
+			TypeParams:  s.TypeParams,
 			BaseType:    s.BaseType,
 			IsFinal:     s.IsFinal,
 			PackageName: packageName,
 		}
 		typeAliasRegistry[packageName][s.Name] = alias
+		if cycle := typeAliasCycle(s.Name); cycle != nil {
+			delete(typeAliasRegistry[packageName], s.Name)
+			return nil, false, ThrowRuntimeError(env, fmt.Sprintf("type alias '%s' is circular: %s", s.Name, strings.Join(cycle, " -> ")))
+		}
 
 		return nil, false, nil
 	case *ast.InterfaceDecl:
@@ -1106,6 +1266,10 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 		return evalThrowStmt(env, s)
 	case *ast.DeferStmt:
 		return evalDeferStmt(env, s)
+	case *ast.YieldStmt:
+		return evalYieldStmt(env, s)
+	case *ast.AssertStmt:
+		return evalAssertStmt(env, s)
 	case *ast.SelectStmt:
 		return evalSelectStmt(env, s)
 	case *ast.SwitchStmt:
@@ -1115,18 +1279,128 @@ func evalStmt(env *common.Env, st ast.Stmt) (val any, returned bool, err error)
 	}
 }
 
+// forInItems returns the elements of values a for-in loop iterates directly:
+// Arrays, Tuples and plain Go slices
+func forInItems(value any) ([]any, bool) {
+	switch v := value.(type) {
+	case []any:
+		return v, true
+	case *ClassInstance:
+		switch v.ClassName {
+		case "Array":
+			items, ok := v.Fields["_items"].([]any)
+			return items, ok && items != nil
+		case "Tuple":
+			items, ok := v.Fields["_elements"].([]any)
+			return items, ok && items != nil
+		}
+	}
+	return nil, false
+}
+
+// entryKey returns the key of a Map entry produced by __entry()
+func entryKey(entry any) any {
+	switch e := entry.(type) {
+	case *ClassInstance:
+		if key, ok := e.Fields["key"]; ok {
+			return key
+		}
+	case []any:
+		if len(e) > 0 {
+			return e[0]
+		}
+	}
+	return entry
+}
+
+// hoistCompoundTarget evaluates the object and index of a compound assignment
+// target once, so `next().count += 1` or `arr[i()] += 1` reads and writes the
+// same element without repeating side effects. Plain identifiers are left as-is.
+func hoistCompoundTarget(env *Env, s *ast.AssignStmt) (*ast.AssignStmt, error) {
+	hoist := func(e ast.Expr) (ast.Expr, error) {
+		switch e.(type) {
+		case *ast.Ident, *ast.NumberLit, *ast.StringLit, *ast.ValueExpr:
+			return e, nil
+		}
+		v, err := evalExpr(env, e)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ValueExpr{Value: v}, nil
+	}
+
+	var target ast.Expr
+	switch t := s.Target.(type) {
+	case *ast.FieldExpr:
+		x, err := hoist(t.X)
+		if err != nil {
+			return nil, err
+		}
+		target = &ast.FieldExpr{Spanned: t.Spanned, X: x, Name: t.Name}
+	case *ast.IndexExpr:
+		x, err := hoist(t.X)
+		if err != nil {
+			return nil, err
+		}
+		index, err := hoist(t.Index)
+		if err != nil {
+			return nil, err
+		}
+		target = &ast.IndexExpr{Spanned: t.Spanned, X: x, Index: index}
+	default:
+		return s, nil
+	}
+
+	value := *s.Value.(*ast.BinaryExpr)
+	value.Lhs = target
+	return &ast.AssignStmt{Located: s.Located, Target: target, Value: &value, Pos: s.Pos, Compound: true}, nil
+}
+
+// optionalCallee evaluates the receiver of `recv?.method(...)` once. It reports
+// nilReceiver when recv is nil; otherwise it returns the callee to evaluate, with
+// a receiver that has side effects already resolved.
+func optionalCallee(env *Env, field *ast.FieldExpr) (callee ast.Expr, nilReceiver bool, err error) {
+	if ident, ok := field.X.(*ast.Ident); ok {
+		// Identifiers may name classes, which are resolved by the FieldExpr case itself
+		v, found := env.Get(ident.Name)
+		return field, found && v == nil, nil
+	}
+	receiver, err := evalExpr(env, field.X)
+	if err != nil || receiver == nil {
+		return nil, receiver == nil, err
+	}
+	return &ast.FieldExpr{Spanned: field.Spanned, X: &ast.ValueExpr{Value: receiver}, Name: field.Name}, false, nil
+}
+
+// evalSpread evaluates the array behind ...x and returns its elements
+func evalSpread(env *Env, spread *ast.SpreadExpr) ([]any, error) {
+	v, err := evalExpr(env, spread.X)
+	if err != nil {
+		return nil, err
+	}
+	switch value := v.(type) {
+	case []any:
+		return value, nil
+	case *ClassInstance:
+		if items, ok := value.Fields["_items"].([]any); ok && value.ClassName == "Array" {
+			return items, nil
+		}
+	}
+	return nil, ThrowTypeError(env, "Array to spread", v)
+}
+
 // installBuiltins populates env with standard namespaces and functions.
 func installBuiltins(env *common.Env, opts Options) {
 	out := opts.Stdout
 	if out == nil {
 		out = io.Discard
 	}
-	env.Set("print", common.Func(func(_ *common.Env, args []any) (any, error) {
+	env.Set("print", common.Func(func(callEnv *common.Env, args []any) (any, error) {
 		for i, a := range args {
 			if i > 0 {
 				fmt.Fprint(out, " ")
 			}
-			fmt.Fprint(out, utils.ToString(a))
+			fmt.Fprint(out, utils.ToStringWithEnv(a, callEnv))
 		}
 		return nil, nil
 	}))
@@ -1141,6 +1415,31 @@ func installBuiltins(env *common.Env, opts Options) {
 		return nil, nil
 	}))
 
+	// input(prompt?) - print the prompt and read one line; nil once the input is exhausted
+	var in *bufio.Reader
+	if opts.Stdin != nil {
+		in = bufio.NewReader(opts.Stdin)
+	}
+	env.Set("input", common.Func(func(e *common.Env, args []any) (any, error) {
+		if len(args) > 1 {
+			return nil, ThrowArityError((*Env)(e), 1, len(args))
+		}
+		if len(args) == 1 {
+			fmt.Fprint(out, utils.ToStringWithEnv(args[0], e))
+		}
+		if in == nil {
+			return nil, nil
+		}
+		line, err := in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if err == io.EOF && line == "" {
+			return nil, nil
+		}
+		return CreateStringInstance(e, strings.TrimSpace(line))
+	}))
+
 	env.Set("int", common.Func(func(e *common.Env, args []any) (any, error) {
 		if len(args) != 1 {
 			return nil, ThrowArityError((*Env)(e), 1, len(args))
@@ -1165,7 +1464,7 @@ func installBuiltins(env *common.Env, opts Options) {
 		if len(args) != 1 {
 			return nil, ThrowArityError((*Env)(e), 1, len(args))
 		}
-		return CreateStringInstance(e, utils.ToString(args[0]))
+		return CreateStringInstance(e, utils.ToStringWithEnv(args[0], e))
 	}))
 	env.Set("bool", common.Func(func(e *common.Env, args []any) (any, error) {
 		if len(args) != 1 {
@@ -1175,19 +1474,25 @@ func installBuiltins(env *common.Env, opts Options) {
 		return CreateBoolInstance(e, b)
 	}))
 
-	// len() - get length of string, array (ClassInstance), or map (ClassInstance)
+	// len() - get length of string, array (ClassInstance), map (ClassInstance), range or channel.
+	// String, Array and Map lengths are Floats and a String's length counts bytes,
+	// as in earlier releases; Range and Channel lengths are Ints.
 	env.Set("len", common.Func(func(e *common.Env, args []any) (any, error) {
 		if len(args) != 1 {
 			return nil, ThrowArityError((*Env)(e), 1, len(args))
 		}
 		switch v := args[0].(type) {
 		case string:
-			return float64(len(v)), nil
+			return CreateFloatInstance(e, float64(len(v)))
 		case *ClassInstance:
 			// For Array and Map ClassInstances, use their length() method
-			if v.ClassName == "Array" {
+			if v.ClassName == "String" {
+				if s, ok := v.Fields["_value"].(string); ok {
+					return CreateFloatInstance(e, float64(len(s)))
+				}
+			} else if v.ClassName == "Array" {
 				if items, ok := v.Fields["_items"].([]any); ok {
-					return CreateFloatInstance(env, float64(len(items)))
+					return CreateFloatInstance(e, float64(len(items)))
 				}
 			} else if v.ClassName == "Map" {
 				if data, ok := v.Fields["_data"].(map[uint64][]*mapEntry); ok {
@@ -1195,12 +1500,20 @@ func installBuiltins(env *common.Env, opts Options) {
 					for _, entries := range data {
 						size += len(entries)
 					}
-					return CreateFloatInstance(env, float64(size))
+					return CreateFloatInstance(e, float64(size))
+				}
+			} else if v.ClassName == "Range" {
+				// Computed arithmetically; the range is never materialized
+				return CreateIntInstance(e, rangeLength(v))
+			} else if v.ClassName == "Channel" {
+				// Number of values waiting in the buffer
+				if ch, ok := v.Fields["_channel"].(*common.Channel); ok {
+					return CreateIntInstance(e, ch.Len())
 				}
 			}
-			return nil, ThrowTypeError(e, "string, array, or map", args[0])
+			return nil, ThrowTypeError(e, "string, array, map, range, or channel", args[0])
 		default:
-			return nil, ThrowTypeError(e, "string, array, or map", args[0])
+			return nil, ThrowTypeError(e, "string, array, map, range, or channel", args[0])
 		}
 	}))
 
@@ -1209,7 +1522,7 @@ func installBuiltins(env *common.Env, opts Options) {
 		if len(args) != 1 {
 			return nil, ThrowArityError((*Env)(e), 1, len(args))
 		}
-		return utils.ToString(args[0]), nil
+		return utils.ToStringWithEnv(args[0], e), nil
 	}))
 
 	env.Set("range", common.Func(func(e *common.Env, args []any) (any, error) {
@@ -1260,9 +1573,27 @@ func installBuiltins(env *common.Env, opts Options) {
 		return CreateRangeInstance((*Env)(e), start, end, step)
 	}))
 
-	// Install Net module
-	InstallNetModule(env, opts)
-	InstallHttpModule(env, opts)
+	// partial(fn, args...) - fn with its first arguments pre-bound
+	env.Set("partial", common.Func(func(e *common.Env, args []any) (any, error) {
+		if len(args) < 1 {
+			return nil, ThrowArityError((*Env)(e), 1, len(args))
+		}
+		return partialFunction((*Env)(e), args[0], args[1:])
+	}))
+
+	// curry(fn) - fn taking its arguments one or more at a time
+	env.Set("curry", common.Func(func(e *common.Env, args []any) (any, error) {
+		if len(args) != 1 {
+			return nil, ThrowArityError((*Env)(e), 1, len(args))
+		}
+		return curryFunction((*Env)(e), args[0])
+	}))
+
+	// Install Net module; a sandbox gets no network access
+	if !opts.Sandbox {
+		InstallNetModule(env, opts)
+		InstallHttpModule(env, opts)
+	}
 
 	// Install Int and Float builtins as classes (so other types can reference them)
 	if err := InstallNumberBuiltin((*Env)(env)); err != nil {
@@ -1301,8 +1632,10 @@ func installBuiltins(env *common.Env, opts Options) {
 	if err := InstallBytesBuiltin(env); err != nil {
 		fmt.Printf("Warning: Failed to install Bytes builtin: %v\n", err)
 	}
-	if err := InstallSocketsModule(env, opts); err != nil {
-		fmt.Printf("Warning: Failed to install Sockets module: %v\n", err)
+	if !opts.Sandbox {
+		if err := InstallSocketsModule(env, opts); err != nil {
+			fmt.Printf("Warning: Failed to install Sockets module: %v\n", err)
+		}
 	}
 
 	// Install MapEntry builtin class
@@ -1354,15 +1687,75 @@ func installBuiltins(env *common.Env, opts Options) {
 	if err := InstallDequeBuiltin((*Env)(env)); err != nil {
 		fmt.Printf("Warning: Failed to install Deque builtin: %v\n", err)
 	}
+
+	// Install Generator builtin (returned by functions that contain yield)
+	if err := InstallGeneratorBuiltin((*Env)(env)); err != nil {
+		fmt.Printf("Warning: Failed to install Generator builtin: %v\n", err)
+	}
+
+	// Install serialize() (needs Array and Map)
+	if err := InstallSerializeBuiltins((*Env)(env)); err != nil {
+		fmt.Printf("Warning: Failed to install serialize builtin: %v\n", err)
+	}
+
+	// Install expect() matchers
+	if err := InstallExpectBuiltins((*Env)(env)); err != nil {
+		fmt.Printf("Warning: Failed to install expect: %v\n", err)
+	}
+
+	// Install describe() and it() for grouped tests
+	if err := InstallDescribeBuiltins((*Env)(env)); err != nil {
+		fmt.Printf("Warning: Failed to install describe: %v\n", err)
+	}
 	//install crypt
 	if err := InstallCryptoModule(env, opts); err != nil {
 		fmt.Printf("Warning: Failed to install Crypto module: %v\n", err)
 	}
 
 	//install InstallIOModule
-	if err := InstallIOModule(env, opts); err != nil {
-		fmt.Printf("Warning: Failed to install IO module: %v\n", err)
+	if !opts.Sandbox {
+		if err := InstallIOModule(env, opts); err != nil {
+			fmt.Printf("Warning: Failed to install IO module: %v\n", err)
+		}
+	}
+
+	// Install Assets module (files embedded by polyloft build)
+	if err := InstallAssetsModule(env, opts); err != nil {
+		fmt.Printf("Warning: Failed to install Assets module: %v\n", err)
 	}
+
+	// Install JSON module (parse/stringify)
+	if err := InstallJSONModule(env, opts); err != nil {
+		fmt.Printf("Warning: Failed to install JSON module: %v\n", err)
+	}
+
+	// Install Regex class (compiled Go regexp)
+	if err := InstallRegexBuiltin((*Env)(env)); err != nil {
+		fmt.Printf("Warning: Failed to install Regex class: %v\n", err)
+	}
+
+	// Install Time class (timestamps, formatting and sleep)
+	if err := InstallTimeModule((*Env)(env)); err != nil {
+		fmt.Printf("Warning: Failed to install Time module: %v\n", err)
+	}
+
+	// Install Process class (running external commands)
+	if !opts.Sandbox {
+		if err := InstallProcessModule((*Env)(env)); err != nil {
+			fmt.Printf("Warning: Failed to install Process module: %v\n", err)
+		}
+	}
+
+	// Install Random class (shuffling, sampling and distributions)
+	if err := InstallRandomModule((*Env)(env)); err != nil {
+		fmt.Printf("Warning: Failed to install Random module: %v\n", err)
+	}
+
+	// Install Terminal class (ANSI styling for terminal output)
+	if err := InstallTerminalModule((*Env)(env), opts); err != nil {
+		fmt.Printf("Warning: Failed to install Terminal module: %v\n", err)
+	}
+
 	// Initialize the unified type converter registry (after all types are installed)
 	InitializeBuiltinTypeConverters()
 	// Initialize instance creators (after types are installed)
@@ -1389,20 +1782,55 @@ var moduleCache = map[string]map[string]any{}
 
 // handleImport loads a module from libs/ or src/ and binds exported names.
 func handleImport(env *common.Env, im *ast.ImportStmt) error {
-	// resolve path to .pf file; try libs first then src
-	rel := filepath.Join(im.Path...)
 	// Builtin registry first
 	if ctor, ok := builtinModules[strings.Join(im.Path, ".")]; ok {
 		symbols := ctor()
 		return bindImports(env, im, symbols)
 	}
 
-	homeDir, _ := os.UserHomeDir()
+	rel := filepath.Join(im.Path...)
+	modules := modulesOf(env)
+	modKey := resolveModule(modules, env.FileName, rel)
+	if modKey == "" {
+		return ThrowRuntimeError(env, fmt.Sprintf("module not found: %s", rel))
+	}
+
+	if cached, ok := moduleCache[modKey]; ok {
+		return bindImports(env, im, cached)
+	}
+
+	// Load and eval
+	symbols := map[string]any{}
+	files := []string{modKey}
+	if strings.HasSuffix(modKey, string(os.PathSeparator)) {
+		// directory: load all .pf files and merge exports
+		files = modules.dirFiles(strings.TrimSuffix(modKey, string(os.PathSeparator)))
+	}
+	for _, fp := range files {
+		m, err := loadModuleFile(fp, env)
+		if err != nil {
+			return err
+		}
+		for k, v := range m {
+			symbols[k] = v
+		}
+	}
+	moduleCache[modKey] = symbols
+	return bindImports(env, im, symbols)
+}
+
+// resolveModule finds the module rel (a path such as "math/vector") imported
+// from fileName: a .pf file, or a directory of them, marked by a trailing
+// separator. Relative imports are tried first, then libs/ and src/, then the
+// global library directories, looking in modules when it is not nil. It returns
+// "" when there is no such module.
+func resolveModule(modules ModuleBundle, fileName, rel string) string {
+	homeDir := modules.home()
 	candidates := []string{}
 
 	// If we have a current file context, try relative imports from current directory first
-	if env.FileName != "" {
-		currentDir := filepath.Dir(env.FileName)
+	if fileName != "" {
+		currentDir := filepath.Dir(fileName)
 		// Relative import: import from same directory or subdirectory
 		candidates = append(candidates,
 			filepath.Join(currentDir, rel+".pf"),                     // same directory: helper.pf
@@ -1432,65 +1860,18 @@ func handleImport(env *common.Env, im *ast.ImportStmt) error {
 			filepath.Join(globalSrc, rel, "index.pf"),
 		)
 	}
-	var modKey string
 	for _, cand := range candidates {
-		if fi, err := os.Stat(cand); err == nil {
-			if fi.IsDir() {
-				continue
-			}
-			modKey = cand
-			break
+		if isDir, ok := modules.stat(cand); ok && !isDir {
+			return cand
 		}
 	}
-	if modKey == "" {
-		// Try directory with multiple .pf files
-		dir := filepath.Join("libs", rel)
-		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
-			modKey = dir + string(os.PathSeparator)
-		} else {
-			dir = filepath.Join("src", rel)
-			if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
-				modKey = dir + string(os.PathSeparator)
-			}
+	// Try directory with multiple .pf files
+	for _, dir := range []string{filepath.Join("libs", rel), filepath.Join("src", rel)} {
+		if isDir, ok := modules.stat(dir); ok && isDir {
+			return dir + string(os.PathSeparator)
 		}
 	}
-	if modKey == "" {
-		return ThrowRuntimeError(env, fmt.Sprintf("module not found: %s", rel))
-	}
-
-	if cached, ok := moduleCache[modKey]; ok {
-		return bindImports(env, im, cached)
-	}
-
-	// Load and eval
-	symbols := map[string]any{}
-	if strings.HasSuffix(modKey, string(os.PathSeparator)) {
-		// directory: load all .pf files and merge exports
-		entries, _ := os.ReadDir(strings.TrimSuffix(modKey, string(os.PathSeparator)))
-		for _, e := range entries {
-			if e.IsDir() || filepath.Ext(e.Name()) != ".pf" {
-				continue
-			}
-			fp := filepath.Join(strings.TrimSuffix(modKey, string(os.PathSeparator)), e.Name())
-			m, err := loadModuleFile(fp, env)
-			if err != nil {
-				return err
-			}
-			for k, v := range m {
-				symbols[k] = v
-			}
-		}
-	} else {
-		m, err := loadModuleFile(modKey, env)
-		if err != nil {
-			return err
-		}
-		for k, v := range m {
-			symbols[k] = v
-		}
-	}
-	moduleCache[modKey] = symbols
-	return bindImports(env, im, symbols)
+	return ""
 }
 
 func bindImports(env *common.Env, im *ast.ImportStmt, symbols map[string]any) error {
@@ -1618,7 +1999,7 @@ func isEnumAccessPermitted(env *common.Env, def *common.EnumDefinition) bool {
 // loadModuleFile parses and evaluates a .pf file, returning its exported symbols.
 // It inherits builtins from the parent environment to avoid re-creating them.
 func loadModuleFile(path string, parentEnv *common.Env) (map[string]any, error) {
-	b, err := os.ReadFile(path)
+	b, err := modulesOf(parentEnv).readFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -1629,6 +2010,9 @@ func loadModuleFile(path string, parentEnv *common.Env) (map[string]any, error)
 	if err != nil {
 		return nil, err
 	}
+	if hooks := hooksOf(parentEnv); hooks != nil && hooks.coverage != nil {
+		hooks.coverage.AddProgram(path, prog)
+	}
 	// evaluate program in a child env that inherits builtins from the parent
 	// This avoids re-creating builtin modules for each import
 	packageName := filepath.Dir(path)
@@ -1667,16 +2051,20 @@ func loadModuleFile(path string, parentEnv *common.Env) (map[string]any, error)
 	if err != nil {
 		return nil, err
 	}
-	// All vars in env are considered exported if declared with 'export' (not yet) or top-level names at end.
-	// For now, expose all top-level function definitions and classes produced by names set in env.
+	// A module that declares anything with 'export' only exposes those names.
+	// Otherwise every top-level name set in env is exposed.
 	// Important: File environment variables (starting with $) are NOT exported
 	// Respect access modifiers: private functions/classes are NOT exported
+	explicit := exportedNames(prog)
 	out := map[string]any{}
 	for k, v := range env.Vars {
 		// Skip file environment variables (they start with $)
 		if strings.HasPrefix(k, "$") {
 			continue
 		}
+		if explicit != nil && !explicit[k] {
+			continue
+		}
 
 		// Check if this is a FunctionDefinition and respect access level
 		if funcDef, ok := v.(*common.FunctionDefinition); ok {
@@ -1715,6 +2103,53 @@ func loadModuleFile(path string, parentEnv *common.Env) (map[string]any, error)
 	return out, nil
 }
 
+// exportedNames collects the top-level names declared with 'export', or nil when
+// the program has no export declarations
+func exportedNames(prog *ast.Program) map[string]bool {
+	var names map[string]bool
+	mark := func(name string) {
+		if names == nil {
+			names = make(map[string]bool)
+		}
+		names[name] = true
+	}
+	for _, st := range prog.Stmts {
+		switch decl := st.(type) {
+		case *ast.LetStmt:
+			if decl.Exported {
+				if len(decl.Names) > 0 {
+					for _, name := range decl.Names {
+						mark(name)
+					}
+				} else {
+					mark(decl.Name)
+				}
+			}
+		case *ast.DefStmt:
+			if decl.Exported {
+				mark(decl.Name)
+			}
+		case *ast.ClassDecl:
+			if decl.Exported {
+				mark(decl.Name)
+			}
+		case *ast.EnumDecl:
+			if decl.Exported {
+				mark(decl.Name)
+			}
+		case *ast.RecordDecl:
+			if decl.Exported {
+				mark(decl.Name)
+			}
+		case *ast.InterfaceDecl:
+			if decl.Exported {
+				mark(decl.Name)
+			}
+		}
+	}
+	return names
+}
+
 // evalProgramWithEnv runs statements into provided env using same evaluator.
 func evalProgramWithEnv(env *common.Env, prog *ast.Program) (any, error) {
 	var last any
@@ -1733,6 +2168,12 @@ func evalProgramWithEnv(env *common.Env, prog *ast.Program) (any, error) {
 
 // runBlock executes a list of statements with handling for break/continue.
 func runBlock(env *common.Env, body []ast.Stmt) (brk, cont, ret bool, val any, err error) {
+	// An empty loop body still uses up the step budget, or `loop: end` would run forever
+	if limits := limitsOf(env); limits != nil && len(body) == 0 {
+		if err := limits.step(env); err != nil {
+			return false, false, false, nil, err
+		}
+	}
 	for _, st := range body {
 		v, r, err := evalStmt(env, st)
 		if err != nil {
@@ -1759,7 +2200,42 @@ func createNumResult(env *common.Env, f float64) (any, error) {
 	return CreateFloatInstance(env, f)
 }
 
+// evalExpr evaluates an expression. When evaluation fails, the innermost expression
+// that carries a source span on the failing line is attached to the exception.
 func evalExpr(env *common.Env, e ast.Expr) (any, error) {
+	v, err := evalExprNode(env, e)
+	if err != nil {
+		annotateErrorSpan(env, e, err)
+	}
+	return v, err
+}
+
+// annotateErrorSpan records the source range of e on exceptions that don't have one yet
+func annotateErrorSpan(env *common.Env, e ast.Expr, err error) {
+	spanned, ok := e.(ast.Spannable)
+	if !ok {
+		return
+	}
+	var exc *HyException
+	if !errors.As(err, &exc) || exc.EndColumn != 0 {
+		return
+	}
+	from, to := spanned.Span()
+	if from.Line == 0 || (exc.Line != 0 && exc.Line != from.Line) {
+		return
+	}
+	if exc.File != "" && exc.File != env.GetFileName() {
+		return
+	}
+	exc.File = env.GetFileName()
+	exc.Line, exc.Column = from.Line, from.Col
+	exc.EndLine, exc.EndColumn = to.Line, to.Col
+	if lines := env.GetSourceLines(); from.Line <= len(lines) {
+		exc.SourceLine = lines[from.Line-1]
+	}
+}
+
+func evalExprNode(env *common.Env, e ast.Expr) (any, error) {
 	switch x := e.(type) {
 	case *ast.Ident:
 		v, ok := env.Get(x.Name)
@@ -1767,6 +2243,8 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 			return nil, ThrowNameError(env, x.Name)
 		}
 		return v, nil
+	case *ast.ValueExpr:
+		return x.Value, nil
 	case *ast.NumberLit:
 		switch v := x.Value.(type) {
 		case int:
@@ -1815,6 +2293,12 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 					}
 					arr = append(arr, intInstance)
 				}
+			} else if spread, ok := e.(*ast.SpreadExpr); ok {
+				items, err := evalSpread(env, spread)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, items...)
 			} else {
 				v, err := evalExpr(env, e)
 				if err != nil {
@@ -1831,18 +2315,18 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 		}
 		return arrayInstance, nil
 	case *ast.MapLit:
-		m := map[string]any{}
+		// Create a Map instance - builtin class must be available
+		mapInstance, err := CreateMapInstance(env, map[string]any{})
+		if err != nil {
+			return nil, err
+		}
+		// Entries are added in source order, which is the Map's iteration order
 		for _, p := range x.Pairs {
 			v, err := evalExpr(env, p.Value)
 			if err != nil {
 				return nil, err
 			}
-			m[p.Key] = v
-		}
-		// Create a Map instance - builtin class must be available
-		mapInstance, err := CreateMapInstance(env, m)
-		if err != nil {
-			return nil, err
+			putMapEntry(env, mapInstance, p.Key, ConvertMapValue(env, v))
 		}
 		return mapInstance, nil
 	case *ast.IndexExpr:
@@ -1883,7 +2367,7 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 				// Select the correct overload based on argument count (start, end)
 				method := common.SelectMethodOverload(methodOverloads, 2)
 				if method == nil {
-					return nil, ThrowRuntimeError(env, fmt.Sprintf("no overload found for %s.__slice with %d arguments", instance.ClassName, 2))
+					return nil, ThrowOverloadError(env, instance.ClassName, "__slice", methodOverloads, 2)
 				}
 				result, err := CallInstanceMethod(instance, *method, env, []any{start, end})
 				if err != nil {
@@ -1912,7 +2396,7 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 			// Select the correct overload based on argument count (index)
 			method := common.SelectMethodOverload(containsOverloads, 1)
 			if method == nil {
-				return nil, ThrowRuntimeError(env, fmt.Sprintf("no overload found for %s.__contains with %d arguments", instance.ClassName, 1))
+				return nil, ThrowOverloadError(env, instance.ClassName, "__contains", containsOverloads, 1)
 			}
 			result, err := CallInstanceMethod(instance, *method, env, []any{idx})
 			if err != nil {
@@ -1929,7 +2413,7 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 			// Select the correct overload based on argument count (index)
 			method = common.SelectMethodOverload(getOverloads, 1)
 			if method == nil {
-				return nil, ThrowRuntimeError(env, fmt.Sprintf("no overload found for %s.__get with %d arguments", instance.ClassName, 1))
+				return nil, ThrowOverloadError(env, instance.ClassName, "__get", getOverloads, 1)
 			}
 			result, err = CallInstanceMethod(instance, *method, env, []any{idx})
 			if err != nil {
@@ -1941,6 +2425,11 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 	case *ast.FieldExpr:
 		// Check if this is a static method call on a built-in type
 		if ident, ok := x.X.(*ast.Ident); ok {
+			if sandboxedModules[ident.Name] && isSandboxed(env) {
+				if _, defined := env.Get(ident.Name); !defined {
+					return nil, ThrowNameError(env, ident.Name)
+				}
+			}
 			// Check if it's a class static method or field access
 			if classDef, exists := lookupClass(ident.Name, env.GetPackageName()); exists {
 				// Check for static fields first
@@ -1949,12 +2438,13 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 				}
 				// Check for static methods (with overload support)
 				if methodOverloads, methodExists := classDef.Methods[x.Name]; methodExists {
+					methodOverloads = common.OverloadsOfKind(methodOverloads, true)
 					// Return a function wrapper that selects the right overload
 					return common.Func(func(callEnv *common.Env, args []any) (any, error) {
 						// Select appropriate method based on argument count
 						method := common.SelectMethodOverload(methodOverloads, len(args))
 						if method == nil {
-							return nil, ThrowRuntimeError((*Env)(callEnv), fmt.Sprintf("no overload found for %s.%s with %d arguments", classDef.Name, x.Name, len(args)))
+							return nil, ThrowOverloadError((*Env)(callEnv), classDef.Name, x.Name, methodOverloads, len(args))
 						}
 
 						// Check if the method is static
@@ -1964,6 +2454,9 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 
 						// Create a new environment for the static method
 						methodEnv := callEnv.Child()
+						if err := bindMethodTypeArgs(callEnv, methodEnv, *method, classDef.Name); err != nil {
+							return nil, err
+						}
 
 						// Bind parameters (including variadic) - validates and binds args
 						if method.Params != nil {
@@ -1981,12 +2474,21 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 						}
 
 						// Execute method body for non-builtin methods
+						if method.IsGenerator {
+							return newGenerator(methodEnv, classDef.Name+"."+x.Name, methodEnv, method.Body)
+						}
+						if limits := limitsOf(env); limits != nil {
+							if err := limits.enter(); err != nil {
+								return nil, err
+							}
+							defer limits.leave()
+						}
 						var result any
 						for _, stmt := range method.Body {
 							var err error
 							val, returned, err := evalStmt(methodEnv, stmt)
 							if err != nil {
-								return nil, err
+								return nil, addStackFrame(err, classDef.Name+"."+x.Name)
 							}
 							if returned {
 								result = val
@@ -2011,7 +2513,13 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+		if base == nil && x.Optional {
+			return nil, nil
+		}
 		switch b := base.(type) {
+		case common.Func, *common.FunctionDefinition, *common.LambdaDefinition:
+			// Functional interface methods (f.apply(x), f.andThen(g), p.negate(), ...)
+			return functionValueMethod(env, b, x.Name)
 		case *common.EnumConstructor:
 			// Access fields from the wrapped enum object
 			return b.EnumObject[x.Name], nil
@@ -2023,12 +2531,13 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 			}
 			// Check for static methods (with overload support)
 			if methodOverloads, methodExists := b.Methods[x.Name]; methodExists {
+				methodOverloads = common.OverloadsOfKind(methodOverloads, true)
 				// Return a function wrapper that selects the right overload
 				return common.Func(func(callEnv *common.Env, args []any) (any, error) {
 					// Select appropriate method based on argument count
 					method := common.SelectMethodOverload(methodOverloads, len(args))
 					if method == nil {
-						return nil, ThrowRuntimeError((*Env)(callEnv), fmt.Sprintf("no overload found for %s.%s with %d arguments", b.Name, x.Name, len(args)))
+						return nil, ThrowOverloadError((*Env)(callEnv), b.Name, x.Name, methodOverloads, len(args))
 					}
 
 					if !method.IsStatic {
@@ -2037,6 +2546,9 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 
 					// Create a new environment for the static method
 					methodEnv := callEnv.Child()
+					if err := bindMethodTypeArgs(callEnv, methodEnv, *method, b.Name); err != nil {
+						return nil, err
+					}
 
 					// Bind parameters (including variadic) - validates and binds args
 					if method.Params != nil {
@@ -2054,12 +2566,21 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 					}
 
 					// Execute method body for non-builtin methods
+					if method.IsGenerator {
+						return newGenerator(methodEnv, b.Name+"."+x.Name, methodEnv, method.Body)
+					}
+					if limits := limitsOf(env); limits != nil {
+						if err := limits.enter(); err != nil {
+							return nil, err
+						}
+						defer limits.leave()
+					}
 					var result any
 					for _, stmt := range method.Body {
 						var err error
 						val, returned, err := evalStmt(methodEnv, stmt)
 						if err != nil {
-							return nil, err
+							return nil, addStackFrame(err, b.Name+"."+x.Name)
 						}
 						if returned {
 							result = val
@@ -2079,7 +2600,7 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 					hash := hashValue(env, x.Name)
 					if entries, exists := hashData[hash]; exists {
 						for _, entry := range entries {
-							if equals(entry.Key, x.Name) {
+							if equals(env, entry.Key, x.Name) {
 								return entry.Value, nil
 							}
 						}
@@ -2176,6 +2697,10 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 		case ast.OpNot:
 			return !utils.AsBool(v), nil
 		case ast.OpNeg:
+			// Negating an Int keeps it an Int, so -2 can be passed where an Int is expected
+			if i, ok := extractPrimitiveValue(v).(int); ok {
+				return CreateIntInstance(env, -i)
+			}
 			f, ok := utils.AsFloat(v)
 			if !ok {
 				return nil, typeError("number", v)
@@ -2189,6 +2714,13 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+		if x.Op == ast.OpNilCoalesce {
+			// Only nil falls back; false, 0 and "" are kept. The right side is not evaluated otherwise.
+			if a != nil {
+				return a, nil
+			}
+			return evalExpr(env, x.Rhs)
+		}
 		b, err := evalExpr(env, x.Rhs)
 		if err != nil {
 			return nil, err
@@ -2203,7 +2735,7 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 			// String concatenation check (early exit)
 			aStr := extractPrimitiveValue(a)
 			if sa, ok := aStr.(string); ok {
-				return sa + utils.ToString(b), nil
+				return sa + utils.ToStringWithEnv(b, env), nil
 			}
 
 			// Numeric addition - quick type check
@@ -2449,6 +2981,12 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 				return CreateIntInstance(env, ia&(ib-1))
 			}
 			return CreateIntInstance(env, ia%ib)
+		case ast.OpPow:
+			// Fast path: operator overloading
+			if result, handled, err := tryOperatorOverload(env, "**", "power", a, b); handled {
+				return result, err
+			}
+			return powValues(env, a, b)
 		case ast.OpEq:
 			// Check for operator overloading first
 			if result, handled, err := tryOperatorOverload(env, "==", "equals", a, b); handled {
@@ -2540,9 +3078,40 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 			return nil, ThrowNotImplementedError(env, fmt.Sprintf("binary operator %d", x.Op))
 		}
 	case *ast.CallExpr:
-		cal, err := evalExpr(env, x.Callee)
-		if err != nil {
-			return nil, err
+		callee := x.Callee
+		if field, ok := callee.(*ast.FieldExpr); ok && field.Optional {
+			// recv?.method(args): skip the call, and its arguments, when recv is nil
+			resolved, nilReceiver, err := optionalCallee(env, field)
+			if err != nil || nilReceiver {
+				return nil, err
+			}
+			callee = resolved
+		}
+		var receiver any
+		if field, ok := callee.(*ast.FieldExpr); ok && len(x.NamedArgs) > 0 {
+			// Keyword arguments are matched against the method's parameters
+			var err error
+			if callee, receiver, err = namedArgsReceiver(env, field); err != nil {
+				return nil, err
+			}
+		}
+		var (
+			cal any
+			err error
+		)
+		if field, ok := callee.(*ast.FieldExpr); ok && receiver == nil && enumAccessors[field.Name] {
+			// value.name() calls the enum method rather than the field of the same name
+			if callee, receiver, err = namedArgsReceiver(env, field); err != nil {
+				return nil, err
+			}
+			if value, ok := receiver.(*common.EnumValueInstance); ok {
+				cal = value.Methods[field.Name]
+			}
+		}
+		if cal == nil {
+			if cal, err = evalExpr(env, callee); err != nil {
+				return nil, err
+			}
 		}
 
 		// Handle ClassConstructor wrapper
@@ -2573,15 +3142,36 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 
 		args := make([]any, 0, len(x.Args))
 		for _, a := range x.Args {
+			if spread, ok := a.(*ast.SpreadExpr); ok {
+				items, err := evalSpread(env, spread)
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, items...)
+				continue
+			}
 			v, err := evalExpr(env, a)
 			if err != nil {
 				return nil, err
 			}
 			args = append(args, v)
 		}
+		if len(x.NamedArgs) > 0 {
+			if args, err = resolveNamedArgs(env, x, cal, receiver, args); err != nil {
+				return nil, err
+			}
+		}
+		if len(x.TypeArgs) > 0 {
+			// Carry explicit type arguments to the method through a call scope
+			callEnv := env.Child()
+			callEnv.Set(methodTypeArgsVar, x.TypeArgs)
+			return fn(callEnv, args)
+		}
 		return fn(env, args)
 	case *ast.GenericCallExpr:
 		return evalGenericCallExpr(env, x)
+	case *ast.SpreadExpr:
+		return nil, ThrowRuntimeError(env, "spread '...' is only allowed in array literals and call arguments")
 	case *ast.InstanceOfExpr:
 		return evalInstanceOfExpr(env, x)
 	case *ast.TypeExpr:
@@ -2625,17 +3215,34 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 			return evalExpr(env, x.FalseBranch)
 		}
 	case *ast.LambdaExpr:
+		// A block lambda containing yield returns a Generator, like a function
+		isGenerator := x.IsBlock && containsYield(x.BlockBody)
+
 		// Create a closure that captures the current environment
+		CaptureEnv(env)
 		fn := common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			hooks := hooksForCall(callEnv, env)
+			if hooks != nil && hooks.limits != nil {
+				if err := hooks.limits.enter(); err != nil {
+					return nil, err
+				}
+				defer hooks.limits.leave()
+			}
 			// Use pooled environment for better performance (2-3x faster lambda calls)
 			lambdaEnv := GetPooledEnv(env)
 			defer ReleaseEnv(lambdaEnv)
+			if hooks != nil {
+				lambdaEnv.Hooks = hooks
+			}
 
 			// Bind parameters with type validation and variadic support
 			err := bindParametersWithVariadic(lambdaEnv, x.Params, args)
 			if err != nil {
 				return nil, err
 			}
+			if isGenerator {
+				return newGenerator(lambdaEnv, "<lambda>", lambdaEnv, x.BlockBody)
+			}
 
 			// Ensure defers are executed after lambda completes
 			defer func() {
@@ -2651,7 +3258,7 @@ func evalExpr(env *common.Env, e ast.Expr) (any, error) {
 				for _, stmt := range x.BlockBody {
 					v, ret, err := evalStmt(lambdaEnv, stmt)
 					if err != nil {
-						return nil, err
+						return nil, addStackFrame(err, "<lambda>")
 					}
 					if ret {
 						return v, nil
@@ -2715,6 +3322,9 @@ func ThrowAttributeErrorWithHint(env *Env, attrName string, typeName string, ava
 		Suggestions: availableMethods,
 		HintType:    "method",
 	}
+	if close := closestNames(attrName, availableMethods); len(close) > 0 {
+		message, hint = didYouMean(message, &ExceptionHint{Suggestions: close, HintType: "typo"})
+	}
 
 	// Create exception with hint
 	exc := &HyException{
@@ -2845,7 +3455,30 @@ type continueSentinel = common.ContinueSentinel
 
 // Options control execution behavior (flags, limits, debug hooks, etc.).
 type Options struct {
-	Stdout io.Writer // where println/print write to
+	Stdout   io.Writer // where println/print write to
+	Stdin    io.Reader // where input reads from
+	Args     []string  // command-line arguments after the script path, returned by Sys.args
+	Debugger *Debugger // when set, pauses at breakpoints before each statement
+	Coverage *Coverage // when set, records which statement lines execute
+	Limits   *Limits   // when set, aborts the run once it exceeds the budget
+
+	// Sandbox runs untrusted code: IO, Process, Net, Http and Sockets are not
+	// installed, Sys cannot touch the environment or signals, and the run is
+	// always bounded by Limits, with SandboxLimits filling their zero fields.
+	Sandbox bool
+
+	// Deterministic makes Map iteration, JSON output and printed Maps and Sets
+	// follow the sorted order of their keys, for golden files and reproducible
+	// output.
+	Deterministic bool
+
+	// Assets holds the files polyloft build embeds in an executable, read by
+	// the Assets module. When nil, Assets reads from the working directory.
+	Assets fs.FS
+
+	// Modules holds the source of the modules a built executable imports.
+	// When set, imports are resolved within it and never read from disk.
+	Modules ModuleBundle
 }
 
 // Use common definitions for Env and Func
@@ -2875,6 +3508,10 @@ func evalThreadSpawnExpr(env *Env, expr *ast.ThreadSpawnExpr) (any, error) {
 		done:   false,
 	}
 
+	// The thread body may outlive the call that spawned it, and the run as well
+	CaptureEnv(env)
+	threadEnv := spawnEnv(env)
+
 	// Start goroutine to execute thread body
 	go func() {
 		defer func() {
@@ -2883,9 +3520,6 @@ func evalThreadSpawnExpr(env *Env, expr *ast.ThreadSpawnExpr) (any, error) {
 			}
 		}()
 
-		// Create a new environment for the thread
-		threadEnv := &Env{Parent: env, Vars: map[string]any{}, Consts: map[string]bool{}}
-
 		var lastResult any
 		for _, stmt := range expr.Body {
 			result, returned, err := evalStmt(threadEnv, stmt)
@@ -2961,7 +3595,7 @@ func processStringInterpolation(env *Env, str string) (string, error) {
 		}
 
 		// Convert value to string and append
-		result += utils.ToString(value)
+		result += utils.ToStringWithEnv(value, env)
 
 		// Move past the closing brace
 		i = end + 1
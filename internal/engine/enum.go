@@ -11,6 +11,10 @@ import (
 // Global registry for enums
 var enumRegistry = make(map[string]*common.EnumDefinition)
 
+// enumAccessors are the members of enum values that are both fields and
+// methods: value.name reads the field and value.name() calls the method
+var enumAccessors = map[string]bool{"name": true, "ordinal": true}
+
 // evalEnumDecl handles enum declaration evaluation
 func evalEnumDecl(env *Env, decl *ast.EnumDecl) (any, error) {
 	if _, exists := enumRegistry[decl.Name]; exists {
@@ -41,6 +45,7 @@ func evalEnumDecl(env *Env, decl *ast.EnumDecl) (any, error) {
 			IsAbstract:  method.IsAbstract,
 			IsStatic:    contains(method.Modifiers, "static"),
 			IsPrivate:   contains(method.Modifiers, "private"),
+			IsGenerator: containsYield(method.Body),
 			BuiltinImpl: nil,
 		}
 		info.Params = append(info.Params, method.Params...)
@@ -187,7 +192,7 @@ func bindEnumInstanceMethods(instance *common.EnumValueInstance) {
 			// Select appropriate method based on argument count
 			method := common.SelectMethodOverload(overloads, len(args))
 			if method == nil {
-				return nil, ThrowRuntimeError(callEnv, fmt.Sprintf("no overload found for %s.%s with %d arguments", def.Name, name, len(args)))
+				return nil, ThrowOverloadError(callEnv, def.Name, name, overloads, len(args))
 			}
 
 			if method.IsStatic || method.IsAbstract {
@@ -200,6 +205,9 @@ func bindEnumInstanceMethods(instance *common.EnumValueInstance) {
 			if err := bindParametersWithVariadic(methodEnv, method.Params, args); err != nil {
 				return nil, err
 			}
+			if method.IsGenerator {
+				return newGenerator(methodEnv, def.Name+"."+name, methodEnv, method.Body)
+			}
 
 			var last any
 			for _, stmt := range method.Body {
@@ -216,6 +224,17 @@ func bindEnumInstanceMethods(instance *common.EnumValueInstance) {
 		})
 	}
 
+	if _, ok := instance.Methods["name"]; !ok {
+		instance.Methods["name"] = Func(func(_ *Env, _ []any) (any, error) {
+			return instance.Name, nil
+		})
+	}
+	if _, ok := instance.Methods["ordinal"]; !ok {
+		instance.Methods["ordinal"] = Func(func(_ *Env, _ []any) (any, error) {
+			return instance.Ordinal, nil
+		})
+	}
+
 	if _, ok := instance.Methods["toString"]; !ok {
 		instance.Methods["toString"] = Func(func(_ *Env, _ []any) (any, error) {
 			if instance.Definition != nil {
@@ -234,7 +253,7 @@ func bindEnumStaticMethods(enumObject map[string]any, def *common.EnumDefinition
 			// Select appropriate method based on argument count
 			method := common.SelectMethodOverload(overloads, len(args))
 			if method == nil {
-				return nil, ThrowRuntimeError(callEnv, fmt.Sprintf("no overload found for static %s.%s with %d arguments", def.Name, name, len(args)))
+				return nil, ThrowOverloadError(callEnv, def.Name, name, overloads, len(args))
 			}
 
 			if !method.IsStatic || method.IsAbstract {
@@ -246,6 +265,9 @@ func bindEnumStaticMethods(enumObject map[string]any, def *common.EnumDefinition
 			if err := bindParametersWithVariadic(methodEnv, method.Params, args); err != nil {
 				return nil, err
 			}
+			if method.IsGenerator {
+				return newGenerator(methodEnv, def.Name+"."+name, methodEnv, method.Body)
+			}
 
 			var last any
 			for _, stmt := range method.Body {
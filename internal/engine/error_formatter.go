@@ -63,6 +63,72 @@ func formatHintSuggestions(hint *ExceptionHint, withColor bool) string {
 	return builder.String()
 }
 
+// formatSourceSpan renders the offending source line with the failing expression underlined
+func formatSourceSpan(hyErr *HyException, withColor bool) string {
+	if hyErr.SourceLine == "" || hyErr.Column <= 0 {
+		return ""
+	}
+
+	end := hyErr.EndColumn
+	if hyErr.EndLine != hyErr.Line || end <= hyErr.Column {
+		end = len([]rune(hyErr.SourceLine)) + 1
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("  %4d | %s\n", hyErr.Line, hyErr.SourceLine))
+	builder.WriteString("       | ")
+	runes := []rune(hyErr.SourceLine)
+	for i := 1; i < hyErr.Column; i++ {
+		// Preserve tabs so the underline stays aligned
+		if i <= len(runes) && runes[i-1] == '\t' {
+			builder.WriteString("\t")
+		} else {
+			builder.WriteString(" ")
+		}
+	}
+	underline := "^" + strings.Repeat("~", max(end-hyErr.Column-1, 0))
+	if withColor {
+		builder.WriteString(fmt.Sprintf("%s%s%s\n", ColorBoldRed, underline, ColorReset))
+	} else {
+		builder.WriteString(underline + "\n")
+	}
+	return builder.String()
+}
+
+// stackTraceEdge is how many frames are shown at each end of a long stack trace
+const stackTraceEdge = 10
+
+// formatStackTrace renders the frames of an exception, most recent call first.
+// Long traces (deep recursion) keep their first and last frames only.
+func formatStackTrace(frames []string, withColor bool) string {
+	if len(frames) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	line := func(text string) {
+		if withColor {
+			builder.WriteString(fmt.Sprintf("  %s%s%s\n", ColorGray, text, ColorReset))
+		} else {
+			builder.WriteString("  " + text + "\n")
+		}
+	}
+	if withColor {
+		builder.WriteString(fmt.Sprintf("\n%sStack trace (most recent call first):%s\n", ColorGray, ColorReset))
+	} else {
+		builder.WriteString("\nStack trace (most recent call first):\n")
+	}
+	for i, frame := range frames {
+		if len(frames) > 2*stackTraceEdge && i == stackTraceEdge {
+			line(fmt.Sprintf("... %d more frames ...", len(frames)-2*stackTraceEdge))
+		}
+		if len(frames) > 2*stackTraceEdge && i >= stackTraceEdge && i < len(frames)-stackTraceEdge {
+			continue
+		}
+		line(fmt.Sprintf("%d. %s", i+1, frame))
+	}
+	return builder.String()
+}
 
 // FormatError formats a HyException with colors and hints
 func FormatError(err error) string {
@@ -91,6 +157,7 @@ func FormatError(err error) string {
 	
 	builder.WriteString(fmt.Sprintf("%s%s%s: %s\n", 
 		ColorBoldRed, errorType, ColorReset, hyErr.Message))
+	builder.WriteString(formatSourceSpan(hyErr, true))
 	
 	// Hints (if available)
 	if hyErr.Hint != nil && len(hyErr.Hint.Suggestions) > 0 {
@@ -98,12 +165,7 @@ func FormatError(err error) string {
 	}
 	
 	// Stack trace (if available and not empty)
-	if len(hyErr.StackTrace) > 0 {
-		builder.WriteString(fmt.Sprintf("\n%sStack trace:%s\n", ColorGray, ColorReset))
-		for i, trace := range hyErr.StackTrace {
-			builder.WriteString(fmt.Sprintf("  %s%d. %s%s\n", ColorGray, i+1, trace, ColorReset))
-		}
-	}
+	builder.WriteString(formatStackTrace(hyErr.StackTrace, true))
 	
 	return builder.String()
 }
@@ -165,12 +227,7 @@ func FormatErrorWithContext(err error, codeContext []string, currentLine int) st
 	}
 	
 	// Stack trace (if available and not empty)
-	if len(hyErr.StackTrace) > 0 {
-		builder.WriteString(fmt.Sprintf("\n%sStack trace:%s\n", ColorGray, ColorReset))
-		for i, trace := range hyErr.StackTrace {
-			builder.WriteString(fmt.Sprintf("  %s%d. %s%s\n", ColorGray, i+1, trace, ColorReset))
-		}
-	}
+	builder.WriteString(formatStackTrace(hyErr.StackTrace, true))
 	
 	return builder.String()
 }
@@ -200,6 +257,7 @@ func FormatErrorPlain(err error) string {
 	}
 	
 	builder.WriteString(fmt.Sprintf("%s: %s\n", errorType, hyErr.Message))
+	builder.WriteString(formatSourceSpan(hyErr, false))
 	
 	// Hints (if available)
 	if hyErr.Hint != nil && len(hyErr.Hint.Suggestions) > 0 {
@@ -207,12 +265,7 @@ func FormatErrorPlain(err error) string {
 	}
 	
 	// Stack trace (if available and not empty)
-	if len(hyErr.StackTrace) > 0 {
-		builder.WriteString("\nStack trace:\n")
-		for i, trace := range hyErr.StackTrace {
-			builder.WriteString(fmt.Sprintf("  %d. %s\n", i+1, trace))
-		}
-	}
+	builder.WriteString(formatStackTrace(hyErr.StackTrace, false))
 	
 	return builder.String()
 }
@@ -268,12 +321,7 @@ func FormatErrorPlainWithContext(err error, codeContext []string, currentLine in
 	}
 	
 	// Stack trace (if available and not empty)
-	if len(hyErr.StackTrace) > 0 {
-		builder.WriteString("\nStack trace:\n")
-		for i, trace := range hyErr.StackTrace {
-			builder.WriteString(fmt.Sprintf("  %d. %s\n", i+1, trace))
-		}
-	}
+	builder.WriteString(formatStackTrace(hyErr.StackTrace, false))
 	
 	return builder.String()
 }
@@ -190,7 +190,7 @@ func getKeywordHint(name string) *ExceptionHint {
 		"for", "in", "loop", "do", "end",
 		"break", "continue", "return",
 		"try", "catch", "finally", "throw",
-		"import", "from",
+		"import", "export", "from",
 		"this", "super",
 		"instanceof", "new",
 		"true", "false", "nil",
@@ -338,27 +338,26 @@ func (hp *HintProvider) GetHintForUndefinedNameWithContext(name string, filepath
 		return keywordHint
 	}
 
-	// Check environment variables for similar names
-	if hp.env != nil {
-		envSuggestions := hp.findSimilarNamesInEnv(name)
-		suggestions = append(suggestions, envSuggestions...)
-	}
-
-	// Check common built-in functions
+	// Common built-in functions
 	builtins := []string{
-		"println", "print", "printlnln", "println",
+		"println", "print", "printlnln",
 		"Array", "Map", "String", "Int", "Float", "Bool",
 		"Math", "Sys", "IO", "Net", "Crypto",
 	}
 
-	for _, builtin := range builtins {
-		// Only suggest if:
-		// 1. Very similar (Levenshtein distance <= 2)
-		// 2. The name is a substantial part of the builtin (at least 3 chars and is substring)
-		if levenshteinDistance(name, builtin) <= 2 {
-			suggestions = append(suggestions, builtin)
-		} else if len(name) >= 3 && strings.Contains(strings.ToLower(builtin), strings.ToLower(name)) {
-			suggestions = append(suggestions, builtin)
+	// Names in scope and builtins that are a small typo away, closest first
+	candidates := builtins
+	if hp.env != nil {
+		candidates = append(hp.namesInEnv(), builtins...)
+	}
+	suggestions = closestNames(name, candidates)
+
+	// Otherwise a builtin the name is a substantial part of
+	if len(suggestions) == 0 && len(name) >= 3 {
+		for _, builtin := range builtins {
+			if strings.Contains(strings.ToLower(builtin), strings.ToLower(name)) {
+				suggestions = append(suggestions, builtin)
+			}
 		}
 	}
 
@@ -378,8 +377,6 @@ func (hp *HintProvider) GetHintForUndefinedNameWithContext(name string, filepath
 
 // GetHintForAttribute provides suggestions for missing attributes
 func (hp *HintProvider) GetHintForAttribute(attrName string, typeName string) *ExceptionHint {
-	suggestions := []string{}
-
 	// Extract class name from typeName (it may be formatted like "'ClassName' instance")
 	className := typeName
 	if strings.Contains(typeName, "'") {
@@ -389,49 +386,39 @@ func (hp *HintProvider) GetHintForAttribute(attrName string, typeName string) *E
 		}
 	}
 
-	// Try to find the class/enum definition and suggest similar names
+	// Try to find the class definition and suggest similar fields and methods,
+	// including inherited ones
 	// Note: For suggestions, we check all packages since we're trying to be helpful
-	if classDef, ok := builtinClasses[className]; ok {
-		// Check fields
-		for fieldName := range classDef.Fields {
-			if levenshteinDistance(attrName, fieldName) <= 2 {
-				suggestions = append(suggestions, fieldName)
+	classDef, ok := builtinClasses[className]
+	if !ok {
+		for _, packageClasses := range classRegistry {
+			if classDef, ok = packageClasses[className]; ok {
+				break
 			}
 		}
-		// Check methods
-		for methodName := range classDef.Methods {
-			if levenshteinDistance(attrName, methodName) <= 2 {
-				suggestions = append(suggestions, methodName)
-			}
+	}
+	var members []string
+	for def := classDef; def != nil; def = def.Parent {
+		for fieldName := range def.Fields {
+			members = append(members, fieldName)
 		}
-	} else {
-		// Check all packages for class definitions
-		for _, packageClasses := range classRegistry {
-			if classDef, ok := packageClasses[className]; ok {
-				// Check fields
-				for fieldName := range classDef.Fields {
-					if levenshteinDistance(attrName, fieldName) <= 2 {
-						suggestions = append(suggestions, fieldName)
-					}
-				}
-				// Check methods
-				for methodName := range classDef.Methods {
-					if levenshteinDistance(attrName, methodName) <= 2 {
-						suggestions = append(suggestions, methodName)
-					}
-				}
-				break
+		for methodName := range def.Methods {
+			if !strings.HasPrefix(methodName, "__") {
+				members = append(members, methodName)
 			}
 		}
 	}
+	suggestions := closestNames(attrName, members)
 
 	// Check enum values
 	for enumName, enumDef := range enumRegistry {
 		if strings.Contains(className, enumName) {
+			var values []string
 			for valueName := range enumDef.Values {
-				if levenshteinDistance(attrName, valueName) <= 2 {
-					suggestions = append(suggestions, fmt.Sprintf("%s.%s", enumName, valueName))
-				}
+				values = append(values, valueName)
+			}
+			for _, valueName := range closestNames(attrName, values) {
+				suggestions = append(suggestions, fmt.Sprintf("%s.%s", enumName, valueName))
 			}
 		}
 	}
@@ -486,22 +473,72 @@ func (hp *HintProvider) GetHintForEnumValue(enumName string, valueName string) *
 	}
 }
 
-// findSimilarNamesInEnv searches for similar variable names in the environment
-func (hp *HintProvider) findSimilarNamesInEnv(name string) []string {
-	suggestions := []string{}
-
+// namesInEnv lists the names visible from the environment, innermost scope first.
+// Internal names such as "$generator" are left out.
+func (hp *HintProvider) namesInEnv() []string {
+	var names []string
 	for cur := hp.env; cur != nil; cur = cur.Parent {
 		for varName := range cur.Vars {
-			if levenshteinDistance(name, varName) <= 2 {
-				suggestions = append(suggestions, varName)
+			if !strings.HasPrefix(varName, "$") {
+				names = append(names, varName)
 			}
 		}
 	}
+	return names
+}
+
+// closestNames returns the candidates a typo away from name, closest first and
+// alphabetically among equally close ones. Longer names allow more edits: none
+// besides case for one or two letters, one up to five, then one more every
+// three letters.
+func closestNames(name string, candidates []string) []string {
+	maxDistance := len(name) / 3
+	if len(name) >= 3 && maxDistance < 1 {
+		maxDistance = 1
+	}
+	distances := make(map[string]int)
+	var matches []string
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		if _, seen := distances[candidate]; seen {
+			continue
+		}
+		distance := levenshteinDistance(name, candidate)
+		distances[candidate] = distance
+		if distance <= maxDistance {
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if distances[matches[i]] != distances[matches[j]] {
+			return distances[matches[i]] < distances[matches[j]]
+		}
+		return matches[i] < matches[j]
+	})
+	return uniqueAndLimit(matches, 3)
+}
 
-	return suggestions
+// didYouMean moves the closest suggestion of a typo hint into message, as
+// "...; did you mean 'length'?", and keeps any others in the hint
+func didYouMean(message string, hint *ExceptionHint) (string, *ExceptionHint) {
+	if hint == nil || hint.HintType != "typo" || len(hint.Suggestions) == 0 {
+		return message, hint
+	}
+	message = fmt.Sprintf("%s; did you mean '%s'?", message, hint.Suggestions[0])
+	if len(hint.Suggestions) == 1 {
+		return message, nil
+	}
+	return message, &ExceptionHint{
+		Message:     "Other close matches:",
+		Suggestions: hint.Suggestions[1:],
+		HintType:    hint.HintType,
+	}
 }
 
-// levenshteinDistance computes the edit distance between two strings
+// levenshteinDistance computes the edit distance between two strings, ignoring
+// case. Swapping two adjacent letters counts as a single edit.
 func levenshteinDistance(s1, s2 string) int {
 	s1Lower := strings.ToLower(s1)
 	s2Lower := strings.ToLower(s2)
@@ -536,6 +573,9 @@ func levenshteinDistance(s1, s2 string) int {
 				matrix[i][j-1]+1,      // insertion
 				matrix[i-1][j-1]+cost, // substitution
 			)
+			if i > 1 && j > 1 && s1Lower[i-1] == s2Lower[j-2] && s1Lower[i-2] == s2Lower[j-1] && matrix[i-2][j-2]+1 < matrix[i][j] {
+				matrix[i][j] = matrix[i-2][j-2] + 1 // transposition
+			}
 		}
 	}
 
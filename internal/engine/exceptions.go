@@ -1,11 +1,14 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/ArubikU/polyloft/internal/ast"
 	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
 )
 
 // HyException represents a Polyloft exception
@@ -18,7 +21,15 @@ type HyException struct {
 	File       string
 	Line       int
 	Column     int
+	EndLine    int    // end of the offending expression, when known
+	EndColumn  int    // column just past the offending expression, when known
+	SourceLine string // source text of Line, used to underline the expression
 	Hint       *ExceptionHint
+
+	// Position reached in the function currently being unwound; see
+	// noteTracePosition and addStackFrame
+	traceFile string
+	traceLine int
 }
 
 func (e *HyException) Error() string {
@@ -61,6 +72,43 @@ func NewHyException(exceptionType, message string) *HyException {
 	}
 }
 
+// maxStackFrames bounds the frames kept on an exception, so runaway recursion
+// does not build an enormous trace
+const maxStackFrames = 100
+
+// noteTracePosition remembers where err passed through the innermost statement of
+// the function being unwound, the line its stack frame will report
+func noteTracePosition(env *Env, err error) {
+	var exc *HyException
+	if errors.As(err, &exc) && exc.traceLine == 0 {
+		// Method and constructor environments don't carry the file name themselves
+		for scope := env; scope != nil && exc.traceFile == ""; scope = scope.Parent {
+			exc.traceFile = scope.GetFileName()
+		}
+		exc.traceLine = env.CurrentLine
+	}
+}
+
+// addStackFrame records a frame for the function named name as err leaves it.
+// Frames are only built while an exception propagates, so calls that succeed cost
+// nothing. It returns err unchanged.
+func addStackFrame(err error, name string) error {
+	var exc *HyException
+	if !errors.As(err, &exc) || exc.traceLine == 0 {
+		return err
+	}
+	if len(exc.StackTrace) < maxStackFrames {
+		location := fmt.Sprintf("line %d", exc.traceLine)
+		if exc.traceFile != "" {
+			location = fmt.Sprintf("%s:%d", exc.traceFile, exc.traceLine)
+		}
+		exc.StackTrace = append(exc.StackTrace, fmt.Sprintf("%s (%s)", name, location))
+	}
+	exc.traceFile = ""
+	exc.traceLine = 0
+	return err
+}
+
 // Exception class registry
 var exceptionClasses = map[string]common.Func{}
 
@@ -78,13 +126,12 @@ func createExceptionClassesProgrammatically(env *Env) error {
 				thisVal, _ := callEnv.This()
 				if instance, ok := thisVal.(*common.ClassInstance); ok {
 					message := "Error"
-					if len(args) > 0 {
-						if msg, ok := args[0].(string); ok {
-							message = msg
-						}
+					if len(args) > 0 && args[0] != nil {
+						message = utils.ToString(args[0])
 					}
 					instance.Fields["message"] = message
-					instance.Fields["type"] = "Throwable"
+					// Built-in subclasses overwrite this; user exception classes keep their own name
+					instance.Fields["type"] = instance.ClassName
 					instance.Fields["stackTrace"] = []any{}
 				}
 				return nil, nil
@@ -129,14 +176,34 @@ func createExceptionClassesProgrammatically(env *Env) error {
 	}
 	exceptionClasses["Throwable"] = throwableConstructor
 
+	// Create Exception class, the base for RuntimeError and for user-defined exceptions
+	exceptionClass, exceptionConstructor, err := NewClassBuilder("Exception").
+		SetParent(throwableClass).
+		SetBuiltinConstructor(
+			[]ast.Parameter{{Name: "message", Type: ast.TypeFromString("string")}},
+			func(callEnv *common.Env, args []any) (any, error) {
+				thisVal, _ := callEnv.This()
+				if instance, ok := thisVal.(*common.ClassInstance); ok {
+					return callParentConstructor(instance, throwableClass, callEnv, args)
+				}
+				return nil, nil
+			},
+		).
+		BuildAndGet(env)
+
+	if err != nil {
+		return err
+	}
+	exceptionClasses["Exception"] = exceptionConstructor
+
 	// Create RuntimeError class
 	_, runtimeErrorConstructor, err := NewClassBuilder("RuntimeError").
-		SetParent(throwableClass).
+		SetParent(exceptionClass).
 		SetBuiltinConstructor(
 			[]ast.Parameter{{Name: "message", Type: ast.TypeFromString("string")}},
 			func(callEnv *common.Env, args []any) (any, error) {
 				// Call parent constructor through super()
-				if classDef, exists := builtinClasses["Throwable"]; exists {
+				if classDef, exists := builtinClasses["Exception"]; exists {
 					thisVal, _ := callEnv.This()
 					if instance, ok := thisVal.(*common.ClassInstance); ok {
 						_, err := callParentConstructor(instance, classDef, callEnv, args)
@@ -196,18 +263,8 @@ func createExceptionClassesProgrammatically(env *Env) error {
 				{Name: "got", Type: ast.TypeFromString("int")},
 			},
 			func(callEnv *common.Env, args []any) (any, error) {
-				expected := 0
-				got := 0
-				if len(args) > 0 {
-					if e, ok := args[0].(float64); ok {
-						expected = int(e)
-					}
-				}
-				if len(args) > 1 {
-					if g, ok := args[1].(float64); ok {
-						got = int(g)
-					}
-				}
+				expected, _ := utils.AsIntArg(args, 0)
+				got, _ := utils.AsIntArg(args, 1)
 
 				message := fmt.Sprintf("arity mismatch: expected %d, got %d", expected, got)
 
@@ -235,6 +292,34 @@ func createExceptionClassesProgrammatically(env *Env) error {
 	}
 	exceptionClasses["ArityError"] = arityErrorConstructor
 
+	// Create AssertionError class
+	_, assertionErrorConstructor, err := NewClassBuilder("AssertionError").
+		SetParent(builtinClasses["RuntimeError"]).
+		SetBuiltinConstructor(
+			[]ast.Parameter{{Name: "message", Type: ast.TypeFromString("string")}},
+			func(callEnv *common.Env, args []any) (any, error) {
+				// Call parent constructor through super()
+				if classDef, exists := builtinClasses["RuntimeError"]; exists {
+					thisVal, _ := callEnv.This()
+					if instance, ok := thisVal.(*common.ClassInstance); ok {
+						_, err := callParentConstructor(instance, classDef, callEnv, args)
+						if err != nil {
+							return nil, err
+						}
+						// Override type to AssertionError
+						instance.Fields["type"] = "AssertionError"
+					}
+				}
+				return nil, nil
+			},
+		).
+		BuildAndGet(env)
+
+	if err != nil {
+		return err
+	}
+	exceptionClasses["AssertionError"] = assertionErrorConstructor
+
 	return nil
 }
 
@@ -318,7 +403,7 @@ func ThrowArityError(env *Env, expected, got int) error {
 	}
 
 	if constructor, exists := exceptionClasses["ArityError"]; exists {
-		instance, err := constructor(env, []any{float64(expected), float64(got)})
+		instance, err := constructor(env, []any{expected, got})
 		if err == nil {
 			exc.Instance = instance
 		}
@@ -327,6 +412,103 @@ func ThrowArityError(env *Env, expected, got int) error {
 	return exc
 }
 
+// ThrowOverloadError throws an ArityError for a call to owner.name that no
+// overload accepts, listing the argument counts and signatures that would work
+func ThrowOverloadError(env *Env, owner, name string, overloads []common.MethodInfo, got int) error {
+	sorted := append([]common.MethodInfo(nil), overloads...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Params) < len(sorted[j].Params) })
+
+	var counts, signatures []string
+	for _, method := range sorted {
+		count := describeArgCount(method.Params)
+		if len(counts) == 0 || counts[len(counts)-1] != count {
+			counts = append(counts, count)
+		}
+		signatures = append(signatures, formatSignature(name, method.Params))
+	}
+	// The closest overload's parameter count fills the ArityError's expected field
+	expected := 0
+	if len(sorted) > 0 {
+		expected = len(sorted[0].Params)
+		for _, method := range sorted {
+			if len(method.Params) <= got {
+				expected = len(method.Params)
+			}
+		}
+	}
+
+	noun := "arguments"
+	if got == 1 {
+		noun = "argument"
+	}
+	message := fmt.Sprintf("no overload of %s.%s takes %d %s; expected %s",
+		owner, name, got, noun, strings.Join(counts, " or "))
+	exc := &HyException{
+		Message: message,
+		Type:    "ArityError",
+		Hint: &ExceptionHint{
+			Message:     "Available overloads:",
+			Suggestions: signatures,
+			HintType:    "general",
+		},
+	}
+	if env != nil {
+		exc.File = env.GetFileName()
+		exc.Line = env.GetCurrentLine()
+		exc.Column = env.CurrentColumn
+	}
+
+	if constructor, exists := exceptionClasses["ArityError"]; exists {
+		instance, err := constructor(env, []any{expected, got})
+		if err == nil {
+			if ci, ok := instance.(*ClassInstance); ok {
+				ci.Fields["message"] = message
+			}
+			exc.Instance = instance
+		}
+	}
+
+	return exc
+}
+
+// describeArgCount renders how many arguments params accept, e.g. "2",
+// "1 to 3" or "at least 1"
+func describeArgCount(params []ast.Parameter) string {
+	required := 0
+	for _, param := range params {
+		if param.IsVariadic {
+			return fmt.Sprintf("at least %d", required)
+		}
+		if param.Default == nil {
+			required++
+		}
+	}
+	if required == len(params) {
+		return fmt.Sprintf("%d", required)
+	}
+	return fmt.Sprintf("%d to %d", required, len(params))
+}
+
+// formatSignature renders a method signature the way it is declared, e.g.
+// "move(dx: Int, dy: Int = ...)" or "sum(numbers...)"
+func formatSignature(name string, params []ast.Parameter) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		part := param.Name
+		if param.IsVariadic {
+			part += "..."
+		}
+		if param.Type != nil {
+			part += ": " + ast.GetTypeNameString(param.Type)
+		}
+		if param.Default != nil {
+			part += " = ..."
+		}
+		parts[i] = part
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(parts, ", "))
+}
+
 // ThrowAttributeError throws an AttributeError exception for missing attributes/fields
 func ThrowAttributeError(env *Env, attrName string, typeName string) error {
 	message := fmt.Sprintf("'%s' object has no attribute '%s'", typeName, attrName)
@@ -334,6 +516,7 @@ func ThrowAttributeError(env *Env, attrName string, typeName string) error {
 	// Generate hint
 	hintProvider := NewHintProvider(env)
 	hint := hintProvider.GetHintForAttribute(attrName, typeName)
+	message, hint = didYouMean(message, hint)
 
 	// Create exception with location from env
 	exc := &HyException{
@@ -370,6 +553,14 @@ func ThrowNameError(env *Env, name string) error {
 	} else {
 		hint = hintProvider.GetHintForUndefinedName(name)
 	}
+	if sandboxedModules[name] && isSandboxed(env) {
+		hint = &ExceptionHint{
+			Message:     "Sandbox:",
+			Suggestions: []string{fmt.Sprintf("%s is not available in sandbox mode", name)},
+			HintType:    "general",
+		}
+	}
+	message, hint = didYouMean(message, hint)
 
 	// Create exception with location from env
 	exc := &HyException{
@@ -431,6 +622,28 @@ func ThrowValueError(env *Env, message string) error {
 	return exc
 }
 
+// ThrowAssertionError throws an AssertionError for a failed assertion or expectation
+func ThrowAssertionError(env *Env, message string) error {
+	exc := &HyException{
+		Message: message,
+		Type:    "AssertionError",
+	}
+	if env != nil {
+		exc.File = env.GetFileName()
+		exc.Line = env.GetCurrentLine()
+		exc.Column = env.CurrentColumn
+	}
+
+	if constructor, exists := exceptionClasses["AssertionError"]; exists {
+		instance, err := constructor(env, []any{message})
+		if err == nil {
+			exc.Instance = instance
+		}
+	}
+
+	return exc
+}
+
 // ThrowNotCallableError throws an error when trying to call a non-callable object
 func ThrowNotCallableError(env *Env, objectType string, value string) error {
 	message := fmt.Sprintf("'%s' object is not callable", objectType)
@@ -632,12 +845,108 @@ func ThrowInitializationError(env *Env, what string) error {
 	return exc
 }
 
+// ThrowCommandNotFoundError throws a CommandNotFoundError when an executable cannot be found
+func ThrowCommandNotFoundError(env *Env, command string) error {
+	message := fmt.Sprintf("command not found: %s", command)
+
+	exc := &HyException{
+		Message: message,
+		Type:    "CommandNotFoundError",
+	}
+	if env != nil {
+		exc.File = env.GetFileName()
+		exc.Line = env.GetCurrentLine()
+		exc.Column = env.CurrentColumn
+	}
+
+	if constructor, exists := exceptionClasses["RuntimeError"]; exists {
+		instance, err := constructor(env, []any{message})
+		if err == nil {
+			exc.Instance = instance
+		}
+	}
+
+	return exc
+}
+
+// ThrowProcessError throws a ProcessError for a command that failed, exited
+// with a non-zero status or timed out
+func ThrowProcessError(env *Env, message string) error {
+	exc := &HyException{
+		Message: message,
+		Type:    "ProcessError",
+	}
+	if env != nil {
+		exc.File = env.GetFileName()
+		exc.Line = env.GetCurrentLine()
+		exc.Column = env.CurrentColumn
+	}
+
+	if constructor, exists := exceptionClasses["RuntimeError"]; exists {
+		instance, err := constructor(env, []any{message})
+		if err == nil {
+			exc.Instance = instance
+		}
+	}
+
+	return exc
+}
+
+// ThrowIOError throws an IOError for a file operation that failed
+func ThrowIOError(env *Env, message string) error {
+	exc := &HyException{
+		Message: message,
+		Type:    "IOError",
+	}
+	if env != nil {
+		exc.File = env.GetFileName()
+		exc.Line = env.GetCurrentLine()
+		exc.Column = env.CurrentColumn
+	}
+
+	if constructor, exists := exceptionClasses["RuntimeError"]; exists {
+		instance, err := constructor(env, []any{message})
+		if err == nil {
+			exc.Instance = instance
+		}
+	}
+
+	return exc
+}
+
+// ThrowResourceError throws a ResourceError when a run exceeds its open handle or
+// memory limit
+func ThrowResourceError(env *Env, message string) error {
+	exc := &HyException{
+		Message: message,
+		Type:    "ResourceError",
+	}
+	if env != nil {
+		exc.File = env.GetFileName()
+		exc.Line = env.GetCurrentLine()
+		exc.Column = env.CurrentColumn
+	}
+
+	if constructor, exists := exceptionClasses["RuntimeError"]; exists {
+		instance, err := constructor(env, []any{message})
+		if err == nil {
+			exc.Instance = instance
+		}
+	}
+
+	return exc
+}
+
 // ValidateArgumentType validates that an argument matches the expected type
 func ValidateArgumentType(value any, expectedType string) error {
 	if expectedType == "" {
 		return nil // No type constraint
 	}
 
+	// Expand aliases first; errors still name the declared type
+	declaredType := expectedType
+	expectedType = resolveTypeAlias(expectedType)
+
 	// Check if it's a union type (contains |)
 	if strings.Contains(expectedType, "|") {
 		// Parse union type and check if value matches any member
@@ -649,16 +958,14 @@ func ValidateArgumentType(value any, expectedType string) error {
 			}
 		}
 		// Value doesn't match any union member
-		actualType := GetTypeName(value)
-		return ThrowTypeError(nil, expectedType, actualType)
+		return ThrowTypeError(nil, declaredType, value)
 	}
 
 	if IsInstanceOf(value, expectedType) {
 		return nil
 	}
 
-	actualType := GetTypeName(value)
-	return ThrowTypeError(nil, expectedType, actualType)
+	return ThrowTypeError(nil, declaredType, value)
 }
 
 // ValidateVariadicArguments validates variadic arguments and converts them to an array
@@ -726,6 +1033,20 @@ func evalTryStmt(env *Env, stmt *ast.TryStmt) (val any, returned bool, err error
 	for _, st := range stmt.Body {
 		v, ret, err := evalStmt(env, st)
 		if err != nil {
+			// An exceeded limit ends the run; it is not an exception
+			var limitErr *LimitError
+			if errors.As(err, &limitErr) {
+				return nil, false, err
+			}
+			// A closed generator unwinds its body through finally blocks, uncaught
+			if errors.Is(err, errGeneratorClosed) {
+				for _, st := range stmt.Finally {
+					if _, _, finallyErr := evalStmt(env, st); finallyErr != nil {
+						return nil, false, finallyErr
+					}
+				}
+				return nil, false, err
+			}
 			// Check if it's a HyException
 			if hyErr, ok := err.(*HyException); ok {
 				caughtException = hyErr
@@ -750,12 +1071,17 @@ func evalTryStmt(env *Env, stmt *ast.TryStmt) (val any, returned bool, err error
 		handled := false
 		for _, catch := range stmt.Catches {
 			// Check if exception type matches (if specified)
-			if catch.ExceptType == "" || catch.ExceptType == caughtException.Type {
+			if exceptionMatches(caughtException, catch.ExceptType) {
 				// Create new scope for catch block
 				catchEnv := &Env{Parent: env, Vars: map[string]any{}, Consts: map[string]bool{}}
 
 				// Bind exception variable if specified
 				if catch.VarName != "" {
+					// Built-in errors such as ValueError reuse the RuntimeError class, so
+					// expose the type that was actually raised as e.type
+					if instance, ok := caughtException.Instance.(*ClassInstance); ok && isThrowableInstance(instance) {
+						instance.Fields["type"] = caughtException.Type
+					}
 					catchEnv.Define(catch.VarName, caughtException.Instance, catch.Modifier)
 				}
 
@@ -800,6 +1126,34 @@ func evalTryStmt(env *Env, stmt *ast.TryStmt) (val any, returned bool, err error
 	return lastValue, returned, nil
 }
 
+// exceptionMatches reports whether a catch clause for typeName handles exc: an empty
+// typeName catches everything, Exception and Throwable catch every error, and
+// otherwise the exception type or, for user exception classes, any class in the
+// thrown instance's parent chain must have that name
+func exceptionMatches(exc *HyException, typeName string) bool {
+	if typeName == "" || typeName == exc.Type {
+		return true
+	}
+	instance, ok := exc.Instance.(*ClassInstance)
+	if !ok || !isThrowableInstance(instance) {
+		return false
+	}
+	if typeName == "Exception" || typeName == "Throwable" {
+		return true
+	}
+	// Built-in errors share classes (a NameError is a RuntimeError instance), so
+	// their class chain does not tell which error was raised
+	if builtinClasses[instance.ParentClass.Name] == instance.ParentClass {
+		return false
+	}
+	for classDef := instance.ParentClass; classDef != nil; classDef = classDef.Parent {
+		if classDef.Name == typeName {
+			return true
+		}
+	}
+	return false
+}
+
 // evalThrowStmt handles throw statements
 func evalThrowStmt(env *Env, stmt *ast.ThrowStmt) (val any, returned bool, err error) {
 	// Evaluate the expression to throw
@@ -815,9 +1169,15 @@ func evalThrowStmt(env *Env, stmt *ast.ThrowStmt) (val any, returned bool, err e
 	}
 	switch val := value.(type) {
 	case *ClassInstance:
-		if msg, ok := val.Fields["message"].(string); ok {
-			typ, ok := val.Fields["type"].(string)
-			if !ok {
+		if msg, ok := val.Fields["message"]; ok && msg != nil {
+			// Instances of Throwable subclasses use their class name when the
+			// constructor did not set a type
+			typ, _ := val.Fields["type"].(string)
+			if typ == "" && isThrowableInstance(val) {
+				typ = val.ClassName
+				val.Fields["type"] = typ
+			}
+			if typ == "" {
 				break
 			}
 
@@ -826,7 +1186,7 @@ func evalThrowStmt(env *Env, stmt *ast.ThrowStmt) (val any, returned bool, err e
 				stackTrace = st
 			}
 			et = &HyException{
-				Message:    msg,
+				Message:    utils.ToString(msg),
 				Type:       typ,
 				StackTrace: stackTrace,
 				Instance:   val,
@@ -837,6 +1197,44 @@ func evalThrowStmt(env *Env, stmt *ast.ThrowStmt) (val any, returned bool, err e
 	return nil, false, et
 }
 
+// evalAssertStmt throws an AssertionError when the condition is falsy
+func evalAssertStmt(env *Env, stmt *ast.AssertStmt) (val any, returned bool, err error) {
+	cond, err := evalExpr(env, stmt.Cond)
+	if err != nil {
+		return nil, false, err
+	}
+	if utils.AsBool(cond) {
+		return nil, false, nil
+	}
+	message := "assertion failed"
+	if stmt.Message != nil {
+		value, err := evalExpr(env, stmt.Message)
+		if err != nil {
+			return nil, false, err
+		}
+		message = utils.ToString(value)
+	}
+	err = ThrowAssertionError(env, message)
+	// Underline the condition that failed
+	annotateErrorSpan(env, stmt.Cond, err)
+	return nil, false, err
+}
+
+// isThrowableInstance reports whether instance's class extends Throwable
+func isThrowableInstance(instance *ClassInstance) bool {
+	return isThrowableClass(instance.ParentClass)
+}
+
+// isThrowableClass reports whether classDef is Throwable or extends it
+func isThrowableClass(classDef *ClassDefinition) bool {
+	for ; classDef != nil; classDef = classDef.Parent {
+		if classDef.Name == "Throwable" && classDef.Parent == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // evalDeferStmt handles defer statements
 func evalDeferStmt(env *Env, stmt *ast.DeferStmt) (val any, returned bool, err error) {
 	// Defer statements schedule a function call to be executed when the current
@@ -0,0 +1,365 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// InstallExpectBuiltins installs expect(actual), which returns an Expectation
+// with fluent matchers. A failed matcher throws an AssertionError describing
+// the expected and actual values.
+func InstallExpectBuiltins(env *Env) error {
+	anyType := ast.ANY
+	expectation := NewClassBuilder("Expectation").
+		AddField("_actual", anyType, []string{"private"})
+
+	// toEqual(expected) - deep equality for collections, records and objects
+	expectation.AddBuiltinMethod("toEqual", anyType, []ast.Parameter{
+		{Name: "expected", Type: anyType},
+	}, common.Func(func(callEnv *common.Env, args []any) (any, error) {
+		actual := expectationActual(callEnv)
+		if !deepEqual(actual, args[0]) {
+			return nil, ThrowAssertionError((*Env)(callEnv), fmt.Sprintf("expected %s to equal %s", describeValue(callEnv, actual), describeValue(callEnv, args[0])))
+		}
+		return nil, nil
+	}), []string{})
+
+	// toContain(item) - substring for strings, element for sequences and sets, key for maps
+	expectation.AddBuiltinMethod("toContain", anyType, []ast.Parameter{
+		{Name: "item", Type: anyType},
+	}, common.Func(func(callEnv *common.Env, args []any) (any, error) {
+		actual := expectationActual(callEnv)
+		contained, ok := containsValue(actual, args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "String, collection or Map for toContain", actual)
+		}
+		if !contained {
+			return nil, ThrowAssertionError((*Env)(callEnv), fmt.Sprintf("expected %s to contain %s", describeValue(callEnv, actual), describeValue(callEnv, args[0])))
+		}
+		return nil, nil
+	}), []string{})
+
+	// toBeNil()
+	expectation.AddBuiltinMethod("toBeNil", anyType, []ast.Parameter{},
+		common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			actual := expectationActual(callEnv)
+			if actual != nil {
+				return nil, ThrowAssertionError((*Env)(callEnv), fmt.Sprintf("expected nil, got %s", describeValue(callEnv, actual)))
+			}
+			return nil, nil
+		}), []string{})
+
+	// toThrow() - the actual value must be a function that throws when called
+	expectation.AddBuiltinMethod("toThrow", anyType, []ast.Parameter{},
+		common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			return nil, expectThrow((*Env)(callEnv), "")
+		}), []string{})
+
+	// toThrow(type) - same, but the exception must have the given type name
+	expectation.AddBuiltinMethod("toThrow", anyType, []ast.Parameter{
+		{Name: "type", Type: anyType},
+	}, common.Func(func(callEnv *common.Env, args []any) (any, error) {
+		var typeName string
+		switch t := args[0].(type) {
+		case *common.ClassConstructor:
+			typeName = t.Definition.Name
+		case *ClassDefinition:
+			typeName = t.Name
+		default:
+			typeName = utils.ToString(t)
+		}
+		return nil, expectThrow((*Env)(callEnv), typeName)
+	}), []string{})
+
+	// toMatchSnapshot() - the value must match the snapshot stored by an earlier run
+	expectation.AddBuiltinMethod("toMatchSnapshot", anyType, []ast.Parameter{},
+		common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			var snapshots *snapshotStore
+			if hooks := hooksOf(callEnv); hooks != nil {
+				snapshots = hooks.snapshots
+			}
+			if snapshots == nil {
+				return nil, ThrowRuntimeError((*Env)(callEnv), "toMatchSnapshot() only works in tests run by polyloft test")
+			}
+			return nil, snapshots.match((*Env)(callEnv), expectationActual(callEnv))
+		}), []string{})
+
+	expectationClass, err := expectation.Build(env)
+	if err != nil {
+		return err
+	}
+
+	fn := NewFunctionBuilder("expect").
+		SetParamsFromNames([]string{"actual"}, nil).
+		SetImplementation(func(callEnv *common.Env, args []any) (any, error) {
+			if len(args) != 1 {
+				return nil, ThrowArityError((*Env)(callEnv), 1, len(args))
+			}
+			instance, err := createClassInstance(expectationClass, (*Env)(callEnv), []any{})
+			if err != nil {
+				return nil, err
+			}
+			instance.(*ClassInstance).Fields["_actual"] = args[0]
+			return instance, nil
+		})
+	_, err = fn.Build(env)
+	return err
+}
+
+func expectationActual(env *common.Env) any {
+	thisVal, _ := env.This()
+	if inst, ok := thisVal.(*ClassInstance); ok {
+		return inst.Fields["_actual"]
+	}
+	return nil
+}
+
+// expectThrow calls the expectation's function and checks that it throws,
+// optionally with an exception of typeName
+func expectThrow(env *Env, typeName string) error {
+	actual := expectationActual(env)
+	fn, ok := common.ExtractFunc(actual)
+	if !ok {
+		return ThrowTypeError(env, "function for toThrow", actual)
+	}
+
+	_, callErr := fn(env, []any{})
+	if callErr == nil {
+		if typeName != "" {
+			return ThrowAssertionError(env, fmt.Sprintf("expected function to throw %s, but it returned normally", typeName))
+		}
+		return ThrowAssertionError(env, "expected function to throw, but it returned normally")
+	}
+	if typeName == "" {
+		return nil
+	}
+
+	thrownType := "RuntimeError"
+	var exc *HyException
+	if errors.As(callErr, &exc) && exc.Type != "" {
+		thrownType = exc.Type
+	}
+	// Match the way catch does, so a subclass of typeName passes
+	matched := thrownType == typeName || exc != nil && exceptionMatches(exc, typeName)
+	if !matched {
+		return ThrowAssertionError(env, fmt.Sprintf("expected function to throw %s, but it threw %s: %s", typeName, thrownType, callErr.Error()))
+	}
+	return nil
+}
+
+// describeValue renders a value for matcher failure messages, quoting strings
+func describeValue(env *common.Env, v any) string {
+	if s, ok := extractPrimitiveValue(v).(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	if v == nil {
+		return "nil"
+	}
+	return utils.ToStringWithEnv(v, env)
+}
+
+// collectionItems returns the elements of sequence-like values in iteration order
+func collectionItems(v any) ([]any, bool) {
+	switch val := v.(type) {
+	case []any:
+		return val, true
+	case *ClassInstance:
+		switch val.ClassName {
+		case "Array":
+			items, ok := val.Fields["_items"].([]any)
+			return items, ok
+		case "List", "Deque":
+			if items, ok := val.Fields["_items"].(*[]any); ok {
+				return *items, true
+			}
+		case "Set":
+			if keys, ok := val.Fields["_keys"].(*[]any); ok {
+				return *keys, true
+			}
+		case "Tuple":
+			items, ok := val.Fields["_elements"].([]any)
+			return items, ok
+		}
+	}
+	return nil, false
+}
+
+// mapEntries returns the key/value pairs of a Map instance or Go map
+func mapEntries(v any) ([]*mapEntry, bool) {
+	switch val := v.(type) {
+	case map[string]any:
+		entries := make([]*mapEntry, 0, len(val))
+		for k, item := range val {
+			entries = append(entries, &mapEntry{Key: k, Value: item})
+		}
+		return entries, true
+	case *ClassInstance:
+		if val.ClassName != "Map" {
+			return nil, false
+		}
+		data, ok := val.Fields["_data"].(map[uint64][]*mapEntry)
+		if !ok {
+			return nil, false
+		}
+		var entries []*mapEntry
+		for _, bucket := range data {
+			entries = append(entries, bucket...)
+		}
+		return entries, true
+	}
+	return nil, false
+}
+
+// maxDeepEqualDepth bounds how far deepEqual descends into nested values;
+// anything nested deeper is reported as unequal rather than overflowing the stack
+const maxDeepEqualDepth = 1000
+
+// deepEqual compares values structurally: collections element by element,
+// maps by key, records by component and objects of the same class field by field.
+// Cyclic values are handled by treating a pair already under comparison as equal.
+func deepEqual(a, b any) bool {
+	return deepEqualVisit(a, b, make(map[[2]any]bool), 0)
+}
+
+func deepEqualVisit(a, b any, visiting map[[2]any]bool, depth int) bool {
+	if equal(a, b) {
+		return true
+	}
+	if depth >= maxDeepEqualDepth {
+		return false
+	}
+	if isReference(a) && isReference(b) {
+		pair := [2]any{a, b}
+		if visiting[pair] {
+			return true
+		}
+		visiting[pair] = true
+		defer delete(visiting, pair)
+	}
+	next := func(x, y any) bool {
+		return deepEqualVisit(x, y, visiting, depth+1)
+	}
+
+	if aItems, ok := collectionItems(a); ok {
+		bItems, ok := collectionItems(b)
+		if !ok || len(aItems) != len(bItems) || isSetInstance(a) != isSetInstance(b) {
+			return false
+		}
+		if isSetInstance(a) {
+			for _, item := range aItems {
+				found := false
+				for _, candidate := range bItems {
+					if next(candidate, item) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					return false
+				}
+			}
+			return true
+		}
+		for i := range aItems {
+			if !next(aItems[i], bItems[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if aEntries, ok := mapEntries(a); ok {
+		bEntries, ok := mapEntries(b)
+		if !ok || len(aEntries) != len(bEntries) {
+			return false
+		}
+		for _, ae := range aEntries {
+			found := false
+			for _, be := range bEntries {
+				if equal(ae.Key, be.Key) {
+					found = next(ae.Value, be.Value)
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch av := a.(type) {
+	case *common.RecordInstance:
+		bv, ok := b.(*common.RecordInstance)
+		if !ok || av.Definition != bv.Definition {
+			return false
+		}
+		for _, component := range av.Definition.Components {
+			if !next(av.Values[component.Name], bv.Values[component.Name]) {
+				return false
+			}
+		}
+		return true
+	case *ClassInstance:
+		bv, ok := b.(*ClassInstance)
+		// Builtin values that equal() could not match hold native state and are not compared by field
+		if !ok || av.ParentClass == nil || av.ParentClass != bv.ParentClass || builtinClasses[av.ClassName] == av.ParentClass {
+			return false
+		}
+		for name, value := range av.Fields {
+			if _, isFunc := common.ExtractFunc(value); isFunc {
+				continue
+			}
+			if !next(value, bv.Fields[name]) {
+				return false
+			}
+		}
+		return len(av.Fields) == len(bv.Fields)
+	}
+	return false
+}
+
+// isReference reports whether v is a pointer value that can take part in a cycle
+func isReference(v any) bool {
+	switch v.(type) {
+	case *ClassInstance, *common.RecordInstance:
+		return true
+	}
+	return false
+}
+
+func isSetInstance(v any) bool {
+	inst, ok := v.(*ClassInstance)
+	return ok && inst.ClassName == "Set"
+}
+
+// containsValue reports whether container holds item; ok is false when
+// container is not a String, collection or Map
+func containsValue(container, item any) (found bool, ok bool) {
+	if s, isString := extractPrimitiveValue(container).(string); isString {
+		sub, isSubString := extractPrimitiveValue(item).(string)
+		return isSubString && strings.Contains(s, sub), true
+	}
+	if items, isCollection := collectionItems(container); isCollection {
+		for _, candidate := range items {
+			if deepEqual(candidate, item) {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	if entries, isMap := mapEntries(container); isMap {
+		for _, entry := range entries {
+			if equal(entry.Key, item) {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	return false, false
+}
@@ -15,9 +15,11 @@ func evalInstanceOfExpr(env *Env, expr *ast.InstanceOfExpr) (any, error) {
 	// Check if obj is instance of TypeName
 	result := IsInstanceOf(obj, expr.TypeName)
 
-	// If variable assignment is specified, assign the object if instanceof is true
+	// If variable assignment is specified, assign the object if instanceof is true.
+	// The binding is narrowed to TypeName, so reassigning it checks against that type.
 	if expr.Variable != "" && result {
 		env.Define(expr.Variable, obj, expr.Modifier)
+		env.DeclareType(expr.Variable, expr.TypeName)
 	}
 
 	return result, nil
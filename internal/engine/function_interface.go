@@ -3,64 +3,267 @@ package engine
 import (
 	"github.com/ArubikU/polyloft/internal/ast"
 	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
 )
 
-// InitFunctionInterfaces initializes the Function and BiFunction interfaces
+// functionalInterfaces are the builtin single-method interfaces. Functions and
+// lambdas satisfy them structurally when their arity matches the method's.
+var functionalInterfaces = []struct {
+	name   string
+	method string
+	params []string
+}{
+	{"Function", "apply", []string{"param"}},              // apply(param: P) -> R
+	{"BiFunction", "apply", []string{"param1", "param2"}}, // apply(param1: P1, param2: P2) -> R
+	{"Consumer", "accept", []string{"value"}},             // accept(value: T) -> Void
+	{"Supplier", "get", nil},                              // get() -> T
+	{"Predicate", "test", []string{"value"}},              // test(value: T) -> Bool
+	{"Runnable", "run", nil},                              // run() -> Void
+}
+
+// InitFunctionInterfaces initializes Function, BiFunction, Consumer, Supplier,
+// Predicate and Runnable
 func InitFunctionInterfaces(env *Env) error {
-	// Create Function<P, R> interface
-	functionInterface := &common.InterfaceDefinition{
-		Name:         "Function",
-		Type:         &ast.Type{Name: "Function", IsBuiltin: true, IsInterface: true},
-		Methods:      make(map[string][]common.MethodSignature),
-		StaticFields: make(map[string]any),
-		AccessLevel:  "public",
-		FileName:     "builtin",
-		PackageName:  "polyloft.lang",
-	}
-
-	// Add apply method: apply(param: P) -> R
-	functionInterface.Methods["apply"] = []common.MethodSignature{{
-		Name: "apply",
-		Params: []ast.Parameter{
-			{Name: "param", Type: nil}, // Generic parameter P
-		},
-		ReturnType: nil, // Generic return type R
-		HasDefault: false,
-	}}
-
-	// Register the Function interface
-	interfaceRegistry["Function"] = functionInterface
-	env.Set("Function", functionInterface)
-
-	// Create BiFunction<P1, P2, R> interface
-	biFunctionInterface := &common.InterfaceDefinition{
-		Name:         "BiFunction",
-		Type:         &ast.Type{Name: "BiFunction", IsBuiltin: true, IsInterface: true},
-		Methods:      make(map[string][]common.MethodSignature),
-		StaticFields: make(map[string]any),
-		AccessLevel:  "public",
-		FileName:     "builtin",
-		PackageName:  "polyloft.lang",
-	}
-
-	// Add apply method: apply(param1: P1, param2: P2) -> R
-	biFunctionInterface.Methods["apply"] = []common.MethodSignature{{
-		Name: "apply",
-		Params: []ast.Parameter{
-			{Name: "param1", Type: nil}, // Generic parameter P1
-			{Name: "param2", Type: nil}, // Generic parameter P2
-		},
-		ReturnType: nil, // Generic return type R
-		HasDefault: false,
-	}}
+	for _, fi := range functionalInterfaces {
+		iface := &common.InterfaceDefinition{
+			Name:         fi.name,
+			Type:         &ast.Type{Name: fi.name, IsBuiltin: true, IsInterface: true},
+			Methods:      make(map[string][]common.MethodSignature),
+			StaticFields: make(map[string]any),
+			AccessLevel:  "public",
+			FileName:     "builtin",
+			PackageName:  "polyloft.lang",
+		}
 
-	// Register the BiFunction interface
-	interfaceRegistry["BiFunction"] = biFunctionInterface
-	env.Set("BiFunction", biFunctionInterface)
+		params := make([]ast.Parameter, len(fi.params))
+		for i, name := range fi.params {
+			params[i] = ast.Parameter{Name: name, Type: nil} // Generic parameter
+		}
+		iface.Methods[fi.method] = []common.MethodSignature{{
+			Name:       fi.method,
+			Params:     params,
+			ReturnType: nil, // Generic return type
+			HasDefault: false,
+		}}
 
+		interfaceRegistry[fi.name] = iface
+		env.Set(fi.name, iface)
+	}
 	return nil
 }
 
+// functionalInterfaceArity returns the parameter count of a builtin functional interface
+func functionalInterfaceArity(name string) (int, bool) {
+	for _, fi := range functionalInterfaces {
+		if fi.name == name {
+			return len(fi.params), true
+		}
+	}
+	return 0, false
+}
+
+// functionValueMethod returns the functional-interface method name of a function value:
+// apply/accept/test/get/run call it, andThen/compose chain it with another function,
+// and negate/and/or combine predicates
+func functionValueMethod(env *Env, fnValue any, name string) (any, error) {
+	fn, _ := common.ExtractFunc(fnValue)
+	params, hasParams := functionValueParams(fnValue)
+
+	// wrap keeps the parameters of the function the result is called like
+	wrap := func(params []ast.Parameter, hasParams bool, impl common.Func) any {
+		if !hasParams {
+			return impl
+		}
+		return &common.LambdaDefinition{Func: impl, Params: params, ReturnType: ast.ANY}
+	}
+	other := func(callEnv *common.Env, value any) (common.Func, error) {
+		otherFn, ok := common.ExtractFunc(value)
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "function", value)
+		}
+		return otherFn, nil
+	}
+
+	switch name {
+	case "apply", "accept", "test", "get", "run":
+		return fn, nil
+	case "andThen":
+		// f.andThen(g)(args) = g(f(args))
+		return common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			if len(args) != 1 {
+				return nil, ThrowArityError((*Env)(callEnv), 1, len(args))
+			}
+			after, err := other(callEnv, args[0])
+			if err != nil {
+				return nil, err
+			}
+			return wrap(params, hasParams, func(e *common.Env, a []any) (any, error) {
+				result, err := fn(e, a)
+				if err != nil {
+					return nil, err
+				}
+				return after(e, []any{result})
+			}), nil
+		}), nil
+	case "compose":
+		// f.compose(g)(args) = f(g(args))
+		return common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			if len(args) != 1 {
+				return nil, ThrowArityError((*Env)(callEnv), 1, len(args))
+			}
+			before, err := other(callEnv, args[0])
+			if err != nil {
+				return nil, err
+			}
+			beforeParams, beforeHasParams := functionValueParams(args[0])
+			return wrap(beforeParams, beforeHasParams, func(e *common.Env, a []any) (any, error) {
+				result, err := before(e, a)
+				if err != nil {
+					return nil, err
+				}
+				return fn(e, []any{result})
+			}), nil
+		}), nil
+	case "negate":
+		return common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			if len(args) != 0 {
+				return nil, ThrowArityError((*Env)(callEnv), 0, len(args))
+			}
+			return wrap(params, hasParams, func(e *common.Env, a []any) (any, error) {
+				result, err := fn(e, a)
+				if err != nil {
+					return nil, err
+				}
+				return !utils.AsBool(result), nil
+			}), nil
+		}), nil
+	case "and", "or":
+		// Short-circuits like the boolean operators
+		isAnd := name == "and"
+		return common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			if len(args) != 1 {
+				return nil, ThrowArityError((*Env)(callEnv), 1, len(args))
+			}
+			second, err := other(callEnv, args[0])
+			if err != nil {
+				return nil, err
+			}
+			return wrap(params, hasParams, func(e *common.Env, a []any) (any, error) {
+				first, err := fn(e, a)
+				if err != nil {
+					return nil, err
+				}
+				if utils.AsBool(first) != isAnd {
+					return !isAnd, nil
+				}
+				result, err := second(e, a)
+				if err != nil {
+					return nil, err
+				}
+				return utils.AsBool(result), nil
+			}), nil
+		}), nil
+	case "partial":
+		return common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			return partialFunction((*Env)(callEnv), fnValue, args)
+		}), nil
+	case "curry":
+		return common.Func(func(callEnv *common.Env, args []any) (any, error) {
+			if len(args) != 0 {
+				return nil, ThrowArityError((*Env)(callEnv), 0, len(args))
+			}
+			return curryFunction((*Env)(callEnv), fnValue)
+		}), nil
+	}
+	return nil, ThrowAttributeError(env, name, "function")
+}
+
+// requiredParamCount returns the number of parameters before the variadic one
+func requiredParamCount(params []ast.Parameter) (required int, variadic bool) {
+	for _, param := range params {
+		if param.IsVariadic {
+			return required, true
+		}
+		required++
+	}
+	return required, false
+}
+
+// partialFunction returns fnValue with its first len(bound) arguments fixed.
+// The result declares the remaining parameters, so arity checks on function
+// types keep working; binding more arguments than fnValue accepts is an arity error.
+func partialFunction(env *Env, fnValue any, bound []any) (any, error) {
+	fn, ok := common.ExtractFunc(fnValue)
+	if !ok {
+		return nil, ThrowTypeError(env, "function", fnValue)
+	}
+	bound = append([]any{}, bound...)
+	impl := common.Func(func(callEnv *common.Env, args []any) (any, error) {
+		return fn(callEnv, append(append([]any{}, bound...), args...))
+	})
+
+	params, hasParams := functionValueParams(fnValue)
+	if !hasParams {
+		return impl, nil
+	}
+	required, variadic := requiredParamCount(params)
+	if !variadic && len(bound) > required {
+		return nil, ThrowArityError(env, required, len(bound))
+	}
+	remaining := params[required:]
+	if len(bound) < required {
+		remaining = params[len(bound):]
+	}
+	return &common.LambdaDefinition{Func: impl, Params: remaining, ReturnType: ast.ANY}, nil
+}
+
+// curryFunction returns an auto-curried form of fnValue: calling it with fewer
+// arguments than fnValue requires returns a function waiting for the rest, and
+// it calls fnValue as soon as all required arguments have been supplied.
+// Plain calls keep their arity checks; currying is only enabled through curry().
+func curryFunction(env *Env, fnValue any) (any, error) {
+	fn, ok := common.ExtractFunc(fnValue)
+	if !ok {
+		return nil, ThrowTypeError(env, "function", fnValue)
+	}
+	params, hasParams := functionValueParams(fnValue)
+	if !hasParams {
+		return nil, ThrowTypeError(env, "function with declared parameters", fnValue)
+	}
+	required, _ := requiredParamCount(params)
+	return curried(fn, params, required, nil), nil
+}
+
+// curried collects arguments until required of them have been given
+func curried(fn common.Func, params []ast.Parameter, required int, collected []any) any {
+	return &common.LambdaDefinition{
+		Params:     params[len(collected):],
+		ReturnType: ast.ANY,
+		Func: func(callEnv *common.Env, args []any) (any, error) {
+			if len(args) == 0 {
+				return nil, ThrowArityError((*Env)(callEnv), required-len(collected), 0)
+			}
+			all := append(append([]any{}, collected...), args...)
+			if len(all) >= required {
+				return fn(callEnv, all)
+			}
+			return curried(fn, params, required, all), nil
+		},
+	}
+}
+
+// functionValueParams returns the declared parameters of a function value;
+// builtin functions have none recorded
+func functionValueParams(fnValue any) ([]ast.Parameter, bool) {
+	switch fn := fnValue.(type) {
+	case *common.FunctionDefinition:
+		return fn.Params, true
+	case *common.LambdaDefinition:
+		return fn.Params, true
+	}
+	return nil, false
+}
+
 // WrapLambdaAsFunction wraps a lambda expression as a Function interface implementation
 func WrapLambdaAsFunction(lambda *ast.LambdaExpr, env *Env) (common.Func, error) {
 	return func(callEnv *Env, args []any) (any, error) {
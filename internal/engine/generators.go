@@ -0,0 +1,402 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// errGeneratorClosed unwinds the body of a generator closed before it finished.
+// Like a LimitError it passes through catch blocks; finally blocks and defers run.
+var errGeneratorClosed = errors.New("generator closed")
+
+// generatorItem is one value handed from a generator body to its consumer. The
+// last item has done set, with the error that ended the body, if any.
+type generatorItem struct {
+	value any
+	done  bool
+	err   error
+}
+
+// generatorState runs the body of a generator function on its own goroutine.
+// The body only runs while the consumer waits for its next value, so the two
+// never execute script code at the same time. A generator abandoned while
+// suspended keeps its goroutine until close() is called.
+type generatorState struct {
+	name    string
+	run     func(g *generatorState) error // evaluates the body
+	resume  chan bool                     // consumer -> body: true to continue, false to close
+	items   chan generatorItem            // body -> consumer
+	started bool
+	done    bool
+	pending *generatorItem // value fetched by hasNext and not yet returned by next
+}
+
+func newGeneratorState(name string, run func(g *generatorState) error) *generatorState {
+	return &generatorState{
+		name:   name,
+		run:    run,
+		resume: make(chan bool),
+		items:  make(chan generatorItem),
+	}
+}
+
+// fetch runs the body up to its next yield
+func (g *generatorState) fetch() generatorItem {
+	if g.pending != nil {
+		item := *g.pending
+		g.pending = nil
+		return item
+	}
+	if g.done {
+		return generatorItem{done: true}
+	}
+	if !g.started {
+		g.started = true
+		go func() {
+			err := g.run(g)
+			if errors.Is(err, errGeneratorClosed) {
+				err = nil
+			}
+			g.items <- generatorItem{done: true, err: err}
+		}()
+	} else {
+		g.resume <- true
+	}
+	item := <-g.items
+	if item.done {
+		g.done = true
+	}
+	return item
+}
+
+// hasNext reports whether the body yields another value, running it up to that yield
+func (g *generatorState) hasNext() (bool, error) {
+	item := g.fetch()
+	if item.err != nil {
+		return false, item.err
+	}
+	if item.done {
+		return false, nil
+	}
+	g.pending = &item
+	return true, nil
+}
+
+// yield hands value to the consumer and waits until the next value is wanted
+func (g *generatorState) yield(value any) error {
+	g.items <- generatorItem{value: value}
+	if !<-g.resume {
+		return errGeneratorClosed
+	}
+	return nil
+}
+
+// close stops a suspended body, running its finally blocks and defers
+func (g *generatorState) close() {
+	g.pending = nil
+	if !g.started || g.done {
+		g.done = true
+		return
+	}
+	for {
+		g.resume <- false
+		if item := <-g.items; item.done {
+			break
+		}
+		// The body yielded again while unwinding; keep closing
+	}
+	g.done = true
+}
+
+// containsYield reports whether a function body yields, which makes the function
+// a generator. Nested functions and classes are generators of their own.
+func containsYield(body []ast.Stmt) bool {
+	for _, st := range body {
+		switch s := st.(type) {
+		case *ast.YieldStmt:
+			return true
+		case *ast.IfStmt:
+			for _, clause := range s.Clauses {
+				if containsYield(clause.Body) {
+					return true
+				}
+			}
+			if containsYield(s.Else) {
+				return true
+			}
+		case *ast.ForInStmt:
+			if containsYield(s.Body) {
+				return true
+			}
+		case *ast.LoopStmt:
+			if containsYield(s.Body) {
+				return true
+			}
+		case *ast.DoLoopStmt:
+			if containsYield(s.Body) {
+				return true
+			}
+		case *ast.TryStmt:
+			if containsYield(s.Body) || containsYield(s.Finally) {
+				return true
+			}
+			for _, catch := range s.Catches {
+				if containsYield(catch.Body) {
+					return true
+				}
+			}
+		case *ast.SelectStmt:
+			for _, c := range s.Cases {
+				if containsYield(c.Body) {
+					return true
+				}
+			}
+		case *ast.SwitchStmt:
+			for _, c := range s.Cases {
+				if containsYield(c.Body) {
+					return true
+				}
+			}
+			if containsYield(s.Default) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newGenerator wraps a body that is ready to run, with its parameters bound in
+// local, in a Generator instance
+func newGenerator(env *Env, name string, local *Env, body []ast.Stmt) (any, error) {
+	// The body runs after the call returns, so its scope must outlive it
+	CaptureEnv(local)
+	genClass, exists := lookupClass("Generator", "")
+	if !exists {
+		return nil, ThrowInitializationError(env, "Generator class")
+	}
+	state := newGeneratorState(name, func(g *generatorState) error {
+		local.Set("$generator", g)
+		defer func() {
+			for i := len(local.Defers) - 1; i >= 0; i-- {
+				_ = local.Defers[i]()
+			}
+		}()
+		for _, st := range body {
+			_, ret, err := evalStmt(local, st)
+			if err != nil {
+				return addStackFrame(err, name)
+			}
+			if ret {
+				return nil
+			}
+		}
+		return nil
+	})
+	value, err := createClassInstance(genClass, env, []any{})
+	if err != nil {
+		return nil, err
+	}
+	value.(*ClassInstance).Fields["_generator"] = state
+	return value, nil
+}
+
+// evalYieldStmt passes a value out of the generator whose body is running
+func evalYieldStmt(env *Env, stmt *ast.YieldStmt) (val any, returned bool, err error) {
+	g, ok := lookupGenerator(env)
+	if !ok {
+		return nil, false, ThrowRuntimeError(env, "yield can only be used in the body of a function, method or block lambda")
+	}
+	value, err := evalExpr(env, stmt.Value)
+	if err != nil {
+		return nil, false, err
+	}
+	return nil, false, g.yield(value)
+}
+
+// lookupGenerator finds the generator that env belongs to
+func lookupGenerator(env *Env) (*generatorState, bool) {
+	value, ok := env.Get("$generator")
+	if !ok {
+		return nil, false
+	}
+	g, ok := value.(*generatorState)
+	return g, ok
+}
+
+// generatorOf returns the state of the Generator a method was called on
+func generatorOf(callEnv *common.Env) *generatorState {
+	thisVal, _ := callEnv.This()
+	state, _ := thisVal.(*ClassInstance).Fields["_generator"].(*generatorState)
+	return state
+}
+
+// InstallGeneratorBuiltin installs the Generator class returned by calling a
+// function that contains yield
+func InstallGeneratorBuiltin(env *Env) error {
+	boolType := common.BuiltinTypeBool.GetTypeDefinition(env)
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	voidType := &ast.Type{Name: "void", IsBuiltin: true}
+
+	builder := NewClassBuilder("Generator").
+		AddField("_generator", ast.ANY, []string{"private"})
+
+	hasNext := func(callEnv *common.Env, args []any) (any, error) {
+		return generatorOf(callEnv).hasNext()
+	}
+	next := func(callEnv *common.Env, args []any) (any, error) {
+		g := generatorOf(callEnv)
+		item := g.fetch()
+		if item.err != nil {
+			return nil, item.err
+		}
+		if item.done {
+			return nil, ThrowStateError((*Env)(callEnv), fmt.Sprintf("generator %s has no more values", g.name))
+		}
+		return item.value, nil
+	}
+
+	// __hasNext() / __next() - the protocol for..in uses for values without a length
+	builder.AddBuiltinMethod("__hasNext", boolType, []ast.Parameter{}, hasNext, []string{})
+	builder.AddBuiltinMethod("__next", ast.ANY, []ast.Parameter{}, next, []string{})
+
+	// hasNext() -> Bool - whether another value follows, running the body up to it
+	builder.AddBuiltinMethod("hasNext", boolType, []ast.Parameter{}, hasNext, []string{})
+
+	// next() -> Any - the next value; StateError once the generator is exhausted
+	builder.AddBuiltinMethod("next", ast.ANY, []ast.Parameter{}, next, []string{})
+
+	// toArray() -> Array - collect the remaining values
+	builder.AddBuiltinMethod("toArray", &ast.Type{Name: "array", IsBuiltin: true}, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		g := generatorOf(callEnv)
+		var items []any
+		for {
+			item := g.fetch()
+			if item.err != nil {
+				return nil, item.err
+			}
+			if item.done {
+				return CreateArrayInstance((*Env)(callEnv), items)
+			}
+			items = append(items, item.value)
+		}
+	}, []string{})
+
+	// close() - stop the body early, running its finally blocks and defers
+	builder.AddBuiltinMethod("close", voidType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		generatorOf(callEnv).close()
+		return nil, nil
+	}, []string{})
+
+	builder.AddBuiltinMethod("toString", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		return CreateStringInstance((*Env)(callEnv), fmt.Sprintf("Generator(%s)", generatorOf(callEnv).name))
+	}, []string{})
+
+	_, err := builder.Build(env)
+	return err
+}
+
+// forInIterator runs a for-in loop over a value that has no length and is read
+// with __hasNext() and __next() instead, such as a Generator
+func forInIterator(env *Env, s *ast.ForInStmt, instance *ClassInstance, hasNextFn, nextFn common.Func) (any, bool, error) {
+	// A loop left by break, return or an error closes the generator it reads,
+	// running its finally blocks and defers; closing an exhausted one does nothing
+	if g, ok := instance.Fields["_generator"].(*generatorState); ok {
+		defer g.close()
+	}
+	for {
+		more, err := hasNextFn(env, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		if !utils.AsBool(more) {
+			return nil, false, nil
+		}
+		el, err := nextFn(env, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := bindForInNames(env, s, el); err != nil {
+			return nil, false, err
+		}
+
+		if s.Where != nil {
+			whereResult, err := evalExpr(env, s.Where)
+			if err != nil {
+				return nil, false, err
+			}
+			if !utils.AsBool(whereResult) {
+				continue
+			}
+		}
+
+		brk, cont, ret, val, err := runBlock(env, s.Body)
+		if err != nil {
+			return nil, false, err
+		}
+		if ret {
+			return val, true, nil
+		}
+		if brk {
+			return nil, false, nil
+		}
+		if cont {
+			continue
+		}
+	}
+}
+
+// bindForInNames assigns one element to the loop variables, destructuring it
+// when the loop names more than one
+func bindForInNames(env *Env, s *ast.ForInStmt, el any) error {
+	if len(s.Names) <= 1 {
+		name := s.Name
+		if len(s.Names) > 0 {
+			name = s.Names[0]
+		}
+		env.Set(name, el)
+		return nil
+	}
+
+	var pieces []any
+	switch elVal := el.(type) {
+	case []any:
+		pieces = elVal
+	case *ClassInstance:
+		unstructured := common.BuiltinInterfaceUnstructured.GetInterfaceDefinition(env)
+		if elVal.ParentClass != nil && elVal.ParentClass.ImplementsInterface(unstructured) {
+			piecesFunc, _ := common.ExtractFunc(elVal.Methods["__pieces"])
+			getPieceFunc, _ := common.ExtractFunc(elVal.Methods["__get_piece"])
+			countVal, err := piecesFunc(env, nil)
+			if err != nil {
+				return err
+			}
+			count, _ := utils.AsInt(countVal)
+			if count != len(s.Names) {
+				return fmt.Errorf("destructuring mismatch: expected %d vars, got %d", len(s.Names), count)
+			}
+			for i := 0; i < count; i++ {
+				piece, err := getPieceFunc(env, []any{i})
+				if err != nil {
+					return err
+				}
+				pieces = append(pieces, piece)
+			}
+		} else {
+			pieces = []any{el}
+		}
+	default:
+		pieces = []any{el}
+	}
+	for i, name := range s.Names {
+		if i < len(pieces) {
+			env.Set(name, pieces[i])
+		} else {
+			env.Set(name, nil)
+		}
+	}
+	return nil
+}
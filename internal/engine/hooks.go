@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"sync/atomic"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+)
+
+// runHooks are the settings of one run of a program that evaluation consults as
+// it goes. They belong to the program's top-level Env rather than to package
+// state, so programs running side by side each keep their own, and threads,
+// promises and handlers still see them after the run returns.
+type runHooks struct {
+	debugger      *Debugger
+	coverage      *Coverage
+	limits        *limitState // nil when the run is unlimited
+	deterministic bool        // see Options.Deterministic
+	modules       ModuleBundle
+
+	// Set by RunTests while it runs a test file
+	specs     *specCollector
+	snapshots *snapshotStore
+}
+
+// hookSlot holds the hooks of the latest run of a program. It is stored in the
+// Hooks field of the top-level Env once, when the Env is created, and a run
+// swaps its hooks in atomically. Work that outlives a run does not read the slot:
+// its Env holds that run's *runHooks instead, see spawnEnv and hooksForCall.
+type hookSlot struct {
+	current atomic.Pointer[runHooks]
+}
+
+// hooksOf returns the hooks env runs with, nil when env is not part of a
+// running program
+func hooksOf(env *Env) *runHooks {
+	for cur := env; cur != nil; cur = cur.Parent {
+		switch hooks := cur.Hooks.(type) {
+		case *runHooks:
+			return hooks
+		case *hookSlot:
+			return hooks.current.Load()
+		}
+	}
+	return nil
+}
+
+// spawnEnv returns a child of env for a thread, promise or handler that may
+// outlive the current run. It keeps that run's hooks, so a later run of the same
+// program does not hand it a fresh budget.
+func spawnEnv(env *Env) *Env {
+	child := &Env{Parent: env, Vars: map[string]any{}, Consts: map[string]bool{}}
+	if hooks := hooksOf(env); hooks != nil {
+		child.Hooks = hooks
+	}
+	return child
+}
+
+// hooksForCall returns the hooks for a call of a function or lambda defined in
+// defEnv: the caller's, so a thread keeps its run's limits in the functions it
+// calls, or the definition's when the caller has none
+func hooksForCall(callEnv, defEnv *Env) *runHooks {
+	if hooks := hooksOf(callEnv); hooks != nil {
+		return hooks
+	}
+	return hooksOf(defEnv)
+}
+
+// newRunHooks returns the debugger, coverage collector, limits, output order and
+// modules of opts as the hooks for a run of prog from fileName
+func newRunHooks(opts Options, fileName string, prog *ast.Program) *runHooks {
+	hooks := &runHooks{
+		debugger:      opts.Debugger,
+		coverage:      opts.Coverage,
+		deterministic: opts.Deterministic,
+		modules:       opts.Modules,
+	}
+	if opts.Coverage != nil {
+		opts.Coverage.AddProgram(fileName, prog)
+	}
+	if limits := effectiveLimits(opts); limits != nil {
+		hooks.limits = newLimitState(*limits)
+	}
+	return hooks
+}
+
+// installHooks makes the hooks of opts those of env's program, for a run of prog
+// from fileName
+func installHooks(env *Env, opts Options, fileName string, prog *ast.Program) *runHooks {
+	return storeHooks(env, newRunHooks(opts, fileName, prog))
+}
+
+// storeHooks publishes hooks, which must be complete, to env's program
+func storeHooks(env *Env, hooks *runHooks) *runHooks {
+	slot, ok := env.Hooks.(*hookSlot)
+	if !ok {
+		slot = &hookSlot{}
+		env.Hooks = slot
+	}
+	slot.current.Store(hooks)
+	return hooks
+}
+
+// limitsOf returns the budget of the run env belongs to, nil when it is unlimited
+func limitsOf(env *Env) *limitState {
+	if hooks := hooksOf(env); hooks != nil {
+		return hooks.limits
+	}
+	return nil
+}
+
+// isDeterministic reports whether env's program runs with Options.Deterministic
+func isDeterministic(env *Env) bool {
+	hooks := hooksOf(env)
+	return hooks != nil && hooks.deterministic
+}
+
+// modulesOf returns the bundled modules of env's program, nil when imports are
+// read from disk
+func modulesOf(env *Env) ModuleBundle {
+	if hooks := hooksOf(env); hooks != nil {
+		return hooks.modules
+	}
+	return nil
+}
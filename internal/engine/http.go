@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ArubikU/polyloft/internal/ast"
@@ -27,6 +30,9 @@ func InstallHttpModule(env *Env, opts Options) {
 	mapType := common.BuiltinTypeMap.GetTypeDefinition(env)
 	voidType := &ast.Type{Name: "void", IsBuiltin: true}
 
+	httpMaxResponseSize.Store(0)
+	resetHttpClientConfig()
+
 	// Step 1: Create HttpRequest builder and get its type BEFORE building
 	httpRequestBuilder := NewClassBuilder("HttpRequest").
 		AddField("method", stringType, []string{"public"}).
@@ -35,7 +41,11 @@ func InstallHttpModule(env *Env, opts Options) {
 		AddField("headers", mapType, []string{"public"}).
 		AddField("query", mapType, []string{"public"}).
 		AddField("params", mapType, []string{"public"}).  // 3.7: Route parameters
-		AddField("body", ast.ANY, []string{"public"})
+		AddField("body", ast.ANY, []string{"public"}).
+		AddField("_request", ast.ANY, []string{"private"}).
+		AddBuiltinMethod("cookie", ast.ANY, []ast.Parameter{
+			{Name: "name", Type: stringType},
+		}, common.Func(httpRequestCookie), []string{})
 
 	// Step 2: Create HttpResponse builder and get its type BEFORE building
 	httpResponseBuilder := NewClassBuilder("HttpResponse").
@@ -56,6 +66,15 @@ func InstallHttpModule(env *Env, opts Options) {
 			{Name: "name", Type: stringType},
 			{Name: "value", Type: stringType},
 		}, common.Func(httpResponseHeader), []string{}).
+		AddBuiltinMethod("cookie", httpResponseType, []ast.Parameter{
+			{Name: "name", Type: stringType},
+			{Name: "value", Type: stringType},
+		}, common.Func(httpResponseCookie), []string{}).
+		AddBuiltinMethod("cookie", httpResponseType, []ast.Parameter{
+			{Name: "name", Type: stringType},
+			{Name: "value", Type: stringType},
+			{Name: "options", Type: mapType},
+		}, common.Func(httpResponseCookie), []string{}).
 		AddBuiltinMethod("json", voidType, []ast.Parameter{
 			{Name: "data", Type: ast.ANY},
 		}, common.Func(httpResponseJson), []string{}).
@@ -134,6 +153,10 @@ func InstallHttpModule(env *Env, opts Options) {
 		AddBuiltinMethod("use", voidType, []ast.Parameter{
 			{Name: "middleware", Type: ast.ANY},
 		}, common.Func(httpServerUse), []string{}).
+		AddBuiltinMethod("static", voidType, []ast.Parameter{
+			{Name: "urlPrefix", Type: stringType},
+			{Name: "dir", Type: stringType},
+		}, common.Func(httpServerStatic), []string{}).
 		AddBuiltinMethod("onError", voidType, []ast.Parameter{
 			{Name: "handler", Type: ast.ANY},
 		}, common.Func(httpServerOnError), []string{}).
@@ -158,30 +181,112 @@ func InstallHttpModule(env *Env, opts Options) {
 
 	httpServerType := httpServerBuilder.GetType()
 
+	httpSessionBuilder := newHttpSessionBuilder(env)
+	httpSessionType := httpSessionBuilder.GetType()
+
+	webSocketBuilder := newWebSocketBuilder(env)
+	webSocketType := webSocketBuilder.GetType()
+
 	// Get Promise type for async methods
 	promiseType := common.BuiltinTypePromise.GetTypeDefinition(env)
 
 	// Step 5: Create Http class with static methods using proper type references
 	httpStaticClassBuilder := NewClassBuilder("Http").
-		// Existing synchronous methods
+		// Existing synchronous methods; the optional timeout may be replaced by, or
+		// followed by, a Map of request headers
+		AddStaticMethod("get", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+		}, common.Func(httpGet)).
+		AddStaticMethod("get", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "timeout", Type: ast.ANY},
+		}, common.Func(httpGet)).
 		AddStaticMethod("get", mapType, []ast.Parameter{
 			{Name: "url", Type: stringType},
 			{Name: "timeout", Type: intType},
+			{Name: "headers", Type: mapType},
 		}, common.Func(httpGet)).
+		AddStaticMethod("post", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "data", Type: ast.ANY},
+		}, common.Func(httpPost)).
+		AddStaticMethod("post", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "data", Type: ast.ANY},
+			{Name: "timeout", Type: ast.ANY},
+		}, common.Func(httpPost)).
 		AddStaticMethod("post", mapType, []ast.Parameter{
 			{Name: "url", Type: stringType},
 			{Name: "data", Type: ast.ANY},
 			{Name: "timeout", Type: intType},
+			{Name: "headers", Type: mapType},
 		}, common.Func(httpPost)).
+		AddStaticMethod("postForm", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "fields", Type: ast.ANY},
+		}, common.Func(httpPostForm)).
+		AddStaticMethod("postForm", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "fields", Type: ast.ANY},
+			{Name: "timeout", Type: ast.ANY},
+		}, common.Func(httpPostForm)).
+		AddStaticMethod("postForm", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "fields", Type: ast.ANY},
+			{Name: "timeout", Type: intType},
+			{Name: "headers", Type: mapType},
+		}, common.Func(httpPostForm)).
+		AddStaticMethod("put", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "data", Type: ast.ANY},
+		}, common.Func(httpPut)).
+		AddStaticMethod("put", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "data", Type: ast.ANY},
+			{Name: "timeout", Type: ast.ANY},
+		}, common.Func(httpPut)).
 		AddStaticMethod("put", mapType, []ast.Parameter{
 			{Name: "url", Type: stringType},
 			{Name: "data", Type: ast.ANY},
 			{Name: "timeout", Type: intType},
+			{Name: "headers", Type: mapType},
 		}, common.Func(httpPut)).
+		AddStaticMethod("delete", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+		}, common.Func(httpDelete)).
+		AddStaticMethod("delete", mapType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "timeout", Type: ast.ANY},
+		}, common.Func(httpDelete)).
 		AddStaticMethod("delete", mapType, []ast.Parameter{
 			{Name: "url", Type: stringType},
 			{Name: "timeout", Type: intType},
+			{Name: "headers", Type: mapType},
 		}, common.Func(httpDelete)).
+		AddStaticMethod("download", intType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "path", Type: stringType},
+		}, common.Func(httpDownload)).
+		AddStaticMethod("download", intType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+			{Name: "path", Type: stringType},
+			{Name: "timeout", Type: intType},
+		}, common.Func(httpDownload)).
+		AddStaticMethod("session", httpSessionType, []ast.Parameter{}, common.Func(httpSession)).
+		AddStaticMethod("connectWs", webSocketType, []ast.Parameter{
+			{Name: "url", Type: stringType},
+		}, common.Func(httpConnectWs)).
+		// Middleware factories for HttpServer
+		AddStaticMethod("cors", ast.ANY, []ast.Parameter{}, common.Func(httpCors)).
+		AddStaticMethod("cors", ast.ANY, []ast.Parameter{
+			{Name: "options", Type: mapType},
+		}, common.Func(httpCors)).
+		AddStaticMethod("configure", voidType, []ast.Parameter{
+			{Name: "options", Type: mapType},
+		}, common.Func(httpConfigure)).
+		AddStaticMethod("setMaxResponseSize", voidType, []ast.Parameter{
+			{Name: "bytes", Type: intType},
+		}, common.Func(httpSetMaxResponseSize)).
 		// Simplified request - 3.3: Allow body without options wrapper
 		AddStaticMethod("request", mapType, []ast.Parameter{
 			{Name: "method", Type: stringType},
@@ -248,197 +353,301 @@ func InstallHttpModule(env *Env, opts Options) {
 	_, _ = httpRequestBuilder.Build(env)
 	_, _ = httpResponseBuilder.Build(env)
 	_, _ = httpServerBuilder.Build(env)
+	_, _ = httpSessionBuilder.Build(env)
+	_, _ = webSocketBuilder.Build(env)
 	_, _ = httpStaticClassBuilder.BuildStatic(env)
 }
 
 // httpGet performs an HTTP GET request
 func httpGet(e *common.Env, args []any) (any, error) {
-	url := utils.ToString(args[0])
-	timeout := 30 * time.Second
-	if len(args) > 1 {
-		if t, ok := utils.AsInt(args[1]); ok {
-			timeout = time.Duration(t) * time.Second
-		}
-	}
+	return httpShortcut(e, nil, "GET", nil, args)
+}
 
-	client := &http.Client{Timeout: timeout}
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// httpPost performs an HTTP POST request
+func httpPost(e *common.Env, args []any) (any, error) {
+	return httpShortcut(e, nil, "POST", jsonBody, args)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+// httpPut performs an HTTP PUT request
+func httpPut(e *common.Env, args []any) (any, error) {
+	return httpShortcut(e, nil, "PUT", jsonBody, args)
+}
 
-	return createHttpResponse((*Env)(e), resp, body), nil
+// httpDelete performs an HTTP DELETE request
+func httpDelete(e *common.Env, args []any) (any, error) {
+	return httpShortcut(e, nil, "DELETE", nil, args)
 }
 
-// httpPost performs an HTTP POST request
-func httpPost(e *common.Env, args []any) (any, error) {
-	url := utils.ToString(args[0])
+// httpPostForm performs an HTTP POST request with a form-encoded body
+func httpPostForm(e *common.Env, args []any) (any, error) {
+	return httpShortcut(e, nil, "POST", formBody, args)
+}
 
-	bodyBytes, err := prepareRequestBody(args[1])
-	if err != nil {
-		return nil, err
-	}
+// httpRequest performs a custom HTTP request
+func httpRequest(e *common.Env, args []any) (any, error) {
+	return httpCustomRequest(e, nil, args)
+}
 
-	timeout := 30 * time.Second
-	if len(args) > 2 {
-		if t, ok := utils.AsInt(args[2]); ok {
-			timeout = time.Duration(t) * time.Second
+// httpBodyEncoder turns the data argument of a request helper into the body bytes
+// and the Content-Type they are sent with
+type httpBodyEncoder func(env *Env, data any) ([]byte, string, error)
+
+// jsonBody encodes Maps and Arrays as JSON; other values are sent as text
+func jsonBody(env *Env, data any) ([]byte, string, error) {
+	body, err := prepareRequestBody(env, data)
+	return body, "application/json", err
+}
+
+// formBody encodes a Map as application/x-www-form-urlencoded. Array values
+// repeat the field once per element; a String is sent as already encoded.
+func formBody(env *Env, data any) ([]byte, string, error) {
+	const contentType = "application/x-www-form-urlencoded"
+	if isStringValue(data) {
+		return []byte(StringValue(data)), contentType, nil
+	}
+	fields, err := httpOptions(env, data)
+	if err != nil {
+		return nil, "", ThrowTypeError(env, "a Map of form fields", data)
+	}
+	values := url.Values{}
+	for _, field := range fields {
+		switch v := field.value.(type) {
+		case nil:
+			values.Add(field.name, "")
+		case []any:
+			for _, item := range v {
+				values.Add(field.name, utils.ToString(item))
+			}
+		case *ClassInstance:
+			if items, ok := v.Fields["_items"].([]any); ok && v.ClassName == "Array" {
+				for _, item := range items {
+					values.Add(field.name, utils.ToString(item))
+				}
+				continue
+			}
+			values.Add(field.name, utils.ToString(v))
+		default:
+			values.Add(field.name, utils.ToString(v))
 		}
 	}
+	return []byte(values.Encode()), contentType, nil
+}
 
-	client := &http.Client{Timeout: timeout}
-	resp, err := client.Post(url, "application/json", bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return nil, err
+// isStringValue reports whether v is a String, boxed or plain
+func isStringValue(v any) bool {
+	if instance, ok := v.(*ClassInstance); ok {
+		return instance.ClassName == "String"
 	}
-	defer resp.Body.Close()
+	_, ok := v.(string)
+	return ok
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// httpShortcut implements get/post/put/delete from their arguments: the URL, the
+// body when encode is set, then the optional timeout and headers. session, when
+// not nil, supplies the cookie jar and interceptors of an HttpSession.
+func httpShortcut(e *common.Env, session *httpSessionState, method string, encode httpBodyEncoder, args []any) (any, error) {
+	required := 1
+	if encode != nil {
+		required = 2
+	}
+	if len(args) < required {
+		return nil, ThrowArityError((*Env)(e), required, len(args))
 	}
 
-	return createHttpResponse((*Env)(e), resp, body), nil
+	var bodyBytes []byte
+	var contentType string
+	if encode != nil {
+		var err error
+		bodyBytes, contentType, err = encode((*Env)(e), args[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sendHttpRequest(e, session, method, utils.ToString(args[0]), bodyBytes, contentType, args[required:])
 }
 
-// httpPut performs an HTTP PUT request
-func httpPut(e *common.Env, args []any) (any, error) {
+// httpCustomRequest implements request(method, url, data?, timeout?, headers?)
+func httpCustomRequest(e *common.Env, session *httpSessionState, args []any) (any, error) {
 	if len(args) < 2 {
 		return nil, ThrowArityError((*Env)(e), 2, len(args))
 	}
-	url := utils.ToString(args[0])
+	method := utils.ToString(args[0])
+	url := utils.ToString(args[1])
 
-	bodyBytes, err := prepareRequestBody(args[1])
-	if err != nil {
-		return nil, err
+	var bodyBytes []byte
+	var contentType string
+	if len(args) > 2 && args[2] != nil {
+		var err error
+		bodyBytes, contentType, err = jsonBody((*Env)(e), args[2])
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	timeout := 30 * time.Second
-	if len(args) > 2 {
-		if t, ok := utils.AsInt(args[2]); ok {
+	var options []any
+	if len(args) > 3 {
+		options = args[3:]
+	}
+	return sendHttpRequest(e, session, method, url, bodyBytes, contentType, options)
+}
+
+// sendHttpRequest performs a synchronous request and builds the response Map.
+// options holds the trailing arguments of the helpers: a timeout in seconds,
+// a headers Map, or a timeout followed by a headers Map.
+func sendHttpRequest(e *common.Env, session *httpSessionState, method, url string, body []byte, contentType string, options []any) (any, error) {
+	timeout := httpTimeout()
+	var headers any
+	for i, option := range options {
+		if t, ok := utils.AsInt(option); ok && i == 0 {
 			timeout = time.Duration(t) * time.Second
+		} else if option != nil {
+			headers = option
+		}
+	}
+
+	var jar http.CookieJar
+	if session != nil {
+		jar = session.cookieJar()
+		var err error
+		if method, url, body, contentType, headers, err = session.interceptRequest((*Env)(e), method, url, body, contentType, headers); err != nil {
+			return nil, err
 		}
 	}
 
-	client := &http.Client{Timeout: timeout}
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(bodyBytes))
+	var reqBody io.Reader
+	if len(body) > 0 {
+		reqBody = bytes.NewBuffer(body)
+	}
+	req, err := http.NewRequest(method, url, reqBody)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	if body != nil && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if err := applyRequestHeaders((*Env)(e), req, headers); err != nil {
+		return nil, err
+	}
 
+	client := newHttpClient(timeout)
+	client.Jar = jar
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := readResponseBody((*Env)(e), resp)
 	if err != nil {
 		return nil, err
 	}
 
-	return createHttpResponse((*Env)(e), resp, body), nil
+	response := createHttpResponse((*Env)(e), resp, respBody)
+	if session != nil {
+		return session.interceptResponse((*Env)(e), response)
+	}
+	return response, nil
 }
 
-// httpDelete performs an HTTP DELETE request
-func httpDelete(e *common.Env, args []any) (any, error) {
-	if len(args) < 1 {
-		return nil, ThrowArityError((*Env)(e), 1, len(args))
-	}
-	url := utils.ToString(args[0])
+// httpMaxResponseSize caps the bodies the client helpers read into memory, in
+// bytes; 0 means no limit. Set with Http.setMaxResponseSize.
+var httpMaxResponseSize atomic.Int64
 
-	timeout := 30 * time.Second
-	if len(args) > 1 {
-		if t, ok := utils.AsInt(args[1]); ok {
-			timeout = time.Duration(t) * time.Second
-		}
+// readResponseBody reads a response body, failing once it grows past the
+// configured maximum instead of buffering it whole
+func readResponseBody(env *Env, resp *http.Response) ([]byte, error) {
+	limit := httpMaxResponseSize.Load()
+	if limit <= 0 {
+		return io.ReadAll(resp.Body)
 	}
-
-	client := &http.Client{Timeout: timeout}
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return nil, err
+	tooLarge := func() error {
+		return ThrowRuntimeError(env, fmt.Sprintf("response body from %s exceeds the maximum size of %d bytes", resp.Request.URL, limit))
 	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	if resp.ContentLength > limit {
+		return nil, tooLarge()
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
 	if err != nil {
 		return nil, err
 	}
-
-	return createHttpResponse((*Env)(e), resp, body), nil
+	if int64(len(body)) > limit {
+		return nil, tooLarge()
+	}
+	return body, nil
 }
 
-// httpRequest performs a custom HTTP request
-func httpRequest(e *common.Env, args []any) (any, error) {
-	if len(args) < 2 {
-		return nil, ThrowArityError((*Env)(e), 2, len(args))
+// httpSetMaxResponseSize implements Http.setMaxResponseSize(bytes)
+func httpSetMaxResponseSize(e *common.Env, args []any) (any, error) {
+	limit, ok := utils.AsInt(args[0])
+	if !ok {
+		return nil, ThrowTypeError((*Env)(e), "Int", args[0])
 	}
-	method := utils.ToString(args[0])
-	url := utils.ToString(args[1])
-
-	var bodyBytes []byte
-	if len(args) > 2 && args[2] != nil {
-		var err error
-		bodyBytes, err = prepareRequestBody(args[2])
-		if err != nil {
-			return nil, err
-		}
+	if limit < 0 {
+		return nil, ThrowValueError((*Env)(e), fmt.Sprintf("maximum response size must not be negative, got %d", limit))
 	}
+	httpMaxResponseSize.Store(int64(limit))
+	return nil, nil
+}
 
-	timeout := 30 * time.Second
-	if len(args) > 3 {
-		if t, ok := utils.AsInt(args[3]); ok {
+// httpDownload implements Http.download(url, path, timeout?): the response body is
+// streamed straight into the file, so it is not subject to the maximum response
+// size. It returns the number of bytes written.
+func httpDownload(e *common.Env, args []any) (any, error) {
+	env := (*Env)(e)
+	url := utils.ToString(args[0])
+	path := utils.ToString(args[1])
+	timeout := httpTimeout()
+	if len(args) > 2 {
+		if t, ok := utils.AsInt(args[2]); ok {
 			timeout = time.Duration(t) * time.Second
 		}
 	}
 
-	client := &http.Client{Timeout: timeout}
-	var req *http.Request
-	var err error
-	if len(bodyBytes) > 0 {
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(bodyBytes))
-	} else {
-		req, err = http.NewRequest(method, url, nil)
-	}
+	client := newHttpClient(timeout)
+	resp, err := client.Get(url)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Add custom headers if provided
-	if len(args) > 4 {
-		if headers, ok := args[4].(map[string]any); ok {
-			for key, value := range headers {
-				req.Header.Set(key, utils.ToString(value))
-			}
-		}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, ThrowRuntimeError(env, fmt.Sprintf("download of %s failed: %s", url, resp.Status))
 	}
 
-	resp, err := client.Do(req)
+	file, err := os.Create(path)
 	if err != nil {
-		return nil, err
+		return nil, ThrowIOError(env, err.Error())
+	}
+	written, err := io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		os.Remove(path)
+		return nil, ThrowIOError(env, fmt.Sprintf("download of %s failed: %v", url, err))
 	}
+	return CreateIntInstance(env, int(written))
+}
 
-	return createHttpResponse((*Env)(e), resp, body), nil
+// applyRequestHeaders sets the entries of a headers Map (or plain Go map) on req,
+// replacing defaults such as Content-Type
+func applyRequestHeaders(env *Env, req *http.Request, headers any) error {
+	switch h := headers.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		for key, value := range h {
+			req.Header.Set(key, utils.ToString(value))
+		}
+		return nil
+	case *ClassInstance:
+		if h.ClassName == "Map" {
+			for _, entry := range orderedMapEntries(h, false) {
+				req.Header.Set(utils.ToString(entry.Key), utils.ToString(entry.Value))
+			}
+			return nil
+		}
+	}
+	return ThrowTypeError(env, "a Map of headers", headers)
 }
 
 // createHttpServer creates a new HTTP server instance
@@ -635,6 +844,9 @@ func httpServerListen(e *common.Env, args []any) (any, error) {
 	if !strings.Contains(port, ":") {
 		port = ":" + port
 	}
+	// Requests keep being handled after the run that started the server, within
+	// that run's limits
+	e = spawnEnv(e)
 
 	// Create HTTP handler with timeout support - 3.13
 	httpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -756,28 +968,23 @@ func httpResponseHtml(e *common.Env, args []any) (any, error) {
 
 // Helper functions
 
-// prepareRequestBody converts request data to JSON bytes
-func prepareRequestBody(data any) ([]byte, error) {
-	// Handle Map instances
-	if mapInstance, ok := data.(*ClassInstance); ok && mapInstance.ClassName == "Map" {
-		// Note: We can't pass env here since we don't have it, so we'll use a simple extraction
-		// This is a limitation but works for most cases
-		objMap := make(map[string]any)
-		if hashData, ok := mapInstance.Fields["_data"].(map[uint64][]*mapEntry); ok {
-			for _, entries := range hashData {
-				for _, entry := range entries {
-					keyStr := utils.ToString(entry.Key)
-					objMap[keyStr] = entry.Value
-				}
-			}
-		}
-		return json.Marshal(objMap)
+// prepareRequestBody converts request data to JSON bytes; Strings are sent as-is
+func prepareRequestBody(env *Env, data any) ([]byte, error) {
+	encode := false
+	switch v := data.(type) {
+	case map[string]any, []any:
+		encode = true
+	case *ClassInstance:
+		encode = v.ClassName == "Map" || v.ClassName == "Array"
 	}
-	// Handle plain Go maps
-	if dataMap, ok := data.(map[string]any); ok {
-		return json.Marshal(dataMap)
+	if !encode {
+		return []byte(utils.ToString(data)), nil
+	}
+	text, err := stringifyJSON(env, data, "")
+	if err != nil {
+		return nil, err
 	}
-	return []byte(utils.ToString(data)), nil
+	return []byte(text), nil
 }
 
 // createHttpResponse creates a standardized HTTP response object
@@ -869,6 +1076,7 @@ type httpRouter struct {
 	config            map[string]any
 	logLevel          string
 	wsHandlers        map[string]common.Func // WebSocket handlers
+	staticMounts      []*staticMount         // directories served with static()
 }
 
 func (r *httpRouter) addRoute(method, path string, handler common.Func, middlewares []common.Func) {
@@ -1015,6 +1223,7 @@ func (r *httpRouter) handleRequest(env *common.Env, w http.ResponseWriter, req *
 	dynamicRoutes := r.dynamicRoutes[req.Method]
 	errorHandler := r.errorHandler
 	globalMiddlewares := r.globalMiddlewares
+	staticMounts := r.staticMounts
 	r.mu.RUnlock()
 
 	// Try to find matching route (static first, then dynamic)
@@ -1040,7 +1249,20 @@ func (r *httpRouter) handleRequest(env *common.Env, w http.ResponseWriter, req *
 		}
 	}
 
-	// 3. No route found
+	// 3. Static files, unless a more specific route matched
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		if mount := staticMountFor(staticMounts, req.URL.Path); mount != nil && (routeHandler == nil || !routeOverridesMount(routeHandler, mount)) {
+			mount.handler.ServeHTTP(w, req)
+			return
+		}
+	}
+
+	// 4. Preflight requests reach the global middlewares even without a route
+	if routeHandler == nil && req.Method == http.MethodOptions && len(globalMiddlewares) > 0 {
+		routeHandler = preflightFallback()
+	}
+
+	// 5. No route found
 	if routeHandler == nil {
 		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(`{"error": "Not Found"}`))
@@ -1101,6 +1323,7 @@ func (r *httpRouter) handleRequest(env *common.Env, w http.ResponseWriter, req *
 			}
 			requestInstance.Fields["params"], _ = CreateMapInstance(env, routeParamsAny)
 			requestInstance.Fields["body"], _ = CreateGenericInstance(env, bodyData)
+			requestInstance.Fields["_request"] = req
 		}
 	}
 
@@ -1502,7 +1725,7 @@ func (r *httpResponse) sendHTML(html string) {
 // httpGetAsync performs an async HTTP GET request returning a Promise
 func httpGetAsync(e *common.Env, args []any) (any, error) {
 	url := utils.ToString(args[0])
-	timeout := 30 * time.Second
+	timeout := httpTimeout()
 	if len(args) > 1 {
 		if t, ok := utils.AsInt(args[1]); ok {
 			timeout = time.Duration(t) * time.Second
@@ -1510,14 +1733,14 @@ func httpGetAsync(e *common.Env, args []any) (any, error) {
 	}
 
 	return createHttpPromise((*Env)(e), func() (any, error) {
-		client := &http.Client{Timeout: timeout}
+		client := newHttpClient(timeout)
 		resp, err := client.Get(url)
 		if err != nil {
 			return nil, err
 		}
 		defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
+		body, err := readResponseBody((*Env)(e), resp)
 		if err != nil {
 			return nil, err
 		}
@@ -1530,12 +1753,12 @@ func httpGetAsync(e *common.Env, args []any) (any, error) {
 func httpPostAsync(e *common.Env, args []any) (any, error) {
 	url := utils.ToString(args[0])
 	
-	bodyBytes, err := prepareRequestBody(args[1])
+	bodyBytes, err := prepareRequestBody((*Env)(e), args[1])
 	if err != nil {
 		return nil, err
 	}
 
-	timeout := 30 * time.Second
+	timeout := httpTimeout()
 	if len(args) > 2 {
 		if t, ok := utils.AsInt(args[2]); ok {
 			timeout = time.Duration(t) * time.Second
@@ -1543,14 +1766,14 @@ func httpPostAsync(e *common.Env, args []any) (any, error) {
 	}
 
 	return createHttpPromise((*Env)(e), func() (any, error) {
-		client := &http.Client{Timeout: timeout}
+		client := newHttpClient(timeout)
 		resp, err := client.Post(url, "application/json", bytes.NewBuffer(bodyBytes))
 		if err != nil {
 			return nil, err
 		}
 		defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
+		body, err := readResponseBody((*Env)(e), resp)
 		if err != nil {
 			return nil, err
 		}
@@ -1563,12 +1786,12 @@ func httpPostAsync(e *common.Env, args []any) (any, error) {
 func httpPutAsync(e *common.Env, args []any) (any, error) {
 	url := utils.ToString(args[0])
 	
-	bodyBytes, err := prepareRequestBody(args[1])
+	bodyBytes, err := prepareRequestBody((*Env)(e), args[1])
 	if err != nil {
 		return nil, err
 	}
 
-	timeout := 30 * time.Second
+	timeout := httpTimeout()
 	if len(args) > 2 {
 		if t, ok := utils.AsInt(args[2]); ok {
 			timeout = time.Duration(t) * time.Second
@@ -1576,7 +1799,7 @@ func httpPutAsync(e *common.Env, args []any) (any, error) {
 	}
 
 	return createHttpPromise((*Env)(e), func() (any, error) {
-		client := &http.Client{Timeout: timeout}
+		client := newHttpClient(timeout)
 		req, err := http.NewRequest("PUT", url, bytes.NewBuffer(bodyBytes))
 		if err != nil {
 			return nil, err
@@ -1589,7 +1812,7 @@ func httpPutAsync(e *common.Env, args []any) (any, error) {
 		}
 		defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
+		body, err := readResponseBody((*Env)(e), resp)
 		if err != nil {
 			return nil, err
 		}
@@ -1602,7 +1825,7 @@ func httpPutAsync(e *common.Env, args []any) (any, error) {
 func httpDeleteAsync(e *common.Env, args []any) (any, error) {
 	url := utils.ToString(args[0])
 
-	timeout := 30 * time.Second
+	timeout := httpTimeout()
 	if len(args) > 1 {
 		if t, ok := utils.AsInt(args[1]); ok {
 			timeout = time.Duration(t) * time.Second
@@ -1610,7 +1833,7 @@ func httpDeleteAsync(e *common.Env, args []any) (any, error) {
 	}
 
 	return createHttpPromise((*Env)(e), func() (any, error) {
-		client := &http.Client{Timeout: timeout}
+		client := newHttpClient(timeout)
 		req, err := http.NewRequest("DELETE", url, nil)
 		if err != nil {
 			return nil, err
@@ -1622,7 +1845,7 @@ func httpDeleteAsync(e *common.Env, args []any) (any, error) {
 		}
 		defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
+		body, err := readResponseBody((*Env)(e), resp)
 		if err != nil {
 			return nil, err
 		}
@@ -1642,15 +1865,15 @@ func httpRequestAsync(e *common.Env, args []any) (any, error) {
 	var bodyBytes []byte
 	if len(args) > 2 && args[2] != nil {
 		var err error
-		bodyBytes, err = prepareRequestBody(args[2])
+		bodyBytes, err = prepareRequestBody((*Env)(e), args[2])
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	return createHttpPromise((*Env)(e), func() (any, error) {
-		timeout := 30 * time.Second
-		client := &http.Client{Timeout: timeout}
+		timeout := httpTimeout()
+		client := newHttpClient(timeout)
 		
 		var req *http.Request
 		var err error
@@ -1670,7 +1893,7 @@ func httpRequestAsync(e *common.Env, args []any) (any, error) {
 		}
 		defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
+		body, err := readResponseBody((*Env)(e), resp)
 		if err != nil {
 			return nil, err
 		}
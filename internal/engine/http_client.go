@@ -0,0 +1,454 @@
+package engine
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+	"github.com/ArubikU/polyloft/internal/version"
+)
+
+// httpDefaultTimeout is the request timeout used until Http.configure sets one
+const httpDefaultTimeout = 30 * time.Second
+
+// httpClientConfig holds the process-wide client settings applied by Http.configure
+type httpClientConfig struct {
+	proxy     *url.URL       // nil uses HTTP_PROXY/HTTPS_PROXY from the environment
+	rootCAs   *x509.CertPool // system roots plus the bundles added with caFile
+	insecure  bool           // skip certificate verification
+	timeout   time.Duration  // 0 uses httpDefaultTimeout
+	headers   http.Header    // sent with every request that does not set them itself
+	transport *http.Transport
+}
+
+var (
+	httpConfigMu sync.RWMutex
+	httpConfig   httpClientConfig
+)
+
+// resetHttpClientConfig restores the default client settings for a new program
+func resetHttpClientConfig() {
+	httpConfigMu.Lock()
+	httpConfig = httpClientConfig{}
+	httpConfigMu.Unlock()
+}
+
+// defaultHttpHeaders returns the headers every request starts from: a User-Agent
+// identifying Polyloft
+func defaultHttpHeaders() http.Header {
+	headers := http.Header{}
+	headers.Set("User-Agent", "Polyloft/"+strings.TrimPrefix(version.Version, "v"))
+	return headers
+}
+
+// httpTimeout returns the configured default request timeout
+func httpTimeout() time.Duration {
+	httpConfigMu.RLock()
+	defer httpConfigMu.RUnlock()
+	if httpConfig.timeout > 0 {
+		return httpConfig.timeout
+	}
+	return httpDefaultTimeout
+}
+
+// newHttpClient returns a client for one request that honors the configured proxy,
+// TLS settings and default headers
+func newHttpClient(timeout time.Duration) *http.Client {
+	httpConfigMu.RLock()
+	defer httpConfigMu.RUnlock()
+	var base http.RoundTripper = http.DefaultTransport
+	if httpConfig.transport != nil {
+		base = httpConfig.transport
+	}
+	headers := httpConfig.headers
+	if headers == nil {
+		headers = defaultHttpHeaders()
+	}
+	return &http.Client{Timeout: timeout, Transport: &defaultHeaderTransport{base: base, headers: headers}}
+}
+
+// defaultHeaderTransport adds the configured default headers to requests that do
+// not set them
+type defaultHeaderTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t *defaultHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var clone *http.Request
+	for name, values := range t.headers {
+		if _, set := req.Header[name]; set {
+			continue
+		}
+		if clone == nil {
+			// A RoundTripper must not modify the request it was given
+			clone = req.Clone(req.Context())
+		}
+		clone.Header[name] = values
+	}
+	if clone == nil {
+		return t.base.RoundTrip(req)
+	}
+	return t.base.RoundTrip(clone)
+}
+
+// httpConfigure implements Http.configure(options). Supported options:
+//
+//	proxy    - URL of an HTTP(S) or SOCKS5 proxy; nil goes back to the environment
+//	caFile   - PEM bundle of extra certificate authorities to trust
+//	insecure - true disables certificate verification (prints a warning)
+//	timeout  - default request timeout in seconds; nil restores 30
+//	headers  - Map of headers sent with every request; nil restores the defaults
+func httpConfigure(e *common.Env, args []any) (any, error) {
+	env := (*Env)(e)
+	options, err := httpOptions(env, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	httpConfigMu.Lock()
+	defer httpConfigMu.Unlock()
+	config := httpConfig
+	for _, option := range options {
+		switch option.name {
+		case "proxy":
+			if option.value == nil {
+				config.proxy = nil
+				continue
+			}
+			proxy, err := url.Parse(utils.ToString(option.value))
+			if err != nil || proxy.Host == "" || (proxy.Scheme != "http" && proxy.Scheme != "https" && proxy.Scheme != "socks5") {
+				return nil, ThrowValueError(env, fmt.Sprintf("invalid proxy URL '%s' (expected http://, https:// or socks5://host:port)", utils.ToString(option.value)))
+			}
+			config.proxy = proxy
+		case "caFile":
+			path := utils.ToString(option.value)
+			pem, err := os.ReadFile(path)
+			if err != nil {
+				return nil, ThrowIOError(env, err.Error())
+			}
+			if config.rootCAs == nil {
+				if config.rootCAs, err = x509.SystemCertPool(); err != nil {
+					config.rootCAs = x509.NewCertPool()
+				}
+			}
+			if !config.rootCAs.AppendCertsFromPEM(pem) {
+				return nil, ThrowValueError(env, fmt.Sprintf("no PEM certificates found in '%s'", path))
+			}
+		case "insecure":
+			config.insecure = utils.AsBool(option.value)
+			if config.insecure {
+				fmt.Fprintln(os.Stderr, "Warning: Http certificate verification is disabled; responses can be intercepted or forged")
+			}
+		case "timeout":
+			if option.value == nil {
+				config.timeout = 0
+				continue
+			}
+			seconds, ok := utils.AsFloat(option.value)
+			if !ok || seconds <= 0 {
+				return nil, ThrowValueError(env, fmt.Sprintf("timeout must be a positive number of seconds, got %s", utils.ToString(option.value)))
+			}
+			config.timeout = time.Duration(seconds * float64(time.Second))
+		case "headers":
+			if option.value == nil {
+				config.headers = nil
+				continue
+			}
+			entries, err := httpOptions(env, option.value)
+			if err != nil {
+				return nil, ThrowTypeError(env, "a Map of headers", option.value)
+			}
+			config.headers = defaultHttpHeaders()
+			for _, entry := range entries {
+				if entry.value == nil {
+					// nil drops a default, e.g. {"User-Agent": nil}
+					config.headers.Del(entry.name)
+					continue
+				}
+				config.headers.Set(entry.name, utils.ToString(entry.value))
+			}
+		default:
+			return nil, ThrowValueError(env, fmt.Sprintf("unknown Http option '%s' (expected proxy, caFile, insecure, timeout or headers)", option.name))
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.proxy != nil {
+		transport.Proxy = http.ProxyURL(config.proxy)
+	}
+	transport.TLSClientConfig = &tls.Config{RootCAs: config.rootCAs, InsecureSkipVerify: config.insecure}
+	config.transport = transport
+	httpConfig = config
+	return nil, nil
+}
+
+// httpOption is one entry of an options Map, in the order it was written
+type httpOption struct {
+	name  string
+	value any
+}
+
+// httpOptions reads the entries of an options Map
+func httpOptions(env *Env, value any) ([]httpOption, error) {
+	var options []httpOption
+	switch v := value.(type) {
+	case map[string]any:
+		for name, val := range v {
+			options = append(options, httpOption{name, val})
+		}
+		return options, nil
+	case *ClassInstance:
+		if v.ClassName == "Map" {
+			for _, entry := range orderedMapEntries(v, false) {
+				options = append(options, httpOption{utils.ToString(entry.Key), entry.Value})
+			}
+			return options, nil
+		}
+	}
+	return nil, ThrowTypeError(env, "a Map of options", value)
+}
+
+// httpSessionState is the client state behind an HttpSession: its cookie jar and
+// the interceptors registered with onRequest and onResponse, in order
+type httpSessionState struct {
+	mu         sync.Mutex
+	jar        http.CookieJar
+	onRequest  []common.Func
+	onResponse []common.Func
+}
+
+// cookieJar returns the jar that stores the session's cookies
+func (s *httpSessionState) cookieJar() http.CookieJar {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jar
+}
+
+// interceptors returns a snapshot of the registered interceptors
+func (s *httpSessionState) interceptors() (onRequest, onResponse []common.Func) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.onRequest, s.onResponse
+}
+
+// interceptRequest passes a request Map {method, url, headers, body} through the
+// onRequest interceptors. Each one may change the Map in place or return a new
+// Map, and the request is sent with what the last one left. A body replaced by
+// something other than a String is sent as JSON.
+func (s *httpSessionState) interceptRequest(env *Env, method, url string, body []byte, contentType string, headers any) (string, string, []byte, string, any, error) {
+	onRequest, _ := s.interceptors()
+	if len(onRequest) == 0 {
+		return method, url, body, contentType, headers, nil
+	}
+
+	headerEntries := make(map[string]any)
+	if headers != nil {
+		options, err := httpOptions(env, headers)
+		if err != nil {
+			return "", "", nil, "", nil, ThrowTypeError(env, "a Map of headers", headers)
+		}
+		for _, option := range options {
+			headerEntries[option.name] = option.value
+		}
+	}
+	headerMap, err := CreateMapInstance(env, headerEntries)
+	if err != nil {
+		return "", "", nil, "", nil, err
+	}
+	var bodyValue any
+	if body != nil {
+		bodyValue = string(body)
+	}
+	request, err := CreateMapInstance(env, map[string]any{
+		"method":  method,
+		"url":     url,
+		"headers": headerMap,
+		"body":    bodyValue,
+	})
+	if err != nil {
+		return "", "", nil, "", nil, err
+	}
+
+	for _, fn := range onRequest {
+		result, err := fn((*common.Env)(env), []any{request})
+		if err != nil {
+			return "", "", nil, "", nil, err
+		}
+		if replaced, ok := result.(*ClassInstance); ok && replaced.ClassName == "Map" {
+			request = replaced
+		}
+	}
+
+	field := func(name string) any {
+		if entry := findMapEntry(env, request, name); entry != nil {
+			return entry.Value
+		}
+		return nil
+	}
+	body = nil
+	if value := field("body"); value != nil {
+		if isStringValue(value) {
+			body = []byte(StringValue(value))
+		} else if body, contentType, err = jsonBody(env, value); err != nil {
+			return "", "", nil, "", nil, err
+		}
+	}
+	return utils.ToString(field("method")), utils.ToString(field("url")), body, contentType, field("headers"), nil
+}
+
+// interceptResponse passes the response Map through the onResponse interceptors;
+// an interceptor that returns a Map replaces the response
+func (s *httpSessionState) interceptResponse(env *Env, response any) (any, error) {
+	_, onResponse := s.interceptors()
+	for _, fn := range onResponse {
+		result, err := fn((*common.Env)(env), []any{response})
+		if err != nil {
+			return nil, err
+		}
+		if replaced, ok := result.(*ClassInstance); ok && replaced.ClassName == "Map" {
+			response = replaced
+		}
+	}
+	return response, nil
+}
+
+// newHttpSessionBuilder describes the HttpSession class returned by Http.session().
+// A session keeps a cookie jar and its interceptors in a private field, so cookies
+// set by one response are sent with the following requests. Its request methods
+// take the same arguments as the Http helpers.
+func newHttpSessionBuilder(env *Env) *ClassBuilder {
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	mapType := common.BuiltinTypeMap.GetTypeDefinition(env)
+	voidType := &ast.Type{Name: "void", IsBuiltin: true}
+
+	builder := NewClassBuilder("HttpSession").
+		AddField("_session", ast.ANY, []string{"private"})
+
+	// get/delete(url, timeout?, headers?) and post/put(url, data, timeout?, headers?)
+	// and postForm(url, fields, timeout?, headers?)
+	shortcuts := []struct {
+		name   string
+		method string
+		encode httpBodyEncoder
+	}{
+		{"get", "GET", nil},
+		{"post", "POST", jsonBody},
+		{"postForm", "POST", formBody},
+		{"put", "PUT", jsonBody},
+		{"delete", "DELETE", nil},
+	}
+	for _, shortcut := range shortcuts {
+		params := []ast.Parameter{{Name: "url", Type: stringType}}
+		if shortcut.encode != nil {
+			params = append(params, ast.Parameter{Name: "data", Type: ast.ANY})
+		}
+		fn := func(callEnv *common.Env, args []any) (any, error) {
+			return httpShortcut(callEnv, sessionState(callEnv), shortcut.method, shortcut.encode, args)
+		}
+		for _, extra := range [][]ast.Parameter{
+			nil,
+			{{Name: "timeout", Type: ast.ANY}},
+			{{Name: "timeout", Type: ast.ANY}, {Name: "headers", Type: mapType}},
+		} {
+			builder.AddBuiltinMethod(shortcut.name, mapType, append(append([]ast.Parameter{}, params...), extra...), fn, []string{})
+		}
+	}
+
+	// request(method, url, data?, timeout?, headers?)
+	requestParams := []ast.Parameter{
+		{Name: "method", Type: stringType},
+		{Name: "url", Type: stringType},
+		{Name: "data", Type: ast.ANY},
+		{Name: "timeout", Type: ast.ANY},
+		{Name: "headers", Type: mapType},
+	}
+	for count := 2; count <= len(requestParams); count++ {
+		builder.AddBuiltinMethod("request", mapType, requestParams[:count], func(callEnv *common.Env, args []any) (any, error) {
+			return httpCustomRequest(callEnv, sessionState(callEnv), args)
+		}, []string{})
+	}
+
+	// onRequest(fn) / onResponse(fn) -> HttpSession - register an interceptor;
+	// returns the session so registrations can be chained
+	sessionType := &ast.Type{Name: "HttpSession", IsClass: true}
+	for _, hook := range []string{"onRequest", "onResponse"} {
+		builder.AddBuiltinMethod(hook, sessionType, []ast.Parameter{{Name: "interceptor", Type: ast.ANY}}, func(callEnv *common.Env, args []any) (any, error) {
+			fn, ok := common.ExtractFunc(args[0])
+			if !ok {
+				return nil, ThrowTypeError((*Env)(callEnv), "function", args[0])
+			}
+			state := sessionState(callEnv)
+			state.mu.Lock()
+			if hook == "onRequest" {
+				state.onRequest = append(state.onRequest, fn)
+			} else {
+				state.onResponse = append(state.onResponse, fn)
+			}
+			state.mu.Unlock()
+			thisVal, _ := callEnv.This()
+			return thisVal, nil
+		}, []string{})
+	}
+
+	// cookies(url) -> Map - the cookies the session would send to url, by name
+	builder.AddBuiltinMethod("cookies", mapType, []ast.Parameter{{Name: "url", Type: stringType}}, func(callEnv *common.Env, args []any) (any, error) {
+		target, err := url.Parse(utils.ToString(args[0]))
+		if err != nil {
+			return nil, ThrowValueError((*Env)(callEnv), fmt.Sprintf("invalid URL '%s'", utils.ToString(args[0])))
+		}
+		cookies := make(map[string]any)
+		for _, cookie := range sessionState(callEnv).cookieJar().Cookies(target) {
+			cookies[cookie.Name] = cookie.Value
+		}
+		return CreateMapInstance((*Env)(callEnv), cookies)
+	}, []string{})
+
+	// clearCookies() - forget every stored cookie
+	builder.AddBuiltinMethod("clearCookies", voidType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		jar, _ := cookiejar.New(nil)
+		state := sessionState(callEnv)
+		state.mu.Lock()
+		state.jar = jar
+		state.mu.Unlock()
+		return nil, nil
+	}, []string{})
+
+	return builder
+}
+
+// httpSession implements Http.session()
+func httpSession(e *common.Env, args []any) (any, error) {
+	env := (*Env)(e)
+	sessionClass, exists := lookupClass("HttpSession", "")
+	if !exists {
+		return nil, ThrowInitializationError(env, "HttpSession class")
+	}
+	value, err := createClassInstance(sessionClass, env, []any{})
+	if err != nil {
+		return nil, err
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	value.(*ClassInstance).Fields["_session"] = &httpSessionState{jar: jar}
+	return value, nil
+}
+
+// sessionState returns the state of the HttpSession a method was called on
+func sessionState(callEnv *common.Env) *httpSessionState {
+	thisVal, _ := callEnv.This()
+	state, _ := thisVal.(*ClassInstance).Fields["_session"].(*httpSessionState)
+	return state
+}
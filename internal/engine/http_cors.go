@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// corsPolicy is the configuration behind a middleware returned by Http.cors
+type corsPolicy struct {
+	origins     []string // allowed origins; nil allows any
+	methods     []string
+	headers     []string // allowed request headers; nil echoes the preflight's
+	credentials bool
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for a request from
+// origin, or "" when the origin is not allowed
+func (c *corsPolicy) allowOrigin(origin string) string {
+	if c.origins == nil {
+		// A credentialed response cannot use the wildcard
+		if c.credentials {
+			return origin
+		}
+		return "*"
+	}
+	for _, allowed := range c.origins {
+		if allowed == origin {
+			return origin
+		}
+	}
+	return ""
+}
+
+// corsList reads an option that is an Array of Strings
+func corsList(env *Env, name string, value any) ([]string, error) {
+	items, ok := collectionItems(value)
+	if !ok {
+		return nil, ThrowTypeError(env, fmt.Sprintf("Array for %s", name), value)
+	}
+	list := make([]string, len(items))
+	for i, item := range items {
+		list[i] = utils.ToString(item)
+	}
+	return list, nil
+}
+
+// httpCors implements Http.cors(options?). Options:
+//
+//	origins     - Array of allowed origins, or "*" for any (default "*")
+//	methods     - Array of methods allowed in preflight requests
+//	headers     - Array of request headers allowed in preflight requests
+//	              (default: the headers the browser asks for)
+//	credentials - allow cookies and authorization headers (default false)
+func httpCors(e *common.Env, args []any) (any, error) {
+	env := (*Env)(e)
+	policy := &corsPolicy{methods: []string{"GET", "HEAD", "PUT", "PATCH", "POST", "DELETE"}}
+	if len(args) > 0 && args[0] != nil {
+		options, err := httpOptions(env, args[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, option := range options {
+			switch option.name {
+			case "origins":
+				if isStringValue(option.value) && utils.ToString(option.value) == "*" {
+					policy.origins = nil
+					continue
+				}
+				origins, err := corsList(env, "origins", option.value)
+				if err != nil {
+					return nil, err
+				}
+				policy.origins = origins
+			case "methods":
+				methods, err := corsList(env, "methods", option.value)
+				if err != nil {
+					return nil, err
+				}
+				for i, method := range methods {
+					methods[i] = strings.ToUpper(method)
+				}
+				policy.methods = methods
+			case "headers":
+				headers, err := corsList(env, "headers", option.value)
+				if err != nil {
+					return nil, err
+				}
+				policy.headers = headers
+			case "credentials":
+				policy.credentials = utils.AsBool(option.value)
+			default:
+				return nil, ThrowValueError(env, fmt.Sprintf("unknown cors option '%s' (expected origins, methods, headers or credentials)", option.name))
+			}
+		}
+	}
+
+	// Three parameters, so server.use and route middleware lists accept it
+	return &common.LambdaDefinition{
+		Func: func(callEnv *common.Env, args []any) (any, error) {
+			return corsMiddleware(callEnv, policy, args)
+		},
+		Params: []ast.Parameter{
+			{Name: "req", Type: ast.ANY},
+			{Name: "res", Type: ast.ANY},
+			{Name: "next", Type: ast.ANY},
+		},
+		ReturnType: ast.ANY,
+	}, nil
+}
+
+// corsMiddleware adds the Access-Control-* headers for an allowed origin and
+// answers preflight requests with 204 without running the rest of the chain
+func corsMiddleware(env *common.Env, policy *corsPolicy, args []any) (any, error) {
+	reqInstance, _ := args[0].(*ClassInstance)
+	resInstance, _ := args[1].(*ClassInstance)
+	next, _ := common.ExtractFunc(args[2])
+	if reqInstance == nil || resInstance == nil || next == nil {
+		return nil, ThrowTypeError((*Env)(env), "(HttpRequest, HttpResponse, next)", args[0])
+	}
+	req, _ := reqInstance.Fields["_request"].(*http.Request)
+	resp, _ := resInstance.Fields["_writer"].(*httpResponse)
+	if req == nil || resp == nil {
+		return next(env, nil)
+	}
+
+	header := resp.writer.Header()
+	origin := req.Header.Get("Origin")
+	allowed := ""
+	if origin != "" {
+		allowed = policy.allowOrigin(origin)
+	}
+	if allowed != "" {
+		header.Set("Access-Control-Allow-Origin", allowed)
+		if allowed != "*" {
+			header.Add("Vary", "Origin")
+		}
+		if policy.credentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+	if !preflight {
+		return next(env, nil)
+	}
+	if allowed != "" {
+		header.Set("Access-Control-Allow-Methods", strings.Join(policy.methods, ", "))
+		if policy.headers != nil {
+			header.Set("Access-Control-Allow-Headers", strings.Join(policy.headers, ", "))
+		} else if requested := req.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+	}
+	if !resp.sent {
+		resp.sent = true
+		resp.writer.WriteHeader(http.StatusNoContent)
+	}
+	return nil, nil
+}
+
+// preflightFallback handles OPTIONS requests that match no route, so global
+// middlewares such as Http.cors can answer them; without an answer it is a 404
+func preflightFallback() *routeHandler {
+	return &routeHandler{
+		handler: func(env *common.Env, args []any) (any, error) {
+			if res, ok := args[1].(*ClassInstance); ok {
+				if resp, ok := res.Fields["_writer"].(*httpResponse); ok {
+					resp.statusCode = http.StatusNotFound
+					resp.send(`{"error": "Not Found"}`)
+				}
+			}
+			return nil, nil
+		},
+	}
+}
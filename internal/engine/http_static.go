@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// staticMount serves the files of a directory under a URL prefix
+type staticMount struct {
+	prefix   string // "/assets"; "/" serves the whole site
+	segments int    // number of path segments in prefix, for route precedence
+	handler  http.Handler
+}
+
+// matches reports whether reqPath is inside the mount's prefix
+func (m *staticMount) matches(reqPath string) bool {
+	return m.prefix == "/" || reqPath == m.prefix || strings.HasPrefix(reqPath, m.prefix+"/")
+}
+
+// staticMountFor returns the mount with the longest prefix containing reqPath
+func staticMountFor(mounts []*staticMount, reqPath string) *staticMount {
+	var best *staticMount
+	for _, mount := range mounts {
+		if mount.matches(reqPath) && (best == nil || len(mount.prefix) > len(best.prefix)) {
+			best = mount
+		}
+	}
+	return best
+}
+
+// routeOverridesMount reports whether a matched route takes precedence over a
+// static mount: exact routes always do, dynamic routes only when they have more
+// literal or parameter segments than the mount prefix (a catch-all never does)
+func routeOverridesMount(rh *routeHandler, mount *staticMount) bool {
+	if rh.pattern.isStatic {
+		return true
+	}
+	segments := 0
+	for _, seg := range rh.pattern.segments {
+		if !seg.isWildcard {
+			segments++
+		}
+	}
+	return segments > mount.segments
+}
+
+// noListingFS hides directories without an index.html, so a static mount serves
+// files but never lists a directory's contents
+type noListingFS struct {
+	fs http.FileSystem
+}
+
+func (n noListingFS) Open(name string) (http.File, error) {
+	f, err := n.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if stat, err := f.Stat(); err == nil && stat.IsDir() {
+		index, err := n.fs.Open(path.Join(name, "index.html"))
+		if err != nil {
+			f.Close()
+			return nil, os.ErrNotExist
+		}
+		index.Close()
+	}
+	return f, nil
+}
+
+// httpServerStatic implements server.static(urlPrefix, dir)
+func httpServerStatic(e *common.Env, args []any) (any, error) {
+	env := (*Env)(e)
+	thisVal, _ := e.This()
+	instance, ok := thisVal.(*ClassInstance)
+	if !ok {
+		return nil, ThrowTypeError(env, "HttpServer", thisVal)
+	}
+
+	prefix := "/" + strings.Trim(utils.ToString(args[0]), "/")
+	dir := utils.ToString(args[1])
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return nil, ThrowIOError(env, err.Error())
+	}
+	if !stat.IsDir() {
+		return nil, ThrowIOError(env, fmt.Sprintf("static directory '%s' is not a directory", dir))
+	}
+
+	var handler http.Handler = http.FileServer(noListingFS{http.Dir(dir)})
+	segments := 0
+	if prefix != "/" {
+		handler = http.StripPrefix(prefix, handler)
+		segments = strings.Count(prefix, "/")
+	}
+
+	router := instance.Fields["router"].(*httpRouter)
+	router.mu.Lock()
+	router.staticMounts = append(router.staticMounts, &staticMount{prefix: prefix, segments: segments, handler: handler})
+	router.mu.Unlock()
+	return nil, nil
+}
+
+// httpRequestCookie implements req.cookie(name): the cookie's value, or nil when
+// the request did not send it
+func httpRequestCookie(e *common.Env, args []any) (any, error) {
+	thisVal, _ := e.This()
+	instance, ok := thisVal.(*ClassInstance)
+	if !ok {
+		return nil, ThrowTypeError((*Env)(e), "HttpRequest", thisVal)
+	}
+	req, _ := instance.Fields["_request"].(*http.Request)
+	if req == nil {
+		return nil, nil
+	}
+	cookie, err := req.Cookie(utils.ToString(args[0]))
+	if err != nil {
+		return nil, nil
+	}
+	return cookie.Value, nil
+}
+
+// httpResponseCookie implements res.cookie(name, value, options?). Options:
+//
+//	maxAge   - lifetime in seconds; 0 or less deletes the cookie
+//	path     - URL path the cookie applies to (default "/")
+//	httpOnly - hide the cookie from browser scripts
+//	secure   - only send the cookie over HTTPS
+func httpResponseCookie(e *common.Env, args []any) (any, error) {
+	env := (*Env)(e)
+	thisVal, _ := e.This()
+	instance, ok := thisVal.(*ClassInstance)
+	if !ok {
+		return nil, ThrowTypeError(env, "HttpResponse", thisVal)
+	}
+	resp := instance.Fields["_writer"].(*httpResponse)
+	if resp.sent {
+		return nil, ThrowStateError(env, "cannot set a cookie after the response was sent")
+	}
+
+	cookie := &http.Cookie{Name: utils.ToString(args[0]), Value: utils.ToString(args[1]), Path: "/"}
+	if len(args) > 2 && args[2] != nil {
+		options, err := httpOptions(env, args[2])
+		if err != nil {
+			return nil, err
+		}
+		for _, option := range options {
+			switch option.name {
+			case "maxAge":
+				seconds, ok := utils.AsInt(option.value)
+				if !ok {
+					return nil, ThrowTypeError(env, "Int for maxAge", option.value)
+				}
+				if seconds <= 0 {
+					cookie.MaxAge = -1
+				} else {
+					cookie.MaxAge = seconds
+				}
+			case "path":
+				cookie.Path = utils.ToString(option.value)
+			case "httpOnly":
+				cookie.HttpOnly = utils.AsBool(option.value)
+			case "secure":
+				cookie.Secure = utils.AsBool(option.value)
+			default:
+				return nil, ThrowValueError(env, fmt.Sprintf("unknown cookie option '%s' (expected maxAge, path, httpOnly or secure)", option.name))
+			}
+		}
+	}
+	if err := cookie.Valid(); err != nil {
+		return nil, ThrowValueError(env, err.Error())
+	}
+	http.SetCookie(resp.writer, cookie)
+	return instance, nil
+}
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+	"github.com/gorilla/websocket"
+)
+
+// wsClientState is the connection behind a WebSocket returned by Http.connectWs.
+// Reads happen either through receive() or, once onMessage registered a handler,
+// in a background read loop; gorilla allows one reader and one writer at a time.
+type wsClientState struct {
+	conn      *websocket.Conn
+	handle    *handleToken
+	writeMu   sync.Mutex
+	mu        sync.Mutex
+	onMessage []common.Func
+	reading   bool // the read loop owns the connection's reads
+	closed    bool
+}
+
+// newWebSocketBuilder describes the WebSocket class returned by Http.connectWs(url)
+func newWebSocketBuilder(env *Env) *ClassBuilder {
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	voidType := &ast.Type{Name: "void", IsBuiltin: true}
+
+	builder := NewClassBuilder("WebSocket").
+		AddField("_ws", ast.ANY, []string{"private"})
+
+	// send(message) - send a text message
+	builder.AddBuiltinMethod("send", voidType, []ast.Parameter{{Name: "message", Type: ast.ANY}}, func(callEnv *common.Env, args []any) (any, error) {
+		state := wsState(callEnv)
+		state.writeMu.Lock()
+		defer state.writeMu.Unlock()
+		if err := state.conn.WriteMessage(websocket.TextMessage, []byte(utils.ToString(args[0]))); err != nil {
+			return nil, ThrowIOError((*Env)(callEnv), fmt.Sprintf("WebSocket send failed: %v", err))
+		}
+		return nil, nil
+	}, []string{})
+
+	// receive() -> String? - wait for the next message; nil once the connection is closed
+	builder.AddBuiltinMethod("receive", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		env := (*Env)(callEnv)
+		state := wsState(callEnv)
+		state.mu.Lock()
+		reading, closed := state.reading, state.closed
+		state.mu.Unlock()
+		if closed {
+			return nil, nil
+		}
+		if reading {
+			return nil, ThrowStateError(env, "receive() cannot be used after onMessage registered a handler")
+		}
+		_, message, err := state.conn.ReadMessage()
+		if err != nil {
+			if state.isClosed() || isWsCloseError(err) {
+				return nil, nil
+			}
+			return nil, ThrowIOError(env, fmt.Sprintf("WebSocket receive failed: %v", err))
+		}
+		return CreateStringInstance(env, string(message))
+	}, []string{})
+
+	// onMessage(handler) - call handler with every incoming message from a
+	// background read loop
+	builder.AddBuiltinMethod("onMessage", voidType, []ast.Parameter{{Name: "handler", Type: ast.ANY}}, func(callEnv *common.Env, args []any) (any, error) {
+		handler, ok := common.ExtractFunc(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "function", args[0])
+		}
+		state := wsState(callEnv)
+		state.mu.Lock()
+		state.onMessage = append(state.onMessage, handler)
+		start := !state.reading && !state.closed
+		state.reading = true
+		state.mu.Unlock()
+		if start {
+			go state.readLoop((*Env)(callEnv))
+		}
+		return nil, nil
+	}, []string{})
+
+	// close() - send a close frame and release the connection
+	builder.AddBuiltinMethod("close", voidType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		state := wsState(callEnv)
+		state.mu.Lock()
+		if state.closed {
+			state.mu.Unlock()
+			return nil, nil
+		}
+		state.closed = true
+		state.mu.Unlock()
+		state.handle.release()
+
+		state.writeMu.Lock()
+		closeFrame := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = state.conn.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(time.Second))
+		state.writeMu.Unlock()
+		return nil, state.conn.Close()
+	}, []string{})
+
+	return builder
+}
+
+// httpConnectWs implements Http.connectWs(url)
+func httpConnectWs(e *common.Env, args []any) (any, error) {
+	env := (*Env)(e)
+	wsClass, exists := lookupClass("WebSocket", "")
+	if !exists {
+		return nil, ThrowInitializationError(env, "WebSocket class")
+	}
+	handle, err := acquireHandle(env)
+	if err != nil {
+		return nil, err
+	}
+	url := utils.ToString(args[0])
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		handle.release()
+		return nil, ThrowIOError(env, fmt.Sprintf("cannot connect to %s: %v", url, err))
+	}
+	value, err := createClassInstance(wsClass, env, []any{})
+	if err != nil {
+		conn.Close()
+		handle.release()
+		return nil, err
+	}
+	value.(*ClassInstance).Fields["_ws"] = &wsClientState{conn: conn, handle: handle}
+	return value, nil
+}
+
+// wsState returns the connection of the WebSocket a method was called on
+func wsState(callEnv *common.Env) *wsClientState {
+	thisVal, _ := callEnv.This()
+	state, _ := thisVal.(*ClassInstance).Fields["_ws"].(*wsClientState)
+	return state
+}
+
+func (s *wsClientState) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// readLoop passes incoming messages to the onMessage handlers until the
+// connection closes
+func (s *wsClientState) readLoop(env *Env) {
+	for {
+		_, message, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		handlers := append([]common.Func(nil), s.onMessage...)
+		s.mu.Unlock()
+		for _, handler := range handlers {
+			text, _ := CreateStringInstance(env, string(message))
+			if _, err := handler((*common.Env)(env), []any{text}); err != nil {
+				fmt.Fprintf(os.Stderr, "WebSocket onMessage handler failed: %v\n", err)
+			}
+		}
+	}
+}
+
+// isWsCloseError reports whether err ended the connection with a close frame
+func isWsCloseError(err error) bool {
+	var closeErr *websocket.CloseError
+	return errors.As(err, &closeErr)
+}
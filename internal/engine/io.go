@@ -185,13 +185,27 @@ func InstallIOModule(env *Env, opts Options) error {
 	// ========================================
 	// IO class - File system operations
 	// ========================================
+	if err := installFileClass(env); err != nil {
+		return err
+	}
+
 	ioClass := NewClassBuilder("IO").
+		// open(path: String, mode: String) -> File - mode is "r" (the default), "w" or "a"
+		AddStaticMethod("open", ast.ANY, []ast.Parameter{{Name: "path", Type: stringType}}, Func(func(e *Env, args []any) (any, error) {
+			return openFile(e, utils.ToString(args[0]), "r")
+		})).
+		AddStaticMethod("open", ast.ANY, []ast.Parameter{
+			{Name: "path", Type: stringType},
+			{Name: "mode", Type: stringType},
+		}, Func(func(e *Env, args []any) (any, error) {
+			return openFile(e, utils.ToString(args[0]), utils.ToString(args[1]))
+		})).
 		// File operations
-		AddStaticMethod("readFile", stringType, []ast.Parameter{{Name: "path", Type: stringType}}, Func(func(_ *Env, args []any) (any, error) {
+		AddStaticMethod("readFile", stringType, []ast.Parameter{{Name: "path", Type: stringType}}, Func(func(e *Env, args []any) (any, error) {
 			path := utils.ToString(args[0])
 			data, err := os.ReadFile(path)
 			if err != nil {
-				return nil, err
+				return nil, ThrowIOError(e, err.Error())
 			}
 			return string(data), nil
 		})).
@@ -199,7 +213,7 @@ func InstallIOModule(env *Env, opts Options) error {
 			{Name: "path", Type: stringType},
 			{Name: "content", Type: stringType},
 			{Name: "mode", Type: nil, IsVariadic: true},
-		}, Func(func(_ *Env, args []any) (any, error) {
+		}, Func(func(e *Env, args []any) (any, error) {
 			path := utils.ToString(args[0])
 			content := utils.ToString(args[1])
 
@@ -211,7 +225,7 @@ func InstallIOModule(env *Env, opts Options) error {
 			}
 
 			if err := os.WriteFile(path, []byte(content), perm); err != nil {
-				return nil, err
+				return nil, ThrowIOError(e, err.Error())
 			}
 			return true, nil
 		})).
@@ -346,8 +360,13 @@ func InstallIOModule(env *Env, opts Options) error {
 				return nil, io.ErrUnexpectedEOF
 			}
 
+			handle, err := acquireHandle(e)
+			if err != nil {
+				return nil, err
+			}
 			file, err := os.OpenFile(path, flags, 0644)
 			if err != nil {
+				handle.release()
 				return nil, err
 			}
 
@@ -380,6 +399,7 @@ func InstallIOModule(env *Env, opts Options) error {
 				"_mode":     mode,
 				"_encoding": encodingName,
 				"_closed":   false,
+				"_handle":   handle,
 			}
 
 			return fileHandle, nil
@@ -481,6 +501,9 @@ func InstallIOModule(env *Env, opts Options) error {
 			}
 
 			// Close file
+			if token, ok := handle["_handle"].(*handleToken); ok {
+				token.release()
+			}
 			if file, ok := handle["_file"].(*os.File); ok && file != nil {
 				if err := file.Close(); err != nil {
 					return false, err
@@ -0,0 +1,223 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// fileModes maps the modes accepted by IO.open to os.OpenFile flags
+var fileModes = map[string]int{
+	"r": os.O_RDONLY,
+	"w": os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+	"a": os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+}
+
+// installFileClass installs the File class returned by IO.open. A File wraps an
+// *os.File kept in a private field; reads go through a bufio.Reader and writes
+// through a bufio.Writer that is flushed on close. close() may be called any number
+// of times, so `defer f.close()` is always safe.
+func installFileClass(env *Env) error {
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	intType := common.BuiltinTypeInt.GetTypeDefinition(env)
+	boolType := common.BuiltinTypeBool.GetTypeDefinition(env)
+
+	fileClass := NewClassBuilder("File").
+		AddField("_file", ast.ANY, []string{"private"}).
+		AddField("_reader", ast.ANY, []string{"private"}).
+		AddField("_writer", ast.ANY, []string{"private"}).
+		AddField("_path", ast.ANY, []string{"private"}).
+		AddField("_mode", ast.ANY, []string{"private"}).
+		AddField("_closed", ast.ANY, []string{"private"})
+
+	// read() -> String - everything from the current position to the end
+	fileClass.AddBuiltinMethod("read", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		reader, err := fileReader((*Env)(callEnv), thisVal.(*ClassInstance))
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fileError((*Env)(callEnv), thisVal.(*ClassInstance), err)
+		}
+		return CreateStringInstance((*Env)(callEnv), string(data))
+	}, []string{})
+
+	// readLine() -> String? - the next line without its line break, nil at the end of the file
+	fileClass.AddBuiltinMethod("readLine", ast.ANY, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		reader, err := fileReader((*Env)(callEnv), thisVal.(*ClassInstance))
+		if err != nil {
+			return nil, err
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fileError((*Env)(callEnv), thisVal.(*ClassInstance), err)
+		}
+		if err != nil && line == "" {
+			return nil, nil
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		return CreateStringInstance((*Env)(callEnv), line)
+	}, []string{})
+
+	// eof() -> Bool - whether everything has been read
+	fileClass.AddBuiltinMethod("eof", boolType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		reader, err := fileReader((*Env)(callEnv), thisVal.(*ClassInstance))
+		if err != nil {
+			return nil, err
+		}
+		_, err = reader.Peek(1)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fileError((*Env)(callEnv), thisVal.(*ClassInstance), err)
+		}
+		return CreateBoolInstance((*Env)(callEnv), err != nil)
+	}, []string{})
+
+	// write(text: String) -> Int - bytes written
+	fileClass.AddBuiltinMethod("write", intType, []ast.Parameter{
+		{Name: "text", Type: ast.ANY},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		return fileWrite((*Env)(callEnv), thisVal.(*ClassInstance), utils.ToString(args[0]))
+	}, []string{})
+
+	// writeLine(text: String) -> Int - write text followed by "\n"
+	fileClass.AddBuiltinMethod("writeLine", intType, []ast.Parameter{
+		{Name: "text", Type: ast.ANY},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		return fileWrite((*Env)(callEnv), thisVal.(*ClassInstance), utils.ToString(args[0])+"\n")
+	}, []string{})
+
+	// close() -> Void - flush pending writes and release the file; later calls do nothing
+	fileClass.AddBuiltinMethod("close", ast.ANY, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		if closed, _ := instance.Fields["_closed"].(bool); closed {
+			return nil, nil
+		}
+		instance.Fields["_closed"] = true
+		releaseHandleField(instance)
+		file := instance.Fields["_file"].(*os.File)
+		if writer, ok := instance.Fields["_writer"].(*bufio.Writer); ok {
+			if err := writer.Flush(); err != nil {
+				file.Close()
+				return nil, fileError((*Env)(callEnv), instance, err)
+			}
+		}
+		if err := file.Close(); err != nil {
+			return nil, fileError((*Env)(callEnv), instance, err)
+		}
+		return nil, nil
+	}, []string{})
+
+	// isClosed() -> Bool
+	fileClass.AddBuiltinMethod("isClosed", boolType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		closed, _ := thisVal.(*ClassInstance).Fields["_closed"].(bool)
+		return CreateBoolInstance((*Env)(callEnv), closed)
+	}, []string{})
+
+	// path() -> String
+	fileClass.AddBuiltinMethod("path", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		return CreateStringInstance((*Env)(callEnv), thisVal.(*ClassInstance).Fields["_path"].(string))
+	}, []string{})
+
+	// toString() -> String
+	fileClass.AddBuiltinMethod("toString", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		return CreateStringInstance((*Env)(callEnv), fmt.Sprintf("File(%s, %s)", instance.Fields["_path"], instance.Fields["_mode"]))
+	}, []string{})
+
+	_, err := fileClass.Build(env)
+	return err
+}
+
+// openFile implements IO.open: it opens path in mode "r", "w" or "a" and returns
+// a File instance
+func openFile(env *Env, path string, mode string) (any, error) {
+	flags, ok := fileModes[mode]
+	if !ok {
+		return nil, ThrowValueError(env, fmt.Sprintf("invalid file mode '%s' (expected \"r\", \"w\" or \"a\")", mode))
+	}
+	handle, err := acquireHandle(env)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		handle.release()
+		return nil, ThrowIOError(env, err.Error())
+	}
+
+	fileClass, exists := lookupClass("File", "")
+	if !exists {
+		file.Close()
+		handle.release()
+		return nil, ThrowInitializationError(env, "File class")
+	}
+	value, err := createClassInstance(fileClass, env, []any{})
+	if err != nil {
+		file.Close()
+		handle.release()
+		return nil, err
+	}
+	instance := value.(*ClassInstance)
+	instance.Fields["_file"] = file
+	instance.Fields["_handle"] = handle
+	instance.Fields["_path"] = path
+	instance.Fields["_mode"] = mode
+	instance.Fields["_closed"] = false
+	if mode == "r" {
+		instance.Fields["_reader"] = bufio.NewReader(file)
+	} else {
+		instance.Fields["_writer"] = bufio.NewWriter(file)
+	}
+	return instance, nil
+}
+
+// fileReader returns the reader of an open File, raising IOError when the file is
+// closed or was opened for writing
+func fileReader(env *Env, file *ClassInstance) (*bufio.Reader, error) {
+	if closed, _ := file.Fields["_closed"].(bool); closed {
+		return nil, ThrowIOError(env, fmt.Sprintf("file '%s' is closed", file.Fields["_path"]))
+	}
+	reader, ok := file.Fields["_reader"].(*bufio.Reader)
+	if !ok {
+		return nil, ThrowIOError(env, fmt.Sprintf("file '%s' is not open for reading", file.Fields["_path"]))
+	}
+	return reader, nil
+}
+
+// fileWrite writes text to an open File and returns the number of bytes written
+func fileWrite(env *Env, file *ClassInstance, text string) (any, error) {
+	if closed, _ := file.Fields["_closed"].(bool); closed {
+		return nil, ThrowIOError(env, fmt.Sprintf("file '%s' is closed", file.Fields["_path"]))
+	}
+	writer, ok := file.Fields["_writer"].(*bufio.Writer)
+	if !ok {
+		return nil, ThrowIOError(env, fmt.Sprintf("file '%s' is not open for writing", file.Fields["_path"]))
+	}
+	n, err := writer.WriteString(text)
+	if err != nil {
+		return nil, fileError(env, file, err)
+	}
+	return CreateIntInstance(env, n)
+}
+
+// fileError wraps an error from the operating system as an IOError
+func fileError(env *Env, file *ClassInstance, err error) error {
+	return ThrowIOError(env, fmt.Sprintf("%s: %v", file.Fields["_path"], err))
+}
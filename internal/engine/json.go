@@ -0,0 +1,378 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// InstallJSONModule installs the JSON module with parse and stringify.
+// Objects become Maps that keep the key order of the source, arrays become Arrays,
+// and numbers become Int or Float depending on whether they are written with a
+// fraction or exponent, so JSON.parse(JSON.stringify(x)) keeps int-vs-float.
+func InstallJSONModule(env *Env, opts Options) error {
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+
+	jsonClass := NewClassBuilder("JSON").
+		// parse(text: String) -> Any
+		AddStaticMethod("parse", ast.ANY, []ast.Parameter{
+			{Name: "text", Type: stringType},
+		}, Func(func(env *Env, args []any) (any, error) {
+			return parseJSON(env, utils.ToString(args[0]))
+		})).
+		// stringify(value) -> String (compact)
+		AddStaticMethod("stringify", stringType, []ast.Parameter{
+			{Name: "value", Type: ast.ANY},
+		}, Func(func(env *Env, args []any) (any, error) {
+			return stringifyJSON(env, args[0], "")
+		})).
+		// stringify(value, indent) -> String; indent is a number of spaces or the indent string itself
+		AddStaticMethod("stringify", stringType, []ast.Parameter{
+			{Name: "value", Type: ast.ANY},
+			{Name: "indent", Type: ast.ANY},
+		}, Func(func(env *Env, args []any) (any, error) {
+			var indent string
+			switch v := extractPrimitiveValue(args[1]).(type) {
+			case int:
+				if v < 0 {
+					return nil, ThrowValueError(env, "JSON indent cannot be negative")
+				}
+				indent = strings.Repeat(" ", v)
+			case string:
+				indent = v
+			case nil:
+			default:
+				return nil, ThrowTypeError(env, "Int or String indent", args[1])
+			}
+			return stringifyJSON(env, args[0], indent)
+		}))
+
+	_, err := jsonClass.BuildStatic(env)
+	return err
+}
+
+// parseJSON decodes a single JSON document into Polyloft values
+func parseJSON(env *Env, text string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+
+	value, err := decodeJSONValue(env, dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, ThrowValueError(env, "invalid JSON: unexpected data after the top-level value")
+	}
+	return value, nil
+}
+
+// decodeJSONValue reads one value from dec, building Maps in source key order
+func decodeJSONValue(env *Env, dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, jsonSyntaxError(env, err)
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			mapInstance, err := CreateMapInstance(env, map[string]any{})
+			if err != nil {
+				return nil, err
+			}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, jsonSyntaxError(env, err)
+				}
+				value, err := decodeJSONValue(env, dec)
+				if err != nil {
+					return nil, err
+				}
+				putMapEntry(env, mapInstance, keyTok.(string), value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, jsonSyntaxError(env, err)
+			}
+			return mapInstance, nil
+		case '[':
+			items := []any{}
+			for dec.More() {
+				value, err := decodeJSONValue(env, dec)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, jsonSyntaxError(env, err)
+			}
+			return CreateArrayInstance(env, items)
+		}
+		return nil, ThrowValueError(env, fmt.Sprintf("invalid JSON: unexpected %q", t.String()))
+	case json.Number:
+		return jsonNumber(env, t)
+	case string:
+		return CreateStringInstance(env, t)
+	case bool:
+		return CreateBoolInstance(env, t)
+	case nil:
+		return nil, nil
+	}
+	return nil, ThrowValueError(env, fmt.Sprintf("invalid JSON: unexpected token %v", tok))
+}
+
+// jsonNumber converts a JSON number to Int when it has no fraction or exponent, else Float
+func jsonNumber(env *Env, n json.Number) (any, error) {
+	literal := n.String()
+	if !strings.ContainsAny(literal, ".eE") {
+		if i, err := strconv.Atoi(literal); err == nil {
+			return CreateIntInstance(env, i)
+		}
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, ThrowValueError(env, fmt.Sprintf("invalid JSON number %s", literal))
+	}
+	return CreateFloatInstance(env, f)
+}
+
+func jsonSyntaxError(env *Env, err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ThrowValueError(env, "invalid JSON: unexpected end of JSON input")
+	}
+	return ThrowValueError(env, "invalid JSON: "+err.Error())
+}
+
+// stringifyJSON encodes value as JSON, indenting nested levels with indent when it is not empty
+func stringifyJSON(env *Env, value any, indent string) (string, error) {
+	return writeJSON(env, value, indent, isDeterministic(env))
+}
+
+// writeJSON encodes value like stringifyJSON, writing Map entries in canonical
+// key order when canonical is set
+func writeJSON(env *Env, value any, indent string, canonical bool) (string, error) {
+	w := &jsonWriter{env: env, indent: indent, canonical: canonical, visiting: make(map[any]bool)}
+	if err := w.write(value, 0); err != nil {
+		return "", err
+	}
+	return w.buf.String(), nil
+}
+
+type jsonWriter struct {
+	env       *Env
+	indent    string
+	canonical bool // Map entries in canonical key order instead of insertion order
+	buf       bytes.Buffer
+	visiting  map[any]bool // containers on the current path, to detect cycles
+}
+
+func (w *jsonWriter) write(value any, depth int) error {
+	switch v := extractPrimitiveValue(value).(type) {
+	case nil:
+		w.buf.WriteString("null")
+		return nil
+	case string:
+		w.writeString(v)
+		return nil
+	case bool:
+		w.buf.WriteString(strconv.FormatBool(v))
+		return nil
+	case int:
+		w.buf.WriteString(strconv.Itoa(v))
+		return nil
+	case int64:
+		w.buf.WriteString(strconv.FormatInt(v, 10))
+		return nil
+	case float32:
+		return w.writeFloat(float64(v))
+	case float64:
+		return w.writeFloat(v)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]any, len(keys))
+		for i, k := range keys {
+			values[i] = v[k]
+		}
+		return w.writeObject(keys, values, depth)
+	}
+
+	if items, ok := collectionItems(value); ok {
+		return w.enter(value, func() error { return w.writeArray(items, depth) })
+	}
+
+	inst, isInstance := value.(*ClassInstance)
+	if isInstance && inst.ClassName == "Map" {
+		entries := orderedMapEntries(inst, w.canonical)
+		keys := make([]string, len(entries))
+		values := make([]any, len(entries))
+		for i, entry := range entries {
+			keys[i] = utils.ToString(entry.Key)
+			values[i] = entry.Value
+		}
+		return w.enter(value, func() error { return w.writeObject(keys, values, depth) })
+	}
+
+	// Records, enum values and objects are converted the same way serialize() does
+	switch value.(type) {
+	case *ClassInstance, *common.RecordInstance, *common.EnumValueInstance:
+		if w.visiting[value] {
+			return w.cycleError()
+		}
+		serialized, err := serializeValue(w.env, value, make(map[any]bool))
+		if err != nil {
+			return err
+		}
+		if serialized == value {
+			break
+		}
+		return w.enter(value, func() error { return w.write(serialized, depth) })
+	}
+	return ThrowTypeError(w.env, "JSON-compatible value (Map, Array, String, Int, Float, Bool or nil)", value)
+}
+
+// orderedMapEntries returns the live entries of a Map in insertion order. Entries
+// added without going through _entries (e.g. by set) follow, ordered by hash.
+// With canonical set, as in deterministic runs, all entries are ordered by key
+// instead.
+func orderedMapEntries(inst *ClassInstance, canonical bool) []*mapEntry {
+	data, _ := inst.Fields["_data"].(map[uint64][]*mapEntry)
+	live := make(map[*mapEntry]bool)
+	hashes := make([]uint64, 0, len(data))
+	for hash, bucket := range data {
+		hashes = append(hashes, hash)
+		for _, entry := range bucket {
+			live[entry] = true
+		}
+	}
+
+	var ordered []*mapEntry
+	tracked, _ := inst.Fields["_entries"].([]*mapEntry)
+	for _, entry := range tracked {
+		if live[entry] {
+			ordered = append(ordered, entry)
+			delete(live, entry)
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	for _, hash := range hashes {
+		for _, entry := range data[hash] {
+			if live[entry] {
+				ordered = append(ordered, entry)
+			}
+		}
+	}
+	if canonical {
+		sort.SliceStable(ordered, func(i, j int) bool { return canonicalLess(ordered[i].Key, ordered[j].Key) })
+	}
+	return ordered
+}
+
+// enter marks container as being written while fn runs and reports cycles
+func (w *jsonWriter) enter(container any, fn func() error) error {
+	if w.visiting[container] {
+		return w.cycleError()
+	}
+	w.visiting[container] = true
+	defer delete(w.visiting, container)
+	return fn()
+}
+
+func (w *jsonWriter) cycleError() error {
+	return ThrowValueError(w.env, "cannot convert a cyclic structure to JSON")
+}
+
+func (w *jsonWriter) writeFloat(f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return ThrowValueError(w.env, fmt.Sprintf("cannot convert %v to JSON", f))
+	}
+	// Same layout as JavaScript, but integral floats keep a ".0" so they parse back as Float
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	w.buf.WriteString(s)
+	return nil
+}
+
+func (w *jsonWriter) writeString(s string) {
+	enc := json.NewEncoder(&w.buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	w.buf.Truncate(w.buf.Len() - 1) // drop the newline Encode appends
+}
+
+func (w *jsonWriter) writeArray(items []any, depth int) error {
+	if len(items) == 0 {
+		w.buf.WriteString("[]")
+		return nil
+	}
+	w.buf.WriteByte('[')
+	for i, item := range items {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		w.newline(depth + 1)
+		if err := w.write(item, depth+1); err != nil {
+			return err
+		}
+	}
+	w.newline(depth)
+	w.buf.WriteByte(']')
+	return nil
+}
+
+func (w *jsonWriter) writeObject(keys []string, values []any, depth int) error {
+	if len(keys) == 0 {
+		w.buf.WriteString("{}")
+		return nil
+	}
+	w.buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		w.newline(depth + 1)
+		w.writeString(key)
+		w.buf.WriteByte(':')
+		if w.indent != "" {
+			w.buf.WriteByte(' ')
+		}
+		if err := w.write(values[i], depth+1); err != nil {
+			return err
+		}
+	}
+	w.newline(depth)
+	w.buf.WriteByte('}')
+	return nil
+}
+
+// newline starts an indented line when pretty-printing
+func (w *jsonWriter) newline(depth int) {
+	if w.indent == "" {
+		return
+	}
+	w.buf.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		w.buf.WriteString(w.indent)
+	}
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limits bounds the work a single Eval call may do. Zero fields are unlimited.
+type Limits struct {
+	MaxSteps int64         // statements executed, counting every loop iteration
+	Timeout  time.Duration // wall-clock time of the run
+	MaxDepth int64         // nested calls of script functions, lambdas and methods
+
+	// Resource caps raise a ResourceError that scripts can catch, so a server can
+	// shed load and keep running
+	MaxHandles int64 // files and sockets open at the same time
+
+	// MaxMemory is a soft ceiling on the live heap of the whole process, checked
+	// between statements. Go does not tell which run allocated what, so the host
+	// and other interpreters count too; set it with room for them.
+	MaxMemory int64
+}
+
+// LimitError aborts a run that exceeded its Limits. Unlike exceptions it cannot be
+// caught with try/catch, so a script cannot keep running past its budget.
+type LimitError struct {
+	Message string
+}
+
+func (e *LimitError) Error() string { return e.Message }
+
+// limitState tracks the budget of one run. Threads and handlers the run starts
+// share it, so work left running after Eval returns stays within the limits.
+type limitState struct {
+	limits   Limits
+	steps    atomic.Int64
+	depth    atomic.Int64
+	handles  atomic.Int64
+	deadline time.Time
+}
+
+func newLimitState(limits Limits) *limitState {
+	state := &limitState{limits: limits}
+	if limits.Timeout > 0 {
+		state.deadline = time.Now().Add(limits.Timeout)
+	}
+	return state
+}
+
+// memoryCheckInterval is how many statements run between heap measurements
+const memoryCheckInterval = 1024
+
+// memoryGCInterval is the least time between collections forced by memory
+// checks. lastMemoryGC, in Unix nanoseconds, is shared by every limited run, so
+// a heap over the ceiling costs one collection per interval however many runs
+// notice it.
+const memoryGCInterval = 100 * time.Millisecond
+
+var lastMemoryGC atomic.Int64
+
+// step counts one executed statement and reports an exceeded limit
+func (s *limitState) step(env *Env) error {
+	steps := s.steps.Add(1)
+	if s.limits.MaxSteps > 0 && steps > s.limits.MaxSteps {
+		return &LimitError{Message: fmt.Sprintf("step limit of %d exceeded", s.limits.MaxSteps)}
+	}
+	// Reading the clock on every statement is measurable; every 64th is enough
+	if !s.deadline.IsZero() && steps%64 == 0 && time.Now().After(s.deadline) {
+		return &LimitError{Message: fmt.Sprintf("time limit of %s exceeded", s.limits.Timeout)}
+	}
+	if s.limits.MaxMemory > 0 && steps%memoryCheckInterval == 0 && heapInUse() > uint64(s.limits.MaxMemory) {
+		return s.checkLiveHeap(env)
+	}
+	return nil
+}
+
+// checkLiveHeap collects garbage, which still counts until it is collected, and
+// reports a live heap over the ceiling. When another check collected less than
+// memoryGCInterval ago the heap is measured again at a later check instead.
+func (s *limitState) checkLiveHeap(env *Env) error {
+	now := time.Now().UnixNano()
+	last := lastMemoryGC.Load()
+	if now-last < int64(memoryGCInterval) || !lastMemoryGC.CompareAndSwap(last, now) {
+		return nil
+	}
+	runtime.GC()
+	if used := heapInUse(); used > uint64(s.limits.MaxMemory) {
+		return ThrowResourceError(env, fmt.Sprintf("memory limit of %d bytes exceeded (%d in use)", s.limits.MaxMemory, used))
+	}
+	return nil
+}
+
+// heapInUse returns the bytes of heap objects, without stopping the world
+func heapInUse() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	return sample[0].Value.Uint64()
+}
+
+// enter counts a call into a script function and reports an exceeded depth. A
+// call that enter accepted must be matched by leave.
+func (s *limitState) enter() error {
+	if s.limits.MaxDepth <= 0 {
+		return nil
+	}
+	if s.depth.Add(1) > s.limits.MaxDepth {
+		s.depth.Add(-1)
+		return &LimitError{Message: fmt.Sprintf("call depth limit of %d exceeded", s.limits.MaxDepth)}
+	}
+	return nil
+}
+
+func (s *limitState) leave() {
+	if s.limits.MaxDepth > 0 {
+		s.depth.Add(-1)
+	}
+}
+
+// handleToken is an open file or socket counted against Limits.MaxHandles
+type handleToken struct {
+	state *limitState
+	once  sync.Once
+}
+
+// acquireHandle counts a file or socket about to be opened, raising a
+// ResourceError once Limits.MaxHandles are open. The token must be released when
+// the handle closes or fails to open; a nil token is valid and does nothing.
+func acquireHandle(env *Env) (*handleToken, error) {
+	state := limitsOf(env)
+	if state == nil || state.limits.MaxHandles <= 0 {
+		return nil, nil
+	}
+	if state.handles.Add(1) > state.limits.MaxHandles {
+		state.handles.Add(-1)
+		return nil, ThrowResourceError(env, fmt.Sprintf("too many open files and sockets (limit %d)", state.limits.MaxHandles))
+	}
+	return &handleToken{state: state}, nil
+}
+
+// release returns the handle to the budget; later calls do nothing
+func (h *handleToken) release() {
+	if h == nil {
+		return
+	}
+	h.once.Do(func() { h.state.handles.Add(-1) })
+}
+
+// releaseHandleField releases the token stored in an instance's _handle field
+func releaseHandleField(instance *ClassInstance) {
+	if token, ok := instance.Fields["_handle"].(*handleToken); ok {
+		token.release()
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
 	"github.com/ArubikU/polyloft/internal/engine/utils"
 )
 
@@ -22,6 +23,13 @@ func InstallMathModule(env *Env) {
 			if len(args) < 1 {
 				return nil, ThrowArityError(env, 1, len(args))
 			}
+			// Int stays Int
+			if i, ok := intOperand(env, args[0]); ok {
+				if i < 0 {
+					i = -i
+				}
+				return CreateIntInstance(env, i)
+			}
 			a, _ := utils.AsFloat(args[0])
 			return math.Abs(a), nil
 		})).
@@ -95,9 +103,7 @@ func InstallMathModule(env *Env) {
 			if len(args) < 2 {
 				return nil, ThrowArityError(env, 2, len(args))
 			}
-			a, _ := utils.AsFloat(args[0])
-			b, _ := utils.AsFloat(args[1])
-			return math.Pow(a, b), nil
+			return powValues(env, args[0], args[1])
 		})).
 		AddStaticMethod("min", &ast.Type{Name: "float", IsBuiltin: true}, []ast.Parameter{
 			{Name: "a", Type: ast.TypeFromString("Number")},
@@ -155,3 +161,43 @@ func InstallMathModule(env *Env) {
 		panic(err)
 	}
 }
+
+// intOperand returns v as an int when it is an Int; Floats are rejected even when whole
+func intOperand(env *Env, v any) (int, bool) {
+	switch val := v.(type) {
+	case int:
+		return val, true
+	case *ClassInstance:
+		if val.ParentClass != nil && val.ParentClass.IsSubclassOf(common.BuiltinTypeInt.GetClassDefinition(env)) {
+			return utils.AsInt(val)
+		}
+	}
+	return 0, false
+}
+
+// powValues computes base ** exponent for the ** operator and Math.pow. An Int base
+// with a non-negative Int exponent gives an Int; anything else gives a Float.
+func powValues(env *Env, base, exponent any) (any, error) {
+	if b, ok := intOperand(env, base); ok {
+		if e, ok := intOperand(env, exponent); ok && e >= 0 {
+			result := 1
+			for ; e > 0; e >>= 1 {
+				if e&1 == 1 {
+					result *= b
+				}
+				b *= b
+			}
+			return CreateIntInstance(env, result)
+		}
+	}
+
+	fb, okb := utils.AsFloat(base)
+	fe, oke := utils.AsFloat(exponent)
+	if !okb || !oke {
+		return nil, typeError("number", base, exponent)
+	}
+	if fb == 0 && fe < 0 {
+		return nil, ThrowRuntimeError(env, "zero cannot be raised to a negative power")
+	}
+	return CreateFloatInstance(env, math.Pow(fb, fe))
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModuleBundle holds the source of the modules a built executable imports,
+// keyed by the BundleKey of the path each import resolves to
+type ModuleBundle map[string]string
+
+// bundleHome stands for the user's home directory in bundle keys, so modules
+// from the global library directories resolve the same on every machine
+const bundleHome = "~"
+
+// BundleKey returns the key of the module file at path in a ModuleBundle: the
+// slash-separated path, with the home directory replaced by "~"
+func BundleKey(path string) string {
+	clean := filepath.Clean(path)
+	if home, err := os.UserHomeDir(); err == nil && home != "" && filepath.IsAbs(clean) {
+		if rel, err := filepath.Rel(home, clean); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			clean = filepath.Join(bundleHome, rel)
+		}
+	}
+	return filepath.ToSlash(clean)
+}
+
+// ResolveImport returns the files that importing path (such as ["math",
+// "vector"]) from fileName loads: one .pf file, or every .pf file of a
+// directory module. Builtin modules load no files.
+func ResolveImport(fileName string, path []string) ([]string, error) {
+	if _, ok := builtinModules[strings.Join(path, ".")]; ok {
+		return nil, nil
+	}
+	rel := filepath.Join(path...)
+	var modules ModuleBundle // imports are resolved on disk
+	modKey := resolveModule(modules, fileName, rel)
+	if modKey == "" {
+		return nil, fmt.Errorf("module not found: %s", rel)
+	}
+	if strings.HasSuffix(modKey, string(os.PathSeparator)) {
+		return modules.dirFiles(strings.TrimSuffix(modKey, string(os.PathSeparator))), nil
+	}
+	return []string{modKey}, nil
+}
+
+// The methods below read modules from the bundle, or from disk when the bundle
+// is nil, as it is for programs that are not built executables.
+
+// home returns the home directory whose .polyloft directory holds the global
+// libraries
+func (b ModuleBundle) home() string {
+	if b != nil {
+		return bundleHome
+	}
+	home, _ := os.UserHomeDir()
+	return home
+}
+
+// stat reports whether path exists as a module file or directory
+func (b ModuleBundle) stat(path string) (isDir, ok bool) {
+	if b == nil {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return false, false
+		}
+		return fi.IsDir(), true
+	}
+	key := BundleKey(path)
+	if _, found := b[key]; found {
+		return false, true
+	}
+	for name := range b {
+		if strings.HasPrefix(name, key+"/") {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+func (b ModuleBundle) readFile(path string) ([]byte, error) {
+	if b == nil {
+		return os.ReadFile(path)
+	}
+	source, ok := b[BundleKey(path)]
+	if !ok {
+		return nil, fmt.Errorf("module %s is not bundled", path)
+	}
+	return []byte(source), nil
+}
+
+// dirFiles returns the .pf files directly inside dir, sorted by name
+func (b ModuleBundle) dirFiles(dir string) []string {
+	var files []string
+	if b == nil {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if !e.IsDir() && filepath.Ext(e.Name()) == ".pf" {
+				files = append(files, filepath.Join(dir, e.Name()))
+			}
+		}
+		return files
+	}
+	prefix := BundleKey(dir) + "/"
+	for name := range b {
+		base := strings.TrimPrefix(name, prefix)
+		if base != name && !strings.Contains(base, "/") && filepath.Ext(base) == ".pf" {
+			files = append(files, filepath.Join(dir, base))
+		}
+	}
+	sort.Strings(files)
+	return files
+}
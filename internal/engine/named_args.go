@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+)
+
+// omittedArg fills the slot of a parameter that a call with keyword arguments
+// skipped; bindParametersWithVariadic replaces it with the parameter's default
+type omittedArg struct{}
+
+// namedArgsReceiver evaluates the receiver of a method called with keyword
+// arguments once, so the method's parameters can be looked up on it. The returned
+// callee reads the already evaluated receiver.
+func namedArgsReceiver(env *Env, field *ast.FieldExpr) (ast.Expr, any, error) {
+	switch x := field.X.(type) {
+	case *ast.Ident:
+		if classDef, exists := lookupClass(x.Name, env.GetPackageName()); exists {
+			return field, classDef, nil
+		}
+	case *ast.ValueExpr:
+		return field, x.Value, nil
+	}
+	receiver, err := evalExpr(env, field.X)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ast.FieldExpr{Spanned: field.Spanned, X: &ast.ValueExpr{Value: receiver}, Name: field.Name, Optional: field.Optional}, receiver, nil
+}
+
+// calleeParams returns the parameter lists a callee can be invoked with, one per
+// overload, and the name used for it in error messages
+func calleeParams(cal any, receiver any, method string) ([][]ast.Parameter, string) {
+	switch c := cal.(type) {
+	case *common.FunctionDefinition:
+		return [][]ast.Parameter{c.Params}, c.Name
+	case *common.LambdaDefinition:
+		return [][]ast.Parameter{c.Params}, "lambda"
+	case *common.ClassConstructor:
+		var overloads [][]ast.Parameter
+		if classDef := constructorAncestor(c.Definition); classDef != nil {
+			for _, ctor := range classDef.Constructors {
+				overloads = append(overloads, ctor.Params)
+			}
+		}
+		return overloads, c.Definition.Name
+	}
+
+	var classDef *ClassDefinition
+	switch r := receiver.(type) {
+	case *ClassInstance:
+		classDef = r.ParentClass
+	case *ClassDefinition:
+		classDef = r
+	}
+	for def := classDef; def != nil; def = def.Parent {
+		if methods, exists := def.Methods[method]; exists {
+			overloads := make([][]ast.Parameter, 0, len(methods))
+			for _, m := range methods {
+				overloads = append(overloads, m.Params)
+			}
+			return overloads, def.Name + "." + method
+		}
+	}
+	return nil, ""
+}
+
+// resolveNamedArgs places the keyword arguments of a call after its positional
+// arguments, in the order of the callee's parameters. The first overload that
+// accepts every keyword wins; skipped parameters with defaults get omittedArg.
+func resolveNamedArgs(env *Env, x *ast.CallExpr, cal any, receiver any, args []any) ([]any, error) {
+	method := ""
+	if field, ok := x.Callee.(*ast.FieldExpr); ok {
+		method = field.Name
+	}
+	overloads, name := calleeParams(cal, receiver, method)
+	if len(overloads) == 0 {
+		return nil, ThrowRuntimeError(env, fmt.Sprintf("keyword arguments are not supported when calling %s", describeCallee(cal)))
+	}
+
+	values := make([]any, len(x.NamedArgs))
+	for i, named := range x.NamedArgs {
+		v, err := evalExpr(env, named.Value)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	var firstErr error
+	for _, params := range overloads {
+		arranged, err := arrangeNamedArgs(env, name, params, args, x.NamedArgs, values)
+		if err == nil {
+			return arranged, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// arrangeNamedArgs binds keyword values to the slots of one parameter list
+func arrangeNamedArgs(env *Env, name string, params []ast.Parameter, args []any, named []ast.NamedArg, values []any) ([]any, error) {
+	positional := len(params)
+	if positional > 0 && params[positional-1].IsVariadic {
+		positional--
+	}
+
+	slots := append([]any{}, args...)
+	for i, arg := range named {
+		index := -1
+		for j, param := range params[:positional] {
+			if param.Name == arg.Name {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			if positional < len(params) && params[positional].Name == arg.Name {
+				return nil, ThrowRuntimeError(env, fmt.Sprintf("variadic parameter '%s' of %s cannot be passed by name", arg.Name, name))
+			}
+			return nil, ThrowRuntimeError(env, fmt.Sprintf("unknown parameter '%s' in call to %s", arg.Name, name))
+		}
+		if index < len(args) {
+			return nil, ThrowRuntimeError(env, fmt.Sprintf("parameter '%s' of %s is already given positionally", arg.Name, name))
+		}
+		for len(slots) <= index {
+			slots = append(slots, omittedArg{})
+		}
+		slots[index] = values[i]
+	}
+
+	for j := len(args); j < positional; j++ {
+		missing := j >= len(slots)
+		if !missing {
+			_, missing = slots[j].(omittedArg)
+		}
+		if missing && params[j].Default == nil {
+			return nil, ThrowRuntimeError(env, fmt.Sprintf("missing argument for parameter '%s' in call to %s", params[j].Name, name))
+		}
+	}
+	return slots, nil
+}
+
+// describeCallee names a callee without parameter information for error messages
+func describeCallee(cal any) string {
+	if _, ok := cal.(common.Func); ok {
+		return "a builtin function"
+	}
+	return fmt.Sprintf("%T", cal)
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/ArubikU/polyloft/internal/ast"
@@ -28,16 +29,29 @@ func InstallNetModule(env *Env, opts Options) {
 				return nil, ThrowArityError(env, 1, len(args))
 			}
 			addrStr := utils.ToString(args[0])
+			handle, err := acquireHandle(env)
+			if err != nil {
+				return nil, err
+			}
 			ln, err := net.Listen("tcp", addrStr)
 			if err != nil {
+				handle.release()
 				return nil, err
 			}
 			server := map[string]any{}
 			server["addr"] = ln.Addr().String()
-			server["close"] = Func(func(_ *Env, _ []any) (any, error) { return nil, ln.Close() })
-			server["accept"] = Func(func(_ *Env, _ []any) (any, error) {
+			server["close"] = Func(func(_ *Env, _ []any) (any, error) {
+				handle.release()
+				return nil, ln.Close()
+			})
+			server["accept"] = Func(func(e *Env, _ []any) (any, error) {
+				connHandle, err := acquireHandle(e)
+				if err != nil {
+					return nil, err
+				}
 				c, err := ln.Accept()
 				if err != nil {
+					connHandle.release()
 					return nil, err
 				}
 				br := bufio.NewReader(c)
@@ -69,7 +83,10 @@ func InstallNetModule(env *Env, opts Options) {
 					}
 					return string(buf[:r]), nil
 				})
-				conn["close"] = Func(func(_ *Env, _ []any) (any, error) { return nil, c.Close() })
+				conn["close"] = Func(func(_ *Env, _ []any) (any, error) {
+					connHandle.release()
+					return nil, c.Close()
+				})
 				return conn, nil
 			})
 			return server, nil
@@ -90,8 +107,13 @@ func InstallNetModule(env *Env, opts Options) {
 				}
 			}
 
+			handle, err := acquireHandle(env)
+			if err != nil {
+				return nil, err
+			}
 			conn, err := net.DialTimeout("tcp", addrStr, timeout)
 			if err != nil {
+				handle.release()
 				return nil, err
 			}
 
@@ -136,7 +158,10 @@ func InstallNetModule(env *Env, opts Options) {
 				return string(buf[:r]), nil
 			})
 
-			client["close"] = Func(func(_ *Env, _ []any) (any, error) { return nil, conn.Close() })
+			client["close"] = Func(func(_ *Env, _ []any) (any, error) {
+				handle.release()
+				return nil, conn.Close()
+			})
 
 			return client, nil
 		})).
@@ -153,14 +178,22 @@ func InstallNetModule(env *Env, opts Options) {
 				return nil, err
 			}
 
+			handle, err := acquireHandle(env)
+			if err != nil {
+				return nil, err
+			}
 			conn, err := net.ListenUDP("udp", udpAddr)
 			if err != nil {
+				handle.release()
 				return nil, err
 			}
 
 			server := map[string]any{}
 			server["addr"] = conn.LocalAddr().String()
-			server["close"] = Func(func(_ *Env, _ []any) (any, error) { return nil, conn.Close() })
+			server["close"] = Func(func(_ *Env, _ []any) (any, error) {
+				handle.release()
+				return nil, conn.Close()
+			})
 
 			server["recv"] = Func(func(_ *Env, args []any) (any, error) {
 				n := 1024
@@ -224,15 +257,23 @@ func InstallNetModule(env *Env, opts Options) {
 				return nil, err
 			}
 
+			handle, err := acquireHandle(env)
+			if err != nil {
+				return nil, err
+			}
 			conn, err := net.DialUDP("udp", nil, udpAddr)
 			if err != nil {
+				handle.release()
 				return nil, err
 			}
 
 			client := map[string]any{}
 			client["remote"] = conn.RemoteAddr().String()
 			client["local"] = conn.LocalAddr().String()
-			client["close"] = Func(func(_ *Env, _ []any) (any, error) { return nil, conn.Close() })
+			client["close"] = Func(func(_ *Env, _ []any) (any, error) {
+				handle.release()
+				return nil, conn.Close()
+			})
 
 			client["send"] = Func(func(_ *Env, args []any) (any, error) {
 				if len(args) < 1 {
@@ -347,7 +388,7 @@ func InstallNetModule(env *Env, opts Options) {
 				}
 			}
 
-			addr := fmt.Sprintf("%s:%d", hostStr, port)
+			addr := net.JoinHostPort(hostStr, strconv.Itoa(port))
 			conn, err := net.DialTimeout("tcp", addr, timeout)
 			if err != nil {
 				return false, nil
@@ -127,6 +127,8 @@ var envPool = sync.Pool{
 func GetPooledEnv(parent *common.Env) *common.Env {
 	env := envPool.Get().(*common.Env)
 	env.Parent = parent
+	env.Captured = false
+	env.Hooks = nil
 	
 	// Clear maps (Go 1.21+ has clear() but we'll do it manually for compatibility)
 	for k := range env.Vars {
@@ -138,6 +140,9 @@ func GetPooledEnv(parent *common.Env) *common.Env {
 	for k := range env.Finals {
 		delete(env.Finals, k)
 	}
+	for k := range env.Types {
+		delete(env.Types, k)
+	}
 	for k := range env.ImportedClasses {
 		delete(env.ImportedClasses, k)
 	}
@@ -149,26 +154,34 @@ func GetPooledEnv(parent *common.Env) *common.Env {
 	env.Defers = env.Defers[:0]
 	env.PositionStack = env.PositionStack[:0]
 	env.CodeContext = env.CodeContext[:0]
-	env.SourceLines = env.SourceLines[:0]
 	
-	// Reset other fields
-	env.FileName = ""
-	env.PackageName = ""
-	env.CurrentLine = 0
-	env.CurrentColumn = 0
+	// Inherit file context like Env.Child so positions inside calls stay accurate
+	env.SourceLines = parent.SourceLines
+	env.FileName = parent.FileName
+	env.PackageName = parent.PackageName
+	env.CurrentLine = parent.CurrentLine
+	env.CurrentColumn = parent.CurrentColumn
 	
 	return env
 }
 
-// ReleaseEnv returns an environment to the pool
+// ReleaseEnv returns an environment to the pool, unless a closure still refers to it
 func ReleaseEnv(env *common.Env) {
-	if env == nil {
+	if env == nil || env.Captured {
 		return
 	}
 	env.Parent = nil
 	envPool.Put(env)
 }
 
+// CaptureEnv marks env and its ancestors as referenced by a closure, so that
+// returning from the calls that created them does not recycle them
+func CaptureEnv(env *common.Env) {
+	for cur := env; cur != nil && !cur.Captured; cur = cur.Parent {
+		cur.Captured = true
+	}
+}
+
 // FastIntOperation performs optimized integer arithmetic
 func FastIntOperation(op int, a, b int) int {
 	switch op {
@@ -0,0 +1,287 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// processOptions are the settings accepted in the options Map of Process.exec and Process.stream
+type processOptions struct {
+	timeout time.Duration // 0 waits for the command to finish
+	dir     string        // working directory; empty uses the current one
+	check   bool          // raise ProcessError on a non-zero exit status
+}
+
+// processLine is one line of output read from a running command
+type processLine struct {
+	text   string
+	source string // "stdout" or "stderr"
+}
+
+// InstallProcessModule installs the Process class for running external commands.
+// A command that cannot be found raises CommandNotFoundError; a command that fails,
+// times out or (with the check option) exits with a non-zero status raises ProcessError.
+func InstallProcessModule(env *Env) error {
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	intType := common.BuiltinTypeInt.GetTypeDefinition(env)
+	mapType := common.BuiltinTypeMap.GetTypeDefinition(env)
+	arrayType := common.BuiltinTypeArray.GetTypeDefinition(env)
+
+	processClass := NewClassBuilder("Process").
+		// run(cmd: String, args: String...) -> Map of exitCode, stdout, stderr and ok
+		AddStaticMethod("run", mapType, []ast.Parameter{
+			{Name: "cmd", Type: stringType},
+			{Name: "args", Type: nil, IsVariadic: true},
+		}, Func(func(e *Env, args []any) (any, error) {
+			cmdArgs := make([]string, 0, len(args)-1)
+			for _, arg := range args[1:] {
+				cmdArgs = append(cmdArgs, utils.ToString(arg))
+			}
+			return runProcess(e, utils.ToString(args[0]), cmdArgs, processOptions{})
+		})).
+		// exec(cmd: String, args: Array, options: Map) -> Map - run with timeout, dir and check options
+		AddStaticMethod("exec", mapType, []ast.Parameter{
+			{Name: "cmd", Type: stringType},
+			{Name: "args", Type: arrayType},
+			{Name: "options", Type: mapType},
+		}, Func(func(e *Env, args []any) (any, error) {
+			cmdArgs, err := processArgs(e, args[1])
+			if err != nil {
+				return nil, err
+			}
+			opts, err := parseProcessOptions(e, args[2])
+			if err != nil {
+				return nil, err
+			}
+			return runProcess(e, utils.ToString(args[0]), cmdArgs, opts)
+		})).
+		// stream(cmd: String, args: Array, onLine: Function) -> Int - calls onLine(line, source) for each line
+		AddStaticMethod("stream", intType, []ast.Parameter{
+			{Name: "cmd", Type: stringType},
+			{Name: "args", Type: arrayType},
+			{Name: "onLine", Type: ast.ANY},
+		}, Func(func(e *Env, args []any) (any, error) {
+			return streamProcessCall(e, args, processOptions{})
+		})).
+		AddStaticMethod("stream", intType, []ast.Parameter{
+			{Name: "cmd", Type: stringType},
+			{Name: "args", Type: arrayType},
+			{Name: "onLine", Type: ast.ANY},
+			{Name: "options", Type: mapType},
+		}, Func(func(e *Env, args []any) (any, error) {
+			opts, err := parseProcessOptions(e, args[3])
+			if err != nil {
+				return nil, err
+			}
+			return streamProcessCall(e, args, opts)
+		}))
+
+	_, err := processClass.BuildStatic(env)
+	return err
+}
+
+// processArgs converts an Array of arguments to strings
+func processArgs(env *Env, value any) ([]string, error) {
+	items, ok := collectionItems(value)
+	if !ok {
+		return nil, ThrowTypeError(env, "Array", value)
+	}
+	args := make([]string, len(items))
+	for i, item := range items {
+		args[i] = utils.ToString(item)
+	}
+	return args, nil
+}
+
+// parseProcessOptions reads the timeout (milliseconds), dir and check options
+func parseProcessOptions(env *Env, value any) (processOptions, error) {
+	var opts processOptions
+	mapInstance, ok := value.(*ClassInstance)
+	if !ok || mapInstance.ClassName != "Map" {
+		return opts, ThrowTypeError(env, "Map", value)
+	}
+	fields, err := MapToObject(env, mapInstance)
+	if err != nil {
+		return opts, err
+	}
+	for key, val := range fields {
+		switch key {
+		case "timeout":
+			ms, ok := utils.AsInt(val)
+			if !ok || ms < 0 {
+				return opts, ThrowValueError(env, fmt.Sprintf("timeout must be a non-negative number of milliseconds, got %s", utils.ToString(val)))
+			}
+			opts.timeout = time.Duration(ms) * time.Millisecond
+		case "dir":
+			opts.dir = utils.ToString(val)
+		case "check":
+			opts.check = utils.AsBool(val)
+		default:
+			return opts, ThrowValueError(env, fmt.Sprintf("unknown Process option '%s' (expected timeout, dir or check)", key))
+		}
+	}
+	return opts, nil
+}
+
+// processContext returns the context a command runs under, bounded by the timeout option
+func processContext(opts processOptions) (context.Context, context.CancelFunc) {
+	if opts.timeout > 0 {
+		return context.WithTimeout(context.Background(), opts.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// processExitCode turns the error of a finished command into its exit status,
+// raising CommandNotFoundError or ProcessError when the command did not run to completion
+func processExitCode(env *Env, ctx context.Context, name string, opts processOptions, err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	var execErr *exec.Error
+	if errors.As(err, &execErr) {
+		return 0, ThrowCommandNotFoundError(env, name)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return 0, ThrowProcessError(env, fmt.Sprintf("command '%s' timed out after %d ms", name, opts.timeout.Milliseconds()))
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	return 0, ThrowProcessError(env, fmt.Sprintf("command '%s' failed: %v", name, err))
+}
+
+// runProcess runs a command to completion and collects its output
+func runProcess(env *Env, name string, args []string, opts processOptions) (any, error) {
+	ctx, cancel := processContext(opts)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	exitCode, err := processExitCode(env, ctx, name, opts, cmd.Run())
+	if err != nil {
+		return nil, err
+	}
+	if opts.check && exitCode != 0 {
+		message := fmt.Sprintf("command '%s' exited with status %d", name, exitCode)
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			message += ": " + detail
+		}
+		return nil, ThrowProcessError(env, message)
+	}
+
+	result, err := CreateMapInstance(env, map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+	putMapEntry(env, result, "exitCode", exitCode)
+	putMapEntry(env, result, "stdout", stdout.String())
+	putMapEntry(env, result, "stderr", stderr.String())
+	putMapEntry(env, result, "ok", exitCode == 0)
+	return result, nil
+}
+
+// streamProcessCall unpacks the cmd, args and onLine arguments of Process.stream
+func streamProcessCall(env *Env, args []any, opts processOptions) (any, error) {
+	cmdArgs, err := processArgs(env, args[1])
+	if err != nil {
+		return nil, err
+	}
+	onLine, ok := common.ExtractFunc(args[2])
+	if !ok {
+		return nil, ThrowTypeError(env, "function", args[2])
+	}
+	// onLine may take just the line, or the line and its source
+	passSource := true
+	if params, hasParams := functionValueParams(args[2]); hasParams && len(params) == 1 {
+		passSource = false
+	}
+	return streamProcess(env, utils.ToString(args[0]), cmdArgs, opts, func(line processLine) error {
+		callArgs := []any{line.text}
+		if passSource {
+			callArgs = append(callArgs, line.source)
+		}
+		_, err := onLine((*common.Env)(env), callArgs)
+		return err
+	})
+}
+
+// streamProcess runs a command and hands each line of stdout and stderr to onLine
+// as it is produced. onLine runs on the calling goroutine; if it fails the command is killed.
+func streamProcess(env *Env, name string, args []string, opts processOptions, onLine func(processLine) error) (any, error) {
+	ctx, cancel := processContext(opts)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.dir
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, ThrowProcessError(env, err.Error())
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, ThrowProcessError(env, err.Error())
+	}
+	if err := cmd.Start(); err != nil {
+		_, err = processExitCode(env, ctx, name, opts, err)
+		return nil, err
+	}
+
+	lines := make(chan processLine)
+	var wg sync.WaitGroup
+	scan := func(r io.Reader, source string) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- processLine{text: scanner.Text(), source: source}
+		}
+	}
+	wg.Add(2)
+	go scan(stdout, "stdout")
+	go scan(stderr, "stderr")
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	// Keep draining after a callback error so the readers can finish
+	var callbackErr error
+	for line := range lines {
+		if callbackErr != nil {
+			continue
+		}
+		if err := onLine(line); err != nil {
+			callbackErr = err
+			cancel()
+		}
+	}
+
+	waitErr := cmd.Wait()
+	if callbackErr != nil {
+		return nil, callbackErr
+	}
+	exitCode, err := processExitCode(env, ctx, name, opts, waitErr)
+	if err != nil {
+		return nil, err
+	}
+	if opts.check && exitCode != 0 {
+		return nil, ThrowProcessError(env, fmt.Sprintf("command '%s' exited with status %d", name, exitCode))
+	}
+	return exitCode, nil
+}
@@ -0,0 +1,224 @@
+package engine
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// lockedRand is a math/rand source that can be shared by threads
+type lockedRand struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func (r *lockedRand) intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Intn(n)
+}
+
+// intRange returns a number in [lo, hi], even when the span overflows int64
+func (r *lockedRand) intRange(lo, hi int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if span := uint64(hi) - uint64(lo); span < math.MaxInt64 {
+		return lo + r.rnd.Int63n(int64(span)+1)
+	}
+	// The span covers more than half of all int64 values: draw until one fits
+	for {
+		if n := int64(r.rnd.Uint64()); n >= lo && n <= hi {
+			return n
+		}
+	}
+}
+
+func (r *lockedRand) float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Float64()
+}
+
+func (r *lockedRand) seed(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rnd.Seed(n)
+}
+
+func (r *lockedRand) normFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.NormFloat64()
+}
+
+// shuffle reorders items in place with a Fisher-Yates shuffle
+func (r *lockedRand) shuffle(items []any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(items) - 1; i > 0; i-- {
+		j := r.rnd.Intn(i + 1)
+		items[i], items[j] = items[j], items[i]
+	}
+}
+
+// InstallRandomModule installs the Random class: numbers, shuffling, picking
+// elements, Gaussian numbers and UUIDs. Collections are any Array, List, Deque, Set
+// or Tuple; shuffle reorders an Array or List in place, the other methods leave
+// their input untouched. Everything but uuid draws from one math/rand source, so
+// Random.seed(n) makes the sequence reproducible; uuid always uses crypto/rand.
+func InstallRandomModule(env *Env) error {
+	intType := common.BuiltinTypeInt.GetTypeDefinition(env)
+	floatType := common.BuiltinTypeFloat.GetTypeDefinition(env)
+	arrayType := common.BuiltinTypeArray.GetTypeDefinition(env)
+	numberType := ast.TypeFromString("Number")
+
+	rnd := &lockedRand{rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+	randomClass := NewClassBuilder("Random").
+		// int(min: Int, max: Int) -> Int - a whole number between min and max, inclusive
+		AddStaticMethod("int", intType, []ast.Parameter{
+			{Name: "min", Type: intType},
+			{Name: "max", Type: intType},
+		}, Func(func(e *Env, args []any) (any, error) {
+			lo, ok := utils.AsInt(args[0])
+			if !ok {
+				return nil, ThrowTypeError(e, "Int", args[0])
+			}
+			hi, ok := utils.AsInt(args[1])
+			if !ok {
+				return nil, ThrowTypeError(e, "Int", args[1])
+			}
+			if lo > hi {
+				return nil, ThrowValueError(e, fmt.Sprintf("min must not be greater than max, got %d > %d", lo, hi))
+			}
+			return CreateIntInstance(e, int(rnd.intRange(int64(lo), int64(hi))))
+		})).
+		// float() -> Float - a number in [0, 1)
+		AddStaticMethod("float", floatType, []ast.Parameter{}, Func(func(e *Env, args []any) (any, error) {
+			return CreateFloatInstance(e, rnd.float64())
+		})).
+		// seed(n: Int) -> Void - restart the sequence, so runs with the same seed match
+		AddStaticMethod("seed", &ast.Type{Name: "void", IsBuiltin: true}, []ast.Parameter{
+			{Name: "n", Type: intType},
+		}, Func(func(e *Env, args []any) (any, error) {
+			n, ok := utils.AsInt(args[0])
+			if !ok {
+				return nil, ThrowTypeError(e, "Int", args[0])
+			}
+			rnd.seed(int64(n))
+			return nil, nil
+		})).
+		// uuid() -> String - a random (version 4) UUID
+		AddStaticMethod("uuid", common.BuiltinTypeString.GetTypeDefinition(env), []ast.Parameter{}, Func(func(e *Env, args []any) (any, error) {
+			id, err := newUUID()
+			if err != nil {
+				return nil, ThrowRuntimeError(e, fmt.Sprintf("cannot read random bytes: %v", err))
+			}
+			return CreateStringInstance(e, id)
+		})).
+		// shuffle(items: Array) -> Array - shuffle an Array or List in place and return it
+		AddStaticMethod("shuffle", ast.ANY, []ast.Parameter{
+			{Name: "items", Type: ast.ANY},
+		}, Func(func(e *Env, args []any) (any, error) {
+			inst, ok := args[0].(*ClassInstance)
+			if !ok || (inst.ClassName != "Array" && inst.ClassName != "List") {
+				return nil, ThrowTypeError(e, "Array or List", args[0])
+			}
+			items, _ := collectionItems(inst)
+			rnd.shuffle(items)
+			return inst, nil
+		})).
+		// shuffled(items) -> Array - a shuffled copy of a collection
+		AddStaticMethod("shuffled", arrayType, []ast.Parameter{
+			{Name: "items", Type: ast.ANY},
+		}, Func(func(e *Env, args []any) (any, error) {
+			items, err := randomItems(e, args[0])
+			if err != nil {
+				return nil, err
+			}
+			shuffled := append([]any(nil), items...)
+			rnd.shuffle(shuffled)
+			return CreateArrayInstance(e, shuffled)
+		})).
+		// choice(items) -> Any - one element picked at random
+		AddStaticMethod("choice", ast.ANY, []ast.Parameter{
+			{Name: "items", Type: ast.ANY},
+		}, Func(func(e *Env, args []any) (any, error) {
+			items, err := randomItems(e, args[0])
+			if err != nil {
+				return nil, err
+			}
+			if len(items) == 0 {
+				return nil, ThrowValueError(e, "cannot choose from an empty collection")
+			}
+			return items[rnd.intn(len(items))], nil
+		})).
+		// sample(items, n: Int) -> Array - n distinct elements, without replacement
+		AddStaticMethod("sample", arrayType, []ast.Parameter{
+			{Name: "items", Type: ast.ANY},
+			{Name: "n", Type: intType},
+		}, Func(func(e *Env, args []any) (any, error) {
+			items, err := randomItems(e, args[0])
+			if err != nil {
+				return nil, err
+			}
+			n, ok := utils.AsInt(args[1])
+			if !ok {
+				return nil, ThrowTypeError(e, "Int", args[1])
+			}
+			if n < 0 || n > len(items) {
+				return nil, ThrowValueError(e, fmt.Sprintf("sample size must be between 0 and %d, got %d", len(items), n))
+			}
+			picked := append([]any(nil), items...)
+			rnd.shuffle(picked)
+			return CreateArrayInstance(e, picked[:n])
+		})).
+		// normal(mean: Number, stddev: Number) -> Float - a normally distributed number
+		AddStaticMethod("normal", floatType, []ast.Parameter{
+			{Name: "mean", Type: numberType},
+			{Name: "stddev", Type: numberType},
+		}, Func(func(e *Env, args []any) (any, error) {
+			mean, ok := utils.AsFloat(args[0])
+			if !ok {
+				return nil, ThrowTypeError(e, "Number", args[0])
+			}
+			stddev, ok := utils.AsFloat(args[1])
+			if !ok {
+				return nil, ThrowTypeError(e, "Number", args[1])
+			}
+			if stddev < 0 {
+				return nil, ThrowValueError(e, fmt.Sprintf("standard deviation must be non-negative, got %v", stddev))
+			}
+			return mean + stddev*rnd.normFloat64(), nil
+		}))
+
+	_, err := randomClass.BuildStatic(env)
+	return err
+}
+
+// newUUID returns a version 4 UUID from crypto/rand in its canonical text form
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// randomItems returns the elements of a collection argument
+func randomItems(env *Env, value any) ([]any, error) {
+	items, ok := collectionItems(value)
+	if !ok {
+		return nil, ThrowTypeError(env, "Array, List, Set or Tuple", value)
+	}
+	return items, nil
+}
@@ -40,6 +40,7 @@ func evalRecordDecl(env *Env, decl *ast.RecordDecl) (any, error) {
 			IsAbstract:  method.IsAbstract,
 			IsStatic:    false,
 			IsPrivate:   contains(method.Modifiers, "private"),
+			IsGenerator: containsYield(method.Body),
 			BuiltinImpl: nil,
 		}
 		info.Params = append(info.Params, method.Params...)
@@ -92,7 +93,7 @@ func bindRecordInstanceMethods(instance *common.RecordInstance) {
 			// Select appropriate method based on argument count
 			method := common.SelectMethodOverload(overloads, len(args))
 			if method == nil {
-				return nil, ThrowRuntimeError(callEnv, fmt.Sprintf("no overload found for %s.%s with %d arguments", def.Name, name, len(args)))
+				return nil, ThrowOverloadError(callEnv, def.Name, name, overloads, len(args))
 			}
 
 			if method.IsAbstract {
@@ -105,6 +106,9 @@ func bindRecordInstanceMethods(instance *common.RecordInstance) {
 			if err := bindParametersWithVariadic(methodEnv, method.Params, args); err != nil {
 				return nil, err
 			}
+			if method.IsGenerator {
+				return newGenerator(methodEnv, def.Name+"."+name, methodEnv, method.Body)
+			}
 
 			var last any
 			for _, stmt := range method.Body {
@@ -122,13 +126,13 @@ func bindRecordInstanceMethods(instance *common.RecordInstance) {
 	}
 
 	if _, ok := instance.Methods["toString"]; !ok {
-		instance.Methods["toString"] = Func(func(_ *Env, _ []any) (any, error) {
+		instance.Methods["toString"] = Func(func(callEnv *Env, _ []any) (any, error) {
 			if def == nil {
 				return "record", nil
 			}
 			parts := make([]string, 0, len(def.Components))
 			for _, component := range def.Components {
-				parts = append(parts, fmt.Sprintf("%s=%s", component.Name, utils.ToString(instance.Values[component.Name])))
+				parts = append(parts, fmt.Sprintf("%s=%s", component.Name, utils.ToStringWithEnv(instance.Values[component.Name], callEnv)))
 			}
 			return fmt.Sprintf("%s(%s)", def.Name, strings.Join(parts, ", ")), nil
 		})
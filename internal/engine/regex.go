@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"regexp"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// InstallRegexBuiltin installs the Regex class. Regex(pattern) compiles the
+// pattern once with Go's regexp syntax (RE2) and keeps it in a private field;
+// the capture groups of the last successful find() are available from groups().
+func InstallRegexBuiltin(env *Env) error {
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	boolType := common.BuiltinTypeBool.GetTypeDefinition(env)
+	arrayType := common.BuiltinTypeArray.GetTypeDefinition(env)
+
+	regexBuilder := NewClassBuilder("Regex").
+		AddField("_regex", ast.ANY, []string{"private"}).
+		AddField("_groups", arrayType, []string{"private"})
+
+	// Constructor: Regex(pattern) - compile errors raise ValueError
+	regexBuilder.AddBuiltinConstructor([]ast.Parameter{
+		{Name: "pattern", Type: stringType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		compiled, err := regexp.Compile(utils.ToString(args[0]))
+		if err != nil {
+			return nil, ThrowValueError((*Env)(callEnv), err.Error())
+		}
+		instance.Fields["_regex"] = compiled
+		instance.Fields["_groups"] = []any{}
+		return nil, nil
+	})
+
+	// match(str: String) -> Bool - true when the pattern matches anywhere in str
+	regexBuilder.AddBuiltinMethod("match", boolType, []ast.Parameter{
+		{Name: "str", Type: stringType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		re := regexOf(callEnv)
+		return CreateBoolInstance((*Env)(callEnv), re.MatchString(utils.ToString(args[0])))
+	}, []string{})
+
+	// find(str: String) -> String - the first match, or nil; its capture groups are kept for groups()
+	regexBuilder.AddBuiltinMethod("find", stringType, []ast.Parameter{
+		{Name: "str", Type: stringType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		re := instance.Fields["_regex"].(*regexp.Regexp)
+
+		str := utils.ToString(args[0])
+		loc := re.FindStringSubmatchIndex(str)
+		if loc == nil {
+			instance.Fields["_groups"] = []any{}
+			return nil, nil
+		}
+		groups := make([]any, 0, len(loc)/2-1)
+		for i := 2; i < len(loc); i += 2 {
+			// Groups that did not take part in the match are nil
+			if loc[i] < 0 {
+				groups = append(groups, nil)
+				continue
+			}
+			group, err := CreateStringInstance((*Env)(callEnv), str[loc[i]:loc[i+1]])
+			if err != nil {
+				return nil, err
+			}
+			groups = append(groups, group)
+		}
+		instance.Fields["_groups"] = groups
+		return CreateStringInstance((*Env)(callEnv), str[loc[0]:loc[1]])
+	}, []string{})
+
+	// groups() -> Array - capture groups of the last successful find(), empty otherwise
+	regexBuilder.AddBuiltinMethod("groups", arrayType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		instance := thisVal.(*ClassInstance)
+		groups, _ := instance.Fields["_groups"].([]any)
+		return CreateArrayInstance((*Env)(callEnv), append([]any{}, groups...))
+	}, []string{})
+
+	// findAll(str: String) -> Array - every non-overlapping match
+	regexBuilder.AddBuiltinMethod("findAll", arrayType, []ast.Parameter{
+		{Name: "str", Type: stringType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		re := regexOf(callEnv)
+		matches := re.FindAllString(utils.ToString(args[0]), -1)
+		items := make([]any, len(matches))
+		for i, m := range matches {
+			s, err := CreateStringInstance((*Env)(callEnv), m)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = s
+		}
+		return CreateArrayInstance((*Env)(callEnv), items)
+	}, []string{})
+
+	// replace(str: String, repl: String) -> String - replaces every match; $1 or ${name} expand groups
+	regexBuilder.AddBuiltinMethod("replace", stringType, []ast.Parameter{
+		{Name: "str", Type: stringType},
+		{Name: "repl", Type: stringType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		re := regexOf(callEnv)
+		return CreateStringInstance((*Env)(callEnv), re.ReplaceAllString(utils.ToString(args[0]), utils.ToString(args[1])))
+	}, []string{})
+
+	// pattern() -> String
+	regexBuilder.AddBuiltinMethod("pattern", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		return CreateStringInstance((*Env)(callEnv), regexOf(callEnv).String())
+	}, []string{})
+
+	// toString() -> String
+	regexBuilder.AddBuiltinMethod("toString", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		return CreateStringInstance((*Env)(callEnv), "Regex("+regexOf(callEnv).String()+")")
+	}, []string{})
+
+	_, err := regexBuilder.Build(env)
+	return err
+}
+
+// regexOf returns the compiled pattern of the Regex bound to this
+func regexOf(env *common.Env) *regexp.Regexp {
+	thisVal, _ := env.This()
+	return thisVal.(*ClassInstance).Fields["_regex"].(*regexp.Regexp)
+}
@@ -0,0 +1,57 @@
+package engine
+
+import "time"
+
+// SandboxLimits fills the zero fields of the limits of a sandboxed run, so
+// untrusted code is always bounded
+var SandboxLimits = Limits{MaxSteps: 50_000_000, Timeout: 30 * time.Second, MaxDepth: 2000}
+
+// effectiveLimits returns the limits a run with opts enforces, nil when it is
+// unlimited
+func effectiveLimits(opts Options) *Limits {
+	if !opts.Sandbox {
+		return opts.Limits
+	}
+	limits := SandboxLimits
+	if opts.Limits != nil {
+		if opts.Limits.MaxSteps > 0 {
+			limits.MaxSteps = opts.Limits.MaxSteps
+		}
+		if opts.Limits.Timeout > 0 {
+			limits.Timeout = opts.Limits.Timeout
+		}
+		if opts.Limits.MaxDepth > 0 {
+			limits.MaxDepth = opts.Limits.MaxDepth
+		}
+		limits.MaxHandles = opts.Limits.MaxHandles
+		limits.MaxMemory = opts.Limits.MaxMemory
+	}
+	return &limits
+}
+
+// sandboxedModules are the classes of the modules a sandbox does not install.
+// Builtin classes are registered process-wide, so static access checks them too.
+var sandboxedModules = map[string]bool{
+	"IO": true, "File": true, "Process": true, "Net": true, "Socket": true, "ServerSocket": true,
+	"Http": true, "HttpServer": true, "HttpSession": true, "WebSocket": true,
+}
+
+// isSandboxed reports whether env belongs to a sandboxed program
+func isSandboxed(env *Env) bool {
+	if env == nil {
+		return false
+	}
+	sandboxed, _ := env.Get("$sandbox")
+	return sandboxed == true
+}
+
+// sandboxGuard returns fn, or a function raising an AccessError when opts runs a
+// sandbox, for builtins that reach outside the program
+func sandboxGuard(opts Options, className, method string, fn Func) Func {
+	if !opts.Sandbox {
+		return fn
+	}
+	return func(e *Env, args []any) (any, error) {
+		return nil, ThrowAccessError(e, method, className, "sandboxed")
+	}
+}
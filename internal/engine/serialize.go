@@ -0,0 +1,386 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// InstallSerializeBuiltins installs the global serialize(value) and fromJson(data, Class) functions
+func InstallSerializeBuiltins(env *Env) error {
+	fn := NewFunctionBuilder("serialize").
+		SetParamsFromNames([]string{"value"}, nil).
+		SetImplementation(func(callEnv *common.Env, args []any) (any, error) {
+			if len(args) != 1 {
+				return nil, ThrowArityError((*Env)(callEnv), 1, len(args))
+			}
+			return serializeValue((*Env)(callEnv), args[0], map[any]bool{})
+		})
+	if _, err := fn.Build(env); err != nil {
+		return err
+	}
+
+	fromJson := NewFunctionBuilder("fromJson").
+		SetParamsFromNames([]string{"data", "classRef"}, nil).
+		SetImplementation(func(callEnv *common.Env, args []any) (any, error) {
+			if len(args) != 2 {
+				return nil, ThrowArityError((*Env)(callEnv), 2, len(args))
+			}
+			var classDef *ClassDefinition
+			switch ref := args[1].(type) {
+			case *common.ClassConstructor:
+				classDef = ref.Definition
+			case *ClassDefinition:
+				classDef = ref
+			default:
+				return nil, ThrowTypeError((*Env)(callEnv), "class reference", args[1])
+			}
+			return fromJsonValue((*Env)(callEnv), args[0], classDef)
+		})
+	_, err := fromJson.Build(env)
+	return err
+}
+
+// serializeValue converts a value into a tree of Map, Array and primitive instances.
+// Classes may opt in to a custom representation by defining __serialize__().
+// The seen set guards against cyclic object graphs.
+func serializeValue(env *Env, value any, seen map[any]bool) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string, int, int64, float64, bool:
+		return ConvertToClassInstance(env, v), nil
+	case []any:
+		return serializeSlice(env, v, seen)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]any, len(keys))
+		for i, k := range keys {
+			values[i] = v[k]
+		}
+		return serializeEntries(env, keys, values, seen)
+	case *common.EnumValueInstance:
+		return CreateStringInstance(env, v.Name)
+	case *common.RecordInstance:
+		if seen[v] {
+			return nil, ThrowValueError(env, fmt.Sprintf("cannot serialize cyclic reference to %s", v.Definition.Name))
+		}
+		seen[v] = true
+		defer delete(seen, v)
+
+		keys := make([]string, len(v.Definition.Components))
+		values := make([]any, len(v.Definition.Components))
+		for i, component := range v.Definition.Components {
+			keys[i] = component.Name
+			values[i] = v.Values[component.Name]
+		}
+		return serializeEntries(env, keys, values, seen)
+	case *ClassInstance:
+		return serializeInstance(env, v, seen)
+	}
+
+	if _, ok := common.ExtractFunc(value); ok {
+		return nil, ThrowTypeError(env, "serializable value", value)
+	}
+	return ConvertToClassInstance(env, value), nil
+}
+
+func serializeInstance(env *Env, inst *ClassInstance, seen map[any]bool) (any, error) {
+	switch inst.ClassName {
+	case "String", "Integer", "Int", "Float", "Bool", "Bytes":
+		return inst, nil
+	}
+
+	if seen[inst] {
+		return nil, ThrowValueError(env, fmt.Sprintf("cannot serialize cyclic reference to %s", inst.ClassName))
+	}
+	seen[inst] = true
+	defer delete(seen, inst)
+
+	if inst.ParentClass != nil {
+		if method := common.SelectMethodOverload(inst.ParentClass.GetMethods("__serialize__"), 0); method != nil {
+			result, err := CallInstanceMethod(inst, *method, env, []any{})
+			if err != nil {
+				return nil, err
+			}
+			return serializeValue(env, result, seen)
+		}
+	}
+
+	switch inst.ClassName {
+	case "Array":
+		return serializeSlice(env, inst.Fields["_items"].([]any), seen)
+	case "List", "Deque":
+		if itemsPtr, ok := inst.Fields["_items"].(*[]any); ok {
+			return serializeSlice(env, *itemsPtr, seen)
+		}
+	case "Set":
+		if keysPtr, ok := inst.Fields["_keys"].(*[]any); ok {
+			return serializeSlice(env, *keysPtr, seen)
+		}
+	case "Tuple":
+		if elements, ok := inst.Fields["_elements"].([]any); ok {
+			return serializeSlice(env, elements, seen)
+		}
+	case "Map":
+		if entries, ok := inst.Fields["_entries"].([]*mapEntry); ok {
+			keys := make([]string, len(entries))
+			values := make([]any, len(entries))
+			for i, entry := range entries {
+				keys[i] = utils.ToString(entry.Key)
+				values[i] = entry.Value
+			}
+			return serializeEntries(env, keys, values, seen)
+		}
+	}
+
+	// Plain object: emit its public, non-function fields in declaration-stable order
+	var keys []string
+	var values []any
+	for _, name := range serializableFieldNames(inst) {
+		fieldValue := inst.Fields[name]
+		if _, isFunc := common.ExtractFunc(fieldValue); isFunc {
+			continue
+		}
+		keys = append(keys, name)
+		values = append(values, fieldValue)
+	}
+	return serializeEntries(env, keys, values, seen)
+}
+
+// serializableFieldNames returns the public instance fields of inst, parents first,
+// with each class's fields sorted by name.
+func serializableFieldNames(inst *ClassInstance) []string {
+	var chain []*ClassDefinition
+	for def := inst.ParentClass; def != nil; def = def.Parent {
+		chain = append([]*ClassDefinition{def}, chain...)
+	}
+
+	var names []string
+	declared := make(map[string]bool)
+	for _, def := range chain {
+		classFields := make([]string, 0, len(def.Fields))
+		for name, info := range def.Fields {
+			declared[name] = true
+			if info.IsStatic || info.IsPrivate {
+				continue
+			}
+			classFields = append(classFields, name)
+		}
+		sort.Strings(classFields)
+		names = append(names, classFields...)
+	}
+
+	// Fields assigned dynamically (not declared on the class)
+	var extra []string
+	for name := range inst.Fields {
+		if !declared[name] && len(name) > 0 && name[0] != '_' {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return append(names, extra...)
+}
+
+func serializeSlice(env *Env, items []any, seen map[any]bool) (any, error) {
+	result := make([]any, len(items))
+	for i, item := range items {
+		converted, err := serializeValue(env, item, seen)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = converted
+	}
+	return CreateArrayInstance(env, result)
+}
+
+// serializeEntries builds a Map instance whose iteration order follows keys
+func serializeEntries(env *Env, keys []string, values []any, seen map[any]bool) (any, error) {
+	mapInstance, err := CreateMapInstance(env, map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+	data := mapInstance.Fields["_data"].(map[uint64][]*mapEntry)
+	entries := mapInstance.Fields["_entries"].([]*mapEntry)
+
+	for i, key := range keys {
+		converted, err := serializeValue(env, values[i], seen)
+		if err != nil {
+			return nil, err
+		}
+		keyInstance := ConvertMapKey(env, key)
+		entry := &mapEntry{Key: keyInstance, Value: converted}
+		hash := hashValue(env, keyInstance)
+		data[hash] = append(data[hash], entry)
+		entries = append(entries, entry)
+	}
+
+	mapInstance.Fields["_entries"] = entries
+	return mapInstance, nil
+}
+
+// fromJsonValue builds an instance of classDef from a Map instance, a Go map or a JSON object string.
+// Fields are matched by name and validated against their declared types; fields whose declared type
+// is a known class are populated recursively.
+func fromJsonValue(env *Env, source any, classDef *ClassDefinition) (any, error) {
+	if str, ok := extractPrimitiveValue(source).(string); ok {
+		decoder := json.NewDecoder(strings.NewReader(str))
+		decoder.UseNumber()
+		var data any
+		if err := decoder.Decode(&data); err != nil {
+			return nil, ThrowValueError(env, fmt.Sprintf("invalid JSON for %s: %v", classDef.Name, err))
+		}
+		source = normalizeJSONValue(data)
+	}
+
+	fields, ok := jsonObjectFields(source)
+	if !ok {
+		return nil, ThrowTypeError(env, "Map or JSON object string", source)
+	}
+	if classDef.IsAbstract {
+		return nil, ThrowTypeError(env, "concrete class", fmt.Sprintf("abstract class '%s'", classDef.Name))
+	}
+
+	// Allocate the instance without running a constructor; the JSON data supplies the field values
+	instance := &ClassInstance{
+		ClassName:   classDef.Name,
+		Fields:      make(map[string]any),
+		Methods:     make(map[string]Func),
+		ParentClass: classDef,
+	}
+	if err := initializeFields(instance, classDef); err != nil {
+		return nil, err
+	}
+	if err := bindMethods(instance, classDef, env); err != nil {
+		return nil, err
+	}
+
+	for def := classDef; def != nil; def = def.Parent {
+		for name, info := range def.Fields {
+			if info.IsStatic {
+				continue
+			}
+			raw, present := fields[name]
+			if !present {
+				continue
+			}
+			value, err := fromJsonField(env, raw, info.Type)
+			if err != nil {
+				return nil, err
+			}
+			if typeName := ast.GetTypeNameString(info.Type); typeName != "" && value != nil {
+				if err := ValidateArgumentType(value, typeName); err != nil {
+					return nil, ThrowTypeError(env, fmt.Sprintf("%s for field '%s.%s'", typeName, classDef.Name, name), GetTypeName(value))
+				}
+			}
+			instance.Fields[name] = value
+		}
+	}
+
+	return instance, nil
+}
+
+// fromJsonField converts a decoded JSON value to the runtime value stored in a field of fieldType
+func fromJsonField(env *Env, raw any, fieldType *ast.Type) (any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if fieldType != nil {
+		if nestedDef, ok := lookupClass(fieldType.Name, env.GetPackageName()); ok && !fieldType.IsBuiltin {
+			if _, isObject := jsonObjectFields(raw); isObject {
+				return fromJsonValue(env, raw, nestedDef)
+			}
+		}
+		switch fieldType.Name {
+		case "Float", "float", "Double", "double":
+			if i, ok := extractPrimitiveValue(raw).(int); ok {
+				return CreateFloatInstance(env, float64(i))
+			}
+		case "Array", "array", "List":
+			if len(fieldType.TypeParams) == 1 {
+				if items, ok := jsonArrayItems(raw); ok {
+					converted := make([]any, len(items))
+					for i, item := range items {
+						value, err := fromJsonField(env, item, fieldType.TypeParams[0])
+						if err != nil {
+							return nil, err
+						}
+						converted[i] = value
+					}
+					return CreateArrayInstance(env, converted)
+				}
+			}
+		}
+	}
+	return ConvertToClassInstance(env, raw), nil
+}
+
+// jsonObjectFields exposes the key/value pairs of a Map instance or Go map
+func jsonObjectFields(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true
+	case *ClassInstance:
+		if v.ClassName != "Map" {
+			return nil, false
+		}
+		data, ok := v.Fields["_data"].(map[uint64][]*mapEntry)
+		if !ok {
+			return nil, false
+		}
+		fields := make(map[string]any, len(data))
+		for _, bucket := range data {
+			for _, entry := range bucket {
+				fields[utils.ToString(entry.Key)] = entry.Value
+			}
+		}
+		return fields, true
+	}
+	return nil, false
+}
+
+// jsonArrayItems exposes the items of an Array instance or Go slice
+func jsonArrayItems(value any) ([]any, bool) {
+	switch v := value.(type) {
+	case []any:
+		return v, true
+	case *ClassInstance:
+		if items, ok := v.Fields["_items"].([]any); ok && v.ClassName == "Array" {
+			return items, true
+		}
+	}
+	return nil, false
+}
+
+// normalizeJSONValue replaces json.Number values with int or float64
+func normalizeJSONValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := strconv.Atoi(v.String()); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for k, item := range v {
+			v[k] = normalizeJSONValue(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeJSONValue(item)
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SnapshotDir is the directory, next to a test file, that holds its snapshots
+const SnapshotDir = "__snapshots__"
+
+// SnapshotPath returns the file storing the snapshots of testFile
+func SnapshotPath(testFile string) string {
+	return filepath.Join(filepath.Dir(testFile), SnapshotDir, filepath.Base(testFile)+".snap")
+}
+
+// snapshotStore holds the snapshots of one test file while its tests run.
+// Snapshots are keyed by test name and the number of the toMatchSnapshot call
+// within the test, so a test can take several.
+type snapshotStore struct {
+	path    string
+	update  bool // replace snapshots that do not match instead of failing
+	loaded  bool
+	dirty   bool
+	entries map[string]string
+	test    string         // name of the running test case, "" outside tests
+	counts  map[string]int // toMatchSnapshot calls so far, per test
+}
+
+func newSnapshotStore(path string, update bool) *snapshotStore {
+	return &snapshotStore{path: path, update: update, entries: make(map[string]string), counts: make(map[string]int)}
+}
+
+func (s *snapshotStore) load() error {
+	if s.loaded {
+		return nil
+	}
+	s.loaded = true
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return fmt.Errorf("invalid snapshot file %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// match compares value with its stored snapshot. A missing snapshot is
+// recorded, as is a different one in update mode; otherwise a mismatch throws
+// an AssertionError with a line diff.
+func (s *snapshotStore) match(env *Env, value any) error {
+	if s.test == "" {
+		return ThrowRuntimeError(env, "toMatchSnapshot() must be called from a test")
+	}
+	if err := s.load(); err != nil {
+		return ThrowIOError(env, err.Error())
+	}
+	got, err := renderSnapshot(env, value)
+	if err != nil {
+		return err
+	}
+
+	s.counts[s.test]++
+	key := fmt.Sprintf("%s %d", s.test, s.counts[s.test])
+	want, exists := s.entries[key]
+	if exists && want == got {
+		return nil
+	}
+	if !exists || s.update {
+		s.entries[key] = got
+		s.dirty = true
+		return nil
+	}
+	return ThrowAssertionError(env, fmt.Sprintf("snapshot %q does not match (- snapshot, + received):\n%s", key, lineDiff(want, got)))
+}
+
+// save writes the snapshots back when any were added or updated
+func (s *snapshotStore) save() error {
+	if !s.dirty {
+		return nil
+	}
+	// Snapshot files are read in review, so keys such as "group > test 1" are
+	// written as they are rather than HTML-escaped
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.entries); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data.Bytes(), 0644)
+}
+
+// renderSnapshot turns value into the text stored in a snapshot: Strings as
+// they are and everything else as indented JSON with Map keys in canonical order
+func renderSnapshot(env *Env, value any) (string, error) {
+	if s, ok := extractPrimitiveValue(value).(string); ok {
+		return s, nil
+	}
+	return writeJSON(env, value, "  ", true)
+}
+
+// lineDiff lists the lines of want and got, marking those only in want with
+// "- " and those only in got with "+ "
+func lineDiff(want, got string) string {
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
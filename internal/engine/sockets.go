@@ -2,8 +2,8 @@ package engine
 
 import (
 	"bufio"
-	"fmt"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/ArubikU/polyloft/internal/ast"
@@ -63,15 +63,21 @@ func InstallSocketsModule(env *Env, opts Options) error {
 			}
 		}
 
-		addr := fmt.Sprintf("%s:%d", host, port)
+		handle, err := acquireHandle((*Env)(callEnv))
+		if err != nil {
+			return false, err
+		}
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
 		conn, err := net.DialTimeout("tcp", addr, timeout)
 		if err != nil {
+			handle.release()
 			return false, nil
 		}
 
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
 		instance.Fields["_conn"] = conn
+		instance.Fields["_handle"] = handle
 		instance.Fields["_reader"] = bufio.NewReader(conn)
 		instance.Fields["connected"] = true
 		instance.Fields["remoteAddr"] = conn.RemoteAddr().String()
@@ -207,6 +213,7 @@ func InstallSocketsModule(env *Env, opts Options) error {
 		if ok && conn != nil {
 			conn.Close()
 		}
+		releaseHandleField(instance)
 		instance.Fields["_conn"] = nil
 		instance.Fields["_reader"] = nil
 		instance.Fields["connected"] = false
@@ -289,15 +296,21 @@ func InstallSocketsModule(env *Env, opts Options) error {
 			return false, ThrowTypeError((*Env)(callEnv), "int", args[1])
 		}
 
-		addr := fmt.Sprintf("%s:%d", host, port)
+		handle, err := acquireHandle((*Env)(callEnv))
+		if err != nil {
+			return false, err
+		}
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
 		listener, err := net.Listen("tcp", addr)
 		if err != nil {
+			handle.release()
 			return false, nil
 		}
 
 		thisVal, _ := callEnv.This()
 		instance := thisVal.(*ClassInstance)
 		instance.Fields["_listener"] = listener
+		instance.Fields["_handle"] = handle
 		instance.Fields["listening"] = true
 		instance.Fields["address"] = listener.Addr().String()
 		return true, nil
@@ -313,8 +326,13 @@ func InstallSocketsModule(env *Env, opts Options) error {
 			return nil, ThrowRuntimeError((*Env)(callEnv), "server socket not listening")
 		}
 
+		handle, err := acquireHandle((*Env)(callEnv))
+		if err != nil {
+			return nil, err
+		}
 		conn, err := listener.Accept()
 		if err != nil {
+			handle.release()
 			return nil, err
 		}
 
@@ -331,6 +349,7 @@ func InstallSocketsModule(env *Env, opts Options) error {
 			ParentClass: socketClassDef,
 		}
 		socketInst.Fields["_conn"] = conn
+		socketInst.Fields["_handle"] = handle
 		socketInst.Fields["_reader"] = bufio.NewReader(conn)
 		socketInst.Fields["connected"] = true
 		socketInst.Fields["remoteAddr"] = conn.RemoteAddr().String()
@@ -347,6 +366,7 @@ func InstallSocketsModule(env *Env, opts Options) error {
 		if ok && listener != nil {
 			listener.Close()
 		}
+		releaseHandleField(instance)
 		instance.Fields["_listener"] = nil
 		instance.Fields["listening"] = false
 		return nil, nil
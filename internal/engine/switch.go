@@ -4,6 +4,7 @@ import (
 	"reflect"
 
 	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
 )
 
 // evalSwitchStmt evaluates a switch statement
@@ -22,12 +23,15 @@ func evalSwitchStmt(env *Env, stmt *ast.SwitchStmt) (val any, returned bool, err
 		}
 	}
 
-	// Try each case in order
+	// Try each case in order; a case ending in `fallthrough` also runs the next body
+	fallingThrough := false
 	for _, c := range stmt.Cases {
-		matched := false
+		matched := fallingThrough
 
 		// Type matching case: case (varName: TypeName):
-		if c.TypeName != "" {
+		if matched {
+			// Reached through fallthrough; the case values are not tested
+		} else if c.TypeName != "" {
 			// Get the type of the switch value
 			typeName := GetTypeName(switchValue)
 
@@ -43,6 +47,19 @@ func evalSwitchStmt(env *Env, stmt *ast.SwitchStmt) (val any, returned bool, err
 		} else {
 			// Value matching case: case value1, value2:
 			for _, caseValue := range c.Values {
+				// Range case: case 1...10:
+				if rangeExpr, ok := caseValue.(*ast.RangeExpr); ok {
+					inRange, err := switchValueInRange(env, switchValue, rangeExpr)
+					if err != nil {
+						return nil, false, err
+					}
+					if inRange {
+						matched = true
+						break
+					}
+					continue
+				}
+
 				caseVal, err := evalExpr(env, caseValue)
 				if err != nil {
 					return nil, false, err
@@ -68,12 +85,16 @@ func evalSwitchStmt(env *Env, stmt *ast.SwitchStmt) (val any, returned bool, err
 			if ret {
 				return val, true, nil
 			}
-			// After executing a matching case, exit the switch (no fall-through)
+			if c.Fallthrough {
+				fallingThrough = true
+				continue
+			}
+			// After executing a matching case, exit the switch
 			return nil, false, nil
 		}
 	}
 
-	// If no case matched and there's a default case, execute it
+	// If no case matched (or the last case fell through) and there's a default case, execute it
 	if len(stmt.Default) > 0 {
 		_, _, ret, val, err := runBlock(env, stmt.Default)
 		if err != nil {
@@ -87,6 +108,32 @@ func evalSwitchStmt(env *Env, stmt *ast.SwitchStmt) (val any, returned bool, err
 	return nil, false, nil
 }
 
+// switchValueInRange reports whether value lies within the bounds of a range case,
+// both ends included. Non-numeric values never match.
+func switchValueInRange(env *Env, value any, rangeExpr *ast.RangeExpr) (bool, error) {
+	startVal, err := evalExpr(env, rangeExpr.Start)
+	if err != nil {
+		return false, err
+	}
+	endVal, err := evalExpr(env, rangeExpr.End)
+	if err != nil {
+		return false, err
+	}
+	start, ok1 := utils.AsFloat(startVal)
+	end, ok2 := utils.AsFloat(endVal)
+	if !ok1 || !ok2 {
+		return false, ThrowTypeError(env, "numeric range bounds", startVal)
+	}
+	if _, isString := value.(string); isString {
+		return false, nil
+	}
+	n, ok := utils.AsFloat(value)
+	if !ok {
+		return false, nil
+	}
+	return n >= start && n <= end, nil
+}
+
 // matchesTypeNameSwitch checks if a type name matches the expected type name
 // Handles case-insensitive matching for built-in types and their aliases
 func matchesTypeNameSwitch(actual, expected string) bool {
@@ -136,6 +183,11 @@ func valuesEqual(a, b any) bool {
 		return false
 	}
 
+	// Compare like ==, so boxed Int/String instances match equal literals
+	if equal(a, b) {
+		return true
+	}
+
 	// Use reflection for deep comparison
 	return reflect.DeepEqual(a, b)
 }
@@ -3,8 +3,13 @@ package engine
 import (
 	"fmt"
 	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"math/rand"
+	"runtime"
 	"strings"
 	"time"
 
@@ -110,6 +115,38 @@ func InstallSysModule(env *Env, opts Options) {
 		AddStaticMethod("exit", &ast.Type{Name: "void", IsBuiltin: true}, []ast.Parameter{{Name: "args", Type: nil, IsVariadic: true}}, Func(func(e *Env, args []any) (any, error) {
 			return nil, ThrowRuntimeError(e, fmt.Sprintf("exit: %v", args))
 		})).
+		// env(name: String) -> String? - an environment variable, nil when it is not set
+		AddStaticMethod("env", ast.ANY, []ast.Parameter{
+			{Name: "name", Type: common.BuiltinTypeString.GetTypeDefinition(env)},
+		}, sandboxGuard(opts, "Sys", "env", Func(func(e *Env, args []any) (any, error) {
+			value, ok := os.LookupEnv(utils.ToString(args[0]))
+			if !ok {
+				return nil, nil
+			}
+			return CreateStringInstance(e, value)
+		}))).
+		// setEnv(name: String, value: String) -> Void - set an environment variable for this process and the commands it runs
+		AddStaticMethod("setEnv", &ast.Type{Name: "void", IsBuiltin: true}, []ast.Parameter{
+			{Name: "name", Type: common.BuiltinTypeString.GetTypeDefinition(env)},
+			{Name: "value", Type: ast.ANY},
+		}, sandboxGuard(opts, "Sys", "setEnv", Func(func(e *Env, args []any) (any, error) {
+			if err := os.Setenv(utils.ToString(args[0]), utils.ToString(args[1])); err != nil {
+				return nil, ThrowValueError(e, fmt.Sprintf("cannot set environment variable '%s': %v", utils.ToString(args[0]), err))
+			}
+			return nil, nil
+		}))).
+		// args() -> Array - the command-line arguments that followed the script path
+		AddStaticMethod("args", common.BuiltinTypeArray.GetTypeDefinition(env), []ast.Parameter{}, Func(func(e *Env, _ []any) (any, error) {
+			items := make([]any, len(opts.Args))
+			for i, arg := range opts.Args {
+				str, err := CreateStringInstance(e, arg)
+				if err != nil {
+					return nil, err
+				}
+				items[i] = str
+			}
+			return CreateArrayInstance(e, items)
+		})).
 		AddStaticMethod("input", ast.ANY, []ast.Parameter{{Name: "args", Type: nil, IsVariadic: true}}, Func(func(e *Env, args []any) (any, error) {
 			var prompt string
 			var defaultVal any
@@ -229,10 +266,166 @@ func InstallSysModule(env *Env, opts Options) {
 			// Try to extract string from arg (handles both native string and ClassInstance)
 			typeName := utils.ToString(args[1])
 			return IsInstanceOf(obj, typeName), nil
-		}))
+		})).
+		// Platform the interpreter runs on, e.g. "linux/amd64"
+		AddStaticField("platform", runtime.GOOS+"/"+runtime.GOARCH).
+		AddStaticField("os", runtime.GOOS).
+		AddStaticField("arch", runtime.GOARCH).
+		AddStaticMethod("cpuCount", intType, []ast.Parameter{}, Func(func(_ *Env, _ []any) (any, error) {
+			return runtime.NumCPU(), nil
+		})).
+		// memStats() -> Map of heap and GC statistics, sizes in bytes
+		AddStaticMethod("memStats", common.BuiltinTypeMap.GetTypeDefinition(env), []ast.Parameter{}, Func(func(e *Env, _ []any) (any, error) {
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			stats, err := CreateMapInstance(e, map[string]any{})
+			if err != nil {
+				return nil, err
+			}
+			for _, stat := range []struct {
+				name  string
+				value uint64
+			}{
+				{"heapAlloc", m.HeapAlloc},
+				{"heapSys", m.HeapSys},
+				{"heapObjects", m.HeapObjects},
+				{"totalAlloc", m.TotalAlloc},
+				{"sys", m.Sys},
+				{"numGC", uint64(m.NumGC)},
+			} {
+				putMapEntry(e, stats, stat.name, int(stat.value))
+			}
+			return stats, nil
+		})).
+		AddStaticMethod("gc", &ast.Type{Name: "void", IsBuiltin: true}, []ast.Parameter{}, Func(func(_ *Env, _ []any) (any, error) {
+			runtime.GC()
+			return nil, nil
+		})).
+		// onSignal(name: String, handler: Function) -> Void - calls handler(name) each time the signal arrives
+		AddStaticMethod("onSignal", &ast.Type{Name: "void", IsBuiltin: true}, []ast.Parameter{
+			{Name: "name", Type: common.BuiltinTypeString.GetTypeDefinition(env)},
+			{Name: "handler", Type: ast.ANY},
+		}, sandboxGuard(opts, "Sys", "onSignal", Func(func(e *Env, args []any) (any, error) {
+			return nil, onSignal(e, utils.ToString(args[0]), args[1])
+		}))).
+		// offSignal(name: String) -> Void - removes the handlers and restores the default behavior
+		AddStaticMethod("offSignal", &ast.Type{Name: "void", IsBuiltin: true}, []ast.Parameter{
+			{Name: "name", Type: common.BuiltinTypeString.GetTypeDefinition(env)},
+		}, sandboxGuard(opts, "Sys", "offSignal", Func(func(e *Env, args []any) (any, error) {
+			return nil, offSignal(e, utils.ToString(args[0]))
+		})))
 
 	_, err := sysClass.BuildStatic(env)
 	if err != nil {
 		panic(err)
 	}
 }
+
+// supportedSignals are the signals Sys.onSignal can watch, by name
+var supportedSignals = map[string]os.Signal{
+	"SIGINT":  os.Interrupt,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+}
+
+var (
+	signalMu       sync.Mutex // guards signalWatches
+	signalWatches  = map[string]*signalWatch{}
+	signalDispatch sync.Mutex // handlers run one at a time, whichever signal triggered them
+)
+
+// signalWatch relays one signal to the handlers registered for it
+type signalWatch struct {
+	ch       chan os.Signal
+	handlers []func()
+}
+
+// run calls the handlers each time the signal arrives, until the channel is closed
+func (w *signalWatch) run() {
+	for range w.ch {
+		signalMu.Lock()
+		handlers := append([]func(){}, w.handlers...)
+		signalMu.Unlock()
+
+		signalDispatch.Lock()
+		for _, handler := range handlers {
+			handler()
+		}
+		signalDispatch.Unlock()
+	}
+}
+
+// lookupSignal accepts "SIGINT", "sigint" or "INT"
+func lookupSignal(env *Env, name string) (string, os.Signal, error) {
+	key := strings.ToUpper(name)
+	if !strings.HasPrefix(key, "SIG") {
+		key = "SIG" + key
+	}
+	sig, ok := supportedSignals[key]
+	if !ok {
+		return "", nil, ThrowValueError(env, fmt.Sprintf("unsupported signal '%s' (expected SIGINT, SIGTERM, SIGHUP or SIGQUIT)", name))
+	}
+	return key, sig, nil
+}
+
+// onSignal registers handler for the signal. Handlers run on a separate goroutine,
+// so an exception they raise cannot reach the script; it is reported on stderr instead.
+func onSignal(env *Env, name string, handler any) error {
+	key, sig, err := lookupSignal(env, name)
+	if err != nil {
+		return err
+	}
+	fn, ok := common.ExtractFunc(handler)
+	if !ok {
+		return ThrowTypeError(env, "function", handler)
+	}
+	// The handler receives the signal name unless it takes no parameters
+	var args []any
+	if params, hasParams := functionValueParams(handler); !hasParams || len(params) > 0 {
+		args = []any{key}
+	}
+	// The handler may run after the call that registered it has returned, and
+	// after the run as well
+	CaptureEnv(env)
+	env = spawnEnv(env)
+
+	call := func() {
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Fprintf(os.Stderr, "%s handler panicked: %v\n", key, r)
+			}
+		}()
+		if _, err := fn(env, args); err != nil {
+			fmt.Fprintf(os.Stderr, "%s handler failed: %v\n", key, err)
+		}
+	}
+
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	watch, ok := signalWatches[key]
+	if !ok {
+		watch = &signalWatch{ch: make(chan os.Signal, 1)}
+		signalWatches[key] = watch
+		signal.Notify(watch.ch, sig)
+		go watch.run()
+	}
+	watch.handlers = append(watch.handlers, call)
+	return nil
+}
+
+// offSignal removes every handler for the signal
+func offSignal(env *Env, name string) error {
+	key, _, err := lookupSignal(env, name)
+	if err != nil {
+		return err
+	}
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	if watch, ok := signalWatches[key]; ok {
+		signal.Stop(watch.ch)
+		close(watch.ch)
+		delete(signalWatches, key)
+	}
+	return nil
+}
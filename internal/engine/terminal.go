@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"os"
+	"regexp"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+	"golang.org/x/term"
+)
+
+// colorStyles maps each Terminal style method to the ANSI SGR code it applies
+var colorStyles = []struct {
+	name string
+	code string
+}{
+	{"bold", "1"},
+	{"dim", "2"},
+	{"italic", "3"},
+	{"underline", "4"},
+	{"black", "30"},
+	{"red", "31"},
+	{"green", "32"},
+	{"yellow", "33"},
+	{"blue", "34"},
+	{"magenta", "35"},
+	{"cyan", "36"},
+	{"white", "37"},
+	{"gray", "90"},
+}
+
+// ansiEscape matches the SGR sequences produced by the style methods
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// isTerminal reports whether out writes to a terminal
+func isTerminal(out any) bool {
+	f, ok := out.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// InstallTerminalModule installs the Terminal class for styling terminal output.
+// Colors are on only when the program's stdout is a terminal and NO_COLOR is not
+// set, so piped or redirected output stays plain text; Terminal.setColorEnabled
+// overrides the detection.
+func InstallTerminalModule(env *Env, opts Options) error {
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	boolType := common.BuiltinTypeBool.GetTypeDefinition(env)
+	voidType := &ast.Type{Name: "void", IsBuiltin: true}
+
+	tty := isTerminal(opts.Stdout)
+	_, noColor := os.LookupEnv("NO_COLOR")
+	enabled := tty && !noColor
+
+	terminalClass := NewClassBuilder("Terminal").
+		// isTTY() -> Bool - whether stdout is a terminal
+		AddStaticMethod("isTTY", boolType, []ast.Parameter{}, Func(func(e *Env, args []any) (any, error) {
+			return tty, nil
+		})).
+		// colorEnabled() -> Bool - whether the style methods emit ANSI codes
+		AddStaticMethod("colorEnabled", boolType, []ast.Parameter{}, Func(func(e *Env, args []any) (any, error) {
+			return enabled, nil
+		})).
+		// setColorEnabled(flag: Bool) - force colors on or off
+		AddStaticMethod("setColorEnabled", voidType, []ast.Parameter{
+			{Name: "flag", Type: boolType},
+		}, Func(func(e *Env, args []any) (any, error) {
+			enabled = utils.AsBool(args[0])
+			return nil, nil
+		})).
+		// strip(text: String) -> String - remove ANSI styling
+		AddStaticMethod("strip", stringType, []ast.Parameter{
+			{Name: "text", Type: stringType},
+		}, Func(func(e *Env, args []any) (any, error) {
+			return ansiEscape.ReplaceAllString(utils.ToString(args[0]), ""), nil
+		}))
+
+	// red(text), bold(text), ... -> String - text wrapped in the style, or unchanged when disabled
+	for _, style := range colorStyles {
+		code := style.code
+		terminalClass.AddStaticMethod(style.name, stringType, []ast.Parameter{
+			{Name: "text", Type: ast.ANY},
+		}, Func(func(e *Env, args []any) (any, error) {
+			text := utils.ToStringWithEnv(args[0], (*common.Env)(e))
+			if !enabled {
+				return text, nil
+			}
+			return "\x1b[" + code + "m" + text + "\x1b[0m", nil
+		}))
+	}
+
+	_, err := terminalClass.BuildStatic(env)
+	return err
+}
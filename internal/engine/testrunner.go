@@ -0,0 +1,377 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+)
+
+// TestConfig controls which test functions run
+type TestConfig struct {
+	Filter          string // only run tests whose name contains Filter
+	UpdateSnapshots bool   // rewrite snapshots that toMatchSnapshot finds out of date
+}
+
+// TestResult is the outcome of one test function
+type TestResult struct {
+	Name     string
+	Groups   []string // describe() blocks around an it() test, outermost first
+	Err      error    // nil when the test passed
+	Duration time.Duration
+
+	spec bool // declared with it() rather than as a function
+}
+
+// Passed reports whether the test finished without an error
+func (r TestResult) Passed() bool {
+	return r.Err == nil
+}
+
+// FullName returns the name of the test prefixed with its groups, such as
+// "Stack > push > adds an item"
+func (r TestResult) FullName() string {
+	return specName(r.Groups, r.Name)
+}
+
+// testFunc is a top-level function, or the body of an it() test, that the
+// test runner calls
+type testFunc struct {
+	name   string
+	fn     common.Func
+	def    *ast.DefStmt // nil for it() tests
+	groups []string
+	spec   bool
+}
+
+// testCase is one call of a test function
+type testCase struct {
+	name string
+	args []any
+}
+
+// testSuite holds the functions of a test file by role
+type testSuite struct {
+	tests                 []testFunc
+	beforeAll, afterAll   []testFunc
+	beforeEach, afterEach []testFunc
+}
+
+// RunTests evaluates prog and then calls every top-level test function, in
+// declaration order, with no arguments. Test functions are those marked @Test
+// and those whose name starts with "test_". Functions marked @BeforeAll and
+// @AfterAll run once around the tests, and @BeforeEach and @AfterEach run
+// around each test. A test marked @Params(cases) runs once per case instead,
+// and each run is reported as its own test. Tests declared with it(), inside
+// describe() groups or not, run after the test functions in the order they
+// were declared. Snapshots taken by toMatchSnapshot are saved next to the file
+// once the tests finish.
+//
+// A test fails when it throws, typically an AssertionError from assert or
+// expect, or when one of its @BeforeEach or @AfterEach functions throws; the
+// remaining tests still run. A failing @BeforeAll skips the tests and a failing
+// @AfterAll is reported under its own name. The returned error is set when the
+// file itself fails to run or a test exceeds the run's limits.
+func RunTests(prog *ast.Program, opts Options, fileName, packageName, source string, cfg TestConfig) ([]TestResult, error) {
+	env := newProgramEnv(opts, fileName, packageName, source)
+	// Threads the file starts may read the hooks, so they are complete before
+	// they are stored
+	specs := &specCollector{}
+	snapshots := newSnapshotStore(SnapshotPath(fileName), cfg.UpdateSnapshots)
+	hooks := newRunHooks(opts, fileName, prog)
+	hooks.specs = specs
+	hooks.snapshots = snapshots
+	storeHooks(env, hooks)
+	if _, err := runProgram(env, prog); err != nil {
+		return nil, err
+	}
+	specs.closed = true
+
+	suite := collectTests(env, prog, cfg)
+	for _, spec := range specs.specs {
+		if strings.Contains(specName(spec.groups, spec.name), cfg.Filter) {
+			suite.tests = append(suite.tests, spec)
+		}
+	}
+	if len(suite.tests) == 0 {
+		return nil, nil
+	}
+
+	var results []TestResult
+	// runLifecycle calls each hook until one fails, recording the failure
+	// under the hook's name
+	runLifecycle := func(lifecycle []testFunc) (bool, error) {
+		for _, hook := range lifecycle {
+			start := time.Now()
+			if _, err := hook.fn(env, []any{}); err != nil {
+				results = append(results, TestResult{Name: hook.name, Err: err, Duration: time.Since(start)})
+				return false, limitError(err)
+			}
+		}
+		return true, nil
+	}
+
+	ok, err := runLifecycle(suite.beforeAll)
+	if err != nil {
+		return results, err
+	}
+	if ok {
+		for _, test := range suite.tests {
+			cases, err := testCases(env, test)
+			if err != nil {
+				results = append(results, TestResult{Name: test.name, Groups: test.groups, Err: err, spec: test.spec})
+				if err := limitError(err); err != nil {
+					return results, err
+				}
+				continue
+			}
+			for _, tc := range cases {
+				snapshots.test = specName(test.groups, tc.name)
+				start := time.Now()
+				err := callHooks(env, suite.beforeEach)
+				if err == nil {
+					_, err = test.fn(env, tc.args)
+				}
+				// Tear down even after a failure, keeping the first error
+				if afterErr := callHooks(env, suite.afterEach); err == nil {
+					err = afterErr
+				}
+				snapshots.test = ""
+				results = append(results, TestResult{Name: tc.name, Groups: test.groups, Err: err, Duration: time.Since(start), spec: test.spec})
+				if err := limitError(err); err != nil {
+					return results, err
+				}
+			}
+		}
+	}
+	if _, err = runLifecycle(suite.afterAll); err != nil {
+		return results, err
+	}
+	if err := snapshots.save(); err != nil {
+		return results, fmt.Errorf("failed to save snapshots: %w", err)
+	}
+	return results, nil
+}
+
+// collectTests sorts the top-level functions of prog into tests and lifecycle
+// hooks. Only tests are subject to cfg.Filter.
+func collectTests(env *common.Env, prog *ast.Program, cfg TestConfig) testSuite {
+	var suite testSuite
+	for _, st := range prog.Stmts {
+		def, ok := st.(*ast.DefStmt)
+		if !ok {
+			continue
+		}
+		value, _ := env.Get(def.Name)
+		fn, ok := common.ExtractFunc(value)
+		if !ok {
+			continue
+		}
+		f := testFunc{name: def.Name, fn: fn, def: def}
+
+		switch {
+		case hasAnnotation(def.Annotations, "beforeall"):
+			suite.beforeAll = append(suite.beforeAll, f)
+		case hasAnnotation(def.Annotations, "afterall"):
+			suite.afterAll = append(suite.afterAll, f)
+		case hasAnnotation(def.Annotations, "beforeeach"):
+			suite.beforeEach = append(suite.beforeEach, f)
+		case hasAnnotation(def.Annotations, "aftereach"):
+			suite.afterEach = append(suite.afterEach, f)
+		case hasAnnotation(def.Annotations, "test") || strings.HasPrefix(def.Name, "test_"):
+			if strings.Contains(def.Name, cfg.Filter) {
+				suite.tests = append(suite.tests, f)
+			}
+		}
+	}
+	return suite
+}
+
+// testCases returns the calls to make of test: one without arguments, or one
+// per case of its @Params annotation. The annotation takes a collection of
+// cases, or a function returning one. A case is an array of positional
+// arguments, a map of arguments by parameter name, or a single argument.
+func testCases(env *common.Env, test testFunc) ([]testCase, error) {
+	if test.def == nil {
+		return []testCase{{name: test.name}}, nil
+	}
+	var params *ast.Annotation
+	for i := range test.def.Annotations {
+		if test.def.Annotations[i].Normalized == "params" {
+			params = &test.def.Annotations[i]
+		}
+	}
+	if params == nil {
+		return []testCase{{name: test.name}}, nil
+	}
+	// Errors in the cases point at the test's declaration
+	env.CurrentLine, env.CurrentColumn = test.def.Start.Line, test.def.Start.Col
+	if len(params.Args) != 1 {
+		return nil, ThrowArityError((*Env)(env), 1, len(params.Args))
+	}
+
+	value, err := evalExpr(env, params.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	if provider, ok := common.ExtractFunc(value); ok {
+		if value, err = provider(env, []any{}); err != nil {
+			return nil, err
+		}
+	}
+	items, ok := collectionItems(value)
+	if !ok {
+		return nil, ThrowTypeError((*Env)(env), "collection of test cases", value)
+	}
+
+	cases := make([]testCase, 0, len(items))
+	for i, item := range items {
+		var args []any
+		if positional, ok := collectionItems(item); ok {
+			args = positional
+		} else if entries, ok := mapEntries(item); ok {
+			if args, err = namedTestArgs(env, test, entries); err != nil {
+				return nil, err
+			}
+		} else {
+			args = []any{item}
+		}
+
+		shown := make([]string, len(args))
+		for j, arg := range args {
+			if _, omitted := arg.(omittedArg); omitted {
+				shown[j] = "_"
+				continue
+			}
+			shown[j] = describeValue(env, arg)
+		}
+		cases = append(cases, testCase{
+			name: fmt.Sprintf("%s[%d](%s)", test.name, i, strings.Join(shown, ", ")),
+			args: args,
+		})
+	}
+	return cases, nil
+}
+
+// namedTestArgs arranges a map case by the test's parameter names, leaving
+// parameters it skips to their defaults
+func namedTestArgs(env *common.Env, test testFunc, entries []*mapEntry) ([]any, error) {
+	args := make([]any, len(test.def.Params))
+	for i := range args {
+		args[i] = omittedArg{}
+	}
+	for _, entry := range entries {
+		name := StringValue(entry.Key)
+		index := -1
+		for i, param := range test.def.Params {
+			if param.Name == name && !param.IsVariadic {
+				index = i
+			}
+		}
+		if index < 0 {
+			return nil, ThrowRuntimeError((*Env)(env), fmt.Sprintf("unknown parameter '%s' in test case for %s", name, test.name))
+		}
+		args[index] = entry.Value
+	}
+	for i, param := range test.def.Params {
+		if _, omitted := args[i].(omittedArg); omitted && param.Default == nil && !param.IsVariadic {
+			return nil, ThrowRuntimeError((*Env)(env), fmt.Sprintf("missing argument for parameter '%s' in test case for %s", param.Name, test.name))
+		}
+	}
+	// Trailing omitted parameters are simply not passed
+	for len(args) > 0 {
+		if _, omitted := args[len(args)-1].(omittedArg); !omitted {
+			break
+		}
+		args = args[:len(args)-1]
+	}
+	return args, nil
+}
+
+// callHooks calls each hook in turn and returns the first error
+func callHooks(env *common.Env, hooks []testFunc) error {
+	for _, hook := range hooks {
+		if _, err := hook.fn(env, []any{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limitError returns err when it stops the whole run
+func limitError(err error) error {
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return err
+	}
+	return nil
+}
+
+func hasAnnotation(annotations []ast.Annotation, name string) bool {
+	for _, a := range annotations {
+		if a.Normalized == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatTestResults writes a line per failed test, with its error, and a
+// summary line for the file. Tests declared with it() are all listed, passed
+// or not, under their describe() groups with one level of indentation per
+// group.
+func FormatTestResults(w io.Writer, fileName string, results []TestResult) {
+	passed := 0
+	var shown []string // groups printed above the previous it() test
+	for _, r := range results {
+		if r.Passed() {
+			passed++
+		}
+		if !r.spec {
+			shown = nil
+			if !r.Passed() {
+				fmt.Fprintf(w, "--- FAIL: %s (%s)\n", r.Name, r.Duration.Round(time.Microsecond))
+				writeTestError(w, "    ", r.Err)
+			}
+			continue
+		}
+
+		// Print the headers of the groups not already open
+		same := 0
+		for same < len(shown) && same < len(r.Groups) && shown[same] == r.Groups[same] {
+			same++
+		}
+		for i := same; i < len(r.Groups); i++ {
+			fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", i), r.Groups[i])
+		}
+		shown = r.Groups
+
+		indent := strings.Repeat("  ", len(r.Groups))
+		if r.Passed() {
+			fmt.Fprintf(w, "%sok   %s\n", indent, r.Name)
+			continue
+		}
+		fmt.Fprintf(w, "%sFAIL %s (%s)\n", indent, r.Name, r.Duration.Round(time.Microsecond))
+		writeTestError(w, indent+"    ", r.Err)
+	}
+	status := "ok  "
+	if passed < len(results) {
+		status = "FAIL"
+	}
+	fmt.Fprintf(w, "%s %s: %d passed, %d failed\n", status, fileName, passed, len(results)-passed)
+}
+
+// writeTestError writes the error of a failed test with every line indented
+func writeTestError(w io.Writer, indent string, err error) {
+	for _, line := range strings.Split(strings.TrimRight(FormatErrorPlain(err), "\n"), "\n") {
+		if line == "" {
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s\n", indent, line)
+	}
+}
@@ -0,0 +1,309 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/common"
+	"github.com/ArubikU/polyloft/internal/engine/utils"
+)
+
+// timeFormatDirectives lists the strftime directives understood by Time.format
+const timeFormatDirectives = "%Y %y %m %d %e %H %I %M %S %L %p %b %B %a %A %j %z %Z %s %%"
+
+// InstallTimeModule installs the Time class. Instances wrap a Go time.Time in a
+// private field; Time.now(), Time.fromUnix() and the constructors create them.
+func InstallTimeModule(env *Env) error {
+	stringType := common.BuiltinTypeString.GetTypeDefinition(env)
+	intType := common.BuiltinTypeInt.GetTypeDefinition(env)
+	boolType := common.BuiltinTypeBool.GetTypeDefinition(env)
+	voidType := &ast.Type{Name: "void", IsBuiltin: true}
+
+	timeBuilder := NewClassBuilder("Time").
+		AddField("_time", ast.ANY, []string{"private"})
+	timeType := timeBuilder.GetType()
+
+	var timeClass *ClassDefinition
+	newTime := func(callEnv *common.Env, t time.Time) (any, error) {
+		instance, err := createClassInstance(timeClass, (*Env)(callEnv), []any{})
+		if err != nil {
+			return nil, err
+		}
+		instance.(*ClassInstance).Fields["_time"] = t
+		return instance, nil
+	}
+
+	// Constructor: Time() - the current local time
+	timeBuilder.AddBuiltinConstructor([]ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		thisVal, _ := callEnv.This()
+		thisVal.(*ClassInstance).Fields["_time"] = time.Now()
+		return nil, nil
+	})
+
+	// Constructor: Time(seconds: Int) - seconds since the Unix epoch
+	timeBuilder.AddBuiltinConstructor([]ast.Parameter{
+		{Name: "seconds", Type: intType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		seconds, ok := utils.AsInt(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "Int", args[0])
+		}
+		thisVal, _ := callEnv.This()
+		thisVal.(*ClassInstance).Fields["_time"] = time.Unix(int64(seconds), 0)
+		return nil, nil
+	})
+
+	// format(layout: String) -> String - strftime-style layout such as "%Y-%m-%d %H:%M:%S"
+	timeBuilder.AddBuiltinMethod("format", stringType, []ast.Parameter{
+		{Name: "layout", Type: stringType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		formatted, err := formatTime(timeOf(callEnv), utils.ToString(args[0]))
+		if err != nil {
+			return nil, ThrowValueError((*Env)(callEnv), err.Error())
+		}
+		return CreateStringInstance((*Env)(callEnv), formatted)
+	}, []string{})
+
+	// unix() -> Int - seconds since the Unix epoch
+	timeBuilder.AddBuiltinMethod("unix", intType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		return CreateIntInstance((*Env)(callEnv), int(timeOf(callEnv).Unix()))
+	}, []string{})
+
+	// unixMillis() -> Int - milliseconds since the Unix epoch
+	timeBuilder.AddBuiltinMethod("unixMillis", intType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		return CreateIntInstance((*Env)(callEnv), int(timeOf(callEnv).UnixMilli()))
+	}, []string{})
+
+	// Calendar fields; month() is 1-12 and weekday() is 0 (Sunday) to 6
+	fields := []struct {
+		name string
+		get  func(time.Time) int
+	}{
+		{"year", time.Time.Year},
+		{"month", func(t time.Time) int { return int(t.Month()) }},
+		{"day", time.Time.Day},
+		{"hour", time.Time.Hour},
+		{"minute", time.Time.Minute},
+		{"second", time.Time.Second},
+		{"millisecond", func(t time.Time) int { return t.Nanosecond() / int(time.Millisecond) }},
+		{"weekday", func(t time.Time) int { return int(t.Weekday()) }},
+	}
+	for _, field := range fields {
+		get := field.get
+		timeBuilder.AddBuiltinMethod(field.name, intType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+			return CreateIntInstance((*Env)(callEnv), get(timeOf(callEnv)))
+		}, []string{})
+	}
+
+	// addMillis(n), addSeconds(n), addMinutes(n), addHours(n) -> Time
+	units := []struct {
+		name string
+		unit time.Duration
+	}{
+		{"addMillis", time.Millisecond},
+		{"addSeconds", time.Second},
+		{"addMinutes", time.Minute},
+		{"addHours", time.Hour},
+	}
+	for _, u := range units {
+		unit := u.unit
+		timeBuilder.AddBuiltinMethod(u.name, timeType, []ast.Parameter{
+			{Name: "n", Type: intType},
+		}, func(callEnv *common.Env, args []any) (any, error) {
+			n, ok := utils.AsInt(args[0])
+			if !ok {
+				return nil, ThrowTypeError((*Env)(callEnv), "Int", args[0])
+			}
+			return newTime(callEnv, timeOf(callEnv).Add(time.Duration(n)*unit))
+		}, []string{})
+	}
+	// addDays keeps the wall-clock time across daylight saving changes
+	timeBuilder.AddBuiltinMethod("addDays", timeType, []ast.Parameter{
+		{Name: "n", Type: intType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		n, ok := utils.AsInt(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "Int", args[0])
+		}
+		return newTime(callEnv, timeOf(callEnv).AddDate(0, 0, n))
+	}, []string{})
+
+	// diffMillis(other: Time) -> Int - milliseconds from other to this time
+	timeBuilder.AddBuiltinMethod("diffMillis", intType, []ast.Parameter{
+		{Name: "other", Type: timeType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		other, ok := timeValue(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "Time", args[0])
+		}
+		return CreateIntInstance((*Env)(callEnv), int(timeOf(callEnv).Sub(other).Milliseconds()))
+	}, []string{})
+
+	// isBefore(other: Time) -> Bool
+	timeBuilder.AddBuiltinMethod("isBefore", boolType, []ast.Parameter{
+		{Name: "other", Type: timeType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		other, ok := timeValue(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "Time", args[0])
+		}
+		return CreateBoolInstance((*Env)(callEnv), timeOf(callEnv).Before(other))
+	}, []string{})
+
+	// isAfter(other: Time) -> Bool
+	timeBuilder.AddBuiltinMethod("isAfter", boolType, []ast.Parameter{
+		{Name: "other", Type: timeType},
+	}, func(callEnv *common.Env, args []any) (any, error) {
+		other, ok := timeValue(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "Time", args[0])
+		}
+		return CreateBoolInstance((*Env)(callEnv), timeOf(callEnv).After(other))
+	}, []string{})
+
+	// utc() -> Time - the same instant in UTC
+	timeBuilder.AddBuiltinMethod("utc", timeType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		return newTime(callEnv, timeOf(callEnv).UTC())
+	}, []string{})
+
+	// local() -> Time - the same instant in the local time zone
+	timeBuilder.AddBuiltinMethod("local", timeType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		return newTime(callEnv, timeOf(callEnv).Local())
+	}, []string{})
+
+	// toString() -> String - RFC 3339
+	timeBuilder.AddBuiltinMethod("toString", stringType, []ast.Parameter{}, func(callEnv *common.Env, args []any) (any, error) {
+		return CreateStringInstance((*Env)(callEnv), timeOf(callEnv).Format(time.RFC3339))
+	}, []string{})
+
+	// --- STATIC METHODS ---
+
+	// now() -> Time
+	timeBuilder.AddStaticMethod("now", timeType, []ast.Parameter{}, common.Func(func(callEnv *common.Env, args []any) (any, error) {
+		return newTime(callEnv, time.Now())
+	}))
+
+	// fromUnix(seconds: Int) -> Time
+	timeBuilder.AddStaticMethod("fromUnix", timeType, []ast.Parameter{
+		{Name: "seconds", Type: intType},
+	}, common.Func(func(callEnv *common.Env, args []any) (any, error) {
+		seconds, ok := utils.AsInt(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "Int", args[0])
+		}
+		return newTime(callEnv, time.Unix(int64(seconds), 0))
+	}))
+
+	// fromUnixMillis(millis: Int) -> Time
+	timeBuilder.AddStaticMethod("fromUnixMillis", timeType, []ast.Parameter{
+		{Name: "millis", Type: intType},
+	}, common.Func(func(callEnv *common.Env, args []any) (any, error) {
+		millis, ok := utils.AsInt(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "Int", args[0])
+		}
+		return newTime(callEnv, time.UnixMilli(int64(millis)))
+	}))
+
+	// sleep(ms: Int) -> Void
+	timeBuilder.AddStaticMethod("sleep", voidType, []ast.Parameter{
+		{Name: "ms", Type: intType},
+	}, common.Func(func(callEnv *common.Env, args []any) (any, error) {
+		ms, ok := utils.AsInt(args[0])
+		if !ok {
+			return nil, ThrowTypeError((*Env)(callEnv), "Int", args[0])
+		}
+		if ms < 0 {
+			return nil, ThrowValueError((*Env)(callEnv), "sleep duration cannot be negative")
+		}
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+		return nil, nil
+	}))
+
+	var err error
+	timeClass, err = timeBuilder.Build(env)
+	return err
+}
+
+// timeOf returns the time.Time of the Time instance bound to this
+func timeOf(env *common.Env) time.Time {
+	thisVal, _ := env.This()
+	t, _ := timeValue(thisVal)
+	return t
+}
+
+func timeValue(v any) (time.Time, bool) {
+	inst, ok := v.(*ClassInstance)
+	if !ok || inst.ClassName != "Time" {
+		return time.Time{}, false
+	}
+	t, ok := inst.Fields["_time"].(time.Time)
+	return t, ok
+}
+
+// formatTime renders t with a strftime-style layout
+func formatTime(t time.Time, layout string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			b.WriteByte(layout[i])
+			continue
+		}
+		if i+1 >= len(layout) {
+			return "", fmt.Errorf("invalid time layout %q: trailing '%%' (supported directives: %s)", layout, timeFormatDirectives)
+		}
+		i++
+		switch layout[i] {
+		case 'Y':
+			b.WriteString(strconv.Itoa(t.Year()))
+		case 'y':
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'e':
+			fmt.Fprintf(&b, "%2d", t.Day())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'I':
+			hour := t.Hour() % 12
+			if hour == 0 {
+				hour = 12
+			}
+			fmt.Fprintf(&b, "%02d", hour)
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case 'L':
+			fmt.Fprintf(&b, "%03d", t.Nanosecond()/int(time.Millisecond))
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'b':
+			b.WriteString(t.Format("Jan"))
+		case 'B':
+			b.WriteString(t.Month().String())
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'A':
+			b.WriteString(t.Weekday().String())
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 'z':
+			b.WriteString(t.Format("-0700"))
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case '%':
+			b.WriteByte('%')
+		default:
+			return "", fmt.Errorf("invalid time layout %q: unknown directive %%%c (supported directives: %s)", layout, layout[i], timeFormatDirectives)
+		}
+	}
+	return b.String(), nil
+}
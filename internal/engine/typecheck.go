@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/ArubikU/polyloft/internal/ast"
@@ -208,6 +209,27 @@ func matchesTypeName(baseName, typeName string) bool {
 	return false
 }
 
+// isCallableWithArity reports whether value is a function that accepts n arguments.
+// Builtin functions carry no parameter metadata and accept any count.
+func isCallableWithArity(value any, n int) bool {
+	var params []ast.Parameter
+	switch fn := value.(type) {
+	case *common.FunctionDefinition:
+		params = fn.Params
+	case *common.LambdaDefinition:
+		params = fn.Params
+	case common.Func, *common.ClassConstructor:
+		return true
+	default:
+		return false
+	}
+
+	if len(params) > 0 && params[len(params)-1].IsVariadic {
+		return n >= len(params)-1
+	}
+	return n == len(params)
+}
+
 // IsInstanceOf checks if a value is an instance of the given type name
 // This is the core type checking function used throughout the system
 // Supports: basic types, generic types (Array<Int>), union types (Int | String), wildcards (? extends Number)
@@ -218,6 +240,11 @@ func IsInstanceOf(value any, typeName string) bool {
 		return IsInstanceOf(value, resolvedType)
 	}
 
+	// Function types check that the value is callable with that many arguments
+	if params, _, ok := ast.SplitFunctionType(typeName); ok {
+		return isCallableWithArity(value, len(params))
+	}
+
 	// Parse the type name to check for generic parameters
 	if strings.Contains(typeName, "<") && strings.Contains(typeName, ">") {
 		return isInstanceOfGenericType(value, typeName)
@@ -228,6 +255,14 @@ func IsInstanceOf(value any, typeName string) bool {
 		return isInstanceOfUnionType(value, typeName)
 	}
 
+	// Interfaces, builtin ones like Iterable included, match any value whose
+	// class implements them directly or through a parent class
+	if iface, ok := interfaceRegistry[typeName]; ok {
+		if classDef := classOfValue(value); classDef != nil && classDef.ImplementsInterface(iface) {
+			return true
+		}
+	}
+
 	switch v := value.(type) {
 	case *common.ClassInstance:
 		return isClassInstanceOf(v, typeName)
@@ -255,17 +290,41 @@ func IsInstanceOf(value any, typeName string) bool {
 		return matchesTypeName("map", typeName)
 	case nil:
 		return ast.NIL.MatchesType(typeName)
-	case common.Func:
-		return matchesTypeName("function", typeName)
-	case *common.FunctionDefinition:
-		return matchesTypeName("function", typeName)
-	case *common.LambdaDefinition:
+	case common.Func, *common.FunctionDefinition, *common.LambdaDefinition:
+		if arity, ok := functionalInterfaceArity(typeName); ok && !matchesTypeName("function", typeName) {
+			return isCallableWithArity(v, arity)
+		}
 		return matchesTypeName("function", typeName)
 	default:
 		return false
 	}
 }
 
+// classOfValue returns the class of value, the builtin one for primitives,
+// or nil when value has none
+func classOfValue(value any) *ClassDefinition {
+	var name string
+	switch v := value.(type) {
+	case *common.ClassInstance:
+		return v.ParentClass
+	case int, int32, int64:
+		name = "Integer"
+	case float32, float64:
+		name = "Float"
+	case string:
+		name = "String"
+	case bool:
+		name = "Bool"
+	case []any:
+		name = "Array"
+	case map[string]any:
+		name = "Map"
+	default:
+		return nativeClassFor(value)
+	}
+	return builtinClasses[name]
+}
+
 // isInstanceOfUnionType checks if a value matches any type in a union type
 func isInstanceOfUnionType(value any, typeName string) bool {
 	// Split by | and trim spaces
@@ -324,12 +383,16 @@ func isInstanceOfGenericType(value any, typeName string) bool {
 			typeArgs := make([]string, 0, len(v.GenericTypes))
 			for _, gt := range v.GenericTypes {
 				if len(gt.Bounds) > 0 {
-					typeArgs = append(typeArgs, gt.Bounds[0].Name.Name)
+					typeArgs = append(typeArgs, genericArgName(gt.Bounds[0]))
 				}
 			}
 			if len(typeArgs) > 0 && normalizeTypeName(typeArgs[0]) != "any" {
-				// Check if the stored type arguments are compatible with the requested type
-				return areTypeArgsCompatible(typeArgs, typeParams)
+				// Check the stored type arguments against the requested ones, honoring declared variance
+				var variances []string
+				if v.ParentClass != nil && v.ParentClass.Name == baseName {
+					variances = declaredVariances(v.ParentClass)
+				}
+				return areTypeArgsAssignable(typeArgs, typeParams, variances)
 			}
 		}
 		// If no type args stored or type args is Any, check the elements directly
@@ -370,6 +433,9 @@ func isInstanceOfGenericType(value any, typeName string) bool {
 		return true
 	case *common.FunctionDefinition:
 		// Function<T1, T2, ..., TRet>
+		if arity, ok := functionalInterfaceArity(baseName); ok && !matchesTypeName("function", baseName) {
+			return isCallableWithArity(v, arity)
+		}
 		if !matchesTypeName("function", baseName) {
 			return false
 		}
@@ -390,6 +456,9 @@ func isInstanceOfGenericType(value any, typeName string) bool {
 		return true
 	case *common.LambdaDefinition:
 		// Lambda<T1, T2, ..., TRet>
+		if arity, ok := functionalInterfaceArity(baseName); ok && !matchesTypeName("function", baseName) {
+			return isCallableWithArity(v, arity)
+		}
 		if !matchesTypeName("function", baseName) {
 			return false
 		}
@@ -596,41 +665,68 @@ func isSupertypeOf(value any, typeName string) bool {
 	return IsInstanceOf(value, typeName)
 }
 
-// areTypeArgsCompatible checks if stored type arguments are compatible with requested types
+// checkAssignable throws a TypeError when value cannot be stored in a variable declared
+// with typeName; nil is assignable to every declared type
+func checkAssignable(env *Env, typeName string, value any) error {
+	if err := checkTypeAliasArity(env, typeName); err != nil {
+		return err
+	}
+	if value == nil || IsInstanceOf(value, typeName) {
+		return nil
+	}
+	return ThrowTypeError(env, typeName, value)
+}
+
+// areTypeArgsCompatible checks if stored type arguments are compatible with requested types,
+// treating every type parameter as covariant
 func areTypeArgsCompatible(storedArgs []string, requestedTypes []string) bool {
+	return areTypeArgsAssignable(storedArgs, requestedTypes, nil)
+}
+
+// areTypeArgsAssignable checks stored type arguments against requested ones following
+// the declared variance of each type parameter: "out" (covariant) accepts subtypes,
+// "in" (contravariant) accepts supertypes and "" (invariant) requires the same type.
+// Positions without a declared variance are covariant when variances is nil.
+// Use-site variance in the requested type ("out T", "? extends T", "in T", "? super T")
+// overrides the declared variance.
+func areTypeArgsAssignable(storedArgs []string, requestedTypes []string, variances []string) bool {
 	if len(storedArgs) != len(requestedTypes) {
 		return false
 	}
 
 	for i, requested := range requestedTypes {
 		stored := storedArgs[i]
+		requested = strings.TrimSpace(requested)
 
-		// Any matches everything (both directions)
-		if normalizeTypeName(stored) == "any" || normalizeTypeName(requested) == "any" {
+		// Any matches everything (both directions), and unresolved type parameters are not checked
+		if normalizeTypeName(stored) == "any" || normalizeTypeName(requested) == "any" || isGenericTypeParameter(requested) {
 			continue
 		}
 
-		// Handle wildcards
-		if strings.HasPrefix(requested, "?") {
-			if requested == "?" || requested == "? extends Any" {
-				continue // Unbounded wildcard matches everything
-			}
-			if strings.HasPrefix(requested, "? extends ") {
-				boundType := strings.TrimSpace(requested[len("? extends "):])
-				// Check if stored type is a subtype of bound
-				if !isTypeSubtypeOf(stored, boundType) {
-					return false
-				}
-			}
-			continue
+		variance := "out"
+		if variances != nil && i < len(variances) {
+			variance = variances[i]
+		}
+
+		// Use-site variance
+		switch {
+		case requested == "?" || requested == "? extends Any":
+			continue // Unbounded wildcard matches everything
+		case strings.HasPrefix(requested, "? extends "):
+			variance, requested = "out", strings.TrimSpace(requested[len("? extends "):])
+		case strings.HasPrefix(requested, "? super "):
+			variance, requested = "in", strings.TrimSpace(requested[len("? super "):])
+		case strings.HasPrefix(requested, "out "):
+			variance, requested = "out", strings.TrimSpace(requested[len("out "):])
+		case strings.HasPrefix(requested, "in "):
+			variance, requested = "in", strings.TrimSpace(requested[len("in "):])
 		}
 
 		// Handle union types in requested
 		if strings.Contains(requested, "|") {
-			unionTypes := strings.Split(requested, "|")
 			matched := false
-			for _, unionType := range unionTypes {
-				if normalizeTypeName(stored) == normalizeTypeName(unionType) || isTypeSubtypeOf(stored, unionType) {
+			for _, unionType := range strings.Split(requested, "|") {
+				if isTypeArgAssignable(stored, strings.TrimSpace(unionType), variance) {
 					matched = true
 					break
 				}
@@ -641,8 +737,7 @@ func areTypeArgsCompatible(storedArgs []string, requestedTypes []string) bool {
 			continue
 		}
 
-		// Direct comparison or subtype check
-		if normalizeTypeName(stored) != normalizeTypeName(requested) && !isTypeSubtypeOf(stored, requested) {
+		if !isTypeArgAssignable(stored, requested, variance) {
 			return false
 		}
 	}
@@ -650,8 +745,92 @@ func areTypeArgsCompatible(storedArgs []string, requestedTypes []string) bool {
 	return true
 }
 
+// isTypeArgAssignable checks a single type argument under the given variance
+func isTypeArgAssignable(stored, requested, variance string) bool {
+	if normalizeTypeName(stored) == normalizeTypeName(requested) {
+		return true
+	}
+	// A captured wildcard only guarantees its bound: "? extends B" is known to be a
+	// subtype of B and "? super B" a supertype of B
+	if strings.HasPrefix(stored, "?") {
+		kind, bound := splitWildcard(stored)
+		switch {
+		case kind == "extends" && variance == "out":
+			return isTypeSubtypeOf(bound, requested)
+		case kind == "super" && variance == "in":
+			return isTypeSubtypeOf(requested, bound)
+		}
+		return false
+	}
+	switch variance {
+	case "out":
+		return isTypeSubtypeOf(stored, requested)
+	case "in":
+		return isTypeSubtypeOf(requested, stored)
+	default:
+		return false
+	}
+}
+
+// genericArgName renders a stored type argument, keeping the bound of a wildcard
+// (e.g., "Int", "?", "? extends Number")
+func genericArgName(b common.GenericBound) string {
+	if b.Name.Name != "?" {
+		return b.Name.Name
+	}
+	kind, bound := captureWildcard(b)
+	if bound == "" {
+		return "?"
+	}
+	return "? " + kind + " " + bound
+}
+
+// captureWildcard returns the kind ("extends", "super" or "unbounded") and the bound type
+// name of a wildcard type argument stored by a construction like List<? extends Number>()
+func captureWildcard(b common.GenericBound) (kind, bound string) {
+	kind = b.Variance
+	if kind == "implements" {
+		kind = "extends"
+	}
+	switch {
+	case b.Extends != nil:
+		bound = b.Extends.Name
+	case b.Implements != nil:
+		bound = b.Implements.Name
+	default:
+		kind = "unbounded"
+	}
+	return kind, bound
+}
+
+// splitWildcard splits "? extends B" or "? super B" into its kind and bound
+func splitWildcard(typeName string) (kind, bound string) {
+	fields := strings.Fields(typeName)
+	if len(fields) == 3 {
+		return fields[1], fields[2]
+	}
+	return "unbounded", ""
+}
+
+// declaredVariances returns the declared variance of each type parameter of classDef.
+// Builtin collections keep their covariant instanceof semantics, so they report nil.
+func declaredVariances(classDef *ClassDefinition) []string {
+	if classDef == nil || len(classDef.TypeParams) == 0 || builtinClasses[classDef.Name] == classDef {
+		return nil
+	}
+	variances := make([]string, len(classDef.TypeParams))
+	for i, tp := range classDef.TypeParams {
+		if len(tp.Bounds) > 0 {
+			variances[i] = tp.Bounds[0].Variance
+		}
+	}
+	return variances
+}
+
 // isTypeSubtypeOf checks if one type name is a subtype of another
 func isTypeSubtypeOf(subtype, supertype string) bool {
+	subName := strings.TrimSpace(subtype)
+	superName := strings.TrimSpace(supertype)
 	subtype = normalizeTypeName(subtype)
 	supertype = normalizeTypeName(supertype)
 
@@ -664,9 +843,33 @@ func isTypeSubtypeOf(subtype, supertype string) bool {
 		return subtype == "int" || subtype == "float" || subtype == "integer"
 	}
 
+	// User class hierarchy and implemented interfaces
+	subDef, ok := findClassByName(subName)
+	if !ok {
+		return false
+	}
+	if superDef, ok := findClassByName(superName); ok {
+		return subDef.IsSubclassOf(superDef)
+	}
+	if iface, ok := interfaceRegistry[superName]; ok {
+		return subDef.ImplementsInterface(iface)
+	}
 	return false
 }
 
+// findClassByName looks a class up by name in the builtin classes and every package
+func findClassByName(name string) (*ClassDefinition, bool) {
+	if classDef, ok := builtinClasses[name]; ok {
+		return classDef, true
+	}
+	for _, packageClasses := range classRegistry {
+		if classDef, ok := packageClasses[name]; ok {
+			return classDef, true
+		}
+	}
+	return nil, false
+}
+
 // normalizeTypeName converts type names to lowercase for comparison
 func normalizeTypeName(typeName string) string {
 	typeName = strings.TrimSpace(typeName)
@@ -783,15 +986,105 @@ func IsClassInstanceOfDefinition(instance *common.ClassInstance, class *common.C
 }
 
 // resolveTypeAlias resolves a type name to its base type if it's an alias
-// Returns the input typeName unchanged if it's not an alias
+// Returns the input typeName unchanged if it's not an alias. Generic aliases
+// substitute their type arguments (StringMap<Int> -> Map<String, Int>); used
+// without arguments they resolve to the raw base type.
 func resolveTypeAlias(typeName string) string {
-	// Try to find type alias in all packages
-	// First check current/default package
+	resolved, _ := resolveTypeAliasChain(typeName)
+	return resolved
+}
+
+// resolveTypeAliasChain resolves typeName transitively and returns the alias names
+// it went through. A cycle stops resolution at the alias that closes it, which is
+// reported by the last name of chain appearing earlier in it.
+func resolveTypeAliasChain(typeName string) (string, []string) {
+	var chain []string
+	seen := make(map[string]bool)
+	for {
+		name, args := splitGenericTypeName(typeName)
+		alias, exists := lookupTypeAlias(name)
+		if !exists {
+			return typeName, chain
+		}
+		chain = append(chain, name)
+		if seen[name] {
+			return typeName, chain
+		}
+		seen[name] = true
+
+		switch {
+		case len(alias.TypeParams) == 0 && args == nil:
+			typeName = alias.BaseType
+		case len(alias.TypeParams) > 0 && args == nil:
+			typeName, _ = splitGenericTypeName(alias.BaseType)
+		case len(args) == len(alias.TypeParams):
+			typeName = substituteTypeParams(alias.BaseType, alias.TypeParams, args)
+		default:
+			return typeName, chain
+		}
+	}
+}
+
+// typeAliasCycle returns the chain of alias names forming a cycle through name, or nil
+func typeAliasCycle(name string) []string {
+	_, chain := resolveTypeAliasChain(name)
+	if len(chain) < 2 {
+		return nil
+	}
+	last := chain[len(chain)-1]
+	for _, earlier := range chain[:len(chain)-1] {
+		if earlier == last {
+			return chain
+		}
+	}
+	return nil
+}
+
+// lookupTypeAlias finds an alias by name in any package
+func lookupTypeAlias(name string) (*TypeAlias, bool) {
 	for _, packageAliases := range typeAliasRegistry {
-		if alias, exists := packageAliases[typeName]; exists {
-			// Recursively resolve in case base type is also an alias
-			return resolveTypeAlias(alias.BaseType)
+		if alias, exists := packageAliases[name]; exists {
+			return alias, true
 		}
 	}
-	return typeName
+	return nil, false
+}
+
+// checkTypeAliasArity reports a type alias used with the wrong number of type arguments
+func checkTypeAliasArity(env *Env, typeName string) error {
+	name, args := splitGenericTypeName(typeName)
+	alias, exists := lookupTypeAlias(name)
+	if !exists || args == nil || len(args) == len(alias.TypeParams) {
+		return nil
+	}
+	if len(alias.TypeParams) == 0 {
+		return ThrowRuntimeError(env, fmt.Sprintf("type alias %s is not generic but was used with %d type arguments", name, len(args)))
+	}
+	return ThrowRuntimeError(env, fmt.Sprintf("type alias %s expects %d type arguments, got %d", name, len(alias.TypeParams), len(args)))
+}
+
+// splitGenericTypeName splits "Map<String, Int>" into "Map" and its type arguments;
+// args is nil when typeName has no type arguments
+func splitGenericTypeName(typeName string) (string, []string) {
+	open := strings.Index(typeName, "<")
+	if open == -1 || !strings.HasSuffix(typeName, ">") {
+		return strings.TrimSpace(typeName), nil
+	}
+	return strings.TrimSpace(typeName[:open]), parseTypeParameters(typeName[open+1 : len(typeName)-1])
+}
+
+var typeIdentPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// substituteTypeParams replaces whole-word occurrences of params in typeName with args
+func substituteTypeParams(typeName string, params, args []string) string {
+	bindings := make(map[string]string, len(params))
+	for i, param := range params {
+		bindings[param] = args[i]
+	}
+	return typeIdentPattern.ReplaceAllStringFunc(typeName, func(ident string) string {
+		if arg, ok := bindings[ident]; ok {
+			return arg
+		}
+		return ident
+	})
 }
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -15,6 +16,63 @@ const (
 	strFalse = "false"
 )
 
+// MaxToStringDepth caps how deeply nested values are rendered. Deeper values
+// are shown as a placeholder such as "[...]" instead of overflowing the stack.
+const MaxToStringDepth = 100
+
+// renderState tracks one rendering: the values currently being rendered and how
+// deeply they nest. A value reached again while it is still being rendered is
+// part of a cycle (an array containing itself) and is shown as a placeholder.
+type renderState struct {
+	active map[*common.ClassInstance]bool
+	depth  int
+}
+
+// RenderStateVar holds the renderState in the Env of a toString call. Rendering
+// goes through toString methods that call back into ToStringWithEnv with their
+// own Env, which finds the state of the rendering in progress there.
+const RenderStateVar = "__render_state__"
+
+// renderStateOf returns the rendering in progress in env, or starts a new one
+func renderStateOf(env *common.Env) *renderState {
+	if env != nil {
+		if state, ok := env.Get(RenderStateVar); ok {
+			if state, ok := state.(*renderState); ok {
+				return state
+			}
+		}
+	}
+	return &renderState{active: make(map[*common.ClassInstance]bool)}
+}
+
+// enter marks v as being rendered; it returns false when v is already being
+// rendered or the depth cap is reached.
+func (s *renderState) enter(v *common.ClassInstance) bool {
+	if s.active[v] || s.depth >= MaxToStringDepth {
+		return false
+	}
+	s.active[v] = true
+	s.depth++
+	return true
+}
+
+func (s *renderState) leave(v *common.ClassInstance) {
+	delete(s.active, v)
+	s.depth--
+}
+
+// renderPlaceholder is shown for cyclic or too deeply nested values
+func renderPlaceholder(v *common.ClassInstance) string {
+	switch v.ClassName {
+	case "Array", "List", "Deque":
+		return "[...]"
+	case "Map":
+		return "{...}"
+	default:
+		return v.ClassName + "(...)"
+	}
+}
+
 // ToString converts a value to its string representation.
 // This handles all Polyloft types including primitive wrappers, class instances, etc.
 // For better environment handling, use ToStringWithEnv when an environment is available.
@@ -79,16 +137,21 @@ func ToStringWithEnv(v any, env *common.Env) string {
 				return strFalse
 			}
 		}
+		state := renderStateOf(env)
+		if !state.enter(t) {
+			return renderPlaceholder(t)
+		}
+		defer state.leave(t)
 		// Try to call the toString method if it exists
 		if toStringMethod, exists := t.Methods["toString"]; exists {
 			// Create method environment with proper parent chain
 			var methodEnv *common.Env
 			if env != nil {
 				// Use provided environment as parent to maintain access to builtins
-				methodEnv = &common.Env{Parent: env, Vars: map[string]any{"this": t}, Consts: map[string]bool{}}
+				methodEnv = &common.Env{Parent: env, Vars: map[string]any{"this": t, RenderStateVar: state}, Consts: map[string]bool{}}
 			} else {
 				// Fallback: create isolated environment (may fail if toString needs builtins)
-				methodEnv = &common.Env{Vars: map[string]any{"this": t}, Consts: map[string]bool{}}
+				methodEnv = &common.Env{Vars: map[string]any{"this": t, RenderStateVar: state}, Consts: map[string]bool{}}
 			}
 			// toStringMethod is already a common.Func, no need to cast
 			if result, err := toStringMethod(methodEnv, []any{}); err == nil {
@@ -162,14 +225,18 @@ func ToStringWithEnv(v any, env *common.Env) string {
 				}
 			}
 		}
+		// Go maps have no order; sort the keys so the text is stable
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
 		s := "{"
-		i := 0
-		for k, e := range t {
+		for i, k := range keys {
 			if i > 0 {
 				s += ", "
 			}
-			s += k + ": " + ToString(e)
-			i++
+			s += k + ": " + ToString(t[k])
 		}
 		return s + "}"
 	default:
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -24,8 +25,14 @@ type Installer struct {
 	Config          *config.Config
 	LibDir          string
 	GlobalMode      bool
+	LockPath        string          // Where the lock file is read and written
+	Update          bool            // Ignore locked versions and resolve packages again
 	installed       map[string]bool // Track installed packages to avoid duplicates
 	dependencyChain []string        // Track dependency chain to detect cycles
+	lock            *LockFile
+	lockChanged     bool
+	requirements    map[string][]requirement // Version ranges asked for each package during this install
+	resolved        map[string]string        // Version installed for each package during this install
 }
 
 // New creates a new Installer with the given configuration
@@ -34,8 +41,11 @@ func New(cfg *config.Config) *Installer {
 		Config:          cfg,
 		LibDir:          "libs", // Default library directory
 		GlobalMode:      false,
+		LockPath:        LockFileName,
 		installed:       make(map[string]bool),
 		dependencyChain: []string{},
+		requirements:    make(map[string][]requirement),
+		resolved:        make(map[string]string),
 	}
 }
 
@@ -47,10 +57,106 @@ func (i *Installer) SetGlobalMode(global bool) {
 		homeDir, err := os.UserHomeDir()
 		if err == nil {
 			i.LibDir = filepath.Join(homeDir, ".polyloft", "libs")
+			i.LockPath = filepath.Join(homeDir, ".polyloft", LockFileName)
 		}
 	}
 }
 
+// SetUpdateMode makes the installer ignore the versions pinned in the lock file,
+// download registry packages again and record what they resolve to now
+func (i *Installer) SetUpdateMode(update bool) {
+	i.Update = update
+}
+
+// loadLock reads the lock file before an install
+func (i *Installer) loadLock() error {
+	lock, err := LoadLockFile(i.LockPath)
+	if err != nil {
+		return err
+	}
+	i.lock = lock
+	i.lockChanged = false
+	return nil
+}
+
+// saveLock writes the lock file when the install resolved something new
+func (i *Installer) saveLock() error {
+	if i.lock == nil || !i.lockChanged {
+		return nil
+	}
+	if err := i.lock.Save(i.LockPath); err != nil {
+		return err
+	}
+	i.lockChanged = false
+	return nil
+}
+
+// recordLock stores a resolved package in the lock
+func (i *Installer) recordLock(pkg LockedPackage) {
+	if i.lock == nil {
+		i.lock = &LockFile{}
+	}
+	if i.lock.Set(pkg) {
+		i.lockChanged = true
+	}
+}
+
+// recordExisting notes the version of a package that was already present in the
+// libs directory and locks it when the lock is missing it, with the version from
+// its polyloft.toml. Its checksum is filled in the next time it is downloaded.
+func (i *Installer) recordExisting(name, author, libPath string) {
+	if author == "" || i.lock == nil {
+		return
+	}
+	key := fmt.Sprintf("%s@%s", name, author)
+	version := packageVersion(libPath, "")
+	i.resolved[key] = version
+	if _, ok := i.lock.Get(key); ok {
+		return
+	}
+	i.recordLock(LockedPackage{Name: name, Author: author, Version: version})
+}
+
+// reuseExisting reports whether a package already in libPath is kept as is.
+// Registry packages are downloaded again in update mode, or when their version
+// is outside a range asked for them.
+func (i *Installer) reuseExisting(libPath, author string) bool {
+	if _, err := os.Stat(libPath); err != nil {
+		return false
+	}
+	if author == "" {
+		return true
+	}
+	key := fmt.Sprintf("%s@%s", filepath.Base(libPath), author)
+	return !i.Update && i.satisfiesRequirements(key, packageVersion(libPath, ""))
+}
+
+// needsReinstall reports whether a package installed earlier in this run has to
+// be replaced by version, because a later range ruled out the earlier pick
+func (i *Installer) needsReinstall(key, version string) bool {
+	installed, ok := i.resolved[key]
+	return ok && version != "" && installed != "" && installed != version
+}
+
+// packageVersion returns the version an installed package declares in its
+// polyloft.toml, or fallback when it declares none
+func packageVersion(libPath, fallback string) string {
+	cfg, err := config.Load(filepath.Join(libPath, "polyloft.toml"))
+	if err != nil || cfg.Project.Version == "" {
+		return fallback
+	}
+	return cfg.Project.Version
+}
+
+// failsInstall reports whether err comes from an archive that failed
+// verification against the lock or from conflicting version ranges; such errors
+// fail the install instead of being reported as warnings
+func failsInstall(err error) bool {
+	var checksumErr *ChecksumError
+	var conflictErr *ConflictError
+	return errors.As(err, &checksumErr) || errors.As(err, &conflictErr)
+}
+
 // Install downloads and installs all dependencies
 func (i *Installer) Install() error {
 	cyan := color.New(color.FgCyan).SprintFunc()
@@ -58,6 +164,10 @@ func (i *Installer) Install() error {
 	
 	fmt.Printf("\n%s Installing dependencies...\n", cyan("📦"))
 
+	if err := i.loadLock(); err != nil {
+		return err
+	}
+
 	// Install Go dependencies
 	if err := i.installGoDependencies(); err != nil {
 		return fmt.Errorf("failed to install Go dependencies: %w", err)
@@ -68,6 +178,10 @@ func (i *Installer) Install() error {
 		return fmt.Errorf("failed to install Polyloft dependencies: %w", err)
 	}
 
+	if err := i.saveLock(); err != nil {
+		return err
+	}
+
 	fmt.Printf("\n%s All dependencies installed successfully\n\n", green("✓"))
 	return nil
 }
@@ -80,6 +194,10 @@ func (i *Installer) InstallPackages(packages []string) error {
 	red := color.New(color.FgRed).SprintFunc()
 	
 	fmt.Printf("\n%s Installing %d package(s)...\n", cyan("📦"), len(packages))
+
+	if err := i.loadLock(); err != nil {
+		return err
+	}
 	
 	// Ensure libs directory exists
 	if err := os.MkdirAll(i.LibDir, 0755); err != nil {
@@ -114,11 +232,15 @@ func (i *Installer) InstallPackages(packages []string) error {
 		libPath := filepath.Join(i.LibDir, name)
 		
 		// Check if library already exists
-		if _, err := os.Stat(libPath); err == nil {
+		if i.reuseExisting(libPath, author) {
 			fmt.Printf("    %s %s already exists\n", green("✓"), pkg)
 			i.installed[packageKey] = true
+			i.recordExisting(name, author, libPath)
 			// Still check for transitive dependencies
 			if err := i.installTransitiveDependencies(libPath, packageKey); err != nil {
+				if failsInstall(err) {
+					return err
+				}
 				fmt.Printf("    %s Warning: Failed to install transitive dependencies: %v\n", yellow("⚠"), err)
 			}
 			continue
@@ -126,6 +248,9 @@ func (i *Installer) InstallPackages(packages []string) error {
 		
 		// Download from registry with spinner
 		if err := i.downloadPackageWithAnimation(name, author, "", libPath); err != nil {
+			if failsInstall(err) {
+				return err
+			}
 			fmt.Printf("    %s Failed to download %s: %v\n", red("✗"), packageKey, err)
 			continue
 		}
@@ -135,9 +260,16 @@ func (i *Installer) InstallPackages(packages []string) error {
 		
 		// Install transitive dependencies
 		if err := i.installTransitiveDependencies(libPath, packageKey); err != nil {
+			if failsInstall(err) {
+				return err
+			}
 			fmt.Printf("    %s Warning: Failed to install transitive dependencies: %v\n", yellow("⚠"), err)
 		}
 	}
+
+	if err := i.saveLock(); err != nil {
+		return err
+	}
 	
 	fmt.Printf("\n%s Package installation complete\n\n", green("✓"))
 	return nil
@@ -194,17 +326,23 @@ func (i *Installer) installTransitiveDependencies(packagePath, packageKey string
 		}
 		
 		transKey := fmt.Sprintf("%s@%s", name, author)
+
+		version, err := i.resolveVersion(name, author, dep.Version, packageKey)
+		if err != nil {
+			return err
+		}
 		
 		// Check if already installed
-		if i.installed[transKey] {
+		if i.installed[transKey] && !i.needsReinstall(transKey, version) {
 			continue
 		}
 		
 		libPath := filepath.Join(i.LibDir, name)
 		
 		// Check if library already exists
-		if _, err := os.Stat(libPath); err == nil {
+		if i.reuseExisting(libPath, author) {
 			i.installed[transKey] = true
+			i.recordExisting(name, author, libPath)
 			// Recursively check this package's dependencies
 			if err := i.installTransitiveDependencies(libPath, transKey); err != nil {
 				return err
@@ -213,7 +351,7 @@ func (i *Installer) installTransitiveDependencies(packagePath, packageKey string
 		}
 		
 		// Download the transitive dependency
-		if err := i.downloadPackageWithAnimation(name, author, dep.Version, libPath); err != nil {
+		if err := i.downloadPackageWithAnimation(name, author, version, libPath); err != nil {
 			return fmt.Errorf("failed to download transitive dependency %s: %w", transKey, err)
 		}
 		
@@ -321,14 +459,31 @@ func (i *Installer) installPfDependency(dep config.PfDependency) error {
 	packageKey := fmt.Sprintf("%s@%s", name, author)
 	
 	libPath := filepath.Join(i.LibDir, name)
+
+	requiredBy := i.Config.Project.Name
+	if requiredBy == "" {
+		requiredBy = "polyloft.toml"
+	}
+	version, err := i.resolveVersion(name, author, dep.Version, requiredBy)
+	if err != nil {
+		return err
+	}
 	
+	if i.installed[packageKey] && !i.needsReinstall(packageKey, version) {
+		return nil
+	}
+
 	// Check if library already exists
-	if _, err := os.Stat(libPath); err == nil {
+	if i.reuseExisting(libPath, author) {
 		if !i.installed[packageKey] {
 			fmt.Printf("    %s %s already exists\n", green("✓"), dep.Name)
 			i.installed[packageKey] = true
+			i.recordExisting(name, author, libPath)
 			// Check for transitive dependencies
 			if err := i.installTransitiveDependencies(libPath, packageKey); err != nil {
+				if failsInstall(err) {
+					return err
+				}
 				fmt.Printf("    %s Warning: %v\n", yellow("⚠"), err)
 			}
 		}
@@ -338,7 +493,10 @@ func (i *Installer) installPfDependency(dep config.PfDependency) error {
 	// Try to download from registry if author is specified
 	if author != "" {
 		fmt.Printf("    %s Downloading %s...\n", color.CyanString("→"), packageKey)
-		if err := i.downloadPackageWithAnimation(name, author, dep.Version, libPath); err != nil {
+		if err := i.downloadPackageWithAnimation(name, author, version, libPath); err != nil {
+			if failsInstall(err) {
+				return err
+			}
 			fmt.Printf("    %s Warning: Failed to download from registry: %v\n", yellow("⚠"), err)
 			return nil // Don't fail the install, just warn
 		}
@@ -347,6 +505,9 @@ func (i *Installer) installPfDependency(dep config.PfDependency) error {
 		
 		// Install transitive dependencies
 		if err := i.installTransitiveDependencies(libPath, packageKey); err != nil {
+			if failsInstall(err) {
+				return err
+			}
 			fmt.Printf("    %s Warning: %v\n", yellow("⚠"), err)
 		}
 		return nil
@@ -368,9 +529,23 @@ func (i *Installer) installPfDependency(dep config.PfDependency) error {
 	return nil
 }
 
-// downloadFromRegistry downloads a package from the Polyloft registry
+// downloadFromRegistry downloads a package from the Polyloft registry. Unless the
+// installer is in update mode, a package in the lock is downloaded at its locked
+// version and must match the locked checksum.
 func (i *Installer) downloadFromRegistry(name, author, version, destPath string) error {
 	registryURL := auth.GetRegistryURL()
+
+	key := fmt.Sprintf("%s@%s", name, author)
+	var locked LockedPackage
+	isLocked := false
+	if i.lock != nil && !i.Update {
+		// A version changed in polyloft.toml since the lock was written wins
+		locked, isLocked = i.lock.Get(key)
+		isLocked = isLocked && (version == "" || version == locked.Version)
+		if isLocked && locked.Version != "" {
+			version = locked.Version
+		}
+	}
 	
 	// Construct download URL
 	var downloadURL string
@@ -398,11 +573,29 @@ func (i *Installer) downloadFromRegistry(name, author, version, destPath string)
 		return fmt.Errorf("failed to read package data: %w", err)
 	}
 	
+	checksum := archiveChecksum(archiveData)
+	if isLocked && locked.Checksum != "" && locked.Checksum != checksum {
+		return &ChecksumError{Package: key, Expected: locked.Checksum, Got: checksum}
+	}
+
+	// Replace any previous copy, which update mode downloads again
+	if err := os.RemoveAll(destPath); err != nil {
+		return fmt.Errorf("failed to remove old package: %w", err)
+	}
+
 	// Extract archive
 	if err := i.extractArchive(archiveData, destPath); err != nil {
 		return fmt.Errorf("failed to extract package: %w", err)
 	}
-	
+
+	resolved := packageVersion(destPath, version)
+	i.resolved[key] = resolved
+	i.recordLock(LockedPackage{
+		Name:     name,
+		Author:   author,
+		Version:  resolved,
+		Checksum: checksum,
+	})
 	return nil
 }
 
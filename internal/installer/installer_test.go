@@ -1,6 +1,13 @@
 package installer
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -113,3 +120,188 @@ func TestInstallPackagesParseAuthor(t *testing.T) {
 		})
 	}
 }
+
+// packageArchive builds the tar.gz archive the registry serves for a package;
+// deps is appended to its polyloft.toml
+func packageArchive(t *testing.T, name, version, deps, source string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	files := map[string]string{
+		"polyloft.toml": "[project]\nname = \"" + name + "\"\nversion = \"" + version + "\"\nentry_point = \"main.pf\"\n" + deps,
+		"main.pf":       source,
+	}
+	for _, name := range []string{"polyloft.toml", "main.pf"} {
+		body := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInstallLockFile(t *testing.T) {
+	archive := packageArchive(t, "vec", "1.2.0", "", "let x = 1\n")
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.Write(archive)
+	}))
+	defer server.Close()
+	t.Setenv("POLYLOFT_REGISTRY_URL", server.URL)
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{Project: config.ProjectConfig{Name: "app", Version: "0.1.0", EntryPoint: "src/main.pf"}}
+	newInstaller := func() *Installer {
+		inst := New(cfg)
+		inst.LibDir = filepath.Join(tmpDir, "libs")
+		inst.LockPath = filepath.Join(tmpDir, LockFileName)
+		return inst
+	}
+
+	// The first install resolves the latest version and records it
+	if err := newInstaller().InstallPackages([]string{"vec@me"}); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	lock, err := LoadLockFile(filepath.Join(tmpDir, LockFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked, ok := lock.Get("vec@me")
+	if !ok || locked.Version != "1.2.0" || locked.Checksum != archiveChecksum(archive) {
+		t.Fatalf("unexpected lock entry %+v", locked)
+	}
+
+	// A fresh install downloads the locked version
+	os.RemoveAll(filepath.Join(tmpDir, "libs"))
+	if err := newInstaller().InstallPackages([]string{"vec@me"}); err != nil {
+		t.Fatalf("reinstall: %v", err)
+	}
+	if last := requested[len(requested)-1]; last != "/api/download/me/vec/1.2.0" {
+		t.Errorf("expected the locked version to be requested, got %s", last)
+	}
+
+	// A different archive under the same version fails verification
+	archive = packageArchive(t, "vec", "1.2.0", "", "let x = 2\n")
+	os.RemoveAll(filepath.Join(tmpDir, "libs"))
+	err = newInstaller().InstallPackages([]string{"vec@me"})
+	var checksumErr *ChecksumError
+	if !errors.As(err, &checksumErr) {
+		t.Fatalf("expected a checksum error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "libs", "vec")); !os.IsNotExist(err) {
+		t.Error("a package that failed verification should not be extracted")
+	}
+
+	// Update mode ignores the lock and records the new archive
+	inst := newInstaller()
+	inst.SetUpdateMode(true)
+	if err := inst.InstallPackages([]string{"vec@me"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	lock, _ = LoadLockFile(filepath.Join(tmpDir, LockFileName))
+	if locked, _ := lock.Get("vec@me"); locked.Checksum != archiveChecksum(archive) {
+		t.Errorf("expected the lock to be updated, got %+v", locked)
+	}
+}
+
+func TestLockFileSaveIsSorted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+	lock := &LockFile{}
+	lock.Set(LockedPackage{Name: "zeta", Author: "b", Version: "1.0.0", Checksum: "sha256:01"})
+	lock.Set(LockedPackage{Name: "alpha", Author: "b", Version: "2.0.0", Checksum: "sha256:02"})
+	lock.Set(LockedPackage{Name: "alpha", Author: "a", Version: "3.0.0", Checksum: "sha256:03"})
+	if err := lock.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	first, second, third := strings.Index(text, `version = "3.0.0"`), strings.Index(text, `version = "2.0.0"`), strings.Index(text, `version = "1.0.0"`)
+	if first < 0 || !(first < second && second < third) {
+		t.Errorf("expected packages sorted by name and author, got:\n%s", text)
+	}
+
+	loaded, err := LoadLockFile(path)
+	if err != nil || len(loaded.Packages) != 3 || loaded.Packages[0].Key() != "alpha@a" {
+		t.Errorf("round trip failed: %+v, %v", loaded, err)
+	}
+}
+
+func TestInstallResolvesVersionRanges(t *testing.T) {
+	published := map[string][]string{
+		"vec": {"1.0.0", "1.2.0", "1.2.5", "1.4.0", "2.0.0"},
+		"geo": {"1.0.0", "2.0.0"},
+	}
+	deps := map[string]string{
+		"geo/1.0.0": "[[dependencies.pf]]\nname = \"vec@me\"\nversion = \"~1.2.0\"\n",
+		"geo/2.0.0": "[[dependencies.pf]]\nname = \"vec@me\"\nversion = \"^2.0\"\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(parts) == 5 && parts[1] == "packages" && parts[4] == "versions":
+			json.NewEncoder(w).Encode(map[string][]string{"versions": published[parts[3]]})
+		case len(parts) == 5 && parts[1] == "download":
+			name, version := parts[3], parts[4]
+			w.Write(packageArchive(t, name, version, deps[name+"/"+version], ""))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("POLYLOFT_REGISTRY_URL", server.URL)
+
+	install := func(vecRange, geoVersion string) (*Installer, error) {
+		tmpDir := t.TempDir()
+		cfg := &config.Config{
+			Project: config.ProjectConfig{Name: "app", Version: "0.1.0", EntryPoint: "src/main.pf"},
+			Dependencies: config.DependenciesConfig{Pf: []config.PfDependency{
+				{Name: "vec@me", Version: vecRange},
+				{Name: "geo@me", Version: geoVersion},
+			}},
+		}
+		inst := New(cfg)
+		inst.LibDir = filepath.Join(tmpDir, "libs")
+		inst.LockPath = filepath.Join(tmpDir, LockFileName)
+		return inst, inst.Install()
+	}
+
+	// ^1.0 first picks 1.4.0; geo then narrows vec to ~1.2.0
+	inst, err := install("^1.0", "1.0.0")
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	for key, want := range map[string]string{"vec@me": "1.2.5", "geo@me": "1.0.0"} {
+		if locked, _ := inst.lock.Get(key); locked.Version != want {
+			t.Errorf("%s: expected %s, got %+v", key, want, locked)
+		}
+	}
+	if got := packageVersion(filepath.Join(inst.LibDir, "vec"), ""); got != "1.2.5" {
+		t.Errorf("expected vec 1.2.5 on disk, got %s", got)
+	}
+
+	// geo 2.0.0 needs vec ^2.0, which the project's ^1.0 rules out
+	_, err = install("^1.0", "2.0.0")
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	for _, want := range []string{"vec@me", "^1.0 (required by app)", "^2.0 (required by geo@me)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err.Error())
+		}
+	}
+}
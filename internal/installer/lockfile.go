@@ -0,0 +1,113 @@
+package installer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// LockFileName is the file Install writes next to polyloft.toml
+const LockFileName = "polyloft.lock"
+
+// LockFile records the exact version and checksum of every installed registry
+// package, so later installs download the same archives
+type LockFile struct {
+	Packages []LockedPackage `toml:"package"`
+}
+
+// LockedPackage is one resolved package in a LockFile
+type LockedPackage struct {
+	Name     string `toml:"name"`
+	Author   string `toml:"author"`
+	Version  string `toml:"version"`
+	Checksum string `toml:"checksum"` // "sha256:" followed by the hex digest of the archive
+}
+
+// Key returns the name@author form used to identify the package
+func (p LockedPackage) Key() string {
+	return fmt.Sprintf("%s@%s", p.Name, p.Author)
+}
+
+// ChecksumError reports a downloaded archive whose hash differs from the lock
+type ChecksumError struct {
+	Package  string
+	Expected string
+	Got      string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: %s expects %s, downloaded archive is %s", e.Package, LockFileName, e.Expected, e.Got)
+}
+
+// LoadLockFile reads a lock file; a missing file gives an empty lock
+func LoadLockFile(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &LockFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var lock LockFile
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// Get returns the locked entry for a name@author key
+func (l *LockFile) Get(key string) (LockedPackage, bool) {
+	for _, pkg := range l.Packages {
+		if pkg.Key() == key {
+			return pkg, true
+		}
+	}
+	return LockedPackage{}, false
+}
+
+// Set adds or replaces the entry for pkg, reporting whether the lock changed
+func (l *LockFile) Set(pkg LockedPackage) bool {
+	for idx, existing := range l.Packages {
+		if existing.Key() == pkg.Key() {
+			if existing == pkg {
+				return false
+			}
+			l.Packages[idx] = pkg
+			return true
+		}
+	}
+	l.Packages = append(l.Packages, pkg)
+	return true
+}
+
+// Save writes the lock with its packages sorted by name and author, so the
+// file diffs cleanly between installs
+func (l *LockFile) Save(path string) error {
+	sort.Slice(l.Packages, func(a, b int) bool {
+		if l.Packages[a].Name != l.Packages[b].Name {
+			return l.Packages[a].Name < l.Packages[b].Name
+		}
+		return l.Packages[a].Author < l.Packages[b].Author
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString("# This file is generated by polyloft install. Do not edit it by hand.\n\n")
+	if err := toml.NewEncoder(&buf).Encode(l); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// archiveChecksum returns the checksum recorded for a downloaded archive
+func archiveChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,106 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ArubikU/polyloft/internal/searcher"
+	"github.com/ArubikU/polyloft/internal/semver"
+)
+
+// requirement is a version range a package was asked for with
+type requirement struct {
+	constraint *semver.Constraint
+	requiredBy string
+}
+
+// ConflictError reports a package whose version requirements no published
+// version satisfies together
+type ConflictError struct {
+	Package      string
+	Requirements []string // each range with the package that asked for it
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting version requirements for %s:\n  %s", e.Package, strings.Join(e.Requirements, "\n  "))
+}
+
+// resolveVersion picks the version of name@author to install for a dependency
+// that requiredBy declares with spec, which may be an exact version or a range.
+// It returns "" when spec is empty, meaning the latest version. The version must
+// also satisfy what other packages asked for during this install: the locked
+// version is kept when it does, otherwise the highest matching published version
+// is chosen.
+func (i *Installer) resolveVersion(name, author, spec, requiredBy string) (string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || author == "" {
+		return spec, nil
+	}
+	key := fmt.Sprintf("%s@%s", name, author)
+	constraint, err := semver.ParseConstraint(spec)
+	if err != nil {
+		return "", fmt.Errorf("%s requires %s: %w", requiredBy, key, err)
+	}
+	if i.requirements == nil {
+		i.requirements = make(map[string][]requirement)
+	}
+	i.requirements[key] = append(i.requirements[key], requirement{constraint: constraint, requiredBy: requiredBy})
+
+	// Already installed by this run and good for everyone who asked
+	if installed, ok := i.resolved[key]; ok && i.satisfiesRequirements(key, installed) {
+		return installed, nil
+	}
+
+	if exact, ok := constraint.Exact(); ok {
+		if !i.satisfiesRequirements(key, exact.String()) {
+			return "", i.conflict(key)
+		}
+		return spec, nil
+	}
+
+	if i.lock != nil && !i.Update {
+		if locked, ok := i.lock.Get(key); ok && locked.Version != "" && i.satisfiesRequirements(key, locked.Version) {
+			return locked.Version, nil
+		}
+	}
+
+	versions, err := searcher.New().Versions(name, author)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s %s: %w", key, spec, err)
+	}
+	var constraints []*semver.Constraint
+	for _, req := range i.requirements[key] {
+		constraints = append(constraints, req.constraint)
+	}
+	if best, ok := semver.MaxSatisfying(versions, constraints...); ok {
+		return best, nil
+	}
+	if len(constraints) > 1 {
+		return "", i.conflict(key)
+	}
+	return "", fmt.Errorf("no published version of %s matches %s (available: %s)", key, spec, strings.Join(versions, ", "))
+}
+
+// satisfiesRequirements reports whether version fits every range asked for
+// name@author. A missing or unparsable version is given the benefit of the doubt.
+func (i *Installer) satisfiesRequirements(key, version string) bool {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return true
+	}
+	for _, req := range i.requirements[key] {
+		if !req.constraint.Check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// conflict builds the error listing every range asked for key and by whom
+func (i *Installer) conflict(key string) error {
+	err := &ConflictError{Package: key}
+	for _, req := range i.requirements[key] {
+		err.Requirements = append(err.Requirements, fmt.Sprintf("%s (required by %s)", req.constraint, req.requiredBy))
+	}
+	return err
+}
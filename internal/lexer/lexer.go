@@ -294,6 +294,11 @@ func (l *Lexer) Scan(src []byte) []Item {
 				off += 2
 				col += 2
 				continue
+			case "**":
+				add(STAR_STAR, "**", start, ast.Position{Offset: off + 2, Line: line, Col: col + 2})
+				off += 2
+				col += 2
+				continue
 			case "*=":
 				add(STAR_ASSIGN, "*=", start, ast.Position{Offset: off + 2, Line: line, Col: col + 2})
 				off += 2
@@ -304,6 +309,11 @@ func (l *Lexer) Scan(src []byte) []Item {
 				off += 2
 				col += 2
 				continue
+			case "%=":
+				add(PERCENT_ASSIGN, "%=", start, ast.Position{Offset: off + 2, Line: line, Col: col + 2})
+				off += 2
+				col += 2
+				continue
 			case "==":
 				add(EQ, "==", start, ast.Position{Offset: off + 2, Line: line, Col: col + 2})
 				off += 2
@@ -334,6 +344,21 @@ func (l *Lexer) Scan(src []byte) []Item {
 				off += 2
 				col += 2
 				continue
+			case "|>":
+				add(PIPE_GT, "|>", start, ast.Position{Offset: off + 2, Line: line, Col: col + 2})
+				off += 2
+				col += 2
+				continue
+			case "?.":
+				add(QUESTION_DOT, "?.", start, ast.Position{Offset: off + 2, Line: line, Col: col + 2})
+				off += 2
+				col += 2
+				continue
+			case "??":
+				add(QUESTION_QUESTION, "??", start, ast.Position{Offset: off + 2, Line: line, Col: col + 2})
+				off += 2
+				col += 2
+				continue
 			case "=>":
 				add(ARROW, "=>", start, ast.Position{Offset: off + 2, Line: line, Col: col + 2})
 				off += 2
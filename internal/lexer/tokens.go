@@ -37,6 +37,7 @@ const (
 	KW_INTERFACE
 	KW_CLASS
 	KW_IMPORT
+	KW_EXPORT
 	KW_IMPLEMENTS
 	KW_ABSTRACT
 	KW_SEALED
@@ -72,107 +73,119 @@ const (
 	KW_SWITCH
 	KW_CASE
 	KW_DEFAULT
+	KW_FALLTHROUGH
 	KW_CLOSED
 	KW_WHERE
 	KW_EXTENDS
 	KW_OUT
+	KW_YIELD
+	KW_ASSERT
 
 	// Operators and delimiters
-	ASSIGN       // =
-	PLUS         // +
-	MINUS        // -
-	STAR         // *
-	SLASH        // /
-	PERCENT      // %
-	PLUS_ASSIGN  // +=
-	MINUS_ASSIGN // -=
-	STAR_ASSIGN  // *=
-	SLASH_ASSIGN // /=
-	EQ           // ==
-	NEQ          // !=
-	LT           // <
-	LTE          // <=
-	GT           // >
-	GTE          // >=
-	AND          // &&
-	OR           // ||
-	NOT          // !
-	ARROW        // =>
-	RARROW       // ->
-	COLONASSIGN  // :=
+	ASSIGN         // =
+	PLUS           // +
+	MINUS          // -
+	STAR           // *
+	SLASH          // /
+	PERCENT        // %
+	STAR_STAR      // **
+	PLUS_ASSIGN    // +=
+	MINUS_ASSIGN   // -=
+	STAR_ASSIGN    // *=
+	SLASH_ASSIGN   // /=
+	PERCENT_ASSIGN // %=
+	EQ             // ==
+	NEQ            // !=
+	LT             // <
+	LTE            // <=
+	GT             // >
+	GTE            // >=
+	AND            // &&
+	OR             // ||
+	NOT            // !
+	ARROW          // =>
+	RARROW         // ->
+	COLONASSIGN    // :=
 
 	COMMA    // ,
 	COLON    // :
 	SEMI     // ;
 	QUESTION // ? (for ternary operator)
 
-	LPAREN   // (
-	RPAREN   // )
-	LBRACE   // {
-	RBRACE   // }
-	LBRACK   // [
-	RBRACK   // ]
-	DOT      // .
-	ELLIPSIS // ... (for variadic parameters)
-	AT       // @ (for annotations)
-	PIPE     // | (for union types)
+	LPAREN            // (
+	RPAREN            // )
+	LBRACE            // {
+	RBRACE            // }
+	LBRACK            // [
+	RBRACK            // ]
+	DOT               // .
+	ELLIPSIS          // ... (for variadic parameters)
+	AT                // @ (for annotations)
+	PIPE              // | (for union types)
+	PIPE_GT           // |> (pipe operator)
+	QUESTION_DOT      // ?. (optional chaining)
+	QUESTION_QUESTION // ?? (nil coalescing)
 )
 
 var keywords = map[string]Token{
-	"var":        KW_VAR,
-	"let":        KW_LET,
-	"const":      KW_CONST,
-	"public":     KW_PUBLIC,
-	"pub":        KW_PUBLIC,
-	"private":    KW_PRIVATE,
-	"priv":       KW_PRIVATE,
-	"protected":  KW_PROTECTED,
-	"prot":       KW_PROTECTED,
-	"static":     KW_STATIC,
-	"def":        KW_DEF,
-	"interface":  KW_INTERFACE,
-	"class":      KW_CLASS,
-	"import":     KW_IMPORT,
-	"implements": KW_IMPLEMENTS,
-	"abstract":   KW_ABSTRACT,
-	"sealed":     KW_SEALED,
-	"thread":     KW_THREAD,
-	"spawn":      KW_SPAWN,
-	"join":       KW_JOIN,
-	"return":     KW_RETURN,
-	"true":       KW_TRUE,
-	"false":      KW_FALSE,
-	"nil":        KW_NIL,
-	"if":         KW_IF,
-	"elif":       KW_ELIF,
-	"else":       KW_ELSE,
-	"for":        KW_FOR,
-	"in":         KW_IN,
-	"break":      KW_BREAK,
-	"continue":   KW_CONTINUE,
-	"loop":       KW_LOOP,
-	"end":        KW_END,
-	"do":         KW_DO,
-	"instanceof": KW_INSTANCEOF,
-	"this":       KW_THIS,
-	"super":      KW_SUPER,
-	"enum":       KW_ENUM,
-	"record":     KW_RECORD,
-	"try":        KW_TRY,
-	"catch":      KW_CATCH,
-	"finally":    KW_FINALLY,
-	"throw":      KW_THROW,
-	"final":      KW_FINAL,
-	"defer":      KW_DEFER,
-	"channel":    KW_CHANNEL,
-	"select":     KW_SELECT,
-	"switch":     KW_SWITCH,
-	"case":       KW_CASE,
-	"default":    KW_DEFAULT,
-	"closed":     KW_CLOSED,
-	"where":      KW_WHERE,
-	"extends":    KW_EXTENDS,
-	"out":        KW_OUT,
+	"var":         KW_VAR,
+	"let":         KW_LET,
+	"const":       KW_CONST,
+	"public":      KW_PUBLIC,
+	"pub":         KW_PUBLIC,
+	"private":     KW_PRIVATE,
+	"priv":        KW_PRIVATE,
+	"protected":   KW_PROTECTED,
+	"prot":        KW_PROTECTED,
+	"static":      KW_STATIC,
+	"def":         KW_DEF,
+	"interface":   KW_INTERFACE,
+	"class":       KW_CLASS,
+	"import":      KW_IMPORT,
+	"export":      KW_EXPORT,
+	"implements":  KW_IMPLEMENTS,
+	"abstract":    KW_ABSTRACT,
+	"sealed":      KW_SEALED,
+	"thread":      KW_THREAD,
+	"spawn":       KW_SPAWN,
+	"join":        KW_JOIN,
+	"return":      KW_RETURN,
+	"true":        KW_TRUE,
+	"false":       KW_FALSE,
+	"nil":         KW_NIL,
+	"if":          KW_IF,
+	"elif":        KW_ELIF,
+	"else":        KW_ELSE,
+	"for":         KW_FOR,
+	"in":          KW_IN,
+	"break":       KW_BREAK,
+	"continue":    KW_CONTINUE,
+	"loop":        KW_LOOP,
+	"end":         KW_END,
+	"do":          KW_DO,
+	"instanceof":  KW_INSTANCEOF,
+	"this":        KW_THIS,
+	"super":       KW_SUPER,
+	"enum":        KW_ENUM,
+	"record":      KW_RECORD,
+	"try":         KW_TRY,
+	"catch":       KW_CATCH,
+	"finally":     KW_FINALLY,
+	"throw":       KW_THROW,
+	"final":       KW_FINAL,
+	"defer":       KW_DEFER,
+	"channel":     KW_CHANNEL,
+	"select":      KW_SELECT,
+	"switch":      KW_SWITCH,
+	"case":        KW_CASE,
+	"default":     KW_DEFAULT,
+	"fallthrough": KW_FALLTHROUGH,
+	"closed":      KW_CLOSED,
+	"where":       KW_WHERE,
+	"extends":     KW_EXTENDS,
+	"out":         KW_OUT,
+	"yield":       KW_YIELD,
+	"assert":      KW_ASSERT,
 }
 
 // Item represents a scanned token with its literal text and position.
@@ -226,6 +239,8 @@ func TokenName(tok Token) string {
 		return "keyword 'class'"
 	case KW_IMPORT:
 		return "keyword 'import'"
+	case KW_EXPORT:
+		return "keyword 'export'"
 	case KW_IMPLEMENTS:
 		return "keyword 'implements'"
 	case KW_ABSTRACT:
@@ -294,6 +309,8 @@ func TokenName(tok Token) string {
 		return "keyword 'case'"
 	case KW_DEFAULT:
 		return "keyword 'default'"
+	case KW_FALLTHROUGH:
+		return "keyword 'fallthrough'"
 	case KW_CLOSED:
 		return "keyword 'closed'"
 	case KW_WHERE:
@@ -302,6 +319,10 @@ func TokenName(tok Token) string {
 		return "keyword 'extends'"
 	case KW_OUT:
 		return "keyword 'out'"
+	case KW_YIELD:
+		return "keyword 'yield'"
+	case KW_ASSERT:
+		return "keyword 'assert'"
 	case ASSIGN:
 		return "'='"
 	case PLUS:
@@ -314,6 +335,8 @@ func TokenName(tok Token) string {
 		return "'/'"
 	case PERCENT:
 		return "'%'"
+	case STAR_STAR:
+		return "'**'"
 	case EQ:
 		return "'=='"
 	case NEQ:
@@ -366,6 +389,12 @@ func TokenName(tok Token) string {
 		return "'@'"
 	case PIPE:
 		return "'|'"
+	case PIPE_GT:
+		return "'|>'"
+	case QUESTION_DOT:
+		return "'?.'"
+	case QUESTION_QUESTION:
+		return "'??'"
 	default:
 		return fmt.Sprintf("unknown token (%d)", tok)
 	}
@@ -3,10 +3,13 @@ package mappings
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 )
 
 // Symbol represents a symbol (class, function, variable, etc.) in a Polyloft file
@@ -64,6 +67,18 @@ type Mappings struct {
 type Generator struct {
 	rootPath string
 	libsPath string
+	files    map[string]*fileMapping // parsed files by path, reused by Update
+}
+
+// fileMapping is what one parsed file contributes to its package
+type fileMapping struct {
+	modTime     time.Time
+	size        int64
+	packageName string
+	relPath     string
+	symbols     []Symbol
+	imports     []string
+	exports     []string
 }
 
 // NewGenerator creates a new mappings generator
@@ -71,79 +86,241 @@ func NewGenerator(rootPath string) *Generator {
 	return &Generator{
 		rootPath: rootPath,
 		libsPath: filepath.Join(rootPath, "libs"),
+		files:    make(map[string]*fileMapping),
 	}
 }
 
 // Generate creates a mappings.json file by scanning all .pf files
 func (g *Generator) Generate(outputPath string) error {
-	mappings := Mappings{
-		Version:  "1.0.0",
-		Packages: make(map[string]PackageMapping),
+	g.files = make(map[string]*fileMapping)
+	if _, err := g.update(); err != nil {
+		return err
 	}
+	mappings, err := g.write(outputPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Generated mappings.json with %d packages\n", len(mappings.Packages))
+	return nil
+}
 
-	// Scan libs directory for packages
-	if _, err := os.Stat(g.libsPath); err == nil {
-		err = filepath.Walk(g.libsPath, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
+// Update reparses only the .pf files added, changed or removed since the last
+// Generate or Update and rewrites outputPath when there were any. It returns
+// the paths of those files.
+func (g *Generator) Update(outputPath string) ([]string, error) {
+	changed, err := g.update()
+	if err != nil || len(changed) == 0 {
+		return changed, err
+	}
+	_, err = g.write(outputPath)
+	return changed, err
+}
 
-			if !info.IsDir() && strings.HasSuffix(info.Name(), ".pf") {
-				relPath, _ := filepath.Rel(g.libsPath, filepath.Dir(path))
-				packageName := strings.ReplaceAll(relPath, string(filepath.Separator), ".")
+// Watch keeps outputPath up to date until stop is closed. It polls the libs
+// directory every interval and, once files have stopped changing for debounce,
+// regenerates the mappings of the changed files and logs them to log.
+func (g *Generator) Watch(outputPath string, interval, debounce time.Duration, stop <-chan struct{}, log io.Writer) {
+	// Starting from an empty snapshot makes the first poll compare the files
+	// against what was last generated, so edits made before Watch are not lost
+	last := ""
+	settled := time.Now()
+	pending := false
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
 
-				// Parse the file and extract symbols
-				symbols, imports, exports, err := g.parseFile(path)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to parse %s: %v\n", path, err)
-					return nil
-				}
+		current, err := g.snapshot()
+		if err != nil {
+			fmt.Fprintf(log, "Warning: %v\n", err)
+			continue
+		}
+		if current != last {
+			// Wait for a burst of saves to finish before rebuilding
+			last, settled, pending = current, time.Now(), true
+			continue
+		}
+		if !pending || time.Since(settled) < debounce {
+			continue
+		}
+		pending = false
 
-				// Get or create package mapping
-				pkgMapping, exists := mappings.Packages[packageName]
-				if !exists {
-					pkgMapping = PackageMapping{
-						Name:     packageName,
-						Path:     relPath,
-						Version:  "1.0.0",
-						Symbols:  []Symbol{},
-						Imports:  []string{},
-						Exports:  []string{},
-						Metadata: make(map[string]string),
-					}
-				}
+		changed, err := g.Update(outputPath)
+		if err != nil {
+			fmt.Fprintf(log, "Error regenerating mappings: %v\n", err)
+			continue
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		for _, path := range changed {
+			rel, _ := filepath.Rel(g.rootPath, path)
+			fmt.Fprintf(log, "  changed: %s\n", rel)
+		}
+		fmt.Fprintf(log, "Regenerated %s (%d file(s) changed)\n", outputPath, len(changed))
+	}
+}
 
-				// Add symbols
-				pkgMapping.Symbols = append(pkgMapping.Symbols, symbols...)
+// snapshot summarises the names, sizes and modification times of the .pf files
+// in the libs directory, so that any change to them changes the result
+func (g *Generator) snapshot() (string, error) {
+	files, err := g.scan()
+	if err != nil {
+		return "", err
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&b, "%s %d %d\n", path, files[path].Size(), files[path].ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
 
-				// Merge imports and exports
-				pkgMapping.Imports = append(pkgMapping.Imports, imports...)
-				pkgMapping.Exports = append(pkgMapping.Exports, exports...)
+// update brings the parsed files in line with the libs directory
+func (g *Generator) update() ([]string, error) {
+	current, err := g.scan()
+	if err != nil {
+		return nil, err
+	}
 
-				// Update package
-				mappings.Packages[packageName] = pkgMapping
-			}
+	var changed []string
+	for path, info := range current {
+		if cached, ok := g.files[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			continue
+		}
+		changed = append(changed, path)
+		delete(g.files, path)
 
-			return nil
-		})
+		relPath, _ := filepath.Rel(g.libsPath, filepath.Dir(path))
+		// Parse the file and extract symbols
+		symbols, imports, exports, err := g.parseFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to parse %s: %v\n", path, err)
+			continue
+		}
+		g.files[path] = &fileMapping{
+			modTime:     info.ModTime(),
+			size:        info.Size(),
+			packageName: strings.ReplaceAll(relPath, string(filepath.Separator), "."),
+			relPath:     relPath,
+			symbols:     symbols,
+			imports:     imports,
+			exports:     exports,
+		}
+	}
+	for path := range g.files {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+			delete(g.files, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
 
+// scan lists the .pf files of the libs directory
+func (g *Generator) scan() (map[string]os.FileInfo, error) {
+	files := make(map[string]os.FileInfo)
+	// Scan libs directory for packages
+	if _, err := os.Stat(g.libsPath); err != nil {
+		return files, nil
+	}
+	err := filepath.Walk(g.libsPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			return fmt.Errorf("failed to walk libs directory: %w", err)
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".pf") {
+			files[path] = info
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk libs directory: %w", err)
+	}
+	return files, nil
+}
+
+// write assembles the mappings of the parsed files and writes them to outputPath
+func (g *Generator) write(outputPath string) (*Mappings, error) {
+	mappings := Mappings{
+		Version:  "1.0.0",
+		Packages: make(map[string]PackageMapping),
+	}
+
+	// Files are merged in path order so the output does not depend on which changed last
+	paths := make([]string, 0, len(g.files))
+	for path := range g.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		file := g.files[path]
+
+		// Get or create package mapping
+		pkgMapping, exists := mappings.Packages[file.packageName]
+		if !exists {
+			pkgMapping = PackageMapping{
+				Name:     file.packageName,
+				Path:     file.relPath,
+				Version:  "1.0.0",
+				Symbols:  []Symbol{},
+				Imports:  []string{},
+				Exports:  []string{},
+				Metadata: make(map[string]string),
+			}
+		}
+
+		// Add symbols
+		pkgMapping.Symbols = append(pkgMapping.Symbols, file.symbols...)
+
+		// Merge imports and exports
+		pkgMapping.Imports = append(pkgMapping.Imports, file.imports...)
+		pkgMapping.Exports = append(pkgMapping.Exports, file.exports...)
+
+		// Update package
+		mappings.Packages[file.packageName] = pkgMapping
 	}
 
 	// Write mappings to file
 	data, err := json.MarshalIndent(mappings, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal mappings: %w", err)
+		return nil, fmt.Errorf("failed to marshal mappings: %w", err)
 	}
 
-	if err := os.WriteFile(outputPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write mappings file: %w", err)
+	if err := writeFileAtomic(outputPath, data); err != nil {
+		return nil, fmt.Errorf("failed to write mappings file: %w", err)
 	}
+	return &mappings, nil
+}
 
-	fmt.Printf("Generated mappings.json with %d packages\n", len(mappings.Packages))
-	return nil
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so readers see either the old file or the new one
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 // parseFile parses a Polyloft file and extracts symbols
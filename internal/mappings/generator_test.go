@@ -0,0 +1,137 @@
+package mappings
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeSource(t *testing.T, path, source string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readMappings(t *testing.T, path string) Mappings {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Mappings
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("invalid mappings: %v", err)
+	}
+	return m
+}
+
+func symbolNames(pkg PackageMapping) string {
+	var names []string
+	for _, s := range pkg.Symbols {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestUpdateReparsesChangedFiles(t *testing.T) {
+	root := t.TempDir()
+	out := filepath.Join(root, "mappings.json")
+	mathFile := filepath.Join(root, "libs", "math", "vector.pf")
+	textFile := filepath.Join(root, "libs", "text", "format.pf")
+	writeSource(t, mathFile, "def add(a, b):\n    return a + b\nend\n")
+	writeSource(t, textFile, "def pad(s):\n    return s\nend\n")
+
+	g := NewGenerator(root)
+	if err := g.Generate(out); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if m := readMappings(t, out); len(m.Packages) != 2 || symbolNames(m.Packages["math"]) != "add" {
+		t.Fatalf("unexpected mappings: %+v", m.Packages)
+	}
+
+	changed, err := g.Update(out)
+	if err != nil || len(changed) != 0 {
+		t.Fatalf("Update with no changes = %v, %v", changed, err)
+	}
+
+	writeSource(t, mathFile, "def add(a, b):\n    return a + b\nend\n\ndef sub(a, b):\n    return a - b\nend\n")
+	os.Remove(textFile)
+	changed, err = g.Update(out)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(changed) != 2 || changed[0] != mathFile || changed[1] != textFile {
+		t.Errorf("changed = %v", changed)
+	}
+	m := readMappings(t, out)
+	if _, ok := m.Packages["text"]; ok || symbolNames(m.Packages["math"]) != "add,sub" {
+		t.Errorf("unexpected mappings: %+v", m.Packages)
+	}
+
+	entries, _ := os.ReadDir(root)
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s left behind", entry.Name())
+		}
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchRegeneratesAfterChanges(t *testing.T) {
+	root := t.TempDir()
+	out := filepath.Join(root, "mappings.json")
+	writeSource(t, filepath.Join(root, "libs", "math", "vector.pf"), "def add(a, b):\n    return a + b\nend\n")
+
+	g := NewGenerator(root)
+	if err := g.Generate(out); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	var log syncBuffer
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		g.Watch(out, 10*time.Millisecond, 30*time.Millisecond, stop, &log)
+		close(done)
+	}()
+
+	writeSource(t, filepath.Join(root, "libs", "geo", "point.pf"), "class Point:\nend\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(log.String(), "Regenerated") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(stop)
+	<-done
+
+	if !strings.Contains(log.String(), filepath.Join("libs", "geo", "point.pf")) {
+		t.Errorf("expected the changed file to be logged, got %q", log.String())
+	}
+	if m := readMappings(t, out); symbolNames(m.Packages["geo"]) != "Point" {
+		t.Errorf("unexpected mappings: %+v", m.Packages)
+	}
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/lexer"
+)
+
+func TestParseMethodAnnotations(t *testing.T) {
+	input := `
+class Dog < Animal:
+    @Override
+    def speak():
+        return "woof"
+    end
+
+    @Deprecated
+    public def bark():
+        return "woof"
+    end
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := New(lx.Scan([]byte(input))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	class, ok := prog.Stmts[0].(*ast.ClassDecl)
+	if !ok {
+		t.Fatalf("Expected ClassDecl, got %T", prog.Stmts[0])
+	}
+	if len(class.Methods) != 2 {
+		t.Fatalf("Expected 2 methods, got %d", len(class.Methods))
+	}
+	speak, bark := class.Methods[0], class.Methods[1]
+	if speak.Name != "speak" || !speak.IsOverride || len(speak.Annotations) != 1 {
+		t.Errorf("Expected speak to be marked @Override, got %+v", speak)
+	}
+	if bark.Name != "bark" || bark.IsOverride || len(bark.Annotations) != 1 || bark.Annotations[0].Raw != "Deprecated" {
+		t.Errorf("Expected bark to keep its @Deprecated annotation, got %+v", bark)
+	}
+	if len(bark.Modifiers) != 1 || bark.Modifiers[0] != "public" {
+		t.Errorf("Expected bark to be public, got %v", bark.Modifiers)
+	}
+	if speak.Start.Line != 3 {
+		t.Errorf("Expected speak to start at its annotation on line 3, got %d", speak.Start.Line)
+	}
+}
+
+func TestParseFunctionAnnotations(t *testing.T) {
+	input := `
+@Test
+def adds():
+    assert 1 + 1 == 2
+end
+
+@BeforeEach
+private def reset():
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := New(lx.Scan([]byte(input))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(prog.Stmts) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(prog.Stmts))
+	}
+	adds, ok := prog.Stmts[0].(*ast.DefStmt)
+	if !ok || adds.Name != "adds" || len(adds.Annotations) != 1 || adds.Annotations[0].Normalized != "test" || !adds.Annotations[0].Known {
+		t.Errorf("Expected adds to be marked @Test, got %+v", prog.Stmts[0])
+	}
+	reset, ok := prog.Stmts[1].(*ast.DefStmt)
+	if !ok || reset.AccessLevel != "private" || len(reset.Annotations) != 1 || reset.Annotations[0].Raw != "BeforeEach" {
+		t.Errorf("Expected reset to be a private @BeforeEach function, got %+v", prog.Stmts[1])
+	}
+
+	prog, err = New(lx.Scan([]byte("@Params(cases, [1, 2])\ndef square(n):\nend\n"))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	square := prog.Stmts[0].(*ast.DefStmt)
+	if len(square.Annotations) != 1 || len(square.Annotations[0].Args) != 2 {
+		t.Fatalf("Expected @Params with 2 arguments, got %+v", square.Annotations)
+	}
+	if _, ok := square.Annotations[0].Args[0].(*ast.Ident); !ok {
+		t.Errorf("Expected the first argument to be an identifier, got %T", square.Annotations[0].Args[0])
+	}
+
+	if _, err := New(lx.Scan([]byte("@Test\nlet x = 1\n"))).Parse(); err == nil {
+		t.Error("Expected an error for an annotation on a variable")
+	}
+}
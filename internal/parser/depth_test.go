@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/lexer"
+)
+
+func parseSource(src string, maxDepth int) error {
+	lx := &lexer.Lexer{}
+	_, err := NewWithSource(lx.Scan([]byte(src)), "deep.pf", src).SetMaxDepth(maxDepth).Parse()
+	return err
+}
+
+func TestParse_NestingLimit(t *testing.T) {
+	cases := map[string]string{
+		"parentheses": "let x = " + strings.Repeat("(", 100000) + "1" + strings.Repeat(")", 100000),
+		"arrays":      "let x = " + strings.Repeat("[", 100000) + strings.Repeat("]", 100000),
+		"unary":       "let x = " + strings.Repeat("-", 100000) + "1",
+		"blocks":      strings.Repeat("if true:\n", 5000) + "x\n" + strings.Repeat("end\n", 5000),
+	}
+	for name, src := range cases {
+		err := parseSource(src, 0)
+		var parseErr ParseError
+		if !errors.As(err, &parseErr) || !strings.Contains(parseErr.Msg, "nested too deeply (more than 1000 levels)") {
+			t.Errorf("%s: expected a nesting ParseError, got %v", name, err)
+		}
+	}
+
+	// Long flat chains do not nest, and the limit can be raised
+	if err := parseSource("let x = "+strings.Repeat("1 + ", 20000)+"1", 0); err != nil {
+		t.Errorf("flat chain: %v", err)
+	}
+	nested := "let x = " + strings.Repeat("(", 1500) + "1" + strings.Repeat(")", 1500)
+	if err := parseSource(nested, 0); err == nil {
+		t.Error("expected 1500 parentheses to exceed the default limit")
+	}
+	if err := parseSource(nested, 4000); err != nil {
+		t.Errorf("raised limit: %v", err)
+	}
+}
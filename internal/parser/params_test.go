@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/lexer"
+)
+
+func TestParseParameterDefaults(t *testing.T) {
+	input := `
+def greet(name: String, greeting: String = "Hello", times = 1):
+    return greeting
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := New(lx.Scan([]byte(input))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	def, ok := prog.Stmts[0].(*ast.DefStmt)
+	if !ok {
+		t.Fatalf("Expected DefStmt, got %T", prog.Stmts[0])
+	}
+	if len(def.Params) != 3 {
+		t.Fatalf("Expected 3 parameters, got %d", len(def.Params))
+	}
+	if def.Params[0].Default != nil {
+		t.Errorf("Expected 'name' to have no default")
+	}
+	if def.Params[1].Default == nil || def.Params[2].Default == nil {
+		t.Errorf("Expected 'greeting' and 'times' to have defaults")
+	}
+}
+
+func TestParseParameterDefaultErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			input: "def f(a = 1, b):\n    return a\nend\n",
+			want:  "parameter 'b' without a default value cannot follow parameters with defaults",
+		},
+		{
+			input: "class A:\n    def m(a: Int = 1, b: Int):\n        return a\n    end\nend\n",
+			want:  "parameter 'b' without a default value cannot follow parameters with defaults",
+		},
+		{
+			input: "def f(rest: Int... = 1):\n    return rest\nend\n",
+			want:  "variadic parameter 'rest' cannot have a default value",
+		},
+	}
+
+	for _, tt := range tests {
+		lx := &lexer.Lexer{}
+		_, err := New(lx.Scan([]byte(tt.input))).Parse()
+		if err == nil {
+			t.Errorf("Expected parse error for %q", tt.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected error containing %q, got %v", tt.want, err)
+		}
+	}
+}
+
+func TestParseNamedArguments(t *testing.T) {
+	lx := &lexer.Lexer{}
+	prog, err := New(lx.Scan([]byte(`connect("x", port: 80, secure: a ? b : c)`))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	exprStmt, ok := prog.Stmts[0].(*ast.ExprStmt)
+	if !ok {
+		t.Fatalf("Expected ExprStmt, got %T", prog.Stmts[0])
+	}
+	call, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok {
+		t.Fatalf("Expected CallExpr, got %T", exprStmt.X)
+	}
+	if len(call.Args) != 1 || len(call.NamedArgs) != 2 {
+		t.Fatalf("Expected 1 positional and 2 keyword arguments, got %d and %d", len(call.Args), len(call.NamedArgs))
+	}
+	if call.NamedArgs[0].Name != "port" || call.NamedArgs[1].Name != "secure" {
+		t.Errorf("Unexpected keyword names: %q, %q", call.NamedArgs[0].Name, call.NamedArgs[1].Name)
+	}
+	if _, ok := call.NamedArgs[1].Value.(*ast.TernaryExpr); !ok {
+		t.Errorf("Expected ternary value for 'secure', got %T", call.NamedArgs[1].Value)
+	}
+
+	for input, want := range map[string]string{
+		`f(a: 1, 2)`:    "positional argument cannot follow keyword arguments",
+		`f(a: 1, a: 2)`: "keyword argument 'a' repeated",
+	} {
+		_, err := New(lx.Scan([]byte(input))).Parse()
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q for %s, got %v", want, input, err)
+		}
+	}
+}
+
+func TestParseSpreadElements(t *testing.T) {
+	lx := &lexer.Lexer{}
+	prog, err := New(lx.Scan([]byte(`f(...xs, [...a, 1, ...b], 2...4)`))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	call := prog.Stmts[0].(*ast.ExprStmt).X.(*ast.CallExpr)
+	if len(call.Args) != 3 {
+		t.Fatalf("Expected 3 arguments, got %d", len(call.Args))
+	}
+	if _, ok := call.Args[0].(*ast.SpreadExpr); !ok {
+		t.Errorf("Expected SpreadExpr argument, got %T", call.Args[0])
+	}
+	arr, ok := call.Args[1].(*ast.ArrayLit)
+	if !ok || len(arr.Elems) != 3 {
+		t.Fatalf("Expected array literal with 3 elements, got %#v", call.Args[1])
+	}
+	_, first := arr.Elems[0].(*ast.SpreadExpr)
+	_, last := arr.Elems[2].(*ast.SpreadExpr)
+	if !first || !last {
+		t.Errorf("Expected spread first and last elements, got %T and %T", arr.Elems[0], arr.Elems[2])
+	}
+	if _, ok := call.Args[2].(*ast.RangeExpr); !ok {
+		t.Errorf("Expected range argument to stay a RangeExpr, got %T", call.Args[2])
+	}
+}
@@ -17,8 +17,15 @@ type Parser struct {
 	pos        int
 	file       string
 	sourceCode string // Store source code for better error messages
+	depth      int    // statements and expressions currently being parsed
+	maxDepth   int    // limit on depth; DefaultMaxDepth when zero
 }
 
+// DefaultMaxDepth is how deeply statements and expressions may nest before Parse
+// fails with a ParseError. Hand-written code stays far below it; without a limit,
+// input such as thousands of nested parentheses overflows the Go stack.
+const DefaultMaxDepth = 1000
+
 func New(items []lexer.Item) *Parser                      { return &Parser{items: items} }
 func NewWithFile(items []lexer.Item, file string) *Parser { return &Parser{items: items, file: file} }
 
@@ -27,6 +34,29 @@ func NewWithSource(items []lexer.Item, file string, source string) *Parser {
 	return &Parser{items: items, file: file, sourceCode: source}
 }
 
+// SetMaxDepth changes how deeply statements and expressions may nest; n <= 0
+// restores DefaultMaxDepth
+func (p *Parser) SetMaxDepth(n int) *Parser {
+	p.maxDepth = n
+	return p
+}
+
+// enter counts one more level of nesting and fails once the limit is passed.
+// Every successful enter is paired with a leave.
+func (p *Parser) enter() error {
+	limit := p.maxDepth
+	if limit <= 0 {
+		limit = DefaultMaxDepth
+	}
+	if p.depth >= limit {
+		return p.errf("code is nested too deeply (more than %d levels)", limit)
+	}
+	p.depth++
+	return nil
+}
+
+func (p *Parser) leave() { p.depth-- }
+
 // ParseError includes file and precise position for better diagnostics.
 type ParseError struct {
 	File       string
@@ -239,8 +269,27 @@ func (p *Parser) ParseExpression() (ast.Expr, error) {
 	return expr, nil
 }
 
+// parseStmt parses one statement and records the position of its first token.
 func (p *Parser) parseStmt() (ast.Stmt, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	start := p.curr().Start
+	st, err := p.parseStmtNode()
+	if err != nil {
+		return nil, err
+	}
+	if located, ok := st.(ast.Locatable); ok {
+		located.SetStartPos(start)
+	}
+	return st, nil
+}
+
+func (p *Parser) parseStmtNode() (ast.Stmt, error) {
 	switch p.curr().Tok {
+	case lexer.KW_EXPORT:
+		return p.parseExport()
 	case lexer.KW_PUBLIC, lexer.KW_PRIVATE, lexer.KW_PROTECTED:
 		// Check if this is an access modifier for a class, enum, or function
 		savedPos := p.pos
@@ -290,11 +339,10 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 					var paramType string
 					var isVariadic bool
 					if p.accept(lexer.COLON) {
-						if p.curr().Tok != lexer.IDENT {
+						if !p.atTypeStart() {
 							return nil, p.errf("expected parameter type")
 						}
-						paramType = p.curr().Lit
-						p.next()
+						paramType = p.parseTypeName()
 
 						// Check for variadic parameter (type...)
 						if p.accept(lexer.ELLIPSIS) {
@@ -302,7 +350,11 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 						}
 					}
 
-					params = append(params, ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic})
+					param := ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic}
+					if err := p.parseParamDefault(params, &param); err != nil {
+						return nil, err
+					}
+					params = append(params, param)
 
 					// If this is a variadic parameter, it must be the last one
 					if isVariadic && p.curr().Tok == lexer.COMMA {
@@ -381,6 +433,8 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 		p.pos = savedPos
 		return p.parseVarLike()
 
+	case lexer.AT:
+		return p.parseAnnotatedDef()
 	case lexer.KW_SEALED:
 		if len(p.items) > p.pos+1 {
 			nextTok := p.items[p.pos+1].Tok
@@ -429,6 +483,10 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 		return p.parseThrow()
 	case lexer.KW_DEFER:
 		return p.parseDefer()
+	case lexer.KW_YIELD:
+		return p.parseYield()
+	case lexer.KW_ASSERT:
+		return p.parseAssert()
 	case lexer.KW_SELECT:
 		return p.parseSelect()
 	case lexer.KW_SWITCH:
@@ -439,6 +497,8 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 	case lexer.KW_CONTINUE:
 		p.next()
 		return &ast.ContinueStmt{}, nil
+	case lexer.KW_FALLTHROUGH:
+		return nil, p.errf("'fallthrough' can only be the last statement of a switch case")
 	case lexer.KW_RETURN:
 		p.next()
 		// Check if return has a value or is just "return" alone
@@ -489,11 +549,10 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 				var paramType string
 				var isVariadic bool
 				if p.accept(lexer.COLON) {
-					if p.curr().Tok != lexer.IDENT {
+					if !p.atTypeStart() {
 						return nil, p.errf("expected parameter type")
 					}
-					paramType = p.curr().Lit
-					p.next()
+					paramType = p.parseTypeName()
 
 					// Check for variadic parameter (type...)
 					if p.accept(lexer.ELLIPSIS) {
@@ -501,7 +560,11 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 					}
 				}
 
-				params = append(params, ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic})
+				param := ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic}
+				if err := p.parseParamDefault(params, &param); err != nil {
+					return nil, err
+				}
+				params = append(params, param)
 
 				// If this is a variadic parameter, it must be the last one
 				if isVariadic && p.curr().Tok == lexer.COMMA {
@@ -565,6 +628,11 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 			TypeParams:  typeParams,
 		}, nil
 	default:
+		// typealias is contextual so it stays usable as an identifier elsewhere
+		if p.curr().Tok == lexer.IDENT && p.curr().Lit == "typealias" &&
+			len(p.items) > p.pos+1 && p.items[p.pos+1].Tok == lexer.IDENT {
+			return p.parseVarLike()
+		}
 		// Try to parse as assignment statement first
 		if p.curr().Tok == lexer.IDENT || p.curr().Tok == lexer.KW_THIS {
 			// Look ahead to see if this is an assignment
@@ -589,7 +657,7 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 				// Create assignment statement
 				return &ast.AssignStmt{Target: lhs, Value: rhs, Pos: assignPos}, nil
 
-			case lexer.PLUS_ASSIGN, lexer.MINUS_ASSIGN, lexer.STAR_ASSIGN, lexer.SLASH_ASSIGN:
+			case lexer.PLUS_ASSIGN, lexer.MINUS_ASSIGN, lexer.STAR_ASSIGN, lexer.SLASH_ASSIGN, lexer.PERCENT_ASSIGN:
 				// Handle compound assignment: a += b  becomes  a = a + b
 				op := p.curr().Tok
 				assignPos := p.curr().Start
@@ -610,6 +678,8 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 					basicOp = lexer.STAR
 				case lexer.SLASH_ASSIGN:
 					basicOp = lexer.SLASH
+				case lexer.PERCENT_ASSIGN:
+					basicOp = lexer.PERCENT
 				}
 
 				// Create binary expression: lhs op rhs using ast operator constant
@@ -618,9 +688,10 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 					Op:  p.toOp(basicOp), // Convert to ast operator
 					Rhs: rhs,
 				}
+				p.span(binaryExpr, p.items[saved_pos].Start)
 
 				// Create assignment: lhs = (lhs op rhs)
-				return &ast.AssignStmt{Target: lhs, Value: binaryExpr, Pos: assignPos}, nil
+				return &ast.AssignStmt{Target: lhs, Value: binaryExpr, Pos: assignPos, Compound: true}, nil
 
 			default:
 				// Not an assignment, treat as expression statement
@@ -642,6 +713,76 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 	}
 }
 
+// parseExport parses 'export' followed by a declaration and marks the declaration as exported
+func (p *Parser) parseExport() (ast.Stmt, error) {
+	p.next() // consume 'export'
+	st, err := p.parseStmt()
+	if err != nil {
+		return nil, err
+	}
+	switch decl := st.(type) {
+	case *ast.LetStmt:
+		decl.Exported = true
+	case *ast.DefStmt:
+		decl.Exported = true
+	case *ast.ClassDecl:
+		decl.Exported = true
+	case *ast.EnumDecl:
+		decl.Exported = true
+	case *ast.RecordDecl:
+		decl.Exported = true
+	case *ast.InterfaceDecl:
+		decl.Exported = true
+	default:
+		return nil, p.errf("expected a variable, function, class, enum, record or interface declaration after 'export'")
+	}
+	return st, nil
+}
+
+// parseTypeAlias parses the rest of a type alias after its keyword:
+// Name ('<' T (',' T)* '>')? '=' Type
+func (p *Parser) parseTypeAlias(keyword string, isFinal bool, mods []string) (ast.Stmt, error) {
+	if p.curr().Tok != lexer.IDENT {
+		return nil, p.errf("expected type alias name after '%s'", keyword)
+	}
+	aliasName := p.curr().Lit
+	p.next()
+
+	var typeParams []string
+	if p.accept(lexer.LT) {
+		for {
+			if p.curr().Tok != lexer.IDENT {
+				return nil, p.errf("expected type parameter name in type alias '%s'", aliasName)
+			}
+			typeParams = append(typeParams, p.curr().Lit)
+			p.next()
+			if !p.accept(lexer.COMMA) {
+				break
+			}
+		}
+		if !p.accept(lexer.GT) {
+			return nil, p.errf("expected '>' after type parameters of type alias '%s'", aliasName)
+		}
+	}
+
+	if !p.accept(lexer.ASSIGN) {
+		return nil, p.errf("expected '=' after type alias name")
+	}
+
+	if !p.atTypeStart() {
+		return nil, p.errf("expected base type name after '='")
+	}
+	baseType := p.parseTypeName()
+
+	return &ast.TypeAliasStmt{
+		Name:       aliasName,
+		TypeParams: typeParams,
+		BaseType:   baseType,
+		IsFinal:    isFinal,
+		Modifiers:  mods,
+	}, nil
+}
+
 // parseVarLike handles declarations with modifiers and optional types:
 // [public|private|protected]? [static]? (var|let|const|final) name ( ':' Type )? ( '=' expr | ':=' expr )?
 func (p *Parser) parseVarLike() (ast.Stmt, error) {
@@ -684,32 +825,17 @@ func (p *Parser) parseVarLike() (ast.Stmt, error) {
 		// Check if this is a type alias: final type Age = Int
 		if p.curr().Tok == lexer.IDENT && p.curr().Lit == "type" {
 			p.next() // consume 'type'
-
-			if p.curr().Tok != lexer.IDENT {
-				return nil, p.errf("expected type alias name after 'final type'")
-			}
-			aliasName := p.curr().Lit
-			p.next()
-
-			if !p.accept(lexer.ASSIGN) {
-				return nil, p.errf("expected '=' after type alias name")
-			}
-
-			if p.curr().Tok != lexer.IDENT {
-				return nil, p.errf("expected base type name after '='")
-			}
-			baseType := p.curr().Lit
-			p.next()
-
-			return &ast.TypeAliasStmt{
-				Name:      aliasName,
-				BaseType:  baseType,
-				IsFinal:   true,
-				Modifiers: mods,
-			}, nil
+			return p.parseTypeAlias("final type", true, mods)
 		}
 		// Otherwise, it's a regular final variable declaration
 		// Continue with normal processing below
+	case lexer.IDENT:
+		// Structural type alias: typealias StringMap<V> = Map<String, V>
+		if kindTok.Lit == "typealias" {
+			p.next() // consume 'typealias'
+			return p.parseTypeAlias("typealias", false, mods)
+		}
+		return nil, p.errf("expected declaration keyword after modifiers")
 	default:
 		return nil, p.errf("expected declaration keyword after modifiers")
 	}
@@ -745,11 +871,10 @@ func (p *Parser) parseVarLike() (ast.Stmt, error) {
 	typ := ""
 	inferred := false
 	if p.accept(lexer.COLON) {
-		if p.curr().Tok != lexer.IDENT {
+		if !p.atTypeStart() {
 			return nil, p.errf("expected type name after ':'")
 		}
-		typ = p.curr().Lit
-		p.next()
+		typ = p.parseTypeName()
 		if p.accept(lexer.ASSIGN) {
 			// typed with '='
 		} else if p.accept(lexer.COLONASSIGN) {
@@ -996,6 +1121,8 @@ func (p *Parser) parseLoop() (ast.Stmt, error) {
 			p.curr().Tok != lexer.KW_BREAK &&
 			p.curr().Tok != lexer.KW_CONTINUE &&
 			p.curr().Tok != lexer.KW_THROW &&
+			p.curr().Tok != lexer.KW_YIELD &&
+			p.curr().Tok != lexer.KW_ASSERT &&
 			p.curr().Tok != lexer.KW_VAR &&
 			p.curr().Tok != lexer.KW_LET &&
 			p.curr().Tok != lexer.KW_CONST {
@@ -1213,6 +1340,28 @@ func (p *Parser) parseInterface() (ast.Stmt, error) {
 	return p.parseInterfaceWithModifiers("public", false)
 }
 
+// parseParamDefault parses an optional "= expr" default value after param.
+// Parameters with defaults must come after the required ones (a variadic
+// parameter may still follow them).
+func (p *Parser) parseParamDefault(params []ast.Parameter, param *ast.Parameter) error {
+	if p.curr().Tok != lexer.ASSIGN {
+		if !param.IsVariadic && len(params) > 0 && params[len(params)-1].Default != nil {
+			return p.errf("parameter '%s' without a default value cannot follow parameters with defaults", param.Name)
+		}
+		return nil
+	}
+	if param.IsVariadic {
+		return p.errf("variadic parameter '%s' cannot have a default value", param.Name)
+	}
+	p.next()
+	value, err := p.parseExpr(0)
+	if err != nil {
+		return err
+	}
+	param.Default = value
+	return nil
+}
+
 // parseMethodSignature parses method signatures for interfaces
 func (p *Parser) parseMethodSignature() (ast.MethodSignature, error) {
 	p.next() // consume 'def'
@@ -1265,11 +1414,10 @@ func (p *Parser) parseMethodSignature() (ast.MethodSignature, error) {
 			var paramType string
 			var isVariadic bool
 			if p.accept(lexer.COLON) {
-				if p.curr().Tok != lexer.IDENT {
+				if !p.atTypeStart() {
 					return ast.MethodSignature{}, p.errf("expected parameter type")
 				}
-				paramType = p.curr().Lit
-				p.next()
+				paramType = p.parseTypeName()
 
 				// Check for variadic parameter (type...)
 				if p.accept(lexer.ELLIPSIS) {
@@ -1277,7 +1425,11 @@ func (p *Parser) parseMethodSignature() (ast.MethodSignature, error) {
 				}
 			}
 
-			params = append(params, ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic})
+			param := ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic}
+			if err := p.parseParamDefault(params, &param); err != nil {
+				return ast.MethodSignature{}, err
+			}
+			params = append(params, param)
 
 			// If this is a variadic parameter, it must be the last one
 			if isVariadic && p.curr().Tok == lexer.COMMA {
@@ -1330,6 +1482,7 @@ func (p *Parser) parseMethodSignature() (ast.MethodSignature, error) {
 
 // parseFieldDecl parses field declarations: [modifiers] var/let/const/final name: Type [= value]
 func (p *Parser) parseFieldDecl() (ast.FieldDecl, error) {
+	start := p.curr().Start
 	var modifiers []string
 
 	// Parse modifiers
@@ -1383,6 +1536,7 @@ done_modifiers:
 	}
 
 	return ast.FieldDecl{
+		Located:   ast.Located{Start: start},
 		Name:      name,
 		Type:      ast.TypeFromString(fieldType),
 		Modifiers: modifiers,
@@ -1390,33 +1544,85 @@ done_modifiers:
 	}, nil
 }
 
-// parseMethodDecl parses method declarations: [annotations] [modifiers] def name(params): ReturnType body end
-func (p *Parser) parseMethodDecl() (ast.MethodDecl, error) {
+// parseAnnotations parses a run of @Name and @Name(args) annotations and
+// merges the flags of the registered ones.
+func (p *Parser) parseAnnotations() ([]ast.Annotation, common.AnnotationFlags, error) {
 	var (
-		annotations     []ast.Annotation
-		modifiers       []string
-		annotationFlags common.AnnotationFlags
+		annotations []ast.Annotation
+		flags       common.AnnotationFlags
 	)
-
-	// Parse annotations and capture metadata once so we can expand behaviour later.
 	for p.curr().Tok == lexer.AT {
 		p.next() // consume @
 		if p.curr().Tok != lexer.IDENT {
-			return ast.MethodDecl{}, p.errf("expected annotation name after @")
+			return nil, flags, p.errf("expected annotation name after @")
 		}
 		rawName := p.curr().Lit
 		p.next()
 
+		var args []ast.Expr
+		if p.accept(lexer.LPAREN) {
+			for p.curr().Tok != lexer.RPAREN {
+				arg, err := p.parseExpr(0)
+				if err != nil {
+					return nil, flags, err
+				}
+				args = append(args, arg)
+				if !p.accept(lexer.COMMA) {
+					break
+				}
+			}
+			if !p.accept(lexer.RPAREN) {
+				return nil, flags, p.errf("expected ')' after annotation arguments")
+			}
+		}
+
 		info, known := common.LookupAnnotation(rawName)
 		if known {
-			annotationFlags = annotationFlags.Merge(info.Flags)
+			flags = flags.Merge(info.Flags)
 		}
 		annotations = append(annotations, ast.Annotation{
 			Raw:        rawName,
 			Normalized: info.Name,
 			Known:      known,
+			Args:       args,
 		})
 	}
+	return annotations, flags, nil
+}
+
+// parseAnnotatedDef parses annotations placed before a top-level function,
+// such as @Test, and attaches them to the function.
+func (p *Parser) parseAnnotatedDef() (ast.Stmt, error) {
+	annotations, _, err := p.parseAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.curr().Tok
+	if (tok == lexer.KW_PUBLIC || tok == lexer.KW_PRIVATE || tok == lexer.KW_PROTECTED) && len(p.items) > p.pos+1 {
+		tok = p.items[p.pos+1].Tok
+	}
+	if tok != lexer.KW_DEF {
+		return nil, p.errf("annotations can only be applied to functions and methods")
+	}
+	st, err := p.parseStmtNode()
+	if err != nil {
+		return nil, err
+	}
+	def := st.(*ast.DefStmt)
+	def.Annotations = annotations
+	return def, nil
+}
+
+// parseMethodDecl parses method declarations: [annotations] [modifiers] def name(params): ReturnType body end
+func (p *Parser) parseMethodDecl() (ast.MethodDecl, error) {
+	start := p.curr().Start
+	var modifiers []string
+
+	// Parse annotations and capture metadata once so we can expand behaviour later.
+	annotations, annotationFlags, err := p.parseAnnotations()
+	if err != nil {
+		return ast.MethodDecl{}, err
+	}
 
 	// Parse modifiers (handled before getting here, but could be enhanced)
 	if p.curr().Tok == lexer.KW_PUBLIC || p.curr().Tok == lexer.KW_PRIVATE ||
@@ -1482,6 +1688,14 @@ done_method_modifiers:
 	}
 	p.next()
 
+	// Optional generic type parameters: def convert<R>(value: R) -> R
+	var typeParams []ast.TypeParam
+	if p.curr().Tok == lexer.LT && p.previous().Tok == lexer.IDENT {
+		if params, err := p.tryParseGenericTypeParams(); err == nil && params != nil {
+			typeParams = params
+		}
+	}
+
 	// Parse parameters
 	if !p.accept(lexer.LPAREN) {
 		return ast.MethodDecl{}, p.errf("expected '(' after method name")
@@ -1499,11 +1713,10 @@ done_method_modifiers:
 			var paramType string
 			var isVariadic bool
 			if p.accept(lexer.COLON) {
-				if p.curr().Tok != lexer.IDENT {
+				if !p.atTypeStart() {
 					return ast.MethodDecl{}, p.errf("expected parameter type")
 				}
-				paramType = p.curr().Lit
-				p.next()
+				paramType = p.parseTypeName()
 
 				// Check for variadic parameter (type...)
 				if p.accept(lexer.ELLIPSIS) {
@@ -1511,7 +1724,11 @@ done_method_modifiers:
 				}
 			}
 
-			params = append(params, ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic})
+			param := ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic}
+			if err := p.parseParamDefault(params, &param); err != nil {
+				return ast.MethodDecl{}, err
+			}
+			params = append(params, param)
 
 			// If this is a variadic parameter, it must be the last one
 			if isVariadic && p.curr().Tok == lexer.COMMA {
@@ -1575,7 +1792,9 @@ done_method_modifiers:
 	}
 
 	return ast.MethodDecl{
+		Located:     ast.Located{Start: start},
 		Name:        name,
+		TypeParams:  typeParams,
 		Params:      params,
 		ReturnType:  ast.TypeFromString(returnType),
 		Body:        body,
@@ -1588,6 +1807,7 @@ done_method_modifiers:
 
 // parseConstructorDecl parses constructor declarations: ClassName(params): body end
 func (p *Parser) parseConstructorDecl() (*ast.ConstructorDecl, error) {
+	start := p.curr().Start
 	// Constructor name (should match class name)
 	p.next() // consume constructor name
 
@@ -1608,11 +1828,10 @@ func (p *Parser) parseConstructorDecl() (*ast.ConstructorDecl, error) {
 			var paramType string
 			var isVariadic bool
 			if p.accept(lexer.COLON) {
-				if p.curr().Tok != lexer.IDENT {
+				if !p.atTypeStart() {
 					return nil, p.errf("expected parameter type")
 				}
-				paramType = p.curr().Lit
-				p.next()
+				paramType = p.parseTypeName()
 
 				// Check for variadic parameter (type...)
 				if p.accept(lexer.ELLIPSIS) {
@@ -1620,7 +1839,11 @@ func (p *Parser) parseConstructorDecl() (*ast.ConstructorDecl, error) {
 				}
 			}
 
-			params = append(params, ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic})
+			param := ast.Parameter{Name: paramName, Type: ast.TypeFromString(paramType), IsVariadic: isVariadic}
+			if err := p.parseParamDefault(params, &param); err != nil {
+				return nil, err
+			}
+			params = append(params, param)
 
 			// If this is a variadic parameter, it must be the last one
 			if isVariadic && p.curr().Tok == lexer.COMMA {
@@ -1654,8 +1877,9 @@ func (p *Parser) parseConstructorDecl() (*ast.ConstructorDecl, error) {
 	}
 
 	return &ast.ConstructorDecl{
-		Params: params,
-		Body:   body,
+		Located: ast.Located{Start: start},
+		Params:  params,
+		Body:    body,
 	}, nil
 }
 
@@ -1867,8 +2091,8 @@ func (p *Parser) parseClassInternal(accessLevel string) (ast.Stmt, error) {
 				return nil, err
 			}
 			fields = append(fields, field)
-		case lexer.KW_ABSTRACT:
-			// Abstract method declaration (abstract def methodName)
+		case lexer.KW_ABSTRACT, lexer.AT:
+			// Abstract or annotated method declaration (abstract def methodName, @Override def methodName)
 			method, err := p.parseMethodDecl()
 			if err != nil {
 				return nil, err
@@ -1903,6 +2127,9 @@ func (p *Parser) parseClassInternal(accessLevel string) (ast.Stmt, error) {
 		for p.accept(lexer.SEMI) {
 		}
 	}
+	if depth > 0 {
+		return nil, p.errf("expected 'end' to close class body")
+	}
 
 	return &ast.ClassDecl{
 		Name:             name,
@@ -1960,8 +2187,10 @@ func (p *Parser) parseImport() (ast.Stmt, error) {
 
 // Pratt parser precedence levels
 const (
-	precTernary = iota
-	precRange   // for ... range operator
+	precTernary  = iota
+	precCoalesce // for ?? nil coalescing
+	precPipe     // for |> pipe operator
+	precRange    // for ... range operator
 	precOr
 	precAnd
 	precEq
@@ -1969,6 +2198,7 @@ const (
 	precAdd
 	precMul
 	precUnary
+	precPow // for ** (right-associative, binds tighter than unary minus)
 	precCall
 )
 
@@ -1976,6 +2206,10 @@ func (p *Parser) precedence(tok lexer.Token) int {
 	switch tok {
 	case lexer.QUESTION:
 		return precTernary
+	case lexer.QUESTION_QUESTION:
+		return precCoalesce
+	case lexer.PIPE_GT:
+		return precPipe
 	case lexer.ELLIPSIS:
 		return precRange
 	case lexer.OR:
@@ -1992,15 +2226,37 @@ func (p *Parser) precedence(tok lexer.Token) int {
 		return precAdd
 	case lexer.STAR, lexer.SLASH, lexer.PERCENT:
 		return precMul
+	case lexer.STAR_STAR:
+		return precPow
 	default:
 		return -1
 	}
 }
 
+// span records the source range from start to the end of the last consumed token
+// on expressions that track it, keeping any range set by a nested parse.
+func (p *Parser) span(e ast.Expr, start ast.Position) {
+	if spanned, ok := e.(ast.Spannable); ok {
+		if from, _ := spanned.Span(); from.Line == 0 {
+			spanned.SetSpan(start, p.previous().End)
+		}
+	}
+}
+
+// parseExpr parses an expression whose operators bind tighter than minPrec
 func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	return p.parseExprNode(minPrec)
+}
+
+func (p *Parser) parseExprNode(minPrec int) (ast.Expr, error) {
 	// Parse prefix
 	var left ast.Expr
 	tok := p.curr()
+	start := tok.Start
 	switch tok.Tok {
 	case lexer.IDENT:
 		name := tok.Lit
@@ -2187,17 +2443,25 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 		}
 		p.next() // consume ']'
 
-		// Expect () for channel creation
+		// Expect () or (capacity) for channel creation
 		if p.curr().Tok != lexer.LPAREN {
 			return nil, p.errf("expected '()' after channel[Type]")
 		}
 		p.next() // consume '('
+		var capacity ast.Expr
+		if p.curr().Tok != lexer.RPAREN {
+			c, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			capacity = c
+		}
 		if p.curr().Tok != lexer.RPAREN {
 			return nil, p.errf("expected ')' in channel[Type]()")
 		}
 		p.next() // consume ')'
 
-		left = &ast.ChannelExpr{ElemType: elemType}
+		left = &ast.ChannelExpr{ElemType: elemType, Capacity: capacity}
 	case lexer.MINUS, lexer.NOT:
 		p.next()
 		x, err := p.parseExpr(precUnary)
@@ -2215,7 +2479,7 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 		var elems []ast.Expr
 		if p.curr().Tok != lexer.RBRACK {
 			for {
-				e, err := p.parseExpr(0)
+				e, err := p.parseElement()
 				if err != nil {
 					return nil, err
 				}
@@ -2283,6 +2547,8 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 		return nil, p.errf("unexpected %s, expected expression", tokenName)
 	}
 
+	p.span(left, start)
+
 	// Parse infix/postfix
 	for {
 		tok = p.curr()
@@ -2292,30 +2558,22 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 		// call
 		if tok.Tok == lexer.LPAREN {
 			p.next()
-			var args []ast.Expr
-			if p.curr().Tok != lexer.RPAREN {
-				for {
-					e, err := p.parseExpr(0)
-					if err != nil {
-						return nil, err
-					}
-					args = append(args, e)
-					if p.accept(lexer.COMMA) {
-						continue
-					}
-					break
-				}
+			args, namedArgs, err := p.parseCallArgs()
+			if err != nil {
+				return nil, err
 			}
 			if !p.accept(lexer.RPAREN) {
 				return nil, p.errf("expected ')'")
 			}
-			left = &ast.CallExpr{Callee: left, Args: args}
+			left = &ast.CallExpr{Callee: left, Args: args, NamedArgs: namedArgs}
+			p.span(left, start)
 			continue
 		}
 
 		// index
 		if tok.Tok == lexer.LBRACK {
 			p.next()
+			idxStart := p.curr().Start
 			idx, err := p.parseExpr(0)
 			if err != nil {
 				return nil, err
@@ -2329,17 +2587,19 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 					return nil, err
 				}
 				idx = &ast.RangeExpr{Start: idx, End: endExpr, Inclusive: true}
+				p.span(idx, idxStart)
 			}
 
 			if !p.accept(lexer.RBRACK) {
 				return nil, p.errf("expected ']' in index expression")
 			}
 			left = &ast.IndexExpr{X: left, Index: idx}
+			p.span(left, start)
 			continue
 		}
 
-		// field access or method call
-		if tok.Tok == lexer.DOT {
+		// field access or method call, optionally null-safe with ?.
+		if tok.Tok == lexer.DOT || tok.Tok == lexer.QUESTION_DOT {
 			p.next()
 			id := p.curr()
 			var fieldName string
@@ -2354,12 +2614,36 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 				fieldName = "catch" // allow .catch() method calls
 			case lexer.KW_FINALLY:
 				fieldName = "finally" // allow .finally() method calls
+			case lexer.KW_STATIC:
+				fieldName = "static" // allow server.static() method calls
 			default:
-				return nil, p.errf("expected field or method name after '.', got token: %v", id.Tok)
+				return nil, p.errf("expected field or method name after %s, got token: %v", lexer.TokenName(tok.Tok), id.Tok)
 			}
 
 			p.next()
-			left = &ast.FieldExpr{X: left, Name: fieldName}
+			left = &ast.FieldExpr{X: left, Name: fieldName, Optional: tok.Tok == lexer.QUESTION_DOT}
+			p.span(left, start)
+
+			// Method call with explicit type arguments: obj.method<Int>(x)
+			if p.curr().Tok == lexer.LT {
+				saved := p.pos
+				typeArgs, err := p.tryParseGenericTypeParams()
+				if err == nil && typeArgs != nil && p.curr().Tok == lexer.LPAREN {
+					p.next() // consume '('
+					args, namedArgs, err := p.parseCallArgs()
+					if err != nil {
+						return nil, err
+					}
+					if !p.accept(lexer.RPAREN) {
+						return nil, p.errf("expected ')' after method type arguments")
+					}
+					left = &ast.CallExpr{Callee: left, Args: args, NamedArgs: namedArgs, TypeArgs: typeArgs}
+					p.span(left, start)
+				} else {
+					// Not a type argument list, so '<' is a comparison
+					p.pos = saved
+				}
+			}
 			continue
 		}
 
@@ -2368,15 +2652,9 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 			break
 		}
 
-		// Special handling for instanceof as operator (not method call)
-		// Only process if we're not immediately after a field access
+		// Special handling for instanceof as operator. Obj.instanceof(...) method
+		// calls never get here: the field access above consumes the keyword.
 		if tok.Tok == lexer.KW_INSTANCEOF {
-			// Check if the left side is a field access to avoid conflicts with method calls
-			if _, isFieldExpr := left.(*ast.FieldExpr); isFieldExpr {
-				// This is likely Obj.instanceof(...) method call, don't treat as operator
-				break
-			}
-
 			p.next() // consume 'instanceof'
 			if p.curr().Tok != lexer.IDENT {
 				return nil, p.errf("expected type name after 'instanceof'")
@@ -2412,6 +2690,7 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 				Variable: variable,
 				Modifier: modifier,
 			}
+			p.span(left, start)
 			continue
 		}
 
@@ -2442,6 +2721,19 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 				TrueBranch:  trueBranch,
 				FalseBranch: falseBranch,
 			}
+			p.span(left, start)
+			continue
+		}
+
+		// Pipe operator: x |> f(a) becomes f(x, a), x |> f becomes f(x)
+		if tok.Tok == lexer.PIPE_GT {
+			p.next() // consume '|>'
+			right, err := p.parseExpr(prec + 1)
+			if err != nil {
+				return nil, err
+			}
+			left = pipeCall(left, right)
+			p.span(left, start)
 			continue
 		}
 
@@ -2463,20 +2755,96 @@ func (p *Parser) parseExpr(minPrec int) (ast.Expr, error) {
 				End:       right,
 				Inclusive: true,
 			}
+			p.span(left, start)
 			continue
 		}
 
 		op := tok
 		p.next()
-		right, err := p.parseExpr(prec + 1)
+		// ** is right-associative: 2 ** 3 ** 2 is 2 ** (3 ** 2)
+		rightPrec := prec + 1
+		if op.Tok == lexer.STAR_STAR {
+			rightPrec = prec
+		}
+		right, err := p.parseExpr(rightPrec)
 		if err != nil {
 			return nil, err
 		}
 		left = &ast.BinaryExpr{Op: p.toOp(op.Tok), Lhs: left, Rhs: right}
+		p.span(left, start)
 	}
 	return left, nil
 }
 
+// parseCallArgs parses the arguments of a call up to the closing ')'. Keyword
+// arguments (name: value) may follow the positional ones but not precede them.
+func (p *Parser) parseCallArgs() ([]ast.Expr, []ast.NamedArg, error) {
+	var args []ast.Expr
+	var namedArgs []ast.NamedArg
+	if p.curr().Tok == lexer.RPAREN {
+		return args, namedArgs, nil
+	}
+	for {
+		if p.curr().Tok == lexer.IDENT && len(p.items) > p.pos+1 && p.items[p.pos+1].Tok == lexer.COLON {
+			name := p.curr().Lit
+			for _, named := range namedArgs {
+				if named.Name == name {
+					return nil, nil, p.errf("keyword argument '%s' repeated", name)
+				}
+			}
+			p.next() // name
+			p.next() // ':'
+			e, err := p.parseExpr(0)
+			if err != nil {
+				return nil, nil, err
+			}
+			namedArgs = append(namedArgs, ast.NamedArg{Name: name, Value: e})
+		} else {
+			if len(namedArgs) > 0 {
+				return nil, nil, p.errf("positional argument cannot follow keyword arguments")
+			}
+			e, err := p.parseElement()
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, e)
+		}
+		if !p.accept(lexer.COMMA) {
+			return args, namedArgs, nil
+		}
+	}
+}
+
+// parseElement parses an array literal element or call argument, which may be
+// spread with a leading '...'
+func (p *Parser) parseElement() (ast.Expr, error) {
+	start := p.curr().Start
+	if !p.accept(lexer.ELLIPSIS) {
+		return p.parseExpr(0)
+	}
+	x, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	spread := &ast.SpreadExpr{X: x}
+	p.span(spread, start)
+	return spread, nil
+}
+
+// pipeCall desugars lhs |> rhs. A call on the right receives lhs as its first
+// argument; any other expression is called with lhs as its only argument.
+func pipeCall(lhs, rhs ast.Expr) ast.Expr {
+	switch call := rhs.(type) {
+	case *ast.CallExpr:
+		args := append([]ast.Expr{lhs}, call.Args...)
+		return &ast.CallExpr{Callee: call.Callee, Args: args, NamedArgs: call.NamedArgs, TypeArgs: call.TypeArgs}
+	case *ast.GenericCallExpr:
+		args := append([]ast.Expr{lhs}, call.Args...)
+		return &ast.GenericCallExpr{Name: call.Name, TypeParams: call.TypeParams, Args: args}
+	}
+	return &ast.CallExpr{Callee: rhs, Args: []ast.Expr{lhs}}
+}
+
 // parseParenthesized handles parentheses in expressions:
 // - Grouped expressions: (expr)
 // - Lambda expressions: (a, b) => expr
@@ -2587,11 +2955,10 @@ func (p *Parser) parseParenthesized() (ast.Expr, error) {
 			var paramType string
 			var isVariadic bool
 			if p.accept(lexer.COLON) {
-				if p.curr().Tok != lexer.IDENT {
+				if !p.atTypeStart() {
 					break // Not a valid lambda parameter
 				}
-				paramType = p.curr().Lit
-				p.next()
+				paramType = p.parseTypeName()
 
 				// Check for variadic parameter (type...)
 				if p.accept(lexer.ELLIPSIS) {
@@ -2649,11 +3016,10 @@ func (p *Parser) parseParenthesized() (ast.Expr, error) {
 				var paramType string
 				var isVariadic bool
 				if p.accept(lexer.COLON) {
-					if p.curr().Tok != lexer.IDENT {
+					if !p.atTypeStart() {
 						return nil, p.errf("expected parameter type")
 					}
-					paramType = p.curr().Lit
-					p.next()
+					paramType = p.parseTypeName()
 
 					// Check for variadic parameter (type...)
 					if p.accept(lexer.ELLIPSIS) {
@@ -2756,6 +3122,8 @@ func unquote(q string) (string, error) {
 						b = append(b, '\n')
 					case 't':
 						b = append(b, '\t')
+					case 'r':
+						b = append(b, '\r')
 					case '"':
 						b = append(b, '"')
 					case '\\':
@@ -2786,6 +3154,8 @@ func unquote(q string) (string, error) {
 						b = append(b, '\n')
 					case 't':
 						b = append(b, '\t')
+					case 'r':
+						b = append(b, '\r')
 					case '\'':
 						b = append(b, '\'')
 					case '\\':
@@ -2822,6 +3192,8 @@ func (p *Parser) toOp(tok lexer.Token) int {
 		return ast.OpDiv
 	case lexer.PERCENT:
 		return ast.OpMod
+	case lexer.STAR_STAR:
+		return ast.OpPow
 	case lexer.EQ:
 		return ast.OpEq
 	case lexer.NEQ:
@@ -2838,6 +3210,8 @@ func (p *Parser) toOp(tok lexer.Token) int {
 		return ast.OpAnd
 	case lexer.OR:
 		return ast.OpOr
+	case lexer.QUESTION_QUESTION:
+		return ast.OpNilCoalesce
 	default:
 		return 0
 	}
@@ -3256,6 +3630,38 @@ func (p *Parser) parseDefer() (ast.Stmt, error) {
 	return &ast.DeferStmt{Call: expr, Pos: pos}, nil
 }
 
+// parseYield parses a yield statement
+func (p *Parser) parseYield() (ast.Stmt, error) {
+	p.next() // consume 'yield'
+
+	expr, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.YieldStmt{Value: expr}, nil
+}
+
+// parseAssert parses an assert statement: assert cond or assert cond, message
+func (p *Parser) parseAssert() (ast.Stmt, error) {
+	p.next() // consume 'assert'
+
+	cond, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+
+	var message ast.Expr
+	if p.accept(lexer.COMMA) {
+		message, err = p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ast.AssertStmt{Cond: cond, Message: message}, nil
+}
+
 // parseSelect parses a select statement
 // select
 //
@@ -3458,16 +3864,30 @@ func (p *Parser) parseSwitch() (ast.Stmt, error) {
 			// Check if inline (same line as colon)
 			if p.isOnSameLine(colonLine) {
 				// Inline case: parse single statement
-				stmt, err := p.parseStmt()
-				if err != nil {
-					return nil, err
-				}
-				if stmt != nil {
-					switchCase.Body = append(switchCase.Body, stmt)
+				if p.curr().Tok == lexer.KW_FALLTHROUGH {
+					if err := p.parseFallthrough(); err != nil {
+						return nil, err
+					}
+					switchCase.Fallthrough = true
+				} else {
+					stmt, err := p.parseStmt()
+					if err != nil {
+						return nil, err
+					}
+					if stmt != nil {
+						switchCase.Body = append(switchCase.Body, stmt)
+					}
 				}
 			} else {
 				// Multi-line case: parse until next case, default, or end
 				for p.curr().Tok != lexer.KW_CASE && p.curr().Tok != lexer.KW_DEFAULT && p.curr().Tok != lexer.KW_END && p.curr().Tok != lexer.EOF {
+					if p.curr().Tok == lexer.KW_FALLTHROUGH {
+						if err := p.parseFallthrough(); err != nil {
+							return nil, err
+						}
+						switchCase.Fallthrough = true
+						break
+					}
 					stmt, err := p.parseStmt()
 					if err != nil {
 						return nil, err
@@ -3530,6 +3950,20 @@ func (p *Parser) parseSwitch() (ast.Stmt, error) {
 	}, nil
 }
 
+// parseFallthrough consumes a `fallthrough` statement, which must end a case body
+// that is followed by another case or the default case
+func (p *Parser) parseFallthrough() error {
+	p.next() // consume 'fallthrough'
+	switch p.curr().Tok {
+	case lexer.KW_CASE, lexer.KW_DEFAULT:
+		return nil
+	case lexer.KW_END, lexer.EOF:
+		return p.errf("cannot fallthrough from the final case of a switch")
+	default:
+		return p.errf("'fallthrough' must be the last statement of a switch case")
+	}
+}
+
 // parseBlockUntilKeywords parses statements until one of the specified keywords is found
 func (p *Parser) parseBlockUntilKeywords(keywords []lexer.Token) ([]ast.Stmt, error) {
 	var stmts []ast.Stmt
@@ -3557,6 +3991,64 @@ func (p *Parser) parseBlockUntilKeywords(keywords []lexer.Token) ([]ast.Stmt, er
 	return stmts, nil
 }
 
+// atTypeStart reports whether the current token can begin a type annotation
+func (p *Parser) atTypeStart() bool {
+	return p.curr().Tok == lexer.IDENT || p.curr().Tok == lexer.LPAREN
+}
+
+// parseTypeName parses a type annotation starting at an identifier, including
+// optional type arguments (e.g., Int, List<Animal>, List<? extends Animal>),
+// or a function type such as (Int, Int) -> Int
+func (p *Parser) parseTypeName() string {
+	if p.curr().Tok == lexer.LPAREN {
+		return p.parseFunctionTypeName()
+	}
+	name := p.curr().Lit
+	p.next()
+	if p.curr().Tok == lexer.LT {
+		if args, err := p.tryParseGenericTypeParams(); err == nil && args != nil {
+			return ast.FormatGenericType(name, args)
+		}
+	}
+	return name
+}
+
+// parseFunctionTypeName parses a function type like (Int, String) -> Bool.
+// On malformed input it restores the position and returns "" so the caller reports the error.
+func (p *Parser) parseFunctionTypeName() string {
+	saved := p.pos
+	p.next() // consume '('
+
+	var params []string
+	if p.curr().Tok != lexer.RPAREN {
+		for {
+			if !p.atTypeStart() {
+				p.pos = saved
+				return ""
+			}
+			param := p.parseTypeName()
+			if param == "" {
+				p.pos = saved
+				return ""
+			}
+			params = append(params, param)
+			if !p.accept(lexer.COMMA) {
+				break
+			}
+		}
+	}
+	if !p.accept(lexer.RPAREN) || !p.accept(lexer.RARROW) || !p.atTypeStart() {
+		p.pos = saved
+		return ""
+	}
+	ret := p.parseTypeName()
+	if ret == "" {
+		p.pos = saved
+		return ""
+	}
+	return ast.FormatFunctionType(params, ret)
+}
+
 // tryParseGenericTypeParams attempts to parse generic type parameters like <Int> or <String, Int> or <? extends Number>
 // Returns nil if this is not a generic type (likely a comparison operator instead)
 func (p *Parser) tryParseGenericTypeParams() ([]ast.TypeParam, error) {
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/ast"
+	"github.com/ArubikU/polyloft/internal/lexer"
+)
+
+// missingPositions walks every node reachable from v and reports the expressions
+// without a span and the statements and class members without a start position
+func missingPositions(v reflect.Value, path string, missing *[]string) {
+	switch v.Kind() {
+	case reflect.Interface:
+		missingPositions(v.Elem(), path, missing)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if node, ok := v.Interface().(ast.Node); ok {
+			if spanned, ok := node.(ast.Spannable); ok {
+				if from, to := spanned.Span(); from.Line == 0 || to.Offset <= from.Offset {
+					*missing = append(*missing, fmt.Sprintf("%s: %T has no span", path, node))
+				}
+			}
+			if located, ok := node.(ast.Locatable); ok && located.StartPos().Line == 0 {
+				*missing = append(*missing, fmt.Sprintf("%s: %T has no start position", path, node))
+			}
+		}
+		if _, isType := v.Interface().(*ast.Type); isType {
+			return
+		}
+		missingPositions(v.Elem(), path, missing)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				missingPositions(v.Field(i), path+"."+t.Field(i).Name, missing)
+			}
+		}
+		if v.CanAddr() {
+			// Class members are stored by value
+			switch m := v.Addr().Interface().(type) {
+			case *ast.FieldDecl, *ast.MethodDecl:
+				if m.(ast.Locatable).StartPos().Line == 0 {
+					*missing = append(*missing, fmt.Sprintf("%s: %T has no start position", path, m))
+				}
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			missingPositions(v.Index(i), fmt.Sprintf("%s[%d]", path, i), missing)
+		}
+	}
+}
+
+func TestParsePositionsOnAllNodes(t *testing.T) {
+	input := `
+import math { sqrt }
+
+class Shape:
+    private var name: String = "shape"
+    Shape(name: String):
+        this.name = name
+    end
+    def area() -> Float:
+        return 0.0
+    end
+end
+
+enum Color
+    RED, GREEN
+end
+
+def describe(x, opts: Int...):
+    let label = x instanceof Shape ? "shape" : "other"
+    const parts = [1, 2.5, 0x1F, "a", true, nil, ...opts]
+    var info = {name: label, size: parts.length()}
+    if x == nil:
+        throw "missing"
+    elif -x > 3 && !false:
+        return parts[0...1]
+    else:
+        info["size"] += 1
+    end
+    for i in 1...3 where i != 2:
+        continue
+    end
+    loop i < 3:
+        break
+    end
+    try
+        defer print(info?.name ?? "none")
+        let f = (a: Int, b: Int) => a + b |> print
+        return f(1, b: 2)
+    catch e: RuntimeError
+        return List<Int>()
+    end
+    switch x:
+        case 1, 2:
+            return Color.RED
+        default:
+            return thread spawn do
+                return channel[Int](1)
+            end
+    end
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := New(lx.Scan([]byte(input))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	var missing []string
+	missingPositions(reflect.ValueOf(prog.Stmts), "Stmts", &missing)
+	for _, m := range missing {
+		t.Error(m)
+	}
+}
+
+func TestParseExpressionSpans(t *testing.T) {
+	tests := []struct {
+		input    string
+		from, to int // columns of the expression statement's value
+	}{
+		{`x = [1, 2]`, 5, 11},
+		{`x = {a: 1}`, 5, 11},
+		{`x = "hi"`, 5, 9},
+		{`x = a ? b : c`, 5, 14},
+		{`x = 1...10`, 5, 11},
+		{`x = (a) => a`, 5, 13},
+		{`x = y instanceof Int`, 5, 21},
+	}
+	for _, tt := range tests {
+		lx := &lexer.Lexer{}
+		prog, err := New(lx.Scan([]byte(tt.input))).Parse()
+		if err != nil {
+			t.Fatalf("%q: parse error: %v", tt.input, err)
+		}
+		assign, ok := prog.Stmts[0].(*ast.AssignStmt)
+		if !ok {
+			t.Fatalf("%q: expected AssignStmt, got %T", tt.input, prog.Stmts[0])
+		}
+		from, to := assign.Value.(ast.Spannable).Span()
+		if from.Col != tt.from || to.Col != tt.to {
+			t.Errorf("%q: span is %d-%d, want %d-%d", tt.input, from.Col, to.Col, tt.from, tt.to)
+		}
+	}
+}
@@ -164,3 +164,71 @@ end
 		t.Fatalf("Expected default case to have statements")
 	}
 }
+
+func TestParseSwitchRangeAndFallthrough(t *testing.T) {
+	input := `
+switch x:
+    case 1...5:
+        println("low")
+        fallthrough
+    case 6: fallthrough
+    default:
+        println("other")
+end
+`
+	lx := &lexer.Lexer{}
+	prog, err := New(lx.Scan([]byte(input))).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	switchStmt := prog.Stmts[0].(*ast.SwitchStmt)
+	if _, ok := switchStmt.Cases[0].Values[0].(*ast.RangeExpr); !ok {
+		t.Fatalf("Expected RangeExpr case value, got %T", switchStmt.Cases[0].Values[0])
+	}
+	if !switchStmt.Cases[0].Fallthrough || len(switchStmt.Cases[0].Body) != 1 {
+		t.Fatalf("Expected first case to fall through after 1 statement")
+	}
+	if !switchStmt.Cases[1].Fallthrough || len(switchStmt.Cases[1].Body) != 0 {
+		t.Fatalf("Expected inline fallthrough in second case")
+	}
+}
+
+func TestParseSwitchFallthroughErrors(t *testing.T) {
+	tests := map[string]string{
+		"not last in case": `
+switch x:
+    case 1:
+        fallthrough
+        println("after")
+    case 2:
+        println("two")
+end
+`,
+		"final case": `
+switch x:
+    case 1:
+        fallthrough
+end
+`,
+		"nested in block": `
+switch x:
+    case 1:
+        if y:
+            fallthrough
+        end
+    case 2:
+        println("two")
+end
+`,
+		"outside switch": `fallthrough`,
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			lx := &lexer.Lexer{}
+			if _, err := New(lx.Scan([]byte(input))).Parse(); err == nil {
+				t.Fatalf("Expected parse error, got nil")
+			}
+		})
+	}
+}
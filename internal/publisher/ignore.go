@@ -0,0 +1,84 @@
+package publisher
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// IgnoreFileName lists the files publish leaves out of the package archive
+const IgnoreFileName = ".polyloftignore"
+
+// ignorePattern is one line of an ignore file
+type ignorePattern struct {
+	pattern  string
+	dirOnly  bool // written with a trailing "/": matches directories only
+	anchored bool // contains a "/": matched against the whole path from the project root
+}
+
+// ignoreRules holds the patterns of an ignore file. They follow .gitignore: a
+// pattern without a slash matches a file or directory name at any depth, one
+// with a slash matches a path from the project root, and a trailing slash
+// matches only directories. Blank lines and lines starting with "#" are skipped.
+type ignoreRules struct {
+	patterns []ignorePattern
+}
+
+// loadIgnoreRules reads an ignore file; a missing file ignores nothing
+func loadIgnoreRules(file string) (*ignoreRules, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return &ignoreRules{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer f.Close()
+
+	rules := &ignoreRules{}
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var p ignorePattern
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if _, err := path.Match(line, ""); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern %q", file, lineNo, scanner.Text())
+		}
+		p.pattern = line
+		rules.patterns = append(rules.patterns, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return rules, nil
+}
+
+// match reports whether a slash-separated path relative to the project root is
+// ignored. Files inside an ignored directory are skipped by not walking it.
+func (r *ignoreRules) match(rel string, isDir bool) bool {
+	for _, p := range r.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		target := path.Base(rel)
+		if p.anchored {
+			target = rel
+		}
+		if ok, _ := path.Match(p.pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
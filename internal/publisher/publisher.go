@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,12 +18,15 @@ import (
 
 	"github.com/ArubikU/polyloft/internal/auth"
 	"github.com/ArubikU/polyloft/internal/config"
+	"github.com/ArubikU/polyloft/internal/searcher"
+	"github.com/ArubikU/polyloft/internal/semver"
 )
 
 // Publisher handles publishing packages to the registry
 type Publisher struct {
 	cfg         *config.Config
 	registryURL string
+	dryRun      bool
 }
 
 // New creates a new publisher
@@ -33,6 +37,12 @@ func New(cfg *config.Config) *Publisher {
 	}
 }
 
+// SetDryRun makes Publish build and check the package and print what it would
+// upload, without uploading it
+func (p *Publisher) SetDryRun(dryRun bool) {
+	p.dryRun = dryRun
+}
+
 // Publish publishes the current package to the registry
 func (p *Publisher) Publish() error {
 	// Check authentication
@@ -49,7 +59,11 @@ func (p *Publisher) Publish() error {
 	fmt.Println("📦 Packaging files...")
 	
 	// Create package archive
-	archiveData, checksum, err := p.createArchive()
+	files, err := p.collectFiles()
+	if err != nil {
+		return fmt.Errorf("failed to collect package files: %w", err)
+	}
+	archiveData, checksum, err := p.createArchive(files)
 	if err != nil {
 		return fmt.Errorf("failed to create package archive: %w", err)
 	}
@@ -57,6 +71,10 @@ func (p *Publisher) Publish() error {
 	fmt.Printf("   Archive size: %d bytes\n", len(archiveData))
 	fmt.Printf("   Checksum: %s\n", checksum)
 
+	if p.dryRun {
+		return p.preview(creds.Username, files)
+	}
+
 	// Prepare package metadata
 	metadata := map[string]interface{}{
 		"name":        p.cfg.Project.Name,
@@ -73,29 +91,60 @@ func (p *Publisher) Publish() error {
 	return p.uploadPackage(metadata, creds.Token)
 }
 
-// createArchive creates a tar.gz archive of the package files
-func (p *Publisher) createArchive() ([]byte, string, error) {
-	var buf bytes.Buffer
-	gzWriter := gzip.NewWriter(&buf)
-	tarWriter := tar.NewWriter(gzWriter)
-	
-	// Get the directory containing the entry point
-	baseDir := filepath.Dir(p.cfg.Project.EntryPoint)
-	if baseDir == "." {
-		baseDir = ""
+// preview prints what a dry run would upload and checks with the registry's
+// read-only endpoints that the version is not taken yet
+func (p *Publisher) preview(author string, files []string) error {
+	name, version := p.cfg.Project.Name, p.cfg.Project.Version
+	fmt.Println("🔍 Dry run: nothing will be uploaded")
+	fmt.Printf("   Package: %s@%s\n", name, author)
+	fmt.Printf("   Version: %s\n", version)
+	fmt.Printf("   Files (%d):\n", len(files))
+	for _, file := range files {
+		fmt.Printf("     %s\n", filepath.ToSlash(file))
 	}
-	
+
+	published, err := searcher.New().Versions(name, author)
+	if errors.Is(err, searcher.ErrPackageNotFound) {
+		fmt.Printf("✓ %s@%s is not in the registry yet; this would be its first version\n", name, author)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check published versions: %w", err)
+	}
+	for _, existing := range published {
+		if existing == version {
+			return fmt.Errorf("version %s of %s@%s is already published", version, name, author)
+		}
+	}
+	fmt.Printf("✓ Version %s of %s@%s is available\n", version, name, author)
+	return nil
+}
+
+// collectFiles lists the files to publish: the entry point, polyloft.toml and
+// every .pf file in the project not excluded by .polyloftignore
+func (p *Publisher) collectFiles() ([]string, error) {
+	ignore, err := loadIgnoreRules(IgnoreFileName)
+	if err != nil {
+		return nil, err
+	}
+
 	// Collect files to include
 	filesToInclude := []string{
-		p.cfg.Project.EntryPoint,
+		filepath.Clean(p.cfg.Project.EntryPoint),
 		"polyloft.toml",
 	}
 	
 	// Add all .pf files in the project directory
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if path != "." && ignore.match(filepath.ToSlash(path), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		
 		// Skip directories and non-.pf files (except already included)
 		if info.IsDir() {
@@ -121,14 +170,22 @@ func (p *Publisher) createArchive() ([]byte, string, error) {
 	})
 	
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to walk directory: %w", err)
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
 	}
+	return filesToInclude, nil
+}
+
+// createArchive creates a tar.gz archive of the package files
+func (p *Publisher) createArchive(filesToInclude []string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzWriter)
 	
 	// Add files to archive
 	for _, filePath := range filesToInclude {
 		if err := p.addFileToArchive(tarWriter, filePath); err != nil {
 			// If file doesn't exist, skip it (except for required files)
-			if filePath == p.cfg.Project.EntryPoint || filePath == "polyloft.toml" {
+			if filePath == filepath.Clean(p.cfg.Project.EntryPoint) || filePath == "polyloft.toml" {
 				return nil, "", fmt.Errorf("required file not found: %s", filePath)
 			}
 		}
@@ -191,10 +248,9 @@ func (p *Publisher) validateConfig() error {
 		return fmt.Errorf("project entry_point is required")
 	}
 
-	// Validate version format (basic semver check)
-	// TODO: More robust semver validation
-	if len(p.cfg.Project.Version) == 0 {
-		return fmt.Errorf("version cannot be empty")
+	// Installs resolve version ranges, so the version must be semantic
+	if _, err := semver.Parse(p.cfg.Project.Version); err != nil {
+		return err
 	}
 
 	// Check if entry point file exists
@@ -0,0 +1,116 @@
+package publisher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ArubikU/polyloft/internal/auth"
+	"github.com/ArubikU/polyloft/internal/config"
+)
+
+// writeProject creates a project in a temporary directory and changes into it
+func writeProject(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestCollectFilesHonorsIgnore(t *testing.T) {
+	writeProject(t, map[string]string{
+		"polyloft.toml":     "",
+		"src/main.pf":       "",
+		"src/util.pf":       "",
+		"src/util_test.pf":  "",
+		"lib/dep/dep.pf":    "",
+		"examples/demo.pf":  "",
+		"scratch.pf":        "",
+		"notes/scratch.pf":  "",
+		".polyloftignore":   "# local only\nlib/\n*_test.pf\n/scratch.pf\nexamples\n",
+		"src/ignored.txt":   "",
+		"src/main_test.txt": "",
+	})
+
+	pub := New(&config.Config{Project: config.ProjectConfig{Name: "demo", Version: "1.0.0", EntryPoint: "src/main.pf"}})
+	files, err := pub.collectFiles()
+	if err != nil {
+		t.Fatalf("collectFiles: %v", err)
+	}
+	var got []string
+	for _, file := range files {
+		got = append(got, filepath.ToSlash(file))
+	}
+	want := []string{"src/main.pf", "polyloft.toml", "notes/scratch.pf", "src/util.pf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestPublishDryRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := auth.SaveCredentials(&auth.Credentials{Username: "alice", Token: "token"}); err != nil {
+		t.Fatal(err)
+	}
+	writeProject(t, map[string]string{
+		"polyloft.toml": "",
+		"src/main.pf":   "println(1)\n",
+	})
+
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			uploads++
+		case r.URL.Path == "/api/packages/alice/demo/versions":
+			json.NewEncoder(w).Encode(map[string][]string{"versions": {"1.0.0"}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("POLYLOFT_REGISTRY_URL", server.URL)
+
+	publish := func(name, version string) error {
+		pub := New(&config.Config{Project: config.ProjectConfig{Name: name, Version: version, EntryPoint: "src/main.pf"}})
+		pub.SetDryRun(true)
+		return pub.Publish()
+	}
+
+	if err := publish("demo", "1.1.0"); err != nil {
+		t.Errorf("dry run of a new version failed: %v", err)
+	}
+	if err := publish("fresh", "0.1.0"); err != nil {
+		t.Errorf("dry run of a new package failed: %v", err)
+	}
+	err := publish("demo", "1.0.0")
+	if err == nil || !strings.Contains(err.Error(), "already published") {
+		t.Errorf("expected an already published error, got %v", err)
+	}
+	if err := publish("demo", "1.0"); err == nil {
+		t.Error("expected a non-semantic version to be rejected")
+	}
+	if uploads != 0 {
+		t.Errorf("dry run uploaded %d times", uploads)
+	}
+}
@@ -0,0 +1,80 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory is the number of entries kept in the history file
+const maxHistory = 1000
+
+// history holds the lines entered in previous and current sessions, oldest first.
+// When path is set, new entries are appended to that file as they are added.
+type history struct {
+	entries []string
+	path    string
+}
+
+// historyPath returns ~/.polyloft/repl_history, honouring POLYLOFT_HOME like the
+// rest of the CLI. It returns "" when no home directory can be determined.
+func historyPath() string {
+	home := os.Getenv("POLYLOFT_HOME")
+	if home == "" {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return ""
+		}
+	}
+	return filepath.Join(home, ".polyloft", "repl_history")
+}
+
+// loadHistory reads the history file at path; a missing file is an empty history.
+// Files that grew past maxHistory entries are trimmed.
+func loadHistory(path string) *history {
+	h := &history{path: path}
+	if path == "" {
+		return h
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if line := s.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	f.Close()
+
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+		_ = os.WriteFile(path, []byte(strings.Join(h.entries, "\n")+"\n"), 0600)
+	}
+	return h
+}
+
+// add records line, skipping blank lines and repeats of the previous entry
+func (h *history) add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return
+	}
+	h.entries = append(h.entries, line)
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.WriteString(line + "\n")
+}
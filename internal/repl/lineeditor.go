@@ -0,0 +1,181 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// errInterrupt is returned by readLine when the user presses Ctrl-C
+var errInterrupt = errors.New("interrupt")
+
+// lineReader reads input one line at a time
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+// newLineReader returns a line editor with persistent history when in is a
+// terminal, and a plain line scanner otherwise (pipes, files, tests)
+func newLineReader(in io.Reader, out io.Writer) lineReader {
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return &terminalReader{
+			fd:      int(f.Fd()),
+			in:      bufio.NewReader(f),
+			out:     out,
+			history: loadHistory(historyPath()),
+		}
+	}
+	return &scanReader{s: bufio.NewScanner(in), out: out}
+}
+
+// scanReader reads lines from non-interactive input
+type scanReader struct {
+	s   *bufio.Scanner
+	out io.Writer
+}
+
+func (r *scanReader) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.s.Scan() {
+		if err := r.s.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.s.Text(), nil
+}
+
+// terminalReader edits lines in raw mode: left/right move the cursor, up/down walk
+// the history, Ctrl-A/Ctrl-E jump to the start/end, Ctrl-U clears the line,
+// Ctrl-C cancels the input and Ctrl-D on an empty line ends the session.
+// The terminal is only in raw mode while a line is being read.
+type terminalReader struct {
+	fd      int
+	in      *bufio.Reader
+	out     io.Writer
+	history *history
+}
+
+func (r *terminalReader) readLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(r.fd, state)
+
+	var line []rune
+	pos := 0
+	index := len(r.history.entries) // history entry shown; len(entries) is the line being typed
+	draft := ""                     // the line being typed, kept while browsing the history
+
+	showEntry := func(i int) {
+		if index == len(r.history.entries) {
+			draft = string(line)
+		}
+		index = i
+		if index == len(r.history.entries) {
+			line = []rune(draft)
+		} else {
+			line = []rune(r.history.entries[index])
+		}
+		pos = len(line)
+	}
+	redraw := func() {
+		fmt.Fprintf(r.out, "\r%s%s\x1b[K", prompt, string(line))
+		if back := len(line) - pos; back > 0 {
+			fmt.Fprintf(r.out, "\x1b[%dD", back)
+		}
+	}
+
+	redraw()
+	for {
+		c, _, err := r.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '\r', '\n':
+			fmt.Fprint(r.out, "\r\n")
+			r.history.add(string(line))
+			return string(line), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(r.out, "^C\r\n")
+			return "", errInterrupt
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Fprint(r.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case 1: // Ctrl-A
+			pos = 0
+		case 5: // Ctrl-E
+			pos = len(line)
+		case 21: // Ctrl-U
+			line, pos = nil, 0
+		case 127, 8: // Backspace
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case 27: // Escape sequence: arrows, Home, End, Delete
+			switch r.readEscape() {
+			case "A":
+				if index > 0 {
+					showEntry(index - 1)
+				}
+			case "B":
+				if index < len(r.history.entries) {
+					showEntry(index + 1)
+				}
+			case "C":
+				if pos < len(line) {
+					pos++
+				}
+			case "D":
+				if pos > 0 {
+					pos--
+				}
+			case "H", "1~", "7~":
+				pos = 0
+			case "F", "4~", "8~":
+				pos = len(line)
+			case "3~":
+				if pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+				}
+			}
+		default:
+			if c >= ' ' {
+				line = append(line[:pos], append([]rune{c}, line[pos:]...)...)
+				pos++
+			}
+		}
+		redraw()
+	}
+}
+
+// readEscape reads the rest of an ESC [ or ESC O sequence and returns its final
+// part, e.g. "A" for the up arrow or "3~" for Delete
+func (r *terminalReader) readEscape() string {
+	if b, err := r.in.ReadByte(); err != nil || (b != '[' && b != 'O') {
+		return ""
+	}
+	var seq []byte
+	for {
+		b, err := r.in.ReadByte()
+		if err != nil {
+			return ""
+		}
+		seq = append(seq, b)
+		if (b >= 'A' && b <= 'Z') || b == '~' {
+			return string(seq)
+		}
+	}
+}
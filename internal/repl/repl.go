@@ -1,58 +1,105 @@
 package repl
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 
+	"github.com/ArubikU/polyloft/internal/ast"
 	"github.com/ArubikU/polyloft/internal/engine"
 	"github.com/ArubikU/polyloft/internal/lexer"
 	"github.com/ArubikU/polyloft/internal/parser"
 )
 
-// Start launches a minimal line-oriented REPL with simple evaluation.
+// Start launches a line-oriented REPL. Every entry runs in the same session, so
+// definitions carry over between entries. Input that is not complete yet, such as
+// an `if ... :` without its `end`, is continued on the next line. When in is a
+// terminal, entered lines are saved to ~/.polyloft/repl_history and can be
+// recalled with the arrow keys.
 // Meta commands:
 //
 //	:quit  - exit the REPL
 //	:help  - show brief help
 func Start(in io.Reader, out io.Writer, prompt string) {
-	s := bufio.NewScanner(in)
+	reader := newLineReader(in, out)
+	session := engine.NewSession(engine.Options{Stdout: out})
+
+	// "... " for the default ">>> " prompt
+	trimmed := strings.TrimRight(prompt, " ")
+	continuation := strings.Repeat(".", len(trimmed)) + prompt[len(trimmed):]
+
+	var pending []string // lines of an entry that is not complete yet
 	for {
-		fmt.Fprint(out, prompt)
-		if !s.Scan() {
+		current := prompt
+		if len(pending) > 0 {
+			current = continuation
+		}
+		line, err := reader.readLine(current)
+		if errors.Is(err, errInterrupt) {
+			pending = nil
+			continue
+		}
+		if err != nil {
+			if len(pending) > 0 {
+				// Report what is missing from the unfinished entry
+				if _, perr := parse(strings.Join(pending, "\n")); perr != nil {
+					fmt.Fprintln(out)
+					fmt.Fprintln(out, "error:", perr)
+				}
+			}
 			fmt.Fprintln(out)
 			return
 		}
-		line := strings.TrimSpace(s.Text())
-		switch line {
-		case ":quit", ":q":
-			fmt.Fprintln(out, "bye")
-			return
-		case ":help", ":h":
-			fmt.Fprintln(out, "Polyloft REPL commands:")
-			fmt.Fprintln(out, "  :help  Show this help")
-			fmt.Fprintln(out, "  :quit  Exit the REPL")
-			continue
-		case "":
+
+		if len(pending) == 0 {
+			switch strings.TrimSpace(line) {
+			case ":quit", ":q":
+				fmt.Fprintln(out, "bye")
+				return
+			case ":help", ":h":
+				fmt.Fprintln(out, "Polyloft REPL commands:")
+				fmt.Fprintln(out, "  :help  Show this help")
+				fmt.Fprintln(out, "  :quit  Exit the REPL")
+				fmt.Fprintln(out, "Unfinished input continues on the next line; Ctrl-C discards it.")
+				continue
+			case "":
+				continue
+			}
+		}
+
+		pending = append(pending, line)
+		prog, err := parse(strings.Join(pending, "\n"))
+		if incomplete(err) {
 			continue
 		}
-		// Wrap line as a program (expression statement)
-		lx := &lexer.Lexer{}
-		items := lx.Scan([]byte(line))
-		p := parser.NewWithFile(items, "<repl>")
-		prog, err := p.Parse()
+		pending = nil
 		if err != nil {
 			fmt.Fprintln(out, "error:", err)
 			continue
 		}
-		v, err := engine.Eval(prog, engine.Options{Stdout: out})
+		v, err := session.Eval(prog)
 		if err != nil {
 			fmt.Fprintln(out, "error:", err)
 			continue
 		}
-		if v != nil {
-			fmt.Fprintln(out, "=", v)
+		// Echo the value of an expression; definitions and assignments stay quiet
+		if _, isExpr := prog.Stmts[len(prog.Stmts)-1].(*ast.ExprStmt); isExpr && v != nil {
+			fmt.Fprintln(out, "=", session.Format(v))
 		}
 	}
 }
+
+// parse parses one REPL entry
+func parse(src string) (*ast.Program, error) {
+	lx := &lexer.Lexer{}
+	items := lx.Scan([]byte(src))
+	return parser.NewWithFile(items, "<repl>").Parse()
+}
+
+// incomplete reports whether err means the parser ran out of input, e.g. a
+// block still waiting for its `end`, so more lines should be read
+func incomplete(err error) bool {
+	var perr parser.ParseError
+	return errors.As(err, &perr) && perr.Token.Tok == lexer.EOF
+}
@@ -2,14 +2,22 @@ package searcher
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ArubikU/polyloft/internal/auth"
 )
 
+// ErrPackageNotFound is returned when the registry has no package by that name and author
+var ErrPackageNotFound = errors.New("package not found")
+
 // Searcher handles package searching
 type Searcher struct {
 	registryURL string
@@ -21,6 +29,30 @@ type PackageResult struct {
 	Author      string `json:"author"`
 	Version     string `json:"version"`
 	Description string `json:"description"`
+	Downloads   int    `json:"downloads"`
+	UpdatedAt   string `json:"updated_at"` // RFC 3339 time of the latest version
+}
+
+// Sort orders accepted by SearchOptions
+const (
+	SortDownloads = "downloads" // most downloaded first
+	SortRecent    = "recent"    // most recently updated first
+	SortName      = "name"      // alphabetical by name, then author
+)
+
+// SearchOptions narrows and orders a search
+type SearchOptions struct {
+	Author string // only packages published by this author
+	Sort   string // SortDownloads, SortRecent or SortName; "" keeps the registry's relevance order
+	Limit  int    // maximum number of packages returned; 0 returns them all
+}
+
+// SearchResult is one page of search results
+type SearchResult struct {
+	Packages      []PackageResult
+	Total         int    // packages matching the query, which may be more than Packages when Limit cut them
+	Sort          string // the order Packages are in
+	SortedLocally bool   // the registry ignored Sort, so the returned page was sorted here
 }
 
 // New creates a new searcher
@@ -32,8 +64,35 @@ func New() *Searcher {
 
 // Search searches for packages matching the query
 func (s *Searcher) Search(query string) ([]PackageResult, error) {
-	// Build URL with query parameter
-	searchURL := fmt.Sprintf("%s/api/search?q=%s", s.registryURL, url.QueryEscape(query))
+	result, err := s.SearchWithOptions(query, SearchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Packages, nil
+}
+
+// SearchWithOptions searches for packages matching the query, passing the
+// filters and sort order to the registry. Whatever the registry does not apply
+// is applied here to the page it returned.
+func (s *Searcher) SearchWithOptions(query string, opts SearchOptions) (*SearchResult, error) {
+	switch opts.Sort {
+	case "", SortDownloads, SortRecent, SortName:
+	default:
+		return nil, fmt.Errorf("unknown sort %q: use %s, %s or %s", opts.Sort, SortDownloads, SortRecent, SortName)
+	}
+
+	// Build URL with query parameters
+	params := url.Values{"q": {query}}
+	if opts.Author != "" {
+		params.Set("author", opts.Author)
+	}
+	if opts.Sort != "" {
+		params.Set("sort", opts.Sort)
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	searchURL := fmt.Sprintf("%s/api/search?%s", s.registryURL, params.Encode())
 	
 	resp, err := http.Get(searchURL)
 	if err != nil {
@@ -49,11 +108,87 @@ func (s *Searcher) Search(query string) ([]PackageResult, error) {
 	var response struct {
 		Results []PackageResult `json:"results"`
 		Count   int             `json:"count"`
+		Sort    string          `json:"sort"` // the order the registry applied, if it reports one
 	}
 	
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-	
-	return response.Results, nil
+
+	result := &SearchResult{Packages: response.Results, Total: response.Count, Sort: opts.Sort}
+	if opts.Author != "" {
+		filtered := result.Packages[:0]
+		for _, pkg := range result.Packages {
+			if strings.EqualFold(pkg.Author, opts.Author) {
+				filtered = append(filtered, pkg)
+			}
+		}
+		if len(filtered) < len(result.Packages) {
+			// The registry ignored the filter, so its count is for every author
+			result.Total = len(filtered)
+		}
+		result.Packages = filtered
+	}
+	if result.Total < len(result.Packages) {
+		result.Total = len(result.Packages)
+	}
+	if opts.Sort != "" && response.Sort != opts.Sort {
+		sortPackages(result.Packages, opts.Sort)
+		result.SortedLocally = true
+	}
+	if opts.Limit > 0 && len(result.Packages) > opts.Limit {
+		result.Packages = result.Packages[:opts.Limit]
+	}
+	return result, nil
+}
+
+// sortPackages orders packages by one of the Sort constants
+func sortPackages(packages []PackageResult, order string) {
+	sort.SliceStable(packages, func(a, b int) bool {
+		pa, pb := packages[a], packages[b]
+		switch order {
+		case SortDownloads:
+			return pa.Downloads > pb.Downloads
+		case SortRecent:
+			ta, errA := time.Parse(time.RFC3339, pa.UpdatedAt)
+			tb, errB := time.Parse(time.RFC3339, pb.UpdatedAt)
+			if errA != nil || errB != nil {
+				return pa.UpdatedAt > pb.UpdatedAt
+			}
+			return ta.After(tb)
+		}
+		if pa.Name != pb.Name {
+			return pa.Name < pb.Name
+		}
+		return pa.Author < pb.Author
+	})
+}
+
+// Versions lists every published version of a package
+func (s *Searcher) Versions(name, author string) ([]string, error) {
+	versionsURL := fmt.Sprintf("%s/api/packages/%s/%s/versions", s.registryURL, url.PathEscape(author), url.PathEscape(name))
+
+	resp, err := http.Get(versionsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s@%s: %w", name, author, ErrPackageNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("listing versions failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Versions []string `json:"versions"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Versions, nil
 }
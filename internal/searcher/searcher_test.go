@@ -0,0 +1,112 @@
+package searcher
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+var registryPackages = []PackageResult{
+	{Name: "jsonx", Author: "alice", Version: "2.1.0", Downloads: 40, UpdatedAt: "2026-03-01T10:00:00Z"},
+	{Name: "fastjson", Author: "bob", Version: "1.0.0", Downloads: 900, UpdatedAt: "2026-05-01T10:00:00Z"},
+	{Name: "json5", Author: "alice", Version: "0.3.0", Downloads: 300, UpdatedAt: "2025-11-20T10:00:00Z"},
+	{Name: "ajson", Author: "alice", Version: "1.2.0", Downloads: 120, UpdatedAt: "2026-06-15T10:00:00Z"},
+}
+
+// newRegistry serves registryPackages; when honorParams is false it ignores
+// the author, sort and limit parameters like an older registry would
+func newRegistry(t *testing.T, honorParams bool) *url.Values {
+	t.Helper()
+	var last url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r.URL.Query()
+		results := append([]PackageResult(nil), registryPackages...)
+		response := map[string]any{"count": len(results)}
+		if honorParams {
+			var filtered []PackageResult
+			for _, pkg := range results {
+				if author := last.Get("author"); author == "" || pkg.Author == author {
+					filtered = append(filtered, pkg)
+				}
+			}
+			sortPackages(filtered, last.Get("sort"))
+			response["count"] = len(filtered)
+			response["sort"] = last.Get("sort")
+			if len(filtered) > 2 {
+				filtered = filtered[:2]
+			}
+			results = filtered
+		}
+		response["results"] = results
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("POLYLOFT_REGISTRY_URL", server.URL)
+	return &last
+}
+
+func names(packages []PackageResult) []string {
+	var out []string
+	for _, pkg := range packages {
+		out = append(out, pkg.Name)
+	}
+	return out
+}
+
+func TestSearchWithOptions(t *testing.T) {
+	query := newRegistry(t, true)
+	result, err := New().SearchWithOptions("json", SearchOptions{Author: "alice", Sort: SortDownloads, Limit: 2})
+	if err != nil {
+		t.Fatalf("SearchWithOptions: %v", err)
+	}
+	if query.Get("q") != "json" || query.Get("author") != "alice" || query.Get("sort") != "downloads" || query.Get("limit") != "2" {
+		t.Errorf("registry got query %v", *query)
+	}
+	if got := names(result.Packages); len(got) != 2 || got[0] != "json5" || got[1] != "ajson" {
+		t.Errorf("packages = %v", got)
+	}
+	if result.Total != 3 || result.SortedLocally {
+		t.Errorf("total = %d, sorted locally = %v", result.Total, result.SortedLocally)
+	}
+}
+
+func TestSearchWithOptionsFallsBack(t *testing.T) {
+	newRegistry(t, false)
+	s := New()
+
+	result, err := s.SearchWithOptions("json", SearchOptions{Author: "alice", Sort: SortRecent, Limit: 2})
+	if err != nil {
+		t.Fatalf("SearchWithOptions: %v", err)
+	}
+	if got := names(result.Packages); len(got) != 2 || got[0] != "ajson" || got[1] != "jsonx" {
+		t.Errorf("packages = %v", got)
+	}
+	if result.Total != 3 || !result.SortedLocally || result.Sort != SortRecent {
+		t.Errorf("total = %d, sort = %q, sorted locally = %v", result.Total, result.Sort, result.SortedLocally)
+	}
+
+	result, err = s.SearchWithOptions("json", SearchOptions{Sort: SortName})
+	if err != nil {
+		t.Fatalf("SearchWithOptions: %v", err)
+	}
+	if got := names(result.Packages); len(got) != 4 || got[0] != "ajson" || got[3] != "jsonx" {
+		t.Errorf("packages = %v", got)
+	}
+
+	if _, err := s.SearchWithOptions("json", SearchOptions{Sort: "stars"}); err == nil {
+		t.Error("expected an unknown sort to be rejected")
+	}
+}
+
+func TestVersionsNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	t.Setenv("POLYLOFT_REGISTRY_URL", server.URL)
+
+	if _, err := New().Versions("missing", "alice"); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("expected ErrPackageNotFound, got %v", err)
+	}
+}
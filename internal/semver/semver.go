@@ -0,0 +1,338 @@
+// Package semver parses semantic versions and the version ranges dependencies
+// declare in polyloft.toml, such as "^1.2.0", "~1.2.3" or ">=1.0 <2.0".
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version: MAJOR.MINOR.PATCH with an optional
+// pre-release tag. Build metadata is accepted and ignored.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// Parse parses a version such as "1.2.3", "v1.2.3" or "2.0.0-beta.1"
+func Parse(s string) (Version, error) {
+	p, err := parsePartial(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if p.parts < 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", s)
+	}
+	return p.Version, nil
+}
+
+// String renders the version without a leading "v"
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as a is lower than, equal to or higher than b.
+// A pre-release is lower than the release it precedes.
+func Compare(a, b Version) int {
+	for _, d := range [3]int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case a.Prerelease == b.Prerelease:
+		return 0
+	case a.Prerelease == "":
+		return 1
+	case b.Prerelease == "":
+		return -1
+	}
+	return comparePrerelease(a.Prerelease, b.Prerelease)
+}
+
+// comparePrerelease orders dot-separated pre-release tags: numeric identifiers
+// by value and below alphanumeric ones, and a shorter tag before a longer one
+// it prefixes
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for idx := 0; idx < len(as) && idx < len(bs); idx++ {
+		an, aErr := strconv.Atoi(as[idx])
+		bn, bErr := strconv.Atoi(bs[idx])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[idx], bs[idx]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// partial is a version that may leave out its minor and patch numbers or give
+// them as wildcards ("1", "1.2", "1.x", "*"). parts counts the numbers given.
+type partial struct {
+	Version
+	parts int
+}
+
+func parsePartial(s string) (partial, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if idx := strings.Index(text, "+"); idx >= 0 {
+		text = text[:idx]
+	}
+	var p partial
+	if idx := strings.Index(text, "-"); idx >= 0 {
+		p.Prerelease = text[idx+1:]
+		text = text[:idx]
+		if p.Prerelease == "" {
+			return partial{}, fmt.Errorf("invalid version %q: empty pre-release", s)
+		}
+	}
+	if text == "" {
+		return partial{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	fields := strings.Split(text, ".")
+	if len(fields) > 3 {
+		return partial{}, fmt.Errorf("invalid version %q: too many parts", s)
+	}
+	numbers := [3]*int{&p.Major, &p.Minor, &p.Patch}
+	for idx, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			break
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return partial{}, fmt.Errorf("invalid version %q: %q is not a number", s, field)
+		}
+		*numbers[idx] = n
+		p.parts++
+	}
+	if p.Prerelease != "" && p.parts < 3 {
+		return partial{}, fmt.Errorf("invalid version %q: a pre-release needs MAJOR.MINOR.PATCH", s)
+	}
+	return p, nil
+}
+
+// comparator is one bound of a range, such as ">=1.2.0"
+type comparator struct {
+	op      string // "=", ">", ">=", "<" or "<="
+	version Version
+}
+
+func (c comparator) matches(v Version) bool {
+	cmp := Compare(v, c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// Constraint is a version range. Bounds separated by spaces or commas must all
+// hold; alternatives are separated by "||".
+type Constraint struct {
+	text string
+	sets [][]comparator
+}
+
+// ParseConstraint parses a range such as "^1.2.0", "~1.2.3", ">=1.0 <2.0",
+// "1.2.x" or "1.0.0 || ^2.0.0". A plain version matches only itself.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{text: strings.TrimSpace(s)}
+	for _, alternative := range strings.Split(s, "||") {
+		tokens := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+		var set []comparator
+		for idx := 0; idx < len(tokens); idx++ {
+			token := tokens[idx]
+			// Allow a space between an operator and its version: ">= 1.0"
+			if strings.Trim(token, "<>=^~") == "" && idx+1 < len(tokens) {
+				idx++
+				token += tokens[idx]
+			}
+			bounds, err := parseBound(token)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version range %q: %w", s, err)
+			}
+			set = append(set, bounds...)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+// parseBound expands one operator and version into comparators
+func parseBound(token string) ([]comparator, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(token, candidate) {
+			op = candidate
+			break
+		}
+	}
+	rest := token[len(op):]
+	if rest == "*" || rest == "x" || rest == "X" {
+		if op == "" || op == "=" || op == ">=" || op == "<=" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%q has no version", token)
+	}
+	p, err := parsePartial(rest)
+	if err != nil {
+		return nil, err
+	}
+	if p.parts == 0 {
+		return nil, nil
+	}
+	v := p.Version
+
+	// upper is the first version past the numbers the partial gives
+	upper := func(parts int) Version {
+		switch parts {
+		case 1:
+			return Version{Major: v.Major + 1}
+		case 2:
+			return Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+
+	switch op {
+	case "", "=":
+		if p.parts == 3 {
+			return []comparator{{"=", v}}, nil
+		}
+		return []comparator{{">=", v}, {"<", upper(p.parts)}}, nil
+	case "^":
+		// Changes left of the first non-zero number given are breaking
+		parts := 1
+		if v.Major == 0 && p.parts > 1 {
+			parts = 2
+			if v.Minor == 0 && p.parts > 2 {
+				parts = 3
+			}
+		}
+		return []comparator{{">=", v}, {"<", upper(parts)}}, nil
+	case "~":
+		parts := 2
+		if p.parts == 1 {
+			parts = 1
+		}
+		return []comparator{{">=", v}, {"<", upper(parts)}}, nil
+	case ">=":
+		return []comparator{{">=", v}}, nil
+	case ">":
+		if p.parts == 3 {
+			return []comparator{{">", v}}, nil
+		}
+		return []comparator{{">=", upper(p.parts)}}, nil
+	case "<":
+		return []comparator{{"<", v}}, nil
+	case "<=":
+		if p.parts == 3 {
+			return []comparator{{"<=", v}}, nil
+		}
+		return []comparator{{"<", upper(p.parts)}}, nil
+	}
+	return nil, fmt.Errorf("unknown operator in %q", token)
+}
+
+// String returns the range as it was written
+func (c *Constraint) String() string {
+	return c.text
+}
+
+// Exact returns the version a constraint pins, when it is a plain version
+func (c *Constraint) Exact() (Version, bool) {
+	if len(c.sets) == 1 && len(c.sets[0]) == 1 && c.sets[0][0].op == "=" {
+		return c.sets[0][0].version, true
+	}
+	return Version{}, false
+}
+
+// Check reports whether v is in the range. A pre-release only matches a range
+// that names a pre-release of the same MAJOR.MINOR.PATCH, so "^1.2.0" never
+// picks "1.3.0-beta".
+func (c *Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		if setMatches(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func setMatches(set []comparator, v Version) bool {
+	for _, cmp := range set {
+		if !cmp.matches(v) {
+			return false
+		}
+	}
+	if v.Prerelease == "" {
+		return true
+	}
+	for _, cmp := range set {
+		b := cmp.version
+		if b.Prerelease != "" && b.Major == v.Major && b.Minor == v.Minor && b.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxSatisfying returns the highest of versions that every constraint accepts.
+// Entries that are not valid versions are skipped.
+func MaxSatisfying(versions []string, constraints ...*Constraint) (string, bool) {
+	best, found := "", false
+	var bestVersion Version
+	for _, s := range versions {
+		v, err := Parse(s)
+		if err != nil {
+			continue
+		}
+		ok := true
+		for _, c := range constraints {
+			if !c.Check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok && (!found || Compare(v, bestVersion) > 0) {
+			best, bestVersion, found = s, v, true
+		}
+	}
+	return best, found
+}
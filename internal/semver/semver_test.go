@@ -0,0 +1,95 @@
+package semver
+
+import "testing"
+
+func TestParseAndCompare(t *testing.T) {
+	ordered := []string{"0.9.9", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0", "v1.0.1", "1.2.0+build.5", "1.10.0"}
+	for idx := 1; idx < len(ordered); idx++ {
+		a, err := Parse(ordered[idx-1])
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", ordered[idx-1], err)
+		}
+		b, err := Parse(ordered[idx])
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", ordered[idx], err)
+		}
+		if Compare(a, b) != -1 || Compare(b, a) != 1 {
+			t.Errorf("expected %s < %s", a, b)
+		}
+	}
+
+	for _, bad := range []string{"", "1", "1.2", "1.2.3.4", "1.a.3", "1.2.3-"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) should fail", bad)
+		}
+	}
+}
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		reject     []string
+	}{
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4", "1.2.2"}},
+		{"^1.2.0", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0", "1.3.0-beta"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{">=1.0 <2.0", []string{"1.0.0", "1.9.9"}, []string{"0.9.0", "2.0.0"}},
+		{">= 1.0, < 2.0", []string{"1.5.0"}, []string{"2.1.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"1.2.x", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{"*", []string{"0.0.1", "3.0.0"}, []string{"1.0.0-rc.1"}},
+		{"^1.0.0 || ^3.0.0", []string{"1.4.0", "3.1.0"}, []string{"2.0.0"}},
+		{">=2.0.0-beta.1", []string{"2.0.0-beta.2", "2.0.0", "2.1.0"}, []string{"2.0.0-alpha", "2.1.0-beta"}},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+		}
+		for _, s := range tt.match {
+			if v, _ := Parse(s); !c.Check(v) {
+				t.Errorf("%q should match %s", tt.constraint, s)
+			}
+		}
+		for _, s := range tt.reject {
+			if v, _ := Parse(s); c.Check(v) {
+				t.Errorf("%q should not match %s", tt.constraint, s)
+			}
+		}
+	}
+
+	for _, bad := range []string{"^", ">=abc", "1.2.3.4", "~x"} {
+		if _, err := ParseConstraint(bad); err == nil {
+			t.Errorf("ParseConstraint(%q) should fail", bad)
+		}
+	}
+}
+
+func TestMaxSatisfying(t *testing.T) {
+	versions := []string{"1.0.0", "1.4.2", "1.10.0", "2.0.0", "2.1.0-beta", "not-a-version"}
+	caret, _ := ParseConstraint("^1.2")
+	upper, _ := ParseConstraint("<1.5")
+	if got, ok := MaxSatisfying(versions, caret); !ok || got != "1.10.0" {
+		t.Errorf("MaxSatisfying(^1.2) = %q, %v", got, ok)
+	}
+	if got, ok := MaxSatisfying(versions, caret, upper); !ok || got != "1.4.2" {
+		t.Errorf("MaxSatisfying(^1.2, <1.5) = %q, %v", got, ok)
+	}
+	major, _ := ParseConstraint("^3.0.0")
+	if got, ok := MaxSatisfying(versions, major); ok {
+		t.Errorf("expected no match, got %q", got)
+	}
+
+	exact, _ := ParseConstraint("v1.4.2")
+	if v, ok := exact.Exact(); !ok || v.String() != "1.4.2" {
+		t.Errorf("Exact() = %v, %v", v, ok)
+	}
+	if _, ok := caret.Exact(); ok {
+		t.Error("a range should not be exact")
+	}
+}
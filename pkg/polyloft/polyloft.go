@@ -0,0 +1,179 @@
+// Package polyloft embeds the Polyloft interpreter in Go programs.
+//
+//	interp := polyloft.New(polyloft.Options{Stdout: &out, Timeout: time.Second})
+//	interp.RegisterFunc("lookup", func(args []any) (any, error) {
+//		return db.Get(args[0].(string))
+//	})
+//	result, err := interp.Run(`lookup("answer") + 1`)
+//
+// Values cross the boundary as plain Go values: Int is int, Float is float64,
+// String is string, Bool is bool, Bytes is []byte, Arrays are []any and Maps are
+// map[string]any. Registered Types map to their Go values. Other values (objects,
+// functions) are passed through opaquely.
+package polyloft
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ArubikU/polyloft/internal/engine"
+	"github.com/ArubikU/polyloft/internal/lexer"
+	"github.com/ArubikU/polyloft/internal/parser"
+)
+
+// Options configures an Interpreter
+type Options struct {
+	Stdout io.Writer // where print and println write; os.Stdout when nil
+	Stdin  io.Reader // where input reads from; os.Stdin when nil
+	Args   []string  // returned by Sys.args
+
+	// Limits for each Run; zero is unlimited. A run that exceeds them stops with
+	// an error that scripts cannot catch.
+	MaxSteps int64         // statements executed, counting every loop iteration
+	Timeout  time.Duration // wall-clock time, checked between statements
+	MaxDepth int64         // nested calls of script functions, lambdas and methods
+
+	// Resource caps for each Run; zero is unlimited. Exceeding one raises a
+	// ResourceError that scripts can catch.
+	MaxHandles int64 // files and sockets open at the same time
+	MaxMemory  int64 // soft ceiling on live heap bytes of the whole process, host included
+
+	// Sandbox runs untrusted code: IO, Process, Net, Http and sockets are not
+	// available and the limits always apply; those left zero default to 50,000,000
+	// steps, 30 seconds and 2,000 nested calls.
+	Sandbox bool
+
+	// Deterministic iterates, prints and serializes Maps and Sets in sorted key
+	// order instead of insertion order, so output can be compared across runs.
+	Deterministic bool
+}
+
+// Func is a Go function callable from scripts. It receives the arguments as
+// plain Go values; its result is converted back and a returned error is raised
+// in the script as a RuntimeError.
+type Func func(args []any) (any, error)
+
+// Type exposes a Go type to scripts as a class named Name. Scripts create
+// instances by calling the class, which calls New with the arguments; each method
+// receives the instance's Go value as self. Values of the type returned from
+// functions, methods or Set become instances too, and instances passed back to Go
+// give their Go value.
+type Type struct {
+	Name    string
+	Sample  any                                                // a value of the Go type, e.g. (*Conn)(nil)
+	New     func(args []any) (any, error)                      // nil when scripts cannot create instances
+	Methods map[string]func(self any, args []any) (any, error) // script-visible methods
+}
+
+// Interpreter runs Polyloft code. Variables, functions and classes defined by one
+// Run stay visible to the next.
+type Interpreter struct {
+	session *engine.Session
+}
+
+// runMu serializes New and Run: classes, interfaces and enums are registered
+// process-wide. Options such as limits and Deterministic belong to each
+// Interpreter, so threads a Run leaves behind keep them while another Run goes on.
+var runMu sync.Mutex
+
+// New creates an Interpreter with all builtins installed
+func New(opts Options) *Interpreter {
+	engineOpts := engine.Options{Stdout: opts.Stdout, Stdin: opts.Stdin, Args: opts.Args, Sandbox: opts.Sandbox, Deterministic: opts.Deterministic}
+	if engineOpts.Stdout == nil {
+		engineOpts.Stdout = os.Stdout
+	}
+	if engineOpts.Stdin == nil {
+		engineOpts.Stdin = os.Stdin
+	}
+	if opts.MaxSteps > 0 || opts.Timeout > 0 || opts.MaxDepth > 0 || opts.MaxHandles > 0 || opts.MaxMemory > 0 {
+		engineOpts.Limits = &engine.Limits{
+			MaxSteps:   opts.MaxSteps,
+			Timeout:    opts.Timeout,
+			MaxDepth:   opts.MaxDepth,
+			MaxHandles: opts.MaxHandles,
+			MaxMemory:  opts.MaxMemory,
+		}
+	}
+	runMu.Lock()
+	defer runMu.Unlock()
+	return &Interpreter{session: engine.NewSession(engineOpts)}
+}
+
+// Run executes source and returns the value of its last statement, or of the
+// first top-level return
+func (in *Interpreter) Run(source string) (any, error) {
+	return in.run(source, "<source>", ".")
+}
+
+// RunFile executes the source file at path. Relative imports resolve from its
+// directory.
+func (in *Interpreter) RunFile(path string) (any, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return in.run(string(source), path, filepath.Dir(path))
+}
+
+func (in *Interpreter) run(source, fileName, packageName string) (any, error) {
+	lx := &lexer.Lexer{}
+	prog, err := parser.NewWithSource(lx.Scan([]byte(source)), fileName, source).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	runMu.Lock()
+	defer runMu.Unlock()
+	result, err := in.session.EvalSource(prog, fileName, packageName, source)
+	if err != nil {
+		return nil, err
+	}
+	return engine.NativeValue(result), nil
+}
+
+// RegisterFunc makes fn callable from scripts as name
+func (in *Interpreter) RegisterFunc(name string, fn Func) {
+	in.session.Define(name, engine.NativeFunc(name, fn))
+}
+
+// RegisterType makes t available to scripts as a class. A Go type can be
+// registered under one class name only.
+func (in *Interpreter) RegisterType(t Type) error {
+	return in.session.DefineType(engine.NativeType{Name: t.Name, Sample: t.Sample, New: t.New, Methods: t.Methods})
+}
+
+// Set binds a global variable for scripts, converting a Go value
+func (in *Interpreter) Set(name string, value any) {
+	in.session.Define(name, value)
+}
+
+// Get returns the value of a global variable as a Go value
+func (in *Interpreter) Get(name string) (any, bool) {
+	value, ok := in.session.Lookup(name)
+	if !ok {
+		return nil, false
+	}
+	return engine.NativeValue(value), true
+}
+
+// Eval runs source in a new Interpreter
+func Eval(source string, opts Options) (any, error) {
+	return New(opts).Run(source)
+}
+
+// IsLimitError reports whether err stopped a run because it exceeded MaxSteps,
+// Timeout or MaxDepth
+func IsLimitError(err error) bool {
+	var limitErr *engine.LimitError
+	return errors.As(err, &limitErr)
+}
+
+// FormatError renders an error from Run the way the polyloft command prints it,
+// with the failing source line and hints, without terminal colors
+func FormatError(err error) string {
+	return engine.FormatErrorPlain(err)
+}
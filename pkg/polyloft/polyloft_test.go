@@ -0,0 +1,160 @@
+package polyloft
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitStable waits until counter stops changing and returns its value
+func waitStable(t *testing.T, counter *atomic.Int64) int64 {
+	t.Helper()
+	last := counter.Load()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		time.Sleep(50 * time.Millisecond)
+		current := counter.Load()
+		if current == last {
+			return current
+		}
+		last = current
+	}
+	t.Fatalf("background loop still running after 5s (%d ticks)", last)
+	return 0
+}
+
+func TestInterpreters_KeepTheirOwnOptions(t *testing.T) {
+	var outA, outB bytes.Buffer
+	a := New(Options{Stdout: &outA, Deterministic: true})
+	b := New(Options{Stdout: &outB})
+
+	gate, done := make(chan struct{}), make(chan struct{})
+	a.RegisterFunc("wait", func(args []any) (any, error) {
+		<-gate
+		return nil, nil
+	})
+	a.RegisterFunc("done", func(args []any) (any, error) {
+		close(done)
+		return nil, nil
+	})
+
+	// A's thread is still running while B runs with other options
+	if _, err := a.Run(`thread spawn do
+    wait()
+    println({"b": 1, "a": 2})
+    done()
+end`); err != nil {
+		t.Fatalf("run A: %v", err)
+	}
+	if _, err := b.Run(`println({"b": 1, "a": 2})`); err != nil {
+		t.Fatalf("run B: %v", err)
+	}
+	close(gate)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("thread of A did not finish")
+	}
+
+	if got := outA.String(); got != "{a: 2, b: 1}\n" {
+		t.Errorf("A (Deterministic) printed %q", got)
+	}
+	if got := outB.String(); got != "{b: 1, a: 2}\n" {
+		t.Errorf("B printed %q", got)
+	}
+}
+
+func TestInterpreters_RunWhileAnotherRuns(t *testing.T) {
+	var ticks atomic.Int64
+	a := New(Options{Stdout: &bytes.Buffer{}, MaxSteps: 500})
+	a.RegisterFunc("tick", func(args []any) (any, error) {
+		ticks.Add(1)
+		return nil, nil
+	})
+	if _, err := a.Run(`thread spawn do
+    loop:
+        tick()
+    end
+end`); err != nil {
+		t.Fatalf("run A: %v", err)
+	}
+
+	// A's limits belong to A: B runs far past A's budget
+	var out bytes.Buffer
+	b := New(Options{Stdout: &out})
+	if _, err := b.Run(`let n = 0
+loop n < 2000: n = n + 1
+println(n)`); err != nil {
+		t.Fatalf("run B: %v", err)
+	}
+	if out.String() != "2000\n" {
+		t.Errorf("B printed %q", out.String())
+	}
+
+	if n := waitStable(t, &ticks); n == 0 || n >= 500 {
+		t.Errorf("thread of A ticked %d times, want between 1 and 500", n)
+	}
+}
+
+func TestLimits_StopSpawnedLoopAfterRun(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"MaxSteps", Options{MaxSteps: 1000}},
+		{"Timeout", Options{Timeout: 100 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ticks atomic.Int64
+			tt.opts.Stdout = &bytes.Buffer{}
+			in := New(tt.opts)
+			in.RegisterFunc("tick", func(args []any) (any, error) {
+				ticks.Add(1)
+				return nil, nil
+			})
+			if _, err := in.Run(`thread spawn do
+    loop:
+        tick()
+    end
+end`); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if n := waitStable(t, &ticks); n == 0 {
+				t.Errorf("spawned loop never ran")
+			}
+		})
+	}
+}
+
+func TestLimits_LaterRunsKeepSpawnedBudget(t *testing.T) {
+	var ticks atomic.Int64
+	in := New(Options{Stdout: &bytes.Buffer{}, Timeout: 300 * time.Millisecond})
+	in.RegisterFunc("tick", func(args []any) (any, error) {
+		ticks.Add(1)
+		return nil, nil
+	})
+	if _, err := in.Run(`thread spawn do
+    def work():
+        tick()
+    end
+    loop:
+        work()
+    end
+end`); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	// Each Run gets a new budget; the thread keeps the one of the Run that spawned it
+	for i := 0; i < 5; i++ {
+		time.Sleep(250 * time.Millisecond)
+		if _, err := in.Run("1"); err != nil {
+			t.Fatalf("run %d: %v", i+2, err)
+		}
+	}
+	before := ticks.Load()
+	time.Sleep(200 * time.Millisecond)
+	if after := ticks.Load(); after != before {
+		t.Errorf("thread still running 1.45s into a 300ms budget (%d ticks, then %d)", before, after)
+	}
+}
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 
 	"github.com/ArubikU/polyloft/internal/engine"
@@ -11,6 +12,27 @@ import (
 
 // ExecuteSource compiles and executes Polyloft source code
 func ExecuteSource(source, filename string) error {
+	return ExecuteSourceWithAssets(source, filename, nil)
+}
+
+// ExecuteSourceWithAssets compiles and executes Polyloft source code whose
+// Assets module reads from assets instead of the working directory
+func ExecuteSourceWithAssets(source, filename string, assets fs.FS) error {
+	return Run(Artifact{Source: source, EntryPoint: filename, Assets: assets})
+}
+
+// Artifact is a program built by polyloft build
+type Artifact struct {
+	Source     string            // source of the entry point
+	EntryPoint string            // path of the entry point within the project
+	Modules    map[string]string // source of the imported modules, by engine.BundleKey; nil reads imports from disk
+	Assets     fs.FS             // embedded asset files; nil reads assets from the working directory
+}
+
+// Run compiles and executes a built program
+func Run(a Artifact) error {
+	source, filename := a.Source, a.EntryPoint
+
 	// Tokenize
 	lx := &lexer.Lexer{}
 	items := lx.Scan([]byte(source))
@@ -25,7 +47,7 @@ func ExecuteSource(source, filename string) error {
 	}
 	
 	// Execute
-	_, err = engine.EvalWithContextAndSource(prog, engine.Options{Stdout: os.Stdout}, filename, ".", source)
+	_, err = engine.EvalWithContextAndSource(prog, engine.Options{Stdout: os.Stdout, Stdin: os.Stdin, Args: os.Args[1:], Assets: a.Assets, Modules: a.Modules}, filename, ".", source)
 	if err != nil {
 		formattedErr := engine.FormatError(err)
 		fmt.Fprint(os.Stderr, formattedErr)